@@ -26,7 +26,19 @@ const (
 // 转换请求
 type ConvertRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Html          string                 `protobuf:"bytes,1,opt,name=html,proto3" json:"html,omitempty"` // HTML内容
+	Html          string                 `protobuf:"bytes,1,opt,name=html,proto3" json:"html,omitempty"`                                   // HTML内容
+	Rules         []*Rule                `protobuf:"bytes,2,rep,name=rules,proto3" json:"rules,omitempty"`                                 // 自定义转换规则，优先于服务端配置的规则
+	Plugins       []string               `protobuf:"bytes,3,rep,name=plugins,proto3" json:"plugins,omitempty"`                             // 在默认插件之外额外启用的插件，包括WebAssembly插件
+	Profiles      []string               `protobuf:"bytes,4,rep,name=profiles,proto3" json:"profiles,omitempty"`                           // 启用的预处理配置，如 email
+	Email         *EmailOptions          `protobuf:"bytes,5,opt,name=email,proto3" json:"email,omitempty"`                                 // email预处理配置的选项，设置时自动启用该配置
+	InferStyles   bool                   `protobuf:"varint,6,opt,name=infer_styles,json=inferStyles,proto3" json:"infer_styles,omitempty"` // 按内联样式推断加粗、斜体、代码和标题，并删除隐藏元素
+	Chunking      *ChunkingOptions       `protobuf:"bytes,7,opt,name=chunking,proto3" json:"chunking,omitempty"`                           // 按标题层级将结果切分为块
+	Tokenizer     string                 `protobuf:"bytes,8,opt,name=tokenizer,proto3" json:"tokenizer,omitempty"`                         // 计算token数的分词器，为空时使用默认分词器
+	MaxTokens     int32                  `protobuf:"varint,9,opt,name=max_tokens,json=maxTokens,proto3" json:"max_tokens,omitempty"`       // 结果的最大token数，超出时在块边界截断，为0时不限制
+	Toc           string                 `protobuf:"bytes,10,opt,name=toc,proto3" json:"toc,omitempty"`                                    // 插入目录的位置: top, placeholder（替换 [TOC] 占位行），为空时不插入
+	Inventory     bool                   `protobuf:"varint,11,opt,name=inventory,proto3" json:"inventory,omitempty"`                       // 在响应中返回链接和图片清单
+	Url           string                 `protobuf:"bytes,12,opt,name=url,proto3" json:"url,omitempty"`                                    // 抓取并转换该页面，与 html 二选一
+	BaseUrl       string                 `protobuf:"bytes,13,opt,name=base_url,json=baseUrl,proto3" json:"base_url,omitempty"`             // 补全相对链接和图片地址的基准URL，使用 url 时默认为页面的最终地址
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -68,30 +80,1453 @@ func (x *ConvertRequest) GetHtml() string {
 	return ""
 }
 
+func (x *ConvertRequest) GetRules() []*Rule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *ConvertRequest) GetPlugins() []string {
+	if x != nil {
+		return x.Plugins
+	}
+	return nil
+}
+
+func (x *ConvertRequest) GetProfiles() []string {
+	if x != nil {
+		return x.Profiles
+	}
+	return nil
+}
+
+func (x *ConvertRequest) GetEmail() *EmailOptions {
+	if x != nil {
+		return x.Email
+	}
+	return nil
+}
+
+func (x *ConvertRequest) GetInferStyles() bool {
+	if x != nil {
+		return x.InferStyles
+	}
+	return false
+}
+
+func (x *ConvertRequest) GetChunking() *ChunkingOptions {
+	if x != nil {
+		return x.Chunking
+	}
+	return nil
+}
+
+func (x *ConvertRequest) GetTokenizer() string {
+	if x != nil {
+		return x.Tokenizer
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetMaxTokens() int32 {
+	if x != nil {
+		return x.MaxTokens
+	}
+	return 0
+}
+
+func (x *ConvertRequest) GetToc() string {
+	if x != nil {
+		return x.Toc
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetInventory() bool {
+	if x != nil {
+		return x.Inventory
+	}
+	return false
+}
+
+func (x *ConvertRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ConvertRequest) GetBaseUrl() string {
+	if x != nil {
+		return x.BaseUrl
+	}
+	return ""
+}
+
+// 分块选项
+type ChunkingOptions struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Unit          string                 `protobuf:"bytes,1,opt,name=unit,proto3" json:"unit,omitempty"`                                // 计量单位: chars（默认）, tokens
+	TargetSize    int32                  `protobuf:"varint,2,opt,name=target_size,json=targetSize,proto3" json:"target_size,omitempty"` // 目标大小，相邻内容合并到不超过该大小，为0时等于最大大小
+	MaxSize       int32                  `protobuf:"varint,3,opt,name=max_size,json=maxSize,proto3" json:"max_size,omitempty"`          // 最大大小，为0时等于目标大小
+	Overlap       int32                  `protobuf:"varint,4,opt,name=overlap,proto3" json:"overlap,omitempty"`                         // 同一小节内相邻块的重叠大小
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChunkingOptions) Reset() {
+	*x = ChunkingOptions{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChunkingOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChunkingOptions) ProtoMessage() {}
+
+func (x *ChunkingOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChunkingOptions.ProtoReflect.Descriptor instead.
+func (*ChunkingOptions) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ChunkingOptions) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *ChunkingOptions) GetTargetSize() int32 {
+	if x != nil {
+		return x.TargetSize
+	}
+	return 0
+}
+
+func (x *ChunkingOptions) GetMaxSize() int32 {
+	if x != nil {
+		return x.MaxSize
+	}
+	return 0
+}
+
+func (x *ChunkingOptions) GetOverlap() int32 {
+	if x != nil {
+		return x.Overlap
+	}
+	return 0
+}
+
+// email预处理配置的选项
+type EmailOptions struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Quotes         string                 `protobuf:"bytes,1,opt,name=quotes,proto3" json:"quotes,omitempty"`                                        // 引用历史: keep（默认）, collapse, strip
+	StripSignature bool                   `protobuf:"varint,2,opt,name=strip_signature,json=stripSignature,proto3" json:"strip_signature,omitempty"` // 删除签名
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *EmailOptions) Reset() {
+	*x = EmailOptions{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmailOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmailOptions) ProtoMessage() {}
+
+func (x *EmailOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmailOptions.ProtoReflect.Descriptor instead.
+func (*EmailOptions) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *EmailOptions) GetQuotes() string {
+	if x != nil {
+		return x.Quotes
+	}
+	return ""
+}
+
+func (x *EmailOptions) GetStripSignature() bool {
+	if x != nil {
+		return x.StripSignature
+	}
+	return false
+}
+
+// 自定义转换规则
+type Rule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Selector      string                 `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"` // CSS选择器
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`     // 动作: drop, keep, unwrap, template
+	Template      string                 `protobuf:"bytes,3,opt,name=template,proto3" json:"template,omitempty"` // 动作为template时使用的text/template模板
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Rule) Reset() {
+	*x = Rule{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rule) ProtoMessage() {}
+
+func (x *Rule) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rule.ProtoReflect.Descriptor instead.
+func (*Rule) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Rule) GetSelector() string {
+	if x != nil {
+		return x.Selector
+	}
+	return ""
+}
+
+func (x *Rule) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *Rule) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
 // 转换响应
 type ConvertResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Markdown      string                 `protobuf:"bytes,1,opt,name=markdown,proto3" json:"markdown,omitempty"`   // 转换后的Markdown内容
+	Stats         *ConversionStats       `protobuf:"bytes,2,opt,name=stats,proto3" json:"stats,omitempty"`         // 转换统计信息
+	Chunks        []*Chunk               `protobuf:"bytes,3,rep,name=chunks,proto3" json:"chunks,omitempty"`       // 按请求的分块选项切分的块
+	Outline       []*OutlineHeading      `protobuf:"bytes,4,rep,name=outline,proto3" json:"outline,omitempty"`     // 标题大纲，偏移相对于返回的Markdown
+	Inventory     *Inventory             `protobuf:"bytes,5,opt,name=inventory,proto3" json:"inventory,omitempty"` // 链接和图片清单，请求设置 inventory 时返回
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertResponse) Reset() {
+	*x = ConvertResponse{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertResponse) ProtoMessage() {}
+
+func (x *ConvertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertResponse.ProtoReflect.Descriptor instead.
+func (*ConvertResponse) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ConvertResponse) GetMarkdown() string {
+	if x != nil {
+		return x.Markdown
+	}
+	return ""
+}
+
+func (x *ConvertResponse) GetStats() *ConversionStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *ConvertResponse) GetChunks() []*Chunk {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+func (x *ConvertResponse) GetOutline() []*OutlineHeading {
+	if x != nil {
+		return x.Outline
+	}
+	return nil
+}
+
+func (x *ConvertResponse) GetInventory() *Inventory {
+	if x != nil {
+		return x.Inventory
+	}
+	return nil
+}
+
+// 链接和图片清单，按在文档中出现的顺序排列
+type Inventory struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Links         []*Link                `protobuf:"bytes,1,rep,name=links,proto3" json:"links,omitempty"`   // 链接
+	Images        []*Image               `protobuf:"bytes,2,rep,name=images,proto3" json:"images,omitempty"` // 图片
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Inventory) Reset() {
+	*x = Inventory{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Inventory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Inventory) ProtoMessage() {}
+
+func (x *Inventory) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Inventory.ProtoReflect.Descriptor instead.
+func (*Inventory) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Inventory) GetLinks() []*Link {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *Inventory) GetImages() []*Image {
+	if x != nil {
+		return x.Images
+	}
+	return nil
+}
+
+// 转换结果中的链接
+type Link struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`          // 链接文本
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`            // 链接地址
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`        // title属性
+	Internal      bool                   `protobuf:"varint,4,opt,name=internal,proto3" json:"internal,omitempty"` // 是否为站内链接：相对地址、页内锚点或与域名相同的主机
+	Fragment      string                 `protobuf:"bytes,5,opt,name=fragment,proto3" json:"fragment,omitempty"`  // 地址中 # 之后的部分
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Link) Reset() {
+	*x = Link{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Link) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Link) ProtoMessage() {}
+
+func (x *Link) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Link.ProtoReflect.Descriptor instead.
+func (*Link) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Link) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Link) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Link) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Link) GetInternal() bool {
+	if x != nil {
+		return x.Internal
+	}
+	return false
+}
+
+func (x *Link) GetFragment() string {
+	if x != nil {
+		return x.Fragment
+	}
+	return ""
+}
+
+// 转换结果中的图片
+type Image struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Alt           string                 `protobuf:"bytes,1,opt,name=alt,proto3" json:"alt,omitempty"`                         // 替代文本
+	Src           string                 `protobuf:"bytes,2,opt,name=src,proto3" json:"src,omitempty"`                         // 图片地址
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`                     // title属性
+	Width         int32                  `protobuf:"varint,4,opt,name=width,proto3" json:"width,omitempty"`                    // width属性中的像素宽度，未设置或不是像素值时为0
+	Height        int32                  `protobuf:"varint,5,opt,name=height,proto3" json:"height,omitempty"`                  // height属性中的像素高度
+	DataUri       bool                   `protobuf:"varint,6,opt,name=data_uri,json=dataUri,proto3" json:"data_uri,omitempty"` // 是否为内嵌的data URI
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Image) Reset() {
+	*x = Image{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Image) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Image) ProtoMessage() {}
+
+func (x *Image) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Image.ProtoReflect.Descriptor instead.
+func (*Image) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Image) GetAlt() string {
+	if x != nil {
+		return x.Alt
+	}
+	return ""
+}
+
+func (x *Image) GetSrc() string {
+	if x != nil {
+		return x.Src
+	}
+	return ""
+}
+
+func (x *Image) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Image) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Image) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Image) GetDataUri() bool {
+	if x != nil {
+		return x.DataUri
+	}
+	return false
+}
+
+// 文档大纲中的标题
+type OutlineHeading struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Level         int32                  `protobuf:"varint,1,opt,name=level,proto3" json:"level,omitempty"`      // 标题级别
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`         // 去除Markdown标记的标题文本
+	Slug          string                 `protobuf:"bytes,3,opt,name=slug,proto3" json:"slug,omitempty"`         // 锚点，重复的标题依次追加 -1、-2 后缀
+	Offset        int32                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`    // 标题行在Markdown中的字符偏移
+	Children      []*OutlineHeading      `protobuf:"bytes,5,rep,name=children,proto3" json:"children,omitempty"` // 下级标题
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutlineHeading) Reset() {
+	*x = OutlineHeading{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutlineHeading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutlineHeading) ProtoMessage() {}
+
+func (x *OutlineHeading) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutlineHeading.ProtoReflect.Descriptor instead.
+func (*OutlineHeading) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *OutlineHeading) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *OutlineHeading) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *OutlineHeading) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *OutlineHeading) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *OutlineHeading) GetChildren() []*OutlineHeading {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+// Markdown中的一块
+type Chunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`      // 序号
+	Headings      []string               `protobuf:"bytes,2,rep,name=headings,proto3" json:"headings,omitempty"` // 所在的标题路径，由上级到下级
+	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`   // 内容
+	Start         int32                  `protobuf:"varint,4,opt,name=start,proto3" json:"start,omitempty"`      // 在Markdown中的起始字节偏移，包括重叠部分
+	End           int32                  `protobuf:"varint,5,opt,name=end,proto3" json:"end,omitempty"`          // 在Markdown中的结束字节偏移（不含）
+	Bytes         int32                  `protobuf:"varint,6,opt,name=bytes,proto3" json:"bytes,omitempty"`      // 字节数
+	Chars         int32                  `protobuf:"varint,7,opt,name=chars,proto3" json:"chars,omitempty"`      // 字符数
+	Tokens        int32                  `protobuf:"varint,8,opt,name=tokens,proto3" json:"tokens,omitempty"`    // token数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Chunk) Reset() {
+	*x = Chunk{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Chunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Chunk) ProtoMessage() {}
+
+func (x *Chunk) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Chunk.ProtoReflect.Descriptor instead.
+func (*Chunk) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Chunk) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *Chunk) GetHeadings() []string {
+	if x != nil {
+		return x.Headings
+	}
+	return nil
+}
+
+func (x *Chunk) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Chunk) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Chunk) GetEnd() int32 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *Chunk) GetBytes() int32 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *Chunk) GetChars() int32 {
+	if x != nil {
+		return x.Chars
+	}
+	return 0
+}
+
+func (x *Chunk) GetTokens() int32 {
+	if x != nil {
+		return x.Tokens
+	}
+	return 0
+}
+
+// 转换统计信息
+type ConversionStats struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	InputSize          int32                  `protobuf:"varint,1,opt,name=input_size,json=inputSize,proto3" json:"input_size,omitempty"`                              // 输入HTML大小（字节）
+	OutputSize         int32                  `protobuf:"varint,2,opt,name=output_size,json=outputSize,proto3" json:"output_size,omitempty"`                           // 输出Markdown大小（字节）
+	ProcessingTime     *durationpb.Duration   `protobuf:"bytes,3,opt,name=processing_time,json=processingTime,proto3" json:"processing_time,omitempty"`                // 处理时间
+	Tokens             int32                  `protobuf:"varint,4,opt,name=tokens,proto3" json:"tokens,omitempty"`                                                     // 输出Markdown的token数
+	Tokenizer          string                 `protobuf:"bytes,5,opt,name=tokenizer,proto3" json:"tokenizer,omitempty"`                                                // 计算token数的分词器
+	Truncated          bool                   `protobuf:"varint,6,opt,name=truncated,proto3" json:"truncated,omitempty"`                                               // 是否因 max_tokens 被截断
+	TruncatedBytes     int32                  `protobuf:"varint,7,opt,name=truncated_bytes,json=truncatedBytes,proto3" json:"truncated_bytes,omitempty"`               // 截去的字节数
+	TruncatedTokens    int32                  `protobuf:"varint,8,opt,name=truncated_tokens,json=truncatedTokens,proto3" json:"truncated_tokens,omitempty"`            // 截去部分的token数
+	Links              int32                  `protobuf:"varint,9,opt,name=links,proto3" json:"links,omitempty"`                                                       // 链接数
+	Images             int32                  `protobuf:"varint,10,opt,name=images,proto3" json:"images,omitempty"`                                                    // 图片数
+	Words              int32                  `protobuf:"varint,11,opt,name=words,proto3" json:"words,omitempty"`                                                      // 单词数，中日文每个字计为一个单词，不含代码块
+	Chars              int32                  `protobuf:"varint,12,opt,name=chars,proto3" json:"chars,omitempty"`                                                      // 字符数，不含空白、Markdown标记和代码块
+	ReadingSeconds     int32                  `protobuf:"varint,13,opt,name=reading_seconds,json=readingSeconds,proto3" json:"reading_seconds,omitempty"`              // 预计阅读时间（秒）
+	Paragraphs         int32                  `protobuf:"varint,14,opt,name=paragraphs,proto3" json:"paragraphs,omitempty"`                                            // 段落数，不含标题、列表、代码块和表格
+	Headings           int32                  `protobuf:"varint,15,opt,name=headings,proto3" json:"headings,omitempty"`                                                // 标题数
+	CodeBlocks         int32                  `protobuf:"varint,16,opt,name=code_blocks,json=codeBlocks,proto3" json:"code_blocks,omitempty"`                          // 代码块数
+	Language           string                 `protobuf:"bytes,17,opt,name=language,proto3" json:"language,omitempty"`                                                 // 正文的语言（ISO 639-1），无法识别时为空
+	LanguageConfidence float64                `protobuf:"fixed64,18,opt,name=language_confidence,json=languageConfidence,proto3" json:"language_confidence,omitempty"` // 语言识别的置信度，0到1之间
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ConversionStats) Reset() {
+	*x = ConversionStats{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversionStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversionStats) ProtoMessage() {}
+
+func (x *ConversionStats) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversionStats.ProtoReflect.Descriptor instead.
+func (*ConversionStats) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ConversionStats) GetInputSize() int32 {
+	if x != nil {
+		return x.InputSize
+	}
+	return 0
+}
+
+func (x *ConversionStats) GetOutputSize() int32 {
+	if x != nil {
+		return x.OutputSize
+	}
+	return 0
+}
+
+func (x *ConversionStats) GetProcessingTime() *durationpb.Duration {
+	if x != nil {
+		return x.ProcessingTime
+	}
+	return nil
+}
+
+func (x *ConversionStats) GetTokens() int32 {
+	if x != nil {
+		return x.Tokens
+	}
+	return 0
+}
+
+func (x *ConversionStats) GetTokenizer() string {
+	if x != nil {
+		return x.Tokenizer
+	}
+	return ""
+}
+
+func (x *ConversionStats) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+func (x *ConversionStats) GetTruncatedBytes() int32 {
+	if x != nil {
+		return x.TruncatedBytes
+	}
+	return 0
+}
+
+func (x *ConversionStats) GetTruncatedTokens() int32 {
+	if x != nil {
+		return x.TruncatedTokens
+	}
+	return 0
+}
+
+func (x *ConversionStats) GetLinks() int32 {
+	if x != nil {
+		return x.Links
+	}
+	return 0
+}
+
+func (x *ConversionStats) GetImages() int32 {
+	if x != nil {
+		return x.Images
+	}
+	return 0
+}
+
+func (x *ConversionStats) GetWords() int32 {
+	if x != nil {
+		return x.Words
+	}
+	return 0
+}
+
+func (x *ConversionStats) GetChars() int32 {
+	if x != nil {
+		return x.Chars
+	}
+	return 0
+}
+
+func (x *ConversionStats) GetReadingSeconds() int32 {
+	if x != nil {
+		return x.ReadingSeconds
+	}
+	return 0
+}
+
+func (x *ConversionStats) GetParagraphs() int32 {
+	if x != nil {
+		return x.Paragraphs
+	}
+	return 0
+}
+
+func (x *ConversionStats) GetHeadings() int32 {
+	if x != nil {
+		return x.Headings
+	}
+	return 0
+}
+
+func (x *ConversionStats) GetCodeBlocks() int32 {
+	if x != nil {
+		return x.CodeBlocks
+	}
+	return 0
+}
+
+func (x *ConversionStats) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *ConversionStats) GetLanguageConfidence() float64 {
+	if x != nil {
+		return x.LanguageConfidence
+	}
+	return 0
+}
+
+// 批量转换请求
+type BatchConvertRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*ConvertRequest      `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // 批量转换项目
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchConvertRequest) Reset() {
+	*x = BatchConvertRequest{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchConvertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchConvertRequest) ProtoMessage() {}
+
+func (x *BatchConvertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchConvertRequest.ProtoReflect.Descriptor instead.
+func (*BatchConvertRequest) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *BatchConvertRequest) GetItems() []*ConvertRequest {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// 批量转换响应
+type BatchConvertResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*BatchConvertItem    `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // 批量转换结果
+	Summary       *BatchSummary          `protobuf:"bytes,2,opt,name=summary,proto3" json:"summary,omitempty"` // 批量转换摘要
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchConvertResponse) Reset() {
+	*x = BatchConvertResponse{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchConvertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchConvertResponse) ProtoMessage() {}
+
+func (x *BatchConvertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchConvertResponse.ProtoReflect.Descriptor instead.
+func (*BatchConvertResponse) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *BatchConvertResponse) GetResults() []*BatchConvertItem {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *BatchConvertResponse) GetSummary() *BatchSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+// 批量转换项目结果
+type BatchConvertItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`     // 项目索引
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"` // 是否成功
+	Result        *ConvertResponse       `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`    // 转换结果（成功时）
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`      // 错误信息（失败时）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchConvertItem) Reset() {
+	*x = BatchConvertItem{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchConvertItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchConvertItem) ProtoMessage() {}
+
+func (x *BatchConvertItem) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchConvertItem.ProtoReflect.Descriptor instead.
+func (*BatchConvertItem) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *BatchConvertItem) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BatchConvertItem) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *BatchConvertItem) GetResult() *ConvertResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *BatchConvertItem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// 批量转换摘要
+type BatchSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         int32                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`                               // 总数
+	Success       int32                  `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`                           // 成功数
+	Failed        int32                  `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`                             // 失败数
+	TotalTime     *durationpb.Duration   `protobuf:"bytes,4,opt,name=total_time,json=totalTime,proto3" json:"total_time,omitempty"`       // 总处理时间
+	AverageTime   *durationpb.Duration   `protobuf:"bytes,5,opt,name=average_time,json=averageTime,proto3" json:"average_time,omitempty"` // 平均处理时间
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchSummary) Reset() {
+	*x = BatchSummary{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchSummary) ProtoMessage() {}
+
+func (x *BatchSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchSummary.ProtoReflect.Descriptor instead.
+func (*BatchSummary) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *BatchSummary) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *BatchSummary) GetSuccess() int32 {
+	if x != nil {
+		return x.Success
+	}
+	return 0
+}
+
+func (x *BatchSummary) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *BatchSummary) GetTotalTime() *durationpb.Duration {
+	if x != nil {
+		return x.TotalTime
+	}
+	return nil
+}
+
+func (x *BatchSummary) GetAverageTime() *durationpb.Duration {
+	if x != nil {
+		return x.AverageTime
+	}
+	return nil
+}
+
+// EPUB转换请求
+type ConvertEPUBRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`    // EPUB文件内容
+	Split         bool                   `protobuf:"varint,2,opt,name=split,proto3" json:"split,omitempty"` // 每章输出一个文件并打包为zip，否则合并为单个文档
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertEPUBRequest) Reset() {
+	*x = ConvertEPUBRequest{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertEPUBRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertEPUBRequest) ProtoMessage() {}
+
+func (x *ConvertEPUBRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertEPUBRequest.ProtoReflect.Descriptor instead.
+func (*ConvertEPUBRequest) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ConvertEPUBRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ConvertEPUBRequest) GetSplit() bool {
+	if x != nil {
+		return x.Split
+	}
+	return false
+}
+
+// EPUB转换响应
+type ConvertEPUBResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`       // 书名
+	Markdown      string                 `protobuf:"bytes,2,opt,name=markdown,proto3" json:"markdown,omitempty"` // 附带目录的合并Markdown文档（合并输出时）
+	Archive       []byte                 `protobuf:"bytes,3,opt,name=archive,proto3" json:"archive,omitempty"`   // 包含各章节、目录和图片的zip压缩包（分章输出时）
+	Chapters      []*EPUBChapter         `protobuf:"bytes,4,rep,name=chapters,proto3" json:"chapters,omitempty"` // 按阅读顺序排列的章节
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertEPUBResponse) Reset() {
+	*x = ConvertEPUBResponse{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertEPUBResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertEPUBResponse) ProtoMessage() {}
+
+func (x *ConvertEPUBResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertEPUBResponse.ProtoReflect.Descriptor instead.
+func (*ConvertEPUBResponse) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ConvertEPUBResponse) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ConvertEPUBResponse) GetMarkdown() string {
+	if x != nil {
+		return x.Markdown
+	}
+	return ""
+}
+
+func (x *ConvertEPUBResponse) GetArchive() []byte {
+	if x != nil {
+		return x.Archive
+	}
+	return nil
+}
+
+func (x *ConvertEPUBResponse) GetChapters() []*EPUBChapter {
+	if x != nil {
+		return x.Chapters
+	}
+	return nil
+}
+
+// EPUB章节
+type EPUBChapter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"` // 章节在EPUB中的路径
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`   // 章节标题
+	Output        string                 `protobuf:"bytes,3,opt,name=output,proto3" json:"output,omitempty"` // 分章输出时的Markdown文件路径
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EPUBChapter) Reset() {
+	*x = EPUBChapter{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EPUBChapter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EPUBChapter) ProtoMessage() {}
+
+func (x *EPUBChapter) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EPUBChapter.ProtoReflect.Descriptor instead.
+func (*EPUBChapter) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *EPUBChapter) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *EPUBChapter) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *EPUBChapter) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+// 邮件转换请求
+type ConvertEmailRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`                                      // 邮件或MHTML文件内容
+	FrontMatter   bool                   `protobuf:"varint,2,opt,name=front_matter,json=frontMatter,proto3" json:"front_matter,omitempty"`    // 在开头输出由邮件头组成的YAML front matter
+	InlineImages  bool                   `protobuf:"varint,3,opt,name=inline_images,json=inlineImages,proto3" json:"inline_images,omitempty"` // 将引用的图片内嵌为data URI
+	Archive       bool                   `protobuf:"varint,4,opt,name=archive,proto3" json:"archive,omitempty"`                               // 将 message.md 和图片打包为zip
+	Cleanup       *EmailOptions          `protobuf:"bytes,5,opt,name=cleanup,proto3" json:"cleanup,omitempty"`                                // 引用历史和签名的处理方式
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertEmailRequest) Reset() {
+	*x = ConvertEmailRequest{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertEmailRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertEmailRequest) ProtoMessage() {}
+
+func (x *ConvertEmailRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertEmailRequest.ProtoReflect.Descriptor instead.
+func (*ConvertEmailRequest) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ConvertEmailRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ConvertEmailRequest) GetFrontMatter() bool {
+	if x != nil {
+		return x.FrontMatter
+	}
+	return false
+}
+
+func (x *ConvertEmailRequest) GetInlineImages() bool {
+	if x != nil {
+		return x.InlineImages
+	}
+	return false
+}
+
+func (x *ConvertEmailRequest) GetArchive() bool {
+	if x != nil {
+		return x.Archive
+	}
+	return false
+}
+
+func (x *ConvertEmailRequest) GetCleanup() *EmailOptions {
+	if x != nil {
+		return x.Cleanup
+	}
+	return nil
+}
+
+// 邮件转换响应
+type ConvertEmailResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Markdown      string                 `protobuf:"bytes,1,opt,name=markdown,proto3" json:"markdown,omitempty"` // 转换后的Markdown内容
-	Stats         *ConversionStats       `protobuf:"bytes,2,opt,name=stats,proto3" json:"stats,omitempty"`       // 转换统计信息
+	Header        *EmailHeader           `protobuf:"bytes,2,opt,name=header,proto3" json:"header,omitempty"`     // 邮件头
+	Archive       []byte                 `protobuf:"bytes,3,opt,name=archive,proto3" json:"archive,omitempty"`   // 包含 message.md 和图片的zip压缩包（打包输出时）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ConvertResponse) Reset() {
-	*x = ConvertResponse{}
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[1]
+func (x *ConvertEmailResponse) Reset() {
+	*x = ConvertEmailResponse{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ConvertResponse) String() string {
+func (x *ConvertEmailResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ConvertResponse) ProtoMessage() {}
+func (*ConvertEmailResponse) ProtoMessage() {}
 
-func (x *ConvertResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[1]
+func (x *ConvertEmailResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -102,50 +1537,59 @@ func (x *ConvertResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ConvertResponse.ProtoReflect.Descriptor instead.
-func (*ConvertResponse) Descriptor() ([]byte, []int) {
-	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use ConvertEmailResponse.ProtoReflect.Descriptor instead.
+func (*ConvertEmailResponse) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *ConvertResponse) GetMarkdown() string {
+func (x *ConvertEmailResponse) GetMarkdown() string {
 	if x != nil {
 		return x.Markdown
 	}
 	return ""
 }
 
-func (x *ConvertResponse) GetStats() *ConversionStats {
+func (x *ConvertEmailResponse) GetHeader() *EmailHeader {
 	if x != nil {
-		return x.Stats
+		return x.Header
 	}
 	return nil
 }
 
-// 转换统计信息
-type ConversionStats struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	InputSize      int32                  `protobuf:"varint,1,opt,name=input_size,json=inputSize,proto3" json:"input_size,omitempty"`               // 输入HTML大小（字节）
-	OutputSize     int32                  `protobuf:"varint,2,opt,name=output_size,json=outputSize,proto3" json:"output_size,omitempty"`            // 输出Markdown大小（字节）
-	ProcessingTime *durationpb.Duration   `protobuf:"bytes,3,opt,name=processing_time,json=processingTime,proto3" json:"processing_time,omitempty"` // 处理时间
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+func (x *ConvertEmailResponse) GetArchive() []byte {
+	if x != nil {
+		return x.Archive
+	}
+	return nil
 }
 
-func (x *ConversionStats) Reset() {
-	*x = ConversionStats{}
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[2]
+// 邮件头
+type EmailHeader struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`       // 发件人
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`           // 收件人
+	Subject       string                 `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"` // 主题
+	Date          string                 `protobuf:"bytes,4,opt,name=date,proto3" json:"date,omitempty"`       // 日期，能解析时为RFC 3339格式
+	Url           string                 `protobuf:"bytes,5,opt,name=url,proto3" json:"url,omitempty"`         // MHTML存档的原始网页地址
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmailHeader) Reset() {
+	*x = EmailHeader{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ConversionStats) String() string {
+func (x *EmailHeader) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ConversionStats) ProtoMessage() {}
+func (*EmailHeader) ProtoMessage() {}
 
-func (x *ConversionStats) ProtoReflect() protoreflect.Message {
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[2]
+func (x *EmailHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -156,55 +1600,74 @@ func (x *ConversionStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ConversionStats.ProtoReflect.Descriptor instead.
-func (*ConversionStats) Descriptor() ([]byte, []int) {
-	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use EmailHeader.ProtoReflect.Descriptor instead.
+func (*EmailHeader) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{20}
 }
 
-func (x *ConversionStats) GetInputSize() int32 {
+func (x *EmailHeader) GetFrom() string {
 	if x != nil {
-		return x.InputSize
+		return x.From
 	}
-	return 0
+	return ""
 }
 
-func (x *ConversionStats) GetOutputSize() int32 {
+func (x *EmailHeader) GetTo() string {
 	if x != nil {
-		return x.OutputSize
+		return x.To
 	}
-	return 0
+	return ""
 }
 
-func (x *ConversionStats) GetProcessingTime() *durationpb.Duration {
+func (x *EmailHeader) GetSubject() string {
 	if x != nil {
-		return x.ProcessingTime
+		return x.Subject
 	}
-	return nil
+	return ""
 }
 
-// 批量转换请求
-type BatchConvertRequest struct {
+func (x *EmailHeader) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *EmailHeader) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+// 异步转换任务请求，按任务类型填写对应的参数
+type SubmitJobRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Items         []*ConvertRequest      `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // 批量转换项目
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`       // 任务类型: convert, batch, site（整站压缩包）, epub
+	Convert       *ConvertRequest        `protobuf:"bytes,2,opt,name=convert,proto3" json:"convert,omitempty"` // convert任务的转换参数
+	Batch         *BatchConvertRequest   `protobuf:"bytes,3,opt,name=batch,proto3" json:"batch,omitempty"`     // batch任务的批量转换参数
+	Archive       []byte                 `protobuf:"bytes,4,opt,name=archive,proto3" json:"archive,omitempty"` // site任务的zip压缩包或epub任务的EPUB文件
+	Split         bool                   `protobuf:"varint,5,opt,name=split,proto3" json:"split,omitempty"`    // epub任务每章输出一个Markdown文件，结果为zip压缩包
+	Webhook       string                 `protobuf:"bytes,6,opt,name=webhook,proto3" json:"webhook,omitempty"` // 任务成功或失败后回调的地址，POST JSON格式的任务状态
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BatchConvertRequest) Reset() {
-	*x = BatchConvertRequest{}
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[3]
+func (x *SubmitJobRequest) Reset() {
+	*x = SubmitJobRequest{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BatchConvertRequest) String() string {
+func (x *SubmitJobRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BatchConvertRequest) ProtoMessage() {}
+func (*SubmitJobRequest) ProtoMessage() {}
 
-func (x *BatchConvertRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[3]
+func (x *SubmitJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -215,42 +1678,76 @@ func (x *BatchConvertRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BatchConvertRequest.ProtoReflect.Descriptor instead.
-func (*BatchConvertRequest) Descriptor() ([]byte, []int) {
-	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use SubmitJobRequest.ProtoReflect.Descriptor instead.
+func (*SubmitJobRequest) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *BatchConvertRequest) GetItems() []*ConvertRequest {
+func (x *SubmitJobRequest) GetType() string {
 	if x != nil {
-		return x.Items
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SubmitJobRequest) GetConvert() *ConvertRequest {
+	if x != nil {
+		return x.Convert
 	}
 	return nil
 }
 
-// 批量转换响应
-type BatchConvertResponse struct {
+func (x *SubmitJobRequest) GetBatch() *BatchConvertRequest {
+	if x != nil {
+		return x.Batch
+	}
+	return nil
+}
+
+func (x *SubmitJobRequest) GetArchive() []byte {
+	if x != nil {
+		return x.Archive
+	}
+	return nil
+}
+
+func (x *SubmitJobRequest) GetSplit() bool {
+	if x != nil {
+		return x.Split
+	}
+	return false
+}
+
+func (x *SubmitJobRequest) GetWebhook() string {
+	if x != nil {
+		return x.Webhook
+	}
+	return ""
+}
+
+// 获取或取消异步任务的请求
+type GetJobRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Results       []*BatchConvertItem    `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // 批量转换结果
-	Summary       *BatchSummary          `protobuf:"bytes,2,opt,name=summary,proto3" json:"summary,omitempty"` // 批量转换摘要
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // 任务ID
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BatchConvertResponse) Reset() {
-	*x = BatchConvertResponse{}
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[4]
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BatchConvertResponse) String() string {
+func (x *GetJobRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BatchConvertResponse) ProtoMessage() {}
+func (*GetJobRequest) ProtoMessage() {}
 
-func (x *BatchConvertResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[4]
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -261,51 +1758,50 @@ func (x *BatchConvertResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BatchConvertResponse.ProtoReflect.Descriptor instead.
-func (*BatchConvertResponse) Descriptor() ([]byte, []int) {
-	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{4}
-}
-
-func (x *BatchConvertResponse) GetResults() []*BatchConvertItem {
-	if x != nil {
-		return x.Results
-	}
-	return nil
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *BatchConvertResponse) GetSummary() *BatchSummary {
+func (x *GetJobRequest) GetId() string {
 	if x != nil {
-		return x.Summary
+		return x.Id
 	}
-	return nil
+	return ""
 }
 
-// 批量转换项目结果
-type BatchConvertItem struct {
+// 异步转换任务的状态
+type Job struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`     // 项目索引
-	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"` // 是否成功
-	Result        *ConvertResponse       `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`    // 转换结果（成功时）
-	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`      // 错误信息（失败时）
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                   // 任务ID
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`                               // 任务类型
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`                           // 任务状态: queued, running, succeeded, failed, canceled
+	Done          int32                  `protobuf:"varint,4,opt,name=done,proto3" json:"done,omitempty"`                              // 已处理的项目、页面或章节数
+	Total         int32                  `protobuf:"varint,5,opt,name=total,proto3" json:"total,omitempty"`                            // 总数，EPUB任务在解析完章节前为0
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`                             // 失败原因
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`    // 提交时间
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`    // 开始执行时间
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"` // 结束时间
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`   // 状态和结果的保留期限，任务结束后设置
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BatchConvertItem) Reset() {
-	*x = BatchConvertItem{}
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[5]
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BatchConvertItem) String() string {
+func (x *Job) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BatchConvertItem) ProtoMessage() {}
+func (*Job) ProtoMessage() {}
 
-func (x *BatchConvertItem) ProtoReflect() protoreflect.Message {
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[5]
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -316,66 +1812,108 @@ func (x *BatchConvertItem) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BatchConvertItem.ProtoReflect.Descriptor instead.
-func (*BatchConvertItem) Descriptor() ([]byte, []int) {
-	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *BatchConvertItem) GetIndex() int32 {
+func (x *Job) GetId() string {
 	if x != nil {
-		return x.Index
+		return x.Id
 	}
-	return 0
+	return ""
 }
 
-func (x *BatchConvertItem) GetSuccess() bool {
+func (x *Job) GetType() string {
 	if x != nil {
-		return x.Success
+		return x.Type
 	}
-	return false
+	return ""
 }
 
-func (x *BatchConvertItem) GetResult() *ConvertResponse {
+func (x *Job) GetStatus() string {
 	if x != nil {
-		return x.Result
+		return x.Status
 	}
-	return nil
+	return ""
 }
 
-func (x *BatchConvertItem) GetError() string {
+func (x *Job) GetDone() int32 {
+	if x != nil {
+		return x.Done
+	}
+	return 0
+}
+
+func (x *Job) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *Job) GetError() string {
 	if x != nil {
 		return x.Error
 	}
 	return ""
 }
 
-// 批量转换摘要
-type BatchSummary struct {
+func (x *Job) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Job) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Job) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *Job) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+// 异步任务的结果，按任务类型填写其中一项
+type JobResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Total         int32                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`                               // 总数
-	Success       int32                  `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`                           // 成功数
-	Failed        int32                  `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`                             // 失败数
-	TotalTime     *durationpb.Duration   `protobuf:"bytes,4,opt,name=total_time,json=totalTime,proto3" json:"total_time,omitempty"`       // 总处理时间
-	AverageTime   *durationpb.Duration   `protobuf:"bytes,5,opt,name=average_time,json=averageTime,proto3" json:"average_time,omitempty"` // 平均处理时间
+	Job           *Job                   `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`         // 任务状态
+	Convert       *ConvertResponse       `protobuf:"bytes,2,opt,name=convert,proto3" json:"convert,omitempty"` // convert任务的结果
+	Batch         *BatchConvertResponse  `protobuf:"bytes,3,opt,name=batch,proto3" json:"batch,omitempty"`     // batch任务的结果
+	Epub          *ConvertEPUBResponse   `protobuf:"bytes,4,opt,name=epub,proto3" json:"epub,omitempty"`       // epub任务的结果，分章输出时包含zip压缩包
+	Archive       []byte                 `protobuf:"bytes,5,opt,name=archive,proto3" json:"archive,omitempty"` // site任务的zip压缩包
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BatchSummary) Reset() {
-	*x = BatchSummary{}
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[6]
+func (x *JobResult) Reset() {
+	*x = JobResult{}
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BatchSummary) String() string {
+func (x *JobResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BatchSummary) ProtoMessage() {}
+func (*JobResult) ProtoMessage() {}
 
-func (x *BatchSummary) ProtoReflect() protoreflect.Message {
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[6]
+func (x *JobResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -386,42 +1924,42 @@ func (x *BatchSummary) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BatchSummary.ProtoReflect.Descriptor instead.
-func (*BatchSummary) Descriptor() ([]byte, []int) {
-	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use JobResult.ProtoReflect.Descriptor instead.
+func (*JobResult) Descriptor() ([]byte, []int) {
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *BatchSummary) GetTotal() int32 {
+func (x *JobResult) GetJob() *Job {
 	if x != nil {
-		return x.Total
+		return x.Job
 	}
-	return 0
+	return nil
 }
 
-func (x *BatchSummary) GetSuccess() int32 {
+func (x *JobResult) GetConvert() *ConvertResponse {
 	if x != nil {
-		return x.Success
+		return x.Convert
 	}
-	return 0
+	return nil
 }
 
-func (x *BatchSummary) GetFailed() int32 {
+func (x *JobResult) GetBatch() *BatchConvertResponse {
 	if x != nil {
-		return x.Failed
+		return x.Batch
 	}
-	return 0
+	return nil
 }
 
-func (x *BatchSummary) GetTotalTime() *durationpb.Duration {
+func (x *JobResult) GetEpub() *ConvertEPUBResponse {
 	if x != nil {
-		return x.TotalTime
+		return x.Epub
 	}
 	return nil
 }
 
-func (x *BatchSummary) GetAverageTime() *durationpb.Duration {
+func (x *JobResult) GetArchive() []byte {
 	if x != nil {
-		return x.AverageTime
+		return x.Archive
 	}
 	return nil
 }
@@ -435,7 +1973,7 @@ type HealthCheckRequest struct {
 
 func (x *HealthCheckRequest) Reset() {
 	*x = HealthCheckRequest{}
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[7]
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -447,7 +1985,7 @@ func (x *HealthCheckRequest) String() string {
 func (*HealthCheckRequest) ProtoMessage() {}
 
 func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[7]
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -460,7 +1998,7 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
 func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{7}
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{25}
 }
 
 // 健康检查响应
@@ -477,7 +2015,7 @@ type HealthCheckResponse struct {
 
 func (x *HealthCheckResponse) Reset() {
 	*x = HealthCheckResponse{}
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[8]
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -489,7 +2027,7 @@ func (x *HealthCheckResponse) String() string {
 func (*HealthCheckResponse) ProtoMessage() {}
 
 func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[8]
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -502,7 +2040,7 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
 func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{8}
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *HealthCheckResponse) GetStatus() string {
@@ -553,7 +2091,7 @@ type MemInfo struct {
 
 func (x *MemInfo) Reset() {
 	*x = MemInfo{}
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[9]
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -565,7 +2103,7 @@ func (x *MemInfo) String() string {
 func (*MemInfo) ProtoMessage() {}
 
 func (x *MemInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[9]
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -578,7 +2116,7 @@ func (x *MemInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MemInfo.ProtoReflect.Descriptor instead.
 func (*MemInfo) Descriptor() ([]byte, []int) {
-	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{9}
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *MemInfo) GetAlloc() uint64 {
@@ -618,7 +2156,7 @@ type GetConverterInfoRequest struct {
 
 func (x *GetConverterInfoRequest) Reset() {
 	*x = GetConverterInfoRequest{}
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[10]
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -630,7 +2168,7 @@ func (x *GetConverterInfoRequest) String() string {
 func (*GetConverterInfoRequest) ProtoMessage() {}
 
 func (x *GetConverterInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[10]
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -643,23 +2181,25 @@ func (x *GetConverterInfoRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetConverterInfoRequest.ProtoReflect.Descriptor instead.
 func (*GetConverterInfoRequest) Descriptor() ([]byte, []int) {
-	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{10}
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{28}
 }
 
 // 获取转换器信息响应
 type GetConverterInfoResponse struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	Version          string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`                                                                         // 转换器版本
-	SupportedPlugins []string               `protobuf:"bytes,2,rep,name=supported_plugins,json=supportedPlugins,proto3" json:"supported_plugins,omitempty"`                               // 支持的插件列表
-	Features         []string               `protobuf:"bytes,3,rep,name=features,proto3" json:"features,omitempty"`                                                                       // 功能特性列表
-	Config           map[string]string      `protobuf:"bytes,4,rep,name=config,proto3" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 配置信息
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Version             string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`                                                                         // 转换器版本
+	SupportedPlugins    []string               `protobuf:"bytes,2,rep,name=supported_plugins,json=supportedPlugins,proto3" json:"supported_plugins,omitempty"`                               // 支持的插件列表
+	Features            []string               `protobuf:"bytes,3,rep,name=features,proto3" json:"features,omitempty"`                                                                       // 功能特性列表
+	Config              map[string]string      `protobuf:"bytes,4,rep,name=config,proto3" json:"config,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 配置信息
+	SupportedProfiles   []string               `protobuf:"bytes,5,rep,name=supported_profiles,json=supportedProfiles,proto3" json:"supported_profiles,omitempty"`                            // 支持的预处理配置
+	SupportedTokenizers []string               `protobuf:"bytes,6,rep,name=supported_tokenizers,json=supportedTokenizers,proto3" json:"supported_tokenizers,omitempty"`                      // 支持的分词器
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *GetConverterInfoResponse) Reset() {
 	*x = GetConverterInfoResponse{}
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[11]
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -671,7 +2211,7 @@ func (x *GetConverterInfoResponse) String() string {
 func (*GetConverterInfoResponse) ProtoMessage() {}
 
 func (x *GetConverterInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_grpc_proto_convert_proto_msgTypes[11]
+	mi := &file_api_grpc_proto_convert_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -684,7 +2224,7 @@ func (x *GetConverterInfoResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetConverterInfoResponse.ProtoReflect.Descriptor instead.
 func (*GetConverterInfoResponse) Descriptor() ([]byte, []int) {
-	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{11}
+	return file_api_grpc_proto_convert_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *GetConverterInfoResponse) GetVersion() string {
@@ -715,23 +2255,117 @@ func (x *GetConverterInfoResponse) GetConfig() map[string]string {
 	return nil
 }
 
+func (x *GetConverterInfoResponse) GetSupportedProfiles() []string {
+	if x != nil {
+		return x.SupportedProfiles
+	}
+	return nil
+}
+
+func (x *GetConverterInfoResponse) GetSupportedTokenizers() []string {
+	if x != nil {
+		return x.SupportedTokenizers
+	}
+	return nil
+}
+
 var File_api_grpc_proto_convert_proto protoreflect.FileDescriptor
 
 const file_api_grpc_proto_convert_proto_rawDesc = "" +
 	"\n" +
 	"\x1capi/grpc/proto/convert.proto\x12\n" +
-	"html2md.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\"$\n" +
+	"html2md.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\"\xa8\x03\n" +
 	"\x0eConvertRequest\x12\x12\n" +
-	"\x04html\x18\x01 \x01(\tR\x04html\"`\n" +
+	"\x04html\x18\x01 \x01(\tR\x04html\x12&\n" +
+	"\x05rules\x18\x02 \x03(\v2\x10.html2md.v1.RuleR\x05rules\x12\x18\n" +
+	"\aplugins\x18\x03 \x03(\tR\aplugins\x12\x1a\n" +
+	"\bprofiles\x18\x04 \x03(\tR\bprofiles\x12.\n" +
+	"\x05email\x18\x05 \x01(\v2\x18.html2md.v1.EmailOptionsR\x05email\x12!\n" +
+	"\finfer_styles\x18\x06 \x01(\bR\vinferStyles\x127\n" +
+	"\bchunking\x18\a \x01(\v2\x1b.html2md.v1.ChunkingOptionsR\bchunking\x12\x1c\n" +
+	"\ttokenizer\x18\b \x01(\tR\ttokenizer\x12\x1d\n" +
+	"\n" +
+	"max_tokens\x18\t \x01(\x05R\tmaxTokens\x12\x10\n" +
+	"\x03toc\x18\n" +
+	" \x01(\tR\x03toc\x12\x1c\n" +
+	"\tinventory\x18\v \x01(\bR\tinventory\x12\x10\n" +
+	"\x03url\x18\f \x01(\tR\x03url\x12\x19\n" +
+	"\bbase_url\x18\r \x01(\tR\abaseUrl\"{\n" +
+	"\x0fChunkingOptions\x12\x12\n" +
+	"\x04unit\x18\x01 \x01(\tR\x04unit\x12\x1f\n" +
+	"\vtarget_size\x18\x02 \x01(\x05R\n" +
+	"targetSize\x12\x19\n" +
+	"\bmax_size\x18\x03 \x01(\x05R\amaxSize\x12\x18\n" +
+	"\aoverlap\x18\x04 \x01(\x05R\aoverlap\"O\n" +
+	"\fEmailOptions\x12\x16\n" +
+	"\x06quotes\x18\x01 \x01(\tR\x06quotes\x12'\n" +
+	"\x0fstrip_signature\x18\x02 \x01(\bR\x0estripSignature\"V\n" +
+	"\x04Rule\x12\x1a\n" +
+	"\bselector\x18\x01 \x01(\tR\bselector\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x1a\n" +
+	"\btemplate\x18\x03 \x01(\tR\btemplate\"\xf6\x01\n" +
 	"\x0fConvertResponse\x12\x1a\n" +
 	"\bmarkdown\x18\x01 \x01(\tR\bmarkdown\x121\n" +
-	"\x05stats\x18\x02 \x01(\v2\x1b.html2md.v1.ConversionStatsR\x05stats\"\x95\x01\n" +
+	"\x05stats\x18\x02 \x01(\v2\x1b.html2md.v1.ConversionStatsR\x05stats\x12)\n" +
+	"\x06chunks\x18\x03 \x03(\v2\x11.html2md.v1.ChunkR\x06chunks\x124\n" +
+	"\aoutline\x18\x04 \x03(\v2\x1a.html2md.v1.OutlineHeadingR\aoutline\x123\n" +
+	"\tinventory\x18\x05 \x01(\v2\x15.html2md.v1.InventoryR\tinventory\"^\n" +
+	"\tInventory\x12&\n" +
+	"\x05links\x18\x01 \x03(\v2\x10.html2md.v1.LinkR\x05links\x12)\n" +
+	"\x06images\x18\x02 \x03(\v2\x11.html2md.v1.ImageR\x06images\"z\n" +
+	"\x04Link\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x1a\n" +
+	"\binternal\x18\x04 \x01(\bR\binternal\x12\x1a\n" +
+	"\bfragment\x18\x05 \x01(\tR\bfragment\"\x8a\x01\n" +
+	"\x05Image\x12\x10\n" +
+	"\x03alt\x18\x01 \x01(\tR\x03alt\x12\x10\n" +
+	"\x03src\x18\x02 \x01(\tR\x03src\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x14\n" +
+	"\x05width\x18\x04 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x05 \x01(\x05R\x06height\x12\x19\n" +
+	"\bdata_uri\x18\x06 \x01(\bR\adataUri\"\x9e\x01\n" +
+	"\x0eOutlineHeading\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\x05R\x05level\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x12\n" +
+	"\x04slug\x18\x03 \x01(\tR\x04slug\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\x126\n" +
+	"\bchildren\x18\x05 \x03(\v2\x1a.html2md.v1.OutlineHeadingR\bchildren\"\xbf\x01\n" +
+	"\x05Chunk\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x1a\n" +
+	"\bheadings\x18\x02 \x03(\tR\bheadings\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\x12\x14\n" +
+	"\x05start\x18\x04 \x01(\x05R\x05start\x12\x10\n" +
+	"\x03end\x18\x05 \x01(\x05R\x03end\x12\x14\n" +
+	"\x05bytes\x18\x06 \x01(\x05R\x05bytes\x12\x14\n" +
+	"\x05chars\x18\a \x01(\x05R\x05chars\x12\x16\n" +
+	"\x06tokens\x18\b \x01(\x05R\x06tokens\"\xea\x04\n" +
 	"\x0fConversionStats\x12\x1d\n" +
 	"\n" +
 	"input_size\x18\x01 \x01(\x05R\tinputSize\x12\x1f\n" +
 	"\voutput_size\x18\x02 \x01(\x05R\n" +
 	"outputSize\x12B\n" +
-	"\x0fprocessing_time\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x0eprocessingTime\"G\n" +
+	"\x0fprocessing_time\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x0eprocessingTime\x12\x16\n" +
+	"\x06tokens\x18\x04 \x01(\x05R\x06tokens\x12\x1c\n" +
+	"\ttokenizer\x18\x05 \x01(\tR\ttokenizer\x12\x1c\n" +
+	"\ttruncated\x18\x06 \x01(\bR\ttruncated\x12'\n" +
+	"\x0ftruncated_bytes\x18\a \x01(\x05R\x0etruncatedBytes\x12)\n" +
+	"\x10truncated_tokens\x18\b \x01(\x05R\x0ftruncatedTokens\x12\x14\n" +
+	"\x05links\x18\t \x01(\x05R\x05links\x12\x16\n" +
+	"\x06images\x18\n" +
+	" \x01(\x05R\x06images\x12\x14\n" +
+	"\x05words\x18\v \x01(\x05R\x05words\x12\x14\n" +
+	"\x05chars\x18\f \x01(\x05R\x05chars\x12'\n" +
+	"\x0freading_seconds\x18\r \x01(\x05R\x0ereadingSeconds\x12\x1e\n" +
+	"\n" +
+	"paragraphs\x18\x0e \x01(\x05R\n" +
+	"paragraphs\x12\x1a\n" +
+	"\bheadings\x18\x0f \x01(\x05R\bheadings\x12\x1f\n" +
+	"\vcode_blocks\x18\x10 \x01(\x05R\n" +
+	"codeBlocks\x12\x1a\n" +
+	"\blanguage\x18\x11 \x01(\tR\blanguage\x12/\n" +
+	"\x13language_confidence\x18\x12 \x01(\x01R\x12languageConfidence\"G\n" +
 	"\x13BatchConvertRequest\x120\n" +
 	"\x05items\x18\x01 \x03(\v2\x1a.html2md.v1.ConvertRequestR\x05items\"\x82\x01\n" +
 	"\x14BatchConvertResponse\x126\n" +
@@ -748,7 +2382,66 @@ const file_api_grpc_proto_convert_proto_rawDesc = "" +
 	"\x06failed\x18\x03 \x01(\x05R\x06failed\x128\n" +
 	"\n" +
 	"total_time\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\ttotalTime\x12<\n" +
-	"\faverage_time\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\vaverageTime\"\x14\n" +
+	"\faverage_time\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\vaverageTime\">\n" +
+	"\x12ConvertEPUBRequest\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x14\n" +
+	"\x05split\x18\x02 \x01(\bR\x05split\"\x96\x01\n" +
+	"\x13ConvertEPUBResponse\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x1a\n" +
+	"\bmarkdown\x18\x02 \x01(\tR\bmarkdown\x12\x18\n" +
+	"\aarchive\x18\x03 \x01(\fR\aarchive\x123\n" +
+	"\bchapters\x18\x04 \x03(\v2\x17.html2md.v1.EPUBChapterR\bchapters\"S\n" +
+	"\vEPUBChapter\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x16\n" +
+	"\x06output\x18\x03 \x01(\tR\x06output\"\xbf\x01\n" +
+	"\x13ConvertEmailRequest\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12!\n" +
+	"\ffront_matter\x18\x02 \x01(\bR\vfrontMatter\x12#\n" +
+	"\rinline_images\x18\x03 \x01(\bR\finlineImages\x12\x18\n" +
+	"\aarchive\x18\x04 \x01(\bR\aarchive\x122\n" +
+	"\acleanup\x18\x05 \x01(\v2\x18.html2md.v1.EmailOptionsR\acleanup\"}\n" +
+	"\x14ConvertEmailResponse\x12\x1a\n" +
+	"\bmarkdown\x18\x01 \x01(\tR\bmarkdown\x12/\n" +
+	"\x06header\x18\x02 \x01(\v2\x17.html2md.v1.EmailHeaderR\x06header\x12\x18\n" +
+	"\aarchive\x18\x03 \x01(\fR\aarchive\"q\n" +
+	"\vEmailHeader\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x18\n" +
+	"\asubject\x18\x03 \x01(\tR\asubject\x12\x12\n" +
+	"\x04date\x18\x04 \x01(\tR\x04date\x12\x10\n" +
+	"\x03url\x18\x05 \x01(\tR\x03url\"\xdd\x01\n" +
+	"\x10SubmitJobRequest\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x124\n" +
+	"\aconvert\x18\x02 \x01(\v2\x1a.html2md.v1.ConvertRequestR\aconvert\x125\n" +
+	"\x05batch\x18\x03 \x01(\v2\x1f.html2md.v1.BatchConvertRequestR\x05batch\x12\x18\n" +
+	"\aarchive\x18\x04 \x01(\fR\aarchive\x12\x14\n" +
+	"\x05split\x18\x05 \x01(\bR\x05split\x12\x18\n" +
+	"\awebhook\x18\x06 \x01(\tR\awebhook\"\x1f\n" +
+	"\rGetJobRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xef\x02\n" +
+	"\x03Job\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x12\n" +
+	"\x04done\x18\x04 \x01(\x05R\x04done\x12\x14\n" +
+	"\x05total\x18\x05 \x01(\x05R\x05total\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"started_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"\xec\x01\n" +
+	"\tJobResult\x12!\n" +
+	"\x03job\x18\x01 \x01(\v2\x0f.html2md.v1.JobR\x03job\x125\n" +
+	"\aconvert\x18\x02 \x01(\v2\x1b.html2md.v1.ConvertResponseR\aconvert\x126\n" +
+	"\x05batch\x18\x03 \x01(\v2 .html2md.v1.BatchConvertResponseR\x05batch\x123\n" +
+	"\x04epub\x18\x04 \x01(\v2\x1f.html2md.v1.ConvertEPUBResponseR\x04epub\x12\x18\n" +
+	"\aarchive\x18\x05 \x01(\fR\aarchive\"\x14\n" +
 	"\x12HealthCheckRequest\"\xc6\x01\n" +
 	"\x13HealthCheckResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x128\n" +
@@ -762,18 +2455,27 @@ const file_api_grpc_proto_convert_proto_rawDesc = "" +
 	"totalAlloc\x12\x10\n" +
 	"\x03sys\x18\x03 \x01(\x04R\x03sys\x12\x15\n" +
 	"\x06num_gc\x18\x04 \x01(\rR\x05numGc\"\x19\n" +
-	"\x17GetConverterInfoRequest\"\x82\x02\n" +
+	"\x17GetConverterInfoRequest\"\xe4\x02\n" +
 	"\x18GetConverterInfoResponse\x12\x18\n" +
 	"\aversion\x18\x01 \x01(\tR\aversion\x12+\n" +
 	"\x11supported_plugins\x18\x02 \x03(\tR\x10supportedPlugins\x12\x1a\n" +
 	"\bfeatures\x18\x03 \x03(\tR\bfeatures\x12H\n" +
-	"\x06config\x18\x04 \x03(\v20.html2md.v1.GetConverterInfoResponse.ConfigEntryR\x06config\x1a9\n" +
+	"\x06config\x18\x04 \x03(\v20.html2md.v1.GetConverterInfoResponse.ConfigEntryR\x06config\x12-\n" +
+	"\x12supported_profiles\x18\x05 \x03(\tR\x11supportedProfiles\x121\n" +
+	"\x14supported_tokenizers\x18\x06 \x03(\tR\x13supportedTokenizers\x1a9\n" +
 	"\vConfigEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012\xd6\x02\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x012\xb5\x06\n" +
 	"\x0eConvertService\x12B\n" +
 	"\aConvert\x12\x1a.html2md.v1.ConvertRequest\x1a\x1b.html2md.v1.ConvertResponse\x12Q\n" +
-	"\fConvertBatch\x12\x1f.html2md.v1.BatchConvertRequest\x1a .html2md.v1.BatchConvertResponse\x12N\n" +
+	"\fConvertBatch\x12\x1f.html2md.v1.BatchConvertRequest\x1a .html2md.v1.BatchConvertResponse\x12M\n" +
+	"\rConvertStream\x12\x1a.html2md.v1.ConvertRequest\x1a\x1c.html2md.v1.BatchConvertItem(\x010\x01\x12N\n" +
+	"\vConvertEPUB\x12\x1e.html2md.v1.ConvertEPUBRequest\x1a\x1f.html2md.v1.ConvertEPUBResponse\x12Q\n" +
+	"\fConvertEmail\x12\x1f.html2md.v1.ConvertEmailRequest\x1a .html2md.v1.ConvertEmailResponse\x12:\n" +
+	"\tSubmitJob\x12\x1c.html2md.v1.SubmitJobRequest\x1a\x0f.html2md.v1.Job\x124\n" +
+	"\x06GetJob\x12\x19.html2md.v1.GetJobRequest\x1a\x0f.html2md.v1.Job\x12@\n" +
+	"\fGetJobResult\x12\x19.html2md.v1.GetJobRequest\x1a\x15.html2md.v1.JobResult\x127\n" +
+	"\tCancelJob\x12\x19.html2md.v1.GetJobRequest\x1a\x0f.html2md.v1.Job\x12N\n" +
 	"\vHealthCheck\x12\x1e.html2md.v1.HealthCheckRequest\x1a\x1f.html2md.v1.HealthCheckResponse\x12]\n" +
 	"\x10GetConverterInfo\x12#.html2md.v1.GetConverterInfoRequest\x1a$.html2md.v1.GetConverterInfoResponseB1Z/github.com/relaxcloud-cn/html2md/api/grpc/protob\x06proto3"
 
@@ -789,49 +2491,103 @@ func file_api_grpc_proto_convert_proto_rawDescGZIP() []byte {
 	return file_api_grpc_proto_convert_proto_rawDescData
 }
 
-var file_api_grpc_proto_convert_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_api_grpc_proto_convert_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
 var file_api_grpc_proto_convert_proto_goTypes = []any{
 	(*ConvertRequest)(nil),           // 0: html2md.v1.ConvertRequest
-	(*ConvertResponse)(nil),          // 1: html2md.v1.ConvertResponse
-	(*ConversionStats)(nil),          // 2: html2md.v1.ConversionStats
-	(*BatchConvertRequest)(nil),      // 3: html2md.v1.BatchConvertRequest
-	(*BatchConvertResponse)(nil),     // 4: html2md.v1.BatchConvertResponse
-	(*BatchConvertItem)(nil),         // 5: html2md.v1.BatchConvertItem
-	(*BatchSummary)(nil),             // 6: html2md.v1.BatchSummary
-	(*HealthCheckRequest)(nil),       // 7: html2md.v1.HealthCheckRequest
-	(*HealthCheckResponse)(nil),      // 8: html2md.v1.HealthCheckResponse
-	(*MemInfo)(nil),                  // 9: html2md.v1.MemInfo
-	(*GetConverterInfoRequest)(nil),  // 10: html2md.v1.GetConverterInfoRequest
-	(*GetConverterInfoResponse)(nil), // 11: html2md.v1.GetConverterInfoResponse
-	nil,                              // 12: html2md.v1.GetConverterInfoResponse.ConfigEntry
-	(*durationpb.Duration)(nil),      // 13: google.protobuf.Duration
-	(*timestamppb.Timestamp)(nil),    // 14: google.protobuf.Timestamp
+	(*ChunkingOptions)(nil),          // 1: html2md.v1.ChunkingOptions
+	(*EmailOptions)(nil),             // 2: html2md.v1.EmailOptions
+	(*Rule)(nil),                     // 3: html2md.v1.Rule
+	(*ConvertResponse)(nil),          // 4: html2md.v1.ConvertResponse
+	(*Inventory)(nil),                // 5: html2md.v1.Inventory
+	(*Link)(nil),                     // 6: html2md.v1.Link
+	(*Image)(nil),                    // 7: html2md.v1.Image
+	(*OutlineHeading)(nil),           // 8: html2md.v1.OutlineHeading
+	(*Chunk)(nil),                    // 9: html2md.v1.Chunk
+	(*ConversionStats)(nil),          // 10: html2md.v1.ConversionStats
+	(*BatchConvertRequest)(nil),      // 11: html2md.v1.BatchConvertRequest
+	(*BatchConvertResponse)(nil),     // 12: html2md.v1.BatchConvertResponse
+	(*BatchConvertItem)(nil),         // 13: html2md.v1.BatchConvertItem
+	(*BatchSummary)(nil),             // 14: html2md.v1.BatchSummary
+	(*ConvertEPUBRequest)(nil),       // 15: html2md.v1.ConvertEPUBRequest
+	(*ConvertEPUBResponse)(nil),      // 16: html2md.v1.ConvertEPUBResponse
+	(*EPUBChapter)(nil),              // 17: html2md.v1.EPUBChapter
+	(*ConvertEmailRequest)(nil),      // 18: html2md.v1.ConvertEmailRequest
+	(*ConvertEmailResponse)(nil),     // 19: html2md.v1.ConvertEmailResponse
+	(*EmailHeader)(nil),              // 20: html2md.v1.EmailHeader
+	(*SubmitJobRequest)(nil),         // 21: html2md.v1.SubmitJobRequest
+	(*GetJobRequest)(nil),            // 22: html2md.v1.GetJobRequest
+	(*Job)(nil),                      // 23: html2md.v1.Job
+	(*JobResult)(nil),                // 24: html2md.v1.JobResult
+	(*HealthCheckRequest)(nil),       // 25: html2md.v1.HealthCheckRequest
+	(*HealthCheckResponse)(nil),      // 26: html2md.v1.HealthCheckResponse
+	(*MemInfo)(nil),                  // 27: html2md.v1.MemInfo
+	(*GetConverterInfoRequest)(nil),  // 28: html2md.v1.GetConverterInfoRequest
+	(*GetConverterInfoResponse)(nil), // 29: html2md.v1.GetConverterInfoResponse
+	nil,                              // 30: html2md.v1.GetConverterInfoResponse.ConfigEntry
+	(*durationpb.Duration)(nil),      // 31: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),    // 32: google.protobuf.Timestamp
 }
 var file_api_grpc_proto_convert_proto_depIdxs = []int32{
-	2,  // 0: html2md.v1.ConvertResponse.stats:type_name -> html2md.v1.ConversionStats
-	13, // 1: html2md.v1.ConversionStats.processing_time:type_name -> google.protobuf.Duration
-	0,  // 2: html2md.v1.BatchConvertRequest.items:type_name -> html2md.v1.ConvertRequest
-	5,  // 3: html2md.v1.BatchConvertResponse.results:type_name -> html2md.v1.BatchConvertItem
-	6,  // 4: html2md.v1.BatchConvertResponse.summary:type_name -> html2md.v1.BatchSummary
-	1,  // 5: html2md.v1.BatchConvertItem.result:type_name -> html2md.v1.ConvertResponse
-	13, // 6: html2md.v1.BatchSummary.total_time:type_name -> google.protobuf.Duration
-	13, // 7: html2md.v1.BatchSummary.average_time:type_name -> google.protobuf.Duration
-	14, // 8: html2md.v1.HealthCheckResponse.timestamp:type_name -> google.protobuf.Timestamp
-	9,  // 9: html2md.v1.HealthCheckResponse.memory:type_name -> html2md.v1.MemInfo
-	12, // 10: html2md.v1.GetConverterInfoResponse.config:type_name -> html2md.v1.GetConverterInfoResponse.ConfigEntry
-	0,  // 11: html2md.v1.ConvertService.Convert:input_type -> html2md.v1.ConvertRequest
-	3,  // 12: html2md.v1.ConvertService.ConvertBatch:input_type -> html2md.v1.BatchConvertRequest
-	7,  // 13: html2md.v1.ConvertService.HealthCheck:input_type -> html2md.v1.HealthCheckRequest
-	10, // 14: html2md.v1.ConvertService.GetConverterInfo:input_type -> html2md.v1.GetConverterInfoRequest
-	1,  // 15: html2md.v1.ConvertService.Convert:output_type -> html2md.v1.ConvertResponse
-	4,  // 16: html2md.v1.ConvertService.ConvertBatch:output_type -> html2md.v1.BatchConvertResponse
-	8,  // 17: html2md.v1.ConvertService.HealthCheck:output_type -> html2md.v1.HealthCheckResponse
-	11, // 18: html2md.v1.ConvertService.GetConverterInfo:output_type -> html2md.v1.GetConverterInfoResponse
-	15, // [15:19] is the sub-list for method output_type
-	11, // [11:15] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	3,  // 0: html2md.v1.ConvertRequest.rules:type_name -> html2md.v1.Rule
+	2,  // 1: html2md.v1.ConvertRequest.email:type_name -> html2md.v1.EmailOptions
+	1,  // 2: html2md.v1.ConvertRequest.chunking:type_name -> html2md.v1.ChunkingOptions
+	10, // 3: html2md.v1.ConvertResponse.stats:type_name -> html2md.v1.ConversionStats
+	9,  // 4: html2md.v1.ConvertResponse.chunks:type_name -> html2md.v1.Chunk
+	8,  // 5: html2md.v1.ConvertResponse.outline:type_name -> html2md.v1.OutlineHeading
+	5,  // 6: html2md.v1.ConvertResponse.inventory:type_name -> html2md.v1.Inventory
+	6,  // 7: html2md.v1.Inventory.links:type_name -> html2md.v1.Link
+	7,  // 8: html2md.v1.Inventory.images:type_name -> html2md.v1.Image
+	8,  // 9: html2md.v1.OutlineHeading.children:type_name -> html2md.v1.OutlineHeading
+	31, // 10: html2md.v1.ConversionStats.processing_time:type_name -> google.protobuf.Duration
+	0,  // 11: html2md.v1.BatchConvertRequest.items:type_name -> html2md.v1.ConvertRequest
+	13, // 12: html2md.v1.BatchConvertResponse.results:type_name -> html2md.v1.BatchConvertItem
+	14, // 13: html2md.v1.BatchConvertResponse.summary:type_name -> html2md.v1.BatchSummary
+	4,  // 14: html2md.v1.BatchConvertItem.result:type_name -> html2md.v1.ConvertResponse
+	31, // 15: html2md.v1.BatchSummary.total_time:type_name -> google.protobuf.Duration
+	31, // 16: html2md.v1.BatchSummary.average_time:type_name -> google.protobuf.Duration
+	17, // 17: html2md.v1.ConvertEPUBResponse.chapters:type_name -> html2md.v1.EPUBChapter
+	2,  // 18: html2md.v1.ConvertEmailRequest.cleanup:type_name -> html2md.v1.EmailOptions
+	20, // 19: html2md.v1.ConvertEmailResponse.header:type_name -> html2md.v1.EmailHeader
+	0,  // 20: html2md.v1.SubmitJobRequest.convert:type_name -> html2md.v1.ConvertRequest
+	11, // 21: html2md.v1.SubmitJobRequest.batch:type_name -> html2md.v1.BatchConvertRequest
+	32, // 22: html2md.v1.Job.created_at:type_name -> google.protobuf.Timestamp
+	32, // 23: html2md.v1.Job.started_at:type_name -> google.protobuf.Timestamp
+	32, // 24: html2md.v1.Job.finished_at:type_name -> google.protobuf.Timestamp
+	32, // 25: html2md.v1.Job.expires_at:type_name -> google.protobuf.Timestamp
+	23, // 26: html2md.v1.JobResult.job:type_name -> html2md.v1.Job
+	4,  // 27: html2md.v1.JobResult.convert:type_name -> html2md.v1.ConvertResponse
+	12, // 28: html2md.v1.JobResult.batch:type_name -> html2md.v1.BatchConvertResponse
+	16, // 29: html2md.v1.JobResult.epub:type_name -> html2md.v1.ConvertEPUBResponse
+	32, // 30: html2md.v1.HealthCheckResponse.timestamp:type_name -> google.protobuf.Timestamp
+	27, // 31: html2md.v1.HealthCheckResponse.memory:type_name -> html2md.v1.MemInfo
+	30, // 32: html2md.v1.GetConverterInfoResponse.config:type_name -> html2md.v1.GetConverterInfoResponse.ConfigEntry
+	0,  // 33: html2md.v1.ConvertService.Convert:input_type -> html2md.v1.ConvertRequest
+	11, // 34: html2md.v1.ConvertService.ConvertBatch:input_type -> html2md.v1.BatchConvertRequest
+	0,  // 35: html2md.v1.ConvertService.ConvertStream:input_type -> html2md.v1.ConvertRequest
+	15, // 36: html2md.v1.ConvertService.ConvertEPUB:input_type -> html2md.v1.ConvertEPUBRequest
+	18, // 37: html2md.v1.ConvertService.ConvertEmail:input_type -> html2md.v1.ConvertEmailRequest
+	21, // 38: html2md.v1.ConvertService.SubmitJob:input_type -> html2md.v1.SubmitJobRequest
+	22, // 39: html2md.v1.ConvertService.GetJob:input_type -> html2md.v1.GetJobRequest
+	22, // 40: html2md.v1.ConvertService.GetJobResult:input_type -> html2md.v1.GetJobRequest
+	22, // 41: html2md.v1.ConvertService.CancelJob:input_type -> html2md.v1.GetJobRequest
+	25, // 42: html2md.v1.ConvertService.HealthCheck:input_type -> html2md.v1.HealthCheckRequest
+	28, // 43: html2md.v1.ConvertService.GetConverterInfo:input_type -> html2md.v1.GetConverterInfoRequest
+	4,  // 44: html2md.v1.ConvertService.Convert:output_type -> html2md.v1.ConvertResponse
+	12, // 45: html2md.v1.ConvertService.ConvertBatch:output_type -> html2md.v1.BatchConvertResponse
+	13, // 46: html2md.v1.ConvertService.ConvertStream:output_type -> html2md.v1.BatchConvertItem
+	16, // 47: html2md.v1.ConvertService.ConvertEPUB:output_type -> html2md.v1.ConvertEPUBResponse
+	19, // 48: html2md.v1.ConvertService.ConvertEmail:output_type -> html2md.v1.ConvertEmailResponse
+	23, // 49: html2md.v1.ConvertService.SubmitJob:output_type -> html2md.v1.Job
+	23, // 50: html2md.v1.ConvertService.GetJob:output_type -> html2md.v1.Job
+	24, // 51: html2md.v1.ConvertService.GetJobResult:output_type -> html2md.v1.JobResult
+	23, // 52: html2md.v1.ConvertService.CancelJob:output_type -> html2md.v1.Job
+	26, // 53: html2md.v1.ConvertService.HealthCheck:output_type -> html2md.v1.HealthCheckResponse
+	29, // 54: html2md.v1.ConvertService.GetConverterInfo:output_type -> html2md.v1.GetConverterInfoResponse
+	44, // [44:55] is the sub-list for method output_type
+	33, // [33:44] is the sub-list for method input_type
+	33, // [33:33] is the sub-list for extension type_name
+	33, // [33:33] is the sub-list for extension extendee
+	0,  // [0:33] is the sub-list for field type_name
 }
 
 func init() { file_api_grpc_proto_convert_proto_init() }
@@ -845,7 +2601,7 @@ func file_api_grpc_proto_convert_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_grpc_proto_convert_proto_rawDesc), len(file_api_grpc_proto_convert_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   13,
+			NumMessages:   31,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -21,6 +21,13 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	ConvertService_Convert_FullMethodName          = "/html2md.v1.ConvertService/Convert"
 	ConvertService_ConvertBatch_FullMethodName     = "/html2md.v1.ConvertService/ConvertBatch"
+	ConvertService_ConvertStream_FullMethodName    = "/html2md.v1.ConvertService/ConvertStream"
+	ConvertService_ConvertEPUB_FullMethodName      = "/html2md.v1.ConvertService/ConvertEPUB"
+	ConvertService_ConvertEmail_FullMethodName     = "/html2md.v1.ConvertService/ConvertEmail"
+	ConvertService_SubmitJob_FullMethodName        = "/html2md.v1.ConvertService/SubmitJob"
+	ConvertService_GetJob_FullMethodName           = "/html2md.v1.ConvertService/GetJob"
+	ConvertService_GetJobResult_FullMethodName     = "/html2md.v1.ConvertService/GetJobResult"
+	ConvertService_CancelJob_FullMethodName        = "/html2md.v1.ConvertService/CancelJob"
 	ConvertService_HealthCheck_FullMethodName      = "/html2md.v1.ConvertService/HealthCheck"
 	ConvertService_GetConverterInfo_FullMethodName = "/html2md.v1.ConvertService/GetConverterInfo"
 )
@@ -35,6 +42,20 @@ type ConvertServiceClient interface {
 	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error)
 	// 批量转换HTML为Markdown
 	ConvertBatch(ctx context.Context, in *BatchConvertRequest, opts ...grpc.CallOption) (*BatchConvertResponse, error)
+	// 流式转换：逐条发送HTML，按接收顺序逐条返回结果，index为该条在流中的序号
+	ConvertStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ConvertRequest, BatchConvertItem], error)
+	// 转换EPUB电子书，按阅读顺序转换各章节
+	ConvertEPUB(ctx context.Context, in *ConvertEPUBRequest, opts ...grpc.CallOption) (*ConvertEPUBResponse, error)
+	// 转换邮件（.eml）或MHTML存档
+	ConvertEmail(ctx context.Context, in *ConvertEmailRequest, opts ...grpc.CallOption) (*ConvertEmailResponse, error)
+	// 提交异步转换任务，返回排队中的任务状态
+	SubmitJob(ctx context.Context, in *SubmitJobRequest, opts ...grpc.CallOption) (*Job, error)
+	// 获取异步任务状态
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+	// 获取成功任务的结果，任务未结束或失败时返回FailedPrecondition
+	GetJobResult(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*JobResult, error)
+	// 取消排队或执行中的任务；已结束的任务删除其状态和结果
+	CancelJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
 	// 健康检查
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
 	// 获取转换器信息
@@ -69,6 +90,79 @@ func (c *convertServiceClient) ConvertBatch(ctx context.Context, in *BatchConver
 	return out, nil
 }
 
+func (c *convertServiceClient) ConvertStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ConvertRequest, BatchConvertItem], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ConvertService_ServiceDesc.Streams[0], ConvertService_ConvertStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ConvertRequest, BatchConvertItem]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ConvertService_ConvertStreamClient = grpc.BidiStreamingClient[ConvertRequest, BatchConvertItem]
+
+func (c *convertServiceClient) ConvertEPUB(ctx context.Context, in *ConvertEPUBRequest, opts ...grpc.CallOption) (*ConvertEPUBResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConvertEPUBResponse)
+	err := c.cc.Invoke(ctx, ConvertService_ConvertEPUB_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *convertServiceClient) ConvertEmail(ctx context.Context, in *ConvertEmailRequest, opts ...grpc.CallOption) (*ConvertEmailResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConvertEmailResponse)
+	err := c.cc.Invoke(ctx, ConvertService_ConvertEmail_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *convertServiceClient) SubmitJob(ctx context.Context, in *SubmitJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, ConvertService_SubmitJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *convertServiceClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, ConvertService_GetJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *convertServiceClient) GetJobResult(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*JobResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JobResult)
+	err := c.cc.Invoke(ctx, ConvertService_GetJobResult_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *convertServiceClient) CancelJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, ConvertService_CancelJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *convertServiceClient) HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(HealthCheckResponse)
@@ -99,6 +193,20 @@ type ConvertServiceServer interface {
 	Convert(context.Context, *ConvertRequest) (*ConvertResponse, error)
 	// 批量转换HTML为Markdown
 	ConvertBatch(context.Context, *BatchConvertRequest) (*BatchConvertResponse, error)
+	// 流式转换：逐条发送HTML，按接收顺序逐条返回结果，index为该条在流中的序号
+	ConvertStream(grpc.BidiStreamingServer[ConvertRequest, BatchConvertItem]) error
+	// 转换EPUB电子书，按阅读顺序转换各章节
+	ConvertEPUB(context.Context, *ConvertEPUBRequest) (*ConvertEPUBResponse, error)
+	// 转换邮件（.eml）或MHTML存档
+	ConvertEmail(context.Context, *ConvertEmailRequest) (*ConvertEmailResponse, error)
+	// 提交异步转换任务，返回排队中的任务状态
+	SubmitJob(context.Context, *SubmitJobRequest) (*Job, error)
+	// 获取异步任务状态
+	GetJob(context.Context, *GetJobRequest) (*Job, error)
+	// 获取成功任务的结果，任务未结束或失败时返回FailedPrecondition
+	GetJobResult(context.Context, *GetJobRequest) (*JobResult, error)
+	// 取消排队或执行中的任务；已结束的任务删除其状态和结果
+	CancelJob(context.Context, *GetJobRequest) (*Job, error)
 	// 健康检查
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
 	// 获取转换器信息
@@ -119,6 +227,27 @@ func (UnimplementedConvertServiceServer) Convert(context.Context, *ConvertReques
 func (UnimplementedConvertServiceServer) ConvertBatch(context.Context, *BatchConvertRequest) (*BatchConvertResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ConvertBatch not implemented")
 }
+func (UnimplementedConvertServiceServer) ConvertStream(grpc.BidiStreamingServer[ConvertRequest, BatchConvertItem]) error {
+	return status.Errorf(codes.Unimplemented, "method ConvertStream not implemented")
+}
+func (UnimplementedConvertServiceServer) ConvertEPUB(context.Context, *ConvertEPUBRequest) (*ConvertEPUBResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConvertEPUB not implemented")
+}
+func (UnimplementedConvertServiceServer) ConvertEmail(context.Context, *ConvertEmailRequest) (*ConvertEmailResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConvertEmail not implemented")
+}
+func (UnimplementedConvertServiceServer) SubmitJob(context.Context, *SubmitJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitJob not implemented")
+}
+func (UnimplementedConvertServiceServer) GetJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedConvertServiceServer) GetJobResult(context.Context, *GetJobRequest) (*JobResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJobResult not implemented")
+}
+func (UnimplementedConvertServiceServer) CancelJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelJob not implemented")
+}
 func (UnimplementedConvertServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
@@ -182,6 +311,121 @@ func _ConvertService_ConvertBatch_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ConvertService_ConvertStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ConvertServiceServer).ConvertStream(&grpc.GenericServerStream[ConvertRequest, BatchConvertItem]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ConvertService_ConvertStreamServer = grpc.BidiStreamingServer[ConvertRequest, BatchConvertItem]
+
+func _ConvertService_ConvertEPUB_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertEPUBRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConvertServiceServer).ConvertEPUB(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConvertService_ConvertEPUB_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConvertServiceServer).ConvertEPUB(ctx, req.(*ConvertEPUBRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConvertService_ConvertEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConvertServiceServer).ConvertEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConvertService_ConvertEmail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConvertServiceServer).ConvertEmail(ctx, req.(*ConvertEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConvertService_SubmitJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConvertServiceServer).SubmitJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConvertService_SubmitJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConvertServiceServer).SubmitJob(ctx, req.(*SubmitJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConvertService_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConvertServiceServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConvertService_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConvertServiceServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConvertService_GetJobResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConvertServiceServer).GetJobResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConvertService_GetJobResult_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConvertServiceServer).GetJobResult(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConvertService_CancelJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConvertServiceServer).CancelJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConvertService_CancelJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConvertServiceServer).CancelJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ConvertService_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthCheckRequest)
 	if err := dec(in); err != nil {
@@ -233,6 +477,30 @@ var ConvertService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ConvertBatch",
 			Handler:    _ConvertService_ConvertBatch_Handler,
 		},
+		{
+			MethodName: "ConvertEPUB",
+			Handler:    _ConvertService_ConvertEPUB_Handler,
+		},
+		{
+			MethodName: "ConvertEmail",
+			Handler:    _ConvertService_ConvertEmail_Handler,
+		},
+		{
+			MethodName: "SubmitJob",
+			Handler:    _ConvertService_SubmitJob_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _ConvertService_GetJob_Handler,
+		},
+		{
+			MethodName: "GetJobResult",
+			Handler:    _ConvertService_GetJobResult_Handler,
+		},
+		{
+			MethodName: "CancelJob",
+			Handler:    _ConvertService_CancelJob_Handler,
+		},
 		{
 			MethodName: "HealthCheck",
 			Handler:    _ConvertService_HealthCheck_Handler,
@@ -242,6 +510,13 @@ var ConvertService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _ConvertService_GetConverterInfo_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ConvertStream",
+			Handler:       _ConvertService_ConvertStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "api/grpc/proto/convert.proto",
 }
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/relaxcloud-cn/html2md/api/grpc/proto"
+	"github.com/relaxcloud-cn/html2md/internal/auth"
+)
+
+// methodScopes GRPC方法所需的授权范围，空字符串表示只要求密钥有效
+var methodScopes = map[string]string{
+	pb.ConvertService_Convert_FullMethodName:          auth.ScopeConvert,
+	pb.ConvertService_ConvertBatch_FullMethodName:     auth.ScopeBatch,
+	pb.ConvertService_ConvertStream_FullMethodName:    auth.ScopeBatch,
+	pb.ConvertService_ConvertEPUB_FullMethodName:      auth.ScopeBatch,
+	pb.ConvertService_ConvertEmail_FullMethodName:     auth.ScopeConvert,
+	pb.ConvertService_SubmitJob_FullMethodName:        auth.ScopeBatch,
+	pb.ConvertService_GetJob_FullMethodName:           "",
+	pb.ConvertService_GetJobResult_FullMethodName:     "",
+	pb.ConvertService_CancelJob_FullMethodName:        "",
+	pb.ConvertService_HealthCheck_FullMethodName:      "",
+	pb.ConvertService_GetConverterInfo_FullMethodName: "",
+}
+
+// AuthUnaryInterceptor API密钥认证拦截器
+//
+// 密钥通过metadata中的 authorization: Bearer <key> 或 x-api-key 传递。
+func AuthUnaryInterceptor(authenticator *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, authenticator, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamInterceptor 流式调用的API密钥认证拦截器
+func AuthStreamInterceptor(authenticator *auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), authenticator, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate 校验请求密钥，成功时返回携带调用方的上下文
+func authenticate(ctx context.Context, authenticator *auth.Authenticator, method string) (context.Context, error) {
+	if method == pb.ConvertService_HealthCheck_FullMethodName && authenticator.PublicHealth() {
+		return ctx, nil
+	}
+
+	scope, known := methodScopes[method]
+	if !known {
+		// 未登记的方法要求管理权限
+		scope = auth.ScopeAdmin
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	key := auth.ExtractKey(firstValue(md, "authorization"), firstValue(md, "x-api-key"))
+
+	principal, err := authenticator.Authenticate(key, scope)
+	switch {
+	case errors.Is(err, auth.ErrForbidden):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if principal != nil {
+		ctx = auth.WithPrincipal(ctx, principal)
+	}
+	return ctx, nil
+}
+
+// firstValue 获取metadata中指定键的第一个值
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// authenticatedStream 替换上下文的ServerStream
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回携带调用方的上下文
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
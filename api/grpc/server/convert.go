@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"errors"
+	"io"
 	"runtime"
 	"time"
 
@@ -12,20 +14,26 @@ import (
 
 	pb "github.com/relaxcloud-cn/html2md/api/grpc/proto"
 	"github.com/relaxcloud-cn/html2md/internal/model"
+	"github.com/relaxcloud-cn/html2md/internal/ratelimit"
 	"github.com/relaxcloud-cn/html2md/internal/service"
+	"github.com/relaxcloud-cn/html2md/pkg/chunk"
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+	"github.com/relaxcloud-cn/html2md/pkg/epub"
 )
 
 // ConvertServer GRPC转换服务器
 type ConvertServer struct {
 	pb.UnimplementedConvertServiceServer
 	service   *service.ConvertService
+	jobs      *service.JobQueue
 	startTime time.Time
 }
 
-// NewConvertServer 创建GRPC转换服务器
-func NewConvertServer() *ConvertServer {
+// NewConvertServer 创建GRPC转换服务器，转换服务和异步任务队列与HTTP接口共用
+func NewConvertServer(svc *service.ConvertService, jobs *service.JobQueue) *ConvertServer {
 	return &ConvertServer{
-		service:   service.NewConvertService(),
+		service:   svc,
+		jobs:      jobs,
 		startTime: time.Now(),
 	}
 }
@@ -33,53 +41,42 @@ func NewConvertServer() *ConvertServer {
 // Convert 转换HTML为Markdown
 func (s *ConvertServer) Convert(ctx context.Context, req *pb.ConvertRequest) (*pb.ConvertResponse, error) {
 	// 将protobuf请求转换为内部模型
-	modelReq := &model.ConvertRequest{
-		HTML: req.Html,
-	}
+	modelReq := fromPBRequest(req)
 
 	// 执行转换
-	result, err := s.service.Convert(modelReq)
+	result, err := s.service.Convert(ctx, modelReq)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "转换失败: %v", err)
+		return nil, status.Errorf(errorCode(err), "转换失败: %v", err)
 	}
 
 	// 转换结果为protobuf响应
-	response := &pb.ConvertResponse{
-		Markdown: result.Markdown,
-	}
+	return toPBResponse(result), nil
+}
 
-	// 转换统计信息
-	if result.Stats != nil {
-		response.Stats = &pb.ConversionStats{
-			InputSize:      int32(result.Stats.InputSize),
-			OutputSize:     int32(result.Stats.OutputSize),
-			ProcessingTime: durationpb.New(result.Stats.ProcessingTime),
-		}
+// ConvertBatch 批量转换HTML为Markdown
+func (s *ConvertServer) ConvertBatch(ctx context.Context, req *pb.BatchConvertRequest) (*pb.BatchConvertResponse, error) {
+	// 执行批量转换
+	result, err := s.service.ConvertBatch(ctx, fromPBBatchRequest(req))
+	if err != nil {
+		return nil, status.Errorf(errorCode(err), "批量转换失败: %v", err)
 	}
 
-	return response, nil
+	return toPBBatchResponse(result), nil
 }
 
-// ConvertBatch 批量转换HTML为Markdown
-func (s *ConvertServer) ConvertBatch(ctx context.Context, req *pb.BatchConvertRequest) (*pb.BatchConvertResponse, error) {
-	// 转换请求
+// fromPBBatchRequest 将protobuf批量转换请求转为内部模型
+func fromPBBatchRequest(req *pb.BatchConvertRequest) *model.BatchConvertRequest {
 	modelReq := &model.BatchConvertRequest{
 		Items: make([]model.ConvertRequest, len(req.Items)),
 	}
-
 	for i, item := range req.Items {
-		modelReq.Items[i] = model.ConvertRequest{
-			HTML: item.Html,
-		}
-	}
-
-	// 执行批量转换
-	result, err := s.service.ConvertBatch(modelReq)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "批量转换失败: %v", err)
+		modelReq.Items[i] = *fromPBRequest(item)
 	}
+	return modelReq
+}
 
-	// 转换结果
+// toPBBatchResponse 将批量转换结果转为protobuf响应
+func toPBBatchResponse(result *model.BatchConvertResponse) *pb.BatchConvertResponse {
 	response := &pb.BatchConvertResponse{
 		Results: make([]*pb.BatchConvertItem, len(result.Results)),
 	}
@@ -93,19 +90,7 @@ func (s *ConvertServer) ConvertBatch(ctx context.Context, req *pb.BatchConvertRe
 
 		if item.Result != nil {
 			// 转换成功的结果
-			pbResult := &pb.ConvertResponse{
-				Markdown: item.Result.Markdown,
-			}
-
-			if item.Result.Stats != nil {
-				pbResult.Stats = &pb.ConversionStats{
-					InputSize:      int32(item.Result.Stats.InputSize),
-					OutputSize:     int32(item.Result.Stats.OutputSize),
-					ProcessingTime: durationpb.New(item.Result.Stats.ProcessingTime),
-				}
-			}
-
-			response.Results[i].Result = pbResult
+			response.Results[i].Result = toPBResponse(item.Result)
 		}
 	}
 
@@ -120,7 +105,230 @@ func (s *ConvertServer) ConvertBatch(ctx context.Context, req *pb.BatchConvertRe
 		}
 	}
 
-	return response, nil
+	return response
+}
+
+// ConvertStream 流式转换，单条失败时在结果中返回错误而不中断流
+func (s *ConvertServer) ConvertStream(stream pb.ConvertService_ConvertStreamServer) error {
+	for index := 0; ; index++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		item := &pb.BatchConvertItem{Index: int32(index)}
+		result, err := s.service.Convert(stream.Context(), fromPBRequest(req))
+		if err != nil {
+			item.Error = err.Error()
+		} else {
+			item.Success = true
+			item.Result = toPBResponse(result)
+		}
+
+		if err := stream.Send(item); err != nil {
+			return err
+		}
+	}
+}
+
+// ConvertEPUB 转换EPUB电子书
+func (s *ConvertServer) ConvertEPUB(ctx context.Context, req *pb.ConvertEPUBRequest) (*pb.ConvertEPUBResponse, error) {
+	archive, err := s.service.OpenArchive(req.Data)
+	if err != nil {
+		return nil, status.Errorf(errorCode(err), "EPUB转换失败: %v", err)
+	}
+
+	result, err := s.service.ConvertEPUB(ctx, archive, req.Split)
+	if err != nil {
+		return nil, status.Errorf(errorCode(err), "EPUB转换失败: %v", err)
+	}
+
+	return toPBEPUBResponse(result.Title, result.Markdown, result.Chapters, result.Archive), nil
+}
+
+// toPBEPUBResponse 将EPUB转换结果转为protobuf响应
+func toPBEPUBResponse(title, markdown string, chapters []epub.Chapter, archive []byte) *pb.ConvertEPUBResponse {
+	response := &pb.ConvertEPUBResponse{
+		Title:    title,
+		Markdown: markdown,
+		Archive:  archive,
+		Chapters: make([]*pb.EPUBChapter, len(chapters)),
+	}
+	for i, ch := range chapters {
+		response.Chapters[i] = &pb.EPUBChapter{Source: ch.Source, Title: ch.Title, Output: ch.Output}
+	}
+	return response
+}
+
+// ConvertEmail 转换邮件或MHTML存档
+func (s *ConvertServer) ConvertEmail(ctx context.Context, req *pb.ConvertEmailRequest) (*pb.ConvertEmailResponse, error) {
+	result, err := s.service.ConvertEmail(ctx, req.Data, service.EmailOptions{
+		FrontMatter:  req.FrontMatter,
+		InlineImages: req.InlineImages,
+		Archive:      req.Archive,
+		Cleanup:      fromPBEmailOptions(req.Cleanup),
+	})
+	if err != nil {
+		return nil, status.Errorf(errorCode(err), "邮件转换失败: %v", err)
+	}
+
+	return &pb.ConvertEmailResponse{
+		Markdown: result.Markdown,
+		Header: &pb.EmailHeader{
+			From:    result.Header.From,
+			To:      result.Header.To,
+			Subject: result.Header.Subject,
+			Date:    result.Header.Date,
+			Url:     result.Header.URL,
+		},
+		Archive: result.Archive,
+	}, nil
+}
+
+// fromPBRequest 将protobuf转换请求转为内部模型
+func fromPBRequest(req *pb.ConvertRequest) *model.ConvertRequest {
+	modelReq := &model.ConvertRequest{
+		HTML:        req.Html,
+		URL:         req.Url,
+		BaseURL:     req.BaseUrl,
+		Rules:       fromPBRules(req.Rules),
+		Plugins:     req.Plugins,
+		Profiles:    req.Profiles,
+		InferStyles: req.InferStyles,
+		Tokenizer:   req.Tokenizer,
+		MaxTokens:   int(req.MaxTokens),
+		TOC:         req.Toc,
+		Inventory:   req.Inventory,
+	}
+	if req.Email != nil {
+		opts := fromPBEmailOptions(req.Email)
+		modelReq.Email = &opts
+	}
+	if req.Chunking != nil {
+		modelReq.Chunking = &chunk.Options{
+			Unit:       req.Chunking.Unit,
+			TargetSize: int(req.Chunking.TargetSize),
+			MaxSize:    int(req.Chunking.MaxSize),
+			Overlap:    int(req.Chunking.Overlap),
+		}
+	}
+	return modelReq
+}
+
+// fromPBEmailOptions 转换email预处理配置的选项
+func fromPBEmailOptions(opts *pb.EmailOptions) converter.EmailOptions {
+	return converter.EmailOptions{
+		Quotes:         opts.GetQuotes(),
+		StripSignature: opts.GetStripSignature(),
+	}
+}
+
+// fromPBRules 转换请求中的自定义规则
+func fromPBRules(rules []*pb.Rule) []converter.Rule {
+	if len(rules) == 0 {
+		return nil
+	}
+	result := make([]converter.Rule, len(rules))
+	for i, r := range rules {
+		result[i] = converter.Rule{
+			Selector: r.Selector,
+			Action:   r.Action,
+			Template: r.Template,
+		}
+	}
+	return result
+}
+
+// toPBResponse 将转换结果转为protobuf响应
+func toPBResponse(result *model.ConvertResponse) *pb.ConvertResponse {
+	response := &pb.ConvertResponse{
+		Markdown: result.Markdown,
+	}
+	if result.Stats != nil {
+		response.Stats = &pb.ConversionStats{
+			InputSize:          int32(result.Stats.InputSize),
+			OutputSize:         int32(result.Stats.OutputSize),
+			ProcessingTime:     durationpb.New(result.Stats.ProcessingTime),
+			Tokens:             int32(result.Stats.Tokens),
+			Tokenizer:          result.Stats.Tokenizer,
+			Truncated:          result.Stats.Truncated,
+			TruncatedBytes:     int32(result.Stats.TruncatedBytes),
+			TruncatedTokens:    int32(result.Stats.TruncatedTokens),
+			Links:              int32(result.Stats.Links),
+			Images:             int32(result.Stats.Images),
+			Words:              int32(result.Stats.Words),
+			Chars:              int32(result.Stats.Chars),
+			ReadingSeconds:     int32(result.Stats.ReadingSeconds),
+			Paragraphs:         int32(result.Stats.Paragraphs),
+			Headings:           int32(result.Stats.Headings),
+			CodeBlocks:         int32(result.Stats.CodeBlocks),
+			Language:           result.Stats.Language,
+			LanguageConfidence: result.Stats.LanguageConfidence,
+		}
+	}
+	for _, c := range result.Chunks {
+		response.Chunks = append(response.Chunks, &pb.Chunk{
+			Index:    int32(c.Index),
+			Headings: c.Headings,
+			Content:  c.Content,
+			Start:    int32(c.Start),
+			End:      int32(c.End),
+			Bytes:    int32(c.Bytes),
+			Chars:    int32(c.Chars),
+			Tokens:   int32(c.Tokens),
+		})
+	}
+	response.Outline = toPBOutline(result.Outline)
+	if inv := result.Inventory; inv != nil {
+		response.Inventory = toPBInventory(inv)
+	}
+	return response
+}
+
+// toPBInventory 转换链接和图片清单
+func toPBInventory(inv *converter.Inventory) *pb.Inventory {
+	result := &pb.Inventory{}
+	for _, l := range inv.Links {
+		result.Links = append(result.Links, &pb.Link{
+			Text:     l.Text,
+			Url:      l.URL,
+			Title:    l.Title,
+			Internal: l.Internal,
+			Fragment: l.Fragment,
+		})
+	}
+	for _, img := range inv.Images {
+		result.Images = append(result.Images, &pb.Image{
+			Alt:     img.Alt,
+			Src:     img.Src,
+			Title:   img.Title,
+			Width:   int32(img.Width),
+			Height:  int32(img.Height),
+			DataUri: img.DataURI,
+		})
+	}
+	return result
+}
+
+// toPBOutline 转换标题大纲
+func toPBOutline(headings []chunk.Heading) []*pb.OutlineHeading {
+	if len(headings) == 0 {
+		return nil
+	}
+	result := make([]*pb.OutlineHeading, len(headings))
+	for i, h := range headings {
+		result[i] = &pb.OutlineHeading{
+			Level:    int32(h.Level),
+			Text:     h.Text,
+			Slug:     h.Slug,
+			Offset:   int32(h.Offset),
+			Children: toPBOutline(h.Children),
+		}
+	}
+	return result
 }
 
 // HealthCheck 健康检查
@@ -160,6 +368,12 @@ func (s *ConvertServer) GetConverterInfo(ctx context.Context, req *pb.GetConvert
 	if plugins, ok := info["supported_plugins"].([]string); ok {
 		response.SupportedPlugins = plugins
 	}
+	if profiles, ok := info["supported_profiles"].([]string); ok {
+		response.SupportedProfiles = profiles
+	}
+	if tokenizers, ok := info["supported_tokenizers"].([]string); ok {
+		response.SupportedTokenizers = tokenizers
+	}
 
 	// 转换功能特性
 	if features, ok := info["features"].([]string); ok {
@@ -168,3 +382,18 @@ func (s *ConvertServer) GetConverterInfo(ctx context.Context, req *pb.GetConvert
 
 	return response, nil
 }
+
+// errorCode 根据错误类型选择GRPC状态码
+func errorCode(err error) codes.Code {
+	var limited *ratelimit.LimitError
+	if errors.As(err, &limited) || errors.Is(err, ratelimit.ErrCostTooLarge) {
+		return codes.ResourceExhausted
+	}
+	if service.IsBadRequest(err) {
+		return codes.InvalidArgument
+	}
+	if errors.Is(err, service.ErrFetchFailed) {
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/relaxcloud-cn/html2md/api/grpc/proto"
+	"github.com/relaxcloud-cn/html2md/internal/model"
+	"github.com/relaxcloud-cn/html2md/internal/service"
+)
+
+// SubmitJob 提交异步转换任务
+func (s *ConvertServer) SubmitJob(ctx context.Context, req *pb.SubmitJobRequest) (*pb.Job, error) {
+	job, err := s.jobs.Submit(ctx, fromPBJobRequest(req))
+	if err != nil {
+		return nil, status.Errorf(jobErrorCode(err), "提交任务失败: %v", err)
+	}
+	return toPBJob(job), nil
+}
+
+// GetJob 获取异步任务状态
+func (s *ConvertServer) GetJob(ctx context.Context, req *pb.GetJobRequest) (*pb.Job, error) {
+	job, err := s.jobs.Get(ctx, req.Id)
+	if err != nil {
+		return nil, status.Errorf(jobErrorCode(err), "获取任务失败: %v", err)
+	}
+	return toPBJob(job), nil
+}
+
+// GetJobResult 获取成功任务的结果
+func (s *ConvertServer) GetJobResult(ctx context.Context, req *pb.GetJobRequest) (*pb.JobResult, error) {
+	job, result, err := s.jobs.Result(ctx, req.Id)
+	if err != nil {
+		return nil, status.Errorf(jobErrorCode(err), "获取任务结果失败: %v", err)
+	}
+
+	response := &pb.JobResult{Job: toPBJob(job)}
+	switch {
+	case result.Convert != nil:
+		response.Convert = toPBResponse(result.Convert)
+	case result.Batch != nil:
+		response.Batch = toPBBatchResponse(result.Batch)
+	case result.EPUB != nil:
+		response.Epub = toPBEPUBResponse(result.EPUB.Title, result.EPUB.Markdown, result.EPUB.Chapters, result.Archive)
+	default:
+		response.Archive = result.Archive
+	}
+	return response, nil
+}
+
+// CancelJob 取消异步任务，已结束的任务删除其状态和结果
+func (s *ConvertServer) CancelJob(ctx context.Context, req *pb.GetJobRequest) (*pb.Job, error) {
+	job, err := s.jobs.Cancel(ctx, req.Id)
+	if err != nil {
+		return nil, status.Errorf(jobErrorCode(err), "取消任务失败: %v", err)
+	}
+	return toPBJob(job), nil
+}
+
+// fromPBJobRequest 将protobuf任务请求转为内部模型
+func fromPBJobRequest(req *pb.SubmitJobRequest) *model.JobRequest {
+	modelReq := &model.JobRequest{
+		Type:    req.Type,
+		Archive: req.Archive,
+		Split:   req.Split,
+		Webhook: req.Webhook,
+	}
+	if req.Convert != nil {
+		modelReq.Convert = fromPBRequest(req.Convert)
+	}
+	if req.Batch != nil {
+		modelReq.Batch = fromPBBatchRequest(req.Batch)
+	}
+	return modelReq
+}
+
+// toPBJob 将任务状态转为protobuf消息
+func toPBJob(job *model.Job) *pb.Job {
+	return &pb.Job{
+		Id:         job.ID,
+		Type:       job.Type,
+		Status:     job.Status,
+		Done:       int32(job.Progress.Done),
+		Total:      int32(job.Progress.Total),
+		Error:      job.Error,
+		CreatedAt:  timestamppb.New(job.CreatedAt),
+		StartedAt:  toPBTimestamp(job.StartedAt),
+		FinishedAt: toPBTimestamp(job.FinishedAt),
+		ExpiresAt:  toPBTimestamp(job.ExpiresAt),
+	}
+}
+
+// toPBTimestamp 转换可选的时间，未设置时返回nil
+func toPBTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// jobErrorCode 根据任务错误选择GRPC状态码
+func jobErrorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, service.ErrJobNotFound):
+		return codes.NotFound
+	case errors.Is(err, service.ErrJobNotReady):
+		return codes.FailedPrecondition
+	case errors.Is(err, service.ErrQueueFull), errors.Is(err, service.ErrStoreFull):
+		return codes.Unavailable
+	}
+	return errorCode(err)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/relaxcloud-cn/html2md/api/grpc/proto"
+	"github.com/relaxcloud-cn/html2md/internal/model"
+	"github.com/relaxcloud-cn/html2md/internal/ratelimit"
+	"github.com/relaxcloud-cn/html2md/internal/service"
+)
+
+// RateLimitUnaryInterceptor 限流拦截器，需在认证拦截器之后执行以便按API密钥计算配额
+//
+// 超出配额时返回ResourceExhausted，并在响应头metadata中设置retry-after（秒）。
+func RateLimitUnaryInterceptor(limiter *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		cost, limited := requestCost(req)
+		if !limited {
+			return handler(ctx, req)
+		}
+
+		meter := limiter.Meter(ratelimit.ClientID(ctx, peerIP(ctx)))
+		if err := meter.Charge(ctx, cost); err != nil {
+			return nil, limitStatus(err, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) })
+		}
+
+		// 按URL抓取的页面字节数在抓取后继续扣减
+		return handler(ratelimit.WithMeter(ctx, meter), req)
+	}
+}
+
+// RateLimitStreamInterceptor 流式调用的限流拦截器，每条消息按批量转换的一项计入配额
+func RateLimitStreamInterceptor(limiter *ratelimit.Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		meter := limiter.Meter(ratelimit.ClientID(ctx, peerIP(ctx)))
+		return handler(srv, &rateLimitedStream{ServerStream: ss, meter: meter, ctx: ratelimit.WithMeter(ctx, meter)})
+	}
+}
+
+// rateLimitedStream 接收消息时检查配额的ServerStream
+type rateLimitedStream struct {
+	grpc.ServerStream
+	meter *ratelimit.Meter
+	ctx   context.Context
+}
+
+// Context 返回附带限流计量器的上下文
+func (s *rateLimitedStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecvMsg 接收消息，超出配额时以ResourceExhausted结束流
+func (s *rateLimitedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	req, ok := m.(*pb.ConvertRequest)
+	if !ok {
+		return nil
+	}
+
+	if err := s.meter.Charge(s.ctx, ratelimit.Cost{BatchItems: 1, InputBytes: len(req.Html)}); err != nil {
+		return limitStatus(err, s.SetHeader)
+	}
+	return nil
+}
+
+// limitStatus 将限流错误转换为ResourceExhausted状态，配额不足时通过setHeader设置retry-after（秒）
+func limitStatus(err error, setHeader func(metadata.MD) error) error {
+	var limited *ratelimit.LimitError
+	if errors.As(err, &limited) {
+		retryAfter := strconv.Itoa(ratelimit.RetryAfterSeconds(limited.Wait))
+		_ = setHeader(metadata.Pairs("retry-after", retryAfter))
+		return status.Errorf(codes.ResourceExhausted, "%s，请在%s秒后重试", model.MsgTooManyRequest, retryAfter)
+	}
+	return status.Error(codes.ResourceExhausted, model.MsgTooLarge)
+}
+
+// requestCost 计算请求消耗的配额，不参与限流的请求返回false
+func requestCost(req interface{}) (ratelimit.Cost, bool) {
+	switch r := req.(type) {
+	case *pb.ConvertRequest:
+		return ratelimit.Cost{Requests: 1, InputBytes: len(r.Html)}, true
+	case *pb.BatchConvertRequest:
+		cost := ratelimit.Cost{BatchItems: len(r.Items)}
+		for _, item := range r.Items {
+			cost.InputBytes += len(item.Html)
+		}
+		return cost, true
+	case *pb.ConvertEPUBRequest:
+		// 与HTTP接口一致，按压缩包中的页面数计入批量项目配额
+		cost := ratelimit.Cost{BatchItems: 1, InputBytes: len(r.Data)}
+		if zr, err := zip.NewReader(bytes.NewReader(r.Data), int64(len(r.Data))); err == nil {
+			cost.BatchItems = max(service.CountPages(zr), 1)
+		}
+		return cost, true
+	case *pb.ConvertEmailRequest:
+		return ratelimit.Cost{Requests: 1, InputBytes: len(r.Data)}, true
+	case *pb.SubmitJobRequest:
+		// 按任务类型与对应的同步接口计入相同的配额
+		switch r.Type {
+		case model.JobConvert:
+			return ratelimit.Cost{Requests: 1, InputBytes: len(r.GetConvert().GetHtml())}, true
+		case model.JobBatch:
+			return requestCost(&pb.BatchConvertRequest{Items: r.GetBatch().GetItems()})
+		default:
+			return requestCost(&pb.ConvertEPUBRequest{Data: r.Archive})
+		}
+	default:
+		return ratelimit.Cost{}, false
+	}
+}
+
+// peerIP 获取客户端IP
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return host
+	}
+	return p.Addr.String()
+}
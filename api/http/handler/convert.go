@@ -1,35 +1,51 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/relaxcloud-cn/html2md/internal/model"
+	"github.com/relaxcloud-cn/html2md/internal/ratelimit"
 	"github.com/relaxcloud-cn/html2md/internal/service"
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
 )
 
 // ConvertHandler HTML转换处理器
 type ConvertHandler struct {
 	service *service.ConvertService
+	jobs    *service.JobQueue
+	limiter *ratelimit.Limiter
 }
 
 // NewConvertHandler 创建转换处理器
-func NewConvertHandler(service *service.ConvertService) *ConvertHandler {
+func NewConvertHandler(service *service.ConvertService, jobs *service.JobQueue, limiter *ratelimit.Limiter) *ConvertHandler {
 	return &ConvertHandler{
 		service: service,
+		jobs:    jobs,
+		limiter: limiter,
 	}
 }
 
 // Convert 转换HTML为Markdown
 // @Summary 转换HTML为Markdown
-// @Description 将HTML内容转换为Markdown格式，支持多种转换选项和插件
+// @Description 将HTML内容转换为Markdown格式，支持多种转换选项和插件。设置 url 时由服务端抓取页面，按响应头识别字符集并以最终地址补全相对链接，默认禁止访问内网地址。请求头 Accept: text/markdown 时直接返回Markdown，统计信息在 X-* 响应头中（同 /api/v1/convert/raw）
 // @Tags 转换
 // @Accept json
-// @Produce json
+// @Produce json,text/markdown
 // @Param request body model.ConvertRequest true "转换请求参数"
 // @Success 200 {object} model.APIResponse{data=model.ConvertResponse} "转换成功"
 // @Failure 400 {object} model.APIResponse{data=interface{}} "请求参数错误"
+// @Failure 401 {object} model.APIResponse{data=interface{}} "未授权访问"
+// @Failure 403 {object} model.APIResponse{data=interface{}} "禁止访问"
+// @Failure 413 {object} model.APIResponse{data=interface{}} "超过单次限流配额"
+// @Failure 429 {object} model.APIResponse{data=interface{}} "请求过于频繁"
 // @Failure 500 {object} model.APIResponse{data=interface{}} "内部服务器错误"
+// @Failure 502 {object} model.APIResponse{data=interface{}} "抓取页面失败"
+// @Security ApiKeyAuth
 // @Router /api/v1/convert [post]
 func (h *ConvertHandler) Convert(c *gin.Context) {
 	var req model.ConvertRequest
@@ -42,29 +58,34 @@ func (h *ConvertHandler) Convert(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.Convert(&req)
+	if !h.allow(c, ratelimit.Cost{Requests: 1, InputBytes: len(req.HTML)}) {
+		return
+	}
+
+	result, err := h.service.Convert(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(
-			model.CodeInternalError,
-			"转换失败: "+err.Error(),
-			nil,
-		))
+		h.respondError(c, "转换失败: ", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+	respondConvert(c, result)
 }
 
 // ConvertBatch 批量转换HTML为Markdown
 // @Summary 批量转换HTML为Markdown
-// @Description 批量转换多个HTML内容为Markdown格式
+// @Description 批量转换多个HTML内容为Markdown格式，项目可使用 url 抓取页面，抓取失败记录在该项的结果中
 // @Tags 转换
 // @Accept json
 // @Produce json
 // @Param request body model.BatchConvertRequest true "批量转换请求参数"
 // @Success 200 {object} model.APIResponse{data=model.BatchConvertResponse} "转换成功"
 // @Failure 400 {object} model.APIResponse{data=interface{}} "请求参数错误"
+// @Failure 401 {object} model.APIResponse{data=interface{}} "未授权访问"
+// @Failure 403 {object} model.APIResponse{data=interface{}} "禁止访问"
+// @Failure 413 {object} model.APIResponse{data=interface{}} "超过单次限流配额"
+// @Failure 429 {object} model.APIResponse{data=interface{}} "请求过于频繁"
 // @Failure 500 {object} model.APIResponse{data=interface{}} "内部服务器错误"
+// @Security ApiKeyAuth
 // @Router /api/v1/convert/batch [post]
 func (h *ConvertHandler) ConvertBatch(c *gin.Context) {
 	var req model.BatchConvertRequest
@@ -77,17 +98,214 @@ func (h *ConvertHandler) ConvertBatch(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.ConvertBatch(&req)
+	inputBytes := 0
+	for _, item := range req.Items {
+		inputBytes += len(item.HTML)
+	}
+	if !h.allow(c, ratelimit.Cost{BatchItems: len(req.Items), InputBytes: inputBytes}) {
+		return
+	}
+
+	result, err := h.service.ConvertBatch(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(
-			model.CodeInternalError,
-			"批量转换失败: "+err.Error(),
+		h.respondError(c, "批量转换失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+}
+
+// ConvertSite 转换整站导出压缩包
+// @Summary 转换整站导出压缩包
+// @Description 上传包含HTML目录树的zip压缩包，返回保持目录结构的Markdown压缩包。站内链接改写为 .md 文件和标题锚点，引用的本地资源一并复制，转换报告（含无法解析的链接）位于压缩包内的 html2md-report.json
+// @Tags 转换
+// @Accept multipart/form-data
+// @Produce application/zip
+// @Param file formData file true "整站导出的zip压缩包"
+// @Success 200 {file} file "Markdown目录树压缩包"
+// @Header 200 {integer} X-Converted-Pages "转换成功的页面数"
+// @Header 200 {integer} X-Failed-Pages "转换失败的页面数"
+// @Header 200 {integer} X-Broken-Links "无法解析的站内链接数"
+// @Failure 400 {object} model.APIResponse{data=interface{}} "请求参数错误"
+// @Failure 401 {object} model.APIResponse{data=interface{}} "未授权访问"
+// @Failure 403 {object} model.APIResponse{data=interface{}} "禁止访问"
+// @Failure 413 {object} model.APIResponse{data=interface{}} "超过单次限流配额"
+// @Failure 429 {object} model.APIResponse{data=interface{}} "请求过于频繁"
+// @Failure 500 {object} model.APIResponse{data=interface{}} "内部服务器错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/convert/site [post]
+func (h *ConvertHandler) ConvertSite(c *gin.Context) {
+	data, err := readUpload(c, "file", h.service.MaxArchiveSize())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(
+			model.CodeBadRequest,
+			"请求参数格式错误: "+err.Error(),
 			nil,
 		))
 		return
 	}
 
-	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+	archive, err := h.service.OpenArchive(data)
+	if err != nil {
+		h.respondError(c, "整站转换失败: ", err)
+		return
+	}
+
+	if !h.allow(c, ratelimit.Cost{BatchItems: service.CountPages(archive), InputBytes: len(data)}) {
+		return
+	}
+
+	result, err := h.service.ConvertSite(c.Request.Context(), archive)
+	if err != nil {
+		h.respondError(c, "整站转换失败: ", err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="markdown.zip"`)
+	c.Header("X-Converted-Pages", strconv.Itoa(result.Report.Converted))
+	c.Header("X-Failed-Pages", strconv.Itoa(result.Report.Failed))
+	c.Header("X-Broken-Links", strconv.Itoa(len(result.Report.BrokenLinks)))
+	c.Data(http.StatusOK, "application/zip", result.Archive)
+}
+
+// ConvertEPUB 转换EPUB电子书
+// @Summary 转换EPUB电子书
+// @Description 上传EPUB文件，按阅读顺序转换各章节并改写章节间的链接。output=single（默认）返回附带目录的合并Markdown文档，图片引用保留为书内路径；output=split 返回每章一个Markdown文件的zip压缩包，包含目录 index.md 和引用的图片
+// @Tags 转换
+// @Accept multipart/form-data
+// @Produce json,application/zip
+// @Param file formData file true "EPUB文件"
+// @Param output query string false "输出方式: single, split" Enums(single, split)
+// @Success 200 {object} model.APIResponse{data=model.EPUBResponse} "合并输出"
+// @Header 200 {integer} X-Chapters "章节数"
+// @Failure 400 {object} model.APIResponse{data=interface{}} "请求参数错误"
+// @Failure 401 {object} model.APIResponse{data=interface{}} "未授权访问"
+// @Failure 403 {object} model.APIResponse{data=interface{}} "禁止访问"
+// @Failure 413 {object} model.APIResponse{data=interface{}} "超过单次限流配额"
+// @Failure 429 {object} model.APIResponse{data=interface{}} "请求过于频繁"
+// @Failure 500 {object} model.APIResponse{data=interface{}} "内部服务器错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/convert/epub [post]
+func (h *ConvertHandler) ConvertEPUB(c *gin.Context) {
+	output := c.DefaultQuery("output", "single")
+	if output != "single" && output != "split" {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(
+			model.CodeBadRequest,
+			"请求参数格式错误: output 可选 single 或 split",
+			nil,
+		))
+		return
+	}
+
+	data, err := readUpload(c, "file", h.service.MaxArchiveSize())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(
+			model.CodeBadRequest,
+			"请求参数格式错误: "+err.Error(),
+			nil,
+		))
+		return
+	}
+
+	archive, err := h.service.OpenArchive(data)
+	if err != nil {
+		h.respondError(c, "EPUB转换失败: ", err)
+		return
+	}
+
+	if !h.allow(c, ratelimit.Cost{BatchItems: service.CountPages(archive), InputBytes: len(data)}) {
+		return
+	}
+
+	result, err := h.service.ConvertEPUB(c.Request.Context(), archive, output == "split")
+	if err != nil {
+		h.respondError(c, "EPUB转换失败: ", err)
+		return
+	}
+
+	c.Header("X-Chapters", strconv.Itoa(len(result.Chapters)))
+	if output == "split" {
+		c.Header("Content-Disposition", `attachment; filename="markdown.zip"`)
+		c.Data(http.StatusOK, "application/zip", result.Archive)
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(&model.EPUBResponse{
+		Title:    result.Title,
+		Markdown: result.Markdown,
+		Chapters: result.Chapters,
+	}))
+}
+
+// ConvertEmail 转换邮件或MHTML存档
+// @Summary 转换邮件或MHTML存档
+// @Description 上传 .eml 邮件（message/rfc822）或 .mhtml 网页存档（multipart/related），选取HTML正文（没有时使用纯文本正文），经email预处理（删除跟踪图片和隐藏元素、展开布局表格）后转换为Markdown。正文中 cid: 及按原始地址引用的图片可内嵌为data URI（inline_images=true），或与 message.md 一起打包为zip（output=zip）；默认JSON输出时图片引用改写为 images/ 下的相对路径
+// @Tags 转换
+// @Accept multipart/form-data
+// @Produce json,application/zip
+// @Param file formData file true "邮件或MHTML文件"
+// @Param front_matter query bool false "在开头输出由From、To、Subject、Date组成的YAML front matter"
+// @Param inline_images query bool false "将引用的图片内嵌为data URI"
+// @Param output query string false "输出方式: json, zip" Enums(json, zip)
+// @Param quotes query string false "引用历史: keep, collapse, strip" Enums(keep, collapse, strip)
+// @Param strip_signature query bool false "删除签名"
+// @Success 200 {object} model.APIResponse{data=model.EmailResponse} "JSON输出"
+// @Failure 400 {object} model.APIResponse{data=interface{}} "请求参数错误"
+// @Failure 401 {object} model.APIResponse{data=interface{}} "未授权访问"
+// @Failure 403 {object} model.APIResponse{data=interface{}} "禁止访问"
+// @Failure 413 {object} model.APIResponse{data=interface{}} "超过单次限流配额"
+// @Failure 429 {object} model.APIResponse{data=interface{}} "请求过于频繁"
+// @Failure 500 {object} model.APIResponse{data=interface{}} "内部服务器错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/convert/email [post]
+func (h *ConvertHandler) ConvertEmail(c *gin.Context) {
+	var query model.EmailConvertQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(
+			model.CodeBadRequest,
+			"请求参数格式错误: "+err.Error(),
+			nil,
+		))
+		return
+	}
+	opts := service.EmailOptions{
+		FrontMatter:  query.FrontMatter,
+		InlineImages: query.InlineImages,
+		Archive:      query.Output == "zip",
+		Cleanup: converter.EmailOptions{
+			Quotes:         query.Quotes,
+			StripSignature: query.StripSignature,
+		},
+	}
+
+	data, err := readUpload(c, "file", h.service.MaxArchiveSize())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(
+			model.CodeBadRequest,
+			"请求参数格式错误: "+err.Error(),
+			nil,
+		))
+		return
+	}
+
+	if !h.allow(c, ratelimit.Cost{Requests: 1, InputBytes: len(data)}) {
+		return
+	}
+
+	result, err := h.service.ConvertEmail(c.Request.Context(), data, opts)
+	if err != nil {
+		h.respondError(c, "邮件转换失败: ", err)
+		return
+	}
+
+	if opts.Archive {
+		c.Header("Content-Disposition", `attachment; filename="markdown.zip"`)
+		c.Data(http.StatusOK, "application/zip", result.Archive)
+		return
+	}
+	c.JSON(http.StatusOK, model.NewSuccessResponse(&model.EmailResponse{
+		Header:   result.Header,
+		Markdown: result.Markdown,
+	}))
 }
 
 // Health 健康检查
@@ -118,6 +336,8 @@ func (h *ConvertHandler) Health(c *gin.Context) {
 // @Tags 系统
 // @Produce json
 // @Success 200 {object} model.APIResponse{data=map[string]interface{}} "获取成功"
+// @Failure 401 {object} model.APIResponse{data=interface{}} "未授权访问"
+// @Security ApiKeyAuth
 // @Router /api/v1/info [get]
 func (h *ConvertHandler) GetConverterInfo(c *gin.Context) {
 	info := h.service.GetConverterInfo()
@@ -126,14 +346,19 @@ func (h *ConvertHandler) GetConverterInfo(c *gin.Context) {
 
 // ConvertSimple 简单转换接口（GET方式）
 // @Summary 简单HTML转换（GET方式）
-// @Description 通过URL参数进行简单的HTML转换，适用于快速测试
+// @Description 通过URL参数进行简单的HTML转换，适用于快速测试。请求头 Accept: text/markdown 时直接返回Markdown
 // @Tags 转换
 // @Accept plain
-// @Produce json
+// @Produce json,text/markdown
 // @Param html query string true "HTML内容"
 // @Success 200 {object} model.APIResponse{data=model.ConvertResponse} "转换成功"
 // @Failure 400 {object} model.APIResponse{data=interface{}} "请求参数错误"
+// @Failure 401 {object} model.APIResponse{data=interface{}} "未授权访问"
+// @Failure 403 {object} model.APIResponse{data=interface{}} "禁止访问"
+// @Failure 413 {object} model.APIResponse{data=interface{}} "超过单次限流配额"
+// @Failure 429 {object} model.APIResponse{data=interface{}} "请求过于频繁"
 // @Failure 500 {object} model.APIResponse{data=interface{}} "内部服务器错误"
+// @Security ApiKeyAuth
 // @Router /api/v1/convert/simple [get]
 func (h *ConvertHandler) ConvertSimple(c *gin.Context) {
 	html := c.Query("html")
@@ -150,15 +375,110 @@ func (h *ConvertHandler) ConvertSimple(c *gin.Context) {
 		HTML: html,
 	}
 
-	result, err := h.service.Convert(req)
+	if !h.allow(c, ratelimit.Cost{Requests: 1, InputBytes: len(html)}) {
+		return
+	}
+
+	result, err := h.service.Convert(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.NewErrorResponse(
-			model.CodeInternalError,
-			"转换失败: "+err.Error(),
+		h.respondError(c, "转换失败: ", err)
+		return
+	}
+
+	respondConvert(c, result)
+}
+
+// allow 检查调用方的限流配额，超出时返回429并设置Retry-After，单次请求超过突发容量时返回413
+//
+// 通过检查后将计量器保存到请求上下文，按URL抓取的页面字节数在抓取后继续扣减。
+func (h *ConvertHandler) allow(c *gin.Context, cost ratelimit.Cost) bool {
+	if h.limiter == nil {
+		return true
+	}
+
+	ctx := c.Request.Context()
+	meter := h.limiter.Meter(ratelimit.ClientID(ctx, c.ClientIP()))
+	if err := meter.Charge(ctx, cost); err != nil {
+		respondLimit(c, "", err)
+		return false
+	}
+
+	c.Request = c.Request.WithContext(ratelimit.WithMeter(ctx, meter))
+	return true
+}
+
+// respondLimit 返回限流错误响应，err不是限流错误时返回false
+func respondLimit(c *gin.Context, prefix string, err error) bool {
+	var limited *ratelimit.LimitError
+	switch {
+	case errors.As(err, &limited):
+		c.Header("Retry-After", strconv.Itoa(ratelimit.RetryAfterSeconds(limited.Wait)))
+		c.JSON(http.StatusTooManyRequests, model.NewErrorResponse(
+			model.CodeTooManyRequest,
+			prefix+model.MsgTooManyRequest,
 			nil,
 		))
+	case errors.Is(err, ratelimit.ErrCostTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, model.NewErrorResponse(
+			model.CodeTooLarge,
+			prefix+model.MsgTooLarge,
+			nil,
+		))
+	default:
+		return false
+	}
+	return true
+}
+
+// readUpload 读取multipart上传的文件，超过maxSize字节时返回错误
+func readUpload(c *gin.Context, field string, maxSize int) ([]byte, error) {
+	// 为multipart边界和其他表单字段预留1MB
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(maxSize)+1<<20)
+
+	header, err := c.FormFile(field)
+	if err != nil {
+		return nil, fmt.Errorf("缺少上传文件 %s: %w", field, err)
+	}
+	if header.Size > int64(maxSize) {
+		return nil, fmt.Errorf("上传文件超过大小限制 %d 字节", maxSize)
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		return nil, fmt.Errorf("读取上传文件失败: %w", err)
+	}
+	defer f.Close()
+
+	return io.ReadAll(io.LimitReader(f, int64(maxSize)+1))
+}
+
+// respondError 根据错误类型返回对应的错误响应
+func (h *ConvertHandler) respondError(c *gin.Context, prefix string, err error) {
+	if respondLimit(c, prefix, err) {
 		return
 	}
 
-	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+	if service.IsBadRequest(err) {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(
+			model.CodeBadRequest,
+			prefix+err.Error(),
+			nil,
+		))
+		return
+	}
+
+	if errors.Is(err, service.ErrFetchFailed) {
+		c.JSON(http.StatusBadGateway, model.NewErrorResponse(
+			model.CodeServiceError,
+			prefix+err.Error(),
+			nil,
+		))
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, model.NewErrorResponse(
+		model.CodeInternalError,
+		prefix+err.Error(),
+		nil,
+	))
 }
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/relaxcloud-cn/html2md/internal/model"
+	"github.com/relaxcloud-cn/html2md/internal/ratelimit"
+	"github.com/relaxcloud-cn/html2md/internal/service"
+)
+
+// SubmitJob 提交异步转换任务
+// @Summary 提交异步转换任务
+// @Description 适用于超出请求超时时间的大批量或压缩包转换。任务按提交顺序在后台执行，通过任务ID查询进度和获取结果，结束后的状态和结果保留 jobs.ttl 后删除，使用持久化存储时服务重启后未结束的任务继续执行。设置 webhook 时任务成功或失败后向该地址POST任务状态，配置了 jobs.webhook_secret 时在 X-Html2md-Signature 请求头中附带签名 sha256=hex(HMAC-SHA256(secret, X-Html2md-Timestamp + "." + body))
+// @Tags 任务
+// @Accept json
+// @Produce json
+// @Param request body model.JobRequest true "任务请求参数"
+// @Success 202 {object} model.APIResponse{data=model.Job} "已提交"
+// @Header 202 {string} Location "任务状态地址"
+// @Failure 400 {object} model.APIResponse{data=interface{}} "请求参数错误"
+// @Failure 401 {object} model.APIResponse{data=interface{}} "未授权访问"
+// @Failure 403 {object} model.APIResponse{data=interface{}} "禁止访问"
+// @Failure 413 {object} model.APIResponse{data=interface{}} "请求体过大或超过单次限流配额"
+// @Failure 429 {object} model.APIResponse{data=interface{}} "请求过于频繁"
+// @Failure 503 {object} model.APIResponse{data=interface{}} "任务队列已满或任务存储空间不足"
+// @Security ApiKeyAuth
+// @Router /api/v1/jobs [post]
+func (h *ConvertHandler) SubmitJob(c *gin.Context) {
+	limit := h.jobBodyLimit()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
+	var req model.JobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, model.NewErrorResponse(
+				model.CodeTooLarge,
+				fmt.Sprintf("请求体超过大小限制 %d 字节", limit),
+				nil,
+			))
+			return
+		}
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(
+			model.CodeBadRequest,
+			"请求参数格式错误: "+err.Error(),
+			nil,
+		))
+		return
+	}
+
+	if !h.allow(c, jobCost(&req)) {
+		return
+	}
+
+	job, err := h.jobs.Submit(c.Request.Context(), &req)
+	if err != nil {
+		h.respondJobError(c, "提交任务失败: ", err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, model.NewSuccessResponse(job))
+}
+
+// GetJob 获取异步任务状态
+// @Summary 获取异步任务状态
+// @Description 获取任务的状态和进度，启用认证时只能查询本密钥提交的任务
+// @Tags 任务
+// @Produce json
+// @Param id path string true "任务ID"
+// @Success 200 {object} model.APIResponse{data=model.Job} "获取成功"
+// @Failure 401 {object} model.APIResponse{data=interface{}} "未授权访问"
+// @Failure 404 {object} model.APIResponse{data=interface{}} "任务不存在或已过期"
+// @Security ApiKeyAuth
+// @Router /api/v1/jobs/{id} [get]
+func (h *ConvertHandler) GetJob(c *gin.Context) {
+	job, err := h.jobs.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondJobError(c, "获取任务失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(job))
+}
+
+// GetJobResult 获取异步任务结果
+// @Summary 获取异步任务结果
+// @Description 获取成功任务的结果：convert、batch任务和合并输出的epub任务返回与同步接口相同的JSON数据，site任务和分章输出的epub任务返回zip压缩包
+// @Tags 任务
+// @Produce json,application/zip
+// @Param id path string true "任务ID"
+// @Success 200 {object} model.APIResponse{data=model.ConvertResponse} "convert任务的结果"
+// @Failure 401 {object} model.APIResponse{data=interface{}} "未授权访问"
+// @Failure 404 {object} model.APIResponse{data=interface{}} "任务不存在或已过期"
+// @Failure 409 {object} model.APIResponse{data=interface{}} "任务未结束或失败"
+// @Security ApiKeyAuth
+// @Router /api/v1/jobs/{id}/result [get]
+func (h *ConvertHandler) GetJobResult(c *gin.Context) {
+	_, result, err := h.jobs.Result(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondJobError(c, "获取任务结果失败: ", err)
+		return
+	}
+
+	switch {
+	case result.Archive != nil:
+		c.Header("Content-Disposition", `attachment; filename="markdown.zip"`)
+		c.Data(http.StatusOK, "application/zip", result.Archive)
+	case result.Convert != nil:
+		c.JSON(http.StatusOK, model.NewSuccessResponse(result.Convert))
+	case result.Batch != nil:
+		c.JSON(http.StatusOK, model.NewSuccessResponse(result.Batch))
+	default:
+		c.JSON(http.StatusOK, model.NewSuccessResponse(result.EPUB))
+	}
+}
+
+// CancelJob 取消异步任务
+// @Summary 取消异步任务
+// @Description 取消排队或执行中的任务；对已结束的任务删除其状态和结果
+// @Tags 任务
+// @Produce json
+// @Param id path string true "任务ID"
+// @Success 200 {object} model.APIResponse{data=model.Job} "已取消或删除"
+// @Failure 401 {object} model.APIResponse{data=interface{}} "未授权访问"
+// @Failure 404 {object} model.APIResponse{data=interface{}} "任务不存在或已过期"
+// @Security ApiKeyAuth
+// @Router /api/v1/jobs/{id} [delete]
+func (h *ConvertHandler) CancelJob(c *gin.Context) {
+	job, err := h.jobs.Cancel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondJobError(c, "取消任务失败: ", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(job))
+}
+
+// jobBodyLimit 任务请求体的大小上限: 取base64编码后的压缩包上限和单个HTML上限中的较大者，另为其他字段预留1MB。
+// 批量任务的总大小同样受此限制
+func (h *ConvertHandler) jobBodyLimit() int64 {
+	archive := (int64(h.service.MaxArchiveSize()) + 2) / 3 * 4
+	return max(archive, int64(h.service.MaxInputSize())) + 1<<20
+}
+
+// jobCost 按任务类型计算与对应同步接口相同的限流配额
+func jobCost(req *model.JobRequest) ratelimit.Cost {
+	switch req.Type {
+	case model.JobConvert:
+		if req.Convert == nil {
+			return ratelimit.Cost{Requests: 1}
+		}
+		return ratelimit.Cost{Requests: 1, InputBytes: len(req.Convert.HTML)}
+	case model.JobBatch:
+		if req.Batch == nil {
+			return ratelimit.Cost{BatchItems: 1}
+		}
+		cost := ratelimit.Cost{BatchItems: len(req.Batch.Items)}
+		for _, item := range req.Batch.Items {
+			cost.InputBytes += len(item.HTML)
+		}
+		return cost
+	default:
+		cost := ratelimit.Cost{BatchItems: 1, InputBytes: len(req.Archive)}
+		if zr, err := zip.NewReader(bytes.NewReader(req.Archive), int64(len(req.Archive))); err == nil {
+			cost.BatchItems = max(service.CountPages(zr), 1)
+		}
+		return cost
+	}
+}
+
+// respondJobError 返回任务相关的错误响应
+func (h *ConvertHandler) respondJobError(c *gin.Context, prefix string, err error) {
+	switch {
+	case errors.Is(err, service.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, model.NewErrorResponse(
+			model.CodeNotFound,
+			prefix+err.Error(),
+			nil,
+		))
+	case errors.Is(err, service.ErrJobNotReady):
+		c.JSON(http.StatusConflict, model.NewErrorResponse(
+			model.CodeConflict,
+			prefix+err.Error(),
+			nil,
+		))
+	case errors.Is(err, service.ErrQueueFull), errors.Is(err, service.ErrStoreFull):
+		c.Header("Retry-After", "60")
+		c.JSON(http.StatusServiceUnavailable, model.NewErrorResponse(
+			model.CodeServiceError,
+			prefix+err.Error(),
+			nil,
+		))
+	default:
+		h.respondError(c, prefix, err)
+	}
+}
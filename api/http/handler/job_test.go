@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/relaxcloud-cn/html2md/internal/config"
+	"github.com/relaxcloud-cn/html2md/internal/jobstore"
+	"github.com/relaxcloud-cn/html2md/internal/model"
+	"github.com/relaxcloud-cn/html2md/internal/service"
+)
+
+// newJobRouter 注册任务提交接口，任务保存在内存中
+func newJobRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg, err := config.NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := service.NewConvertService(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	jobs, err := service.NewJobQueue(ctx, cfg, svc, jobstore.NewMemoryStore(0))
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		_ = jobs.Close()
+	})
+
+	r := gin.New()
+	r.POST("/jobs", NewConvertHandler(svc, jobs, nil).SubmitJob)
+	return r
+}
+
+func TestSubmitJobBodyLimit(t *testing.T) {
+	t.Setenv("CONVERTER_MAX_INPUT_SIZE", "1024")
+	t.Setenv("CONVERTER_MAX_ARCHIVE_SIZE", "3072")
+	r := newJobRouter(t)
+
+	tests := []struct {
+		name string
+		html string
+		want int
+	}{
+		{"within limit", "<p>x</p>", http.StatusAccepted},
+		// 上限为base64编码后的压缩包大小4KB加1MB
+		{"too large", strings.Repeat("x", 4096+1<<20), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"type":"convert","convert":{"html":"` + tt.html + `"}}`
+			req := httptest.NewRequest(http.MethodPost, "/jobs", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d, body %.200s", w.Code, tt.want, w.Body)
+			}
+			if tt.want == http.StatusRequestEntityTooLarge && !strings.Contains(w.Body.String(), `"code":413`) {
+				t.Errorf("body = %.200s, want code %d", w.Body, model.CodeTooLarge)
+			}
+		})
+	}
+}
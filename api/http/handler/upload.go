@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/relaxcloud-cn/html2md/internal/auth"
+	"github.com/relaxcloud-cn/html2md/internal/model"
+	"github.com/relaxcloud-cn/html2md/internal/ratelimit"
+	"github.com/relaxcloud-cn/html2md/internal/service"
+)
+
+// mimeMarkdown 直接返回Markdown时的内容类型
+const mimeMarkdown = "text/markdown"
+
+// upload 上传的HTML文件
+type upload struct {
+	name        string
+	contentType string
+	data        []byte
+}
+
+// ConvertRaw 转换原始HTML请求体
+// @Summary 转换原始HTML请求体
+// @Description 请求体直接是HTML（Content-Type: text/html），无需JSON转义。字符集按Content-Type的charset参数、BOM或meta标签识别，转换选项通过查询参数设置。请求头 Accept: text/markdown 时直接返回Markdown，统计信息在 X-* 响应头中
+// @Tags 转换
+// @Accept html
+// @Produce json,text/markdown
+// @Param html body string true "HTML内容"
+// @Param plugins query []string false "额外启用的插件，可重复或以逗号分隔"
+// @Param profiles query []string false "启用的预处理配置，可重复或以逗号分隔"
+// @Param infer_styles query bool false "按内联样式推断格式"
+// @Param tokenizer query string false "计算token数的分词器"
+// @Param max_tokens query int false "结果的最大token数"
+// @Param toc query string false "插入目录的位置: top, placeholder" Enums(top, placeholder)
+// @Param inventory query bool false "返回链接和图片清单"
+// @Success 200 {object} model.APIResponse{data=model.ConvertResponse} "转换成功"
+// @Header 200 {integer} X-Input-Size "输入HTML大小（字节），仅Markdown输出"
+// @Header 200 {integer} X-Output-Size "输出Markdown大小（字节），仅Markdown输出"
+// @Header 200 {number} X-Processing-Time-Ms "处理时间（毫秒），仅Markdown输出"
+// @Header 200 {integer} X-Tokens "token数，仅Markdown输出"
+// @Header 200 {string} X-Tokenizer "分词器，仅Markdown输出"
+// @Header 200 {boolean} X-Truncated "是否因 max_tokens 被截断，仅Markdown输出"
+// @Header 200 {integer} X-Links "链接数，仅Markdown输出"
+// @Header 200 {integer} X-Images "图片数，仅Markdown输出"
+// @Header 200 {integer} X-Words "单词数，仅Markdown输出"
+// @Header 200 {integer} X-Reading-Seconds "预计阅读时间（秒），仅Markdown输出"
+// @Header 200 {string} X-Language "正文的语言，仅Markdown输出且识别成功时"
+// @Failure 400 {object} model.APIResponse{data=interface{}} "请求参数错误"
+// @Failure 401 {object} model.APIResponse{data=interface{}} "未授权访问"
+// @Failure 403 {object} model.APIResponse{data=interface{}} "禁止访问"
+// @Failure 415 {object} model.APIResponse{data=interface{}} "请求体不是HTML"
+// @Failure 413 {object} model.APIResponse{data=interface{}} "超过单次限流配额"
+// @Failure 429 {object} model.APIResponse{data=interface{}} "请求过于频繁"
+// @Failure 500 {object} model.APIResponse{data=interface{}} "内部服务器错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/convert/raw [post]
+func (h *ConvertHandler) ConvertRaw(c *gin.Context) {
+	var query model.ConvertQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(
+			model.CodeBadRequest,
+			"请求参数格式错误: "+err.Error(),
+			nil,
+		))
+		return
+	}
+
+	if mediaType := c.ContentType(); mediaType != binding.MIMEHTML && mediaType != "application/xhtml+xml" {
+		c.JSON(http.StatusUnsupportedMediaType, model.NewErrorResponse(
+			model.CodeUnsupported,
+			"请求体的 Content-Type 必须是 text/html",
+			nil,
+		))
+		return
+	}
+
+	maxSize := h.service.MaxInputSize()
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(maxSize)+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(
+			model.CodeBadRequest,
+			"读取请求体失败: "+err.Error(),
+			nil,
+		))
+		return
+	}
+	if len(data) > maxSize {
+		h.respondError(c, "转换失败: ", fmt.Errorf("%w: 请求体超过 %d 字节", service.ErrInputTooLarge, maxSize))
+		return
+	}
+
+	if !h.allow(c, ratelimit.Cost{Requests: 1, InputBytes: len(data)}) {
+		return
+	}
+
+	result, err := h.convertUpload(c.Request.Context(), &query, upload{contentType: c.GetHeader("Content-Type"), data: data})
+	if err != nil {
+		h.respondError(c, "转换失败: ", err)
+		return
+	}
+
+	respondConvert(c, result)
+}
+
+// ConvertUpload 转换上传的HTML文件
+// @Summary 转换上传的HTML文件
+// @Description 以multipart/form-data上传一个或多个HTML文件（字段名均为 file），总大小上限为 converter.max_archive_size。字符集按文件的Content-Type、BOM或meta标签识别，转换选项通过查询参数设置。上传一个文件时返回单个转换结果，请求头 Accept: text/markdown 时直接返回Markdown；上传多个文件时需要batch授权范围，按上传顺序返回批量转换结果，每项附带文件名
+// @Tags 转换
+// @Accept multipart/form-data
+// @Produce json,text/markdown
+// @Param file formData file true "HTML文件，可上传多个"
+// @Param plugins query []string false "额外启用的插件，可重复或以逗号分隔"
+// @Param profiles query []string false "启用的预处理配置，可重复或以逗号分隔"
+// @Param infer_styles query bool false "按内联样式推断格式"
+// @Param tokenizer query string false "计算token数的分词器"
+// @Param max_tokens query int false "结果的最大token数"
+// @Param toc query string false "插入目录的位置: top, placeholder" Enums(top, placeholder)
+// @Param inventory query bool false "返回链接和图片清单"
+// @Success 200 {object} model.APIResponse{data=model.ConvertResponse} "上传一个文件"
+// @Header 200 {integer} X-Tokens "token数，仅Markdown输出，其余统计信息同 /api/v1/convert/raw"
+// @Failure 400 {object} model.APIResponse{data=interface{}} "请求参数错误"
+// @Failure 401 {object} model.APIResponse{data=interface{}} "未授权访问"
+// @Failure 403 {object} model.APIResponse{data=interface{}} "禁止访问"
+// @Failure 413 {object} model.APIResponse{data=interface{}} "超过单次限流配额"
+// @Failure 429 {object} model.APIResponse{data=interface{}} "请求过于频繁"
+// @Failure 500 {object} model.APIResponse{data=interface{}} "内部服务器错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/convert/upload [post]
+func (h *ConvertHandler) ConvertUpload(c *gin.Context) {
+	var query model.ConvertQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(
+			model.CodeBadRequest,
+			"请求参数格式错误: "+err.Error(),
+			nil,
+		))
+		return
+	}
+
+	files, err := readUploads(c, "file", h.service.MaxArchiveSize())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse(
+			model.CodeBadRequest,
+			"请求参数格式错误: "+err.Error(),
+			nil,
+		))
+		return
+	}
+
+	inputBytes := 0
+	for _, f := range files {
+		inputBytes += len(f.data)
+	}
+
+	if len(files) == 1 {
+		if !h.allow(c, ratelimit.Cost{Requests: 1, InputBytes: inputBytes}) {
+			return
+		}
+		result, err := h.convertUpload(c.Request.Context(), &query, files[0])
+		if err != nil {
+			h.respondError(c, "转换失败: ", err)
+			return
+		}
+		respondConvert(c, result)
+		return
+	}
+
+	if principal := auth.FromContext(c.Request.Context()); principal != nil && !principal.HasScope(auth.ScopeBatch) {
+		c.JSON(http.StatusForbidden, model.NewErrorResponse(
+			model.CodeForbidden,
+			model.MsgForbidden+": 上传多个文件需要 batch 授权范围",
+			nil,
+		))
+		return
+	}
+	if !h.allow(c, ratelimit.Cost{BatchItems: len(files), InputBytes: inputBytes}) {
+		return
+	}
+
+	req := model.BatchConvertRequest{Items: make([]model.ConvertRequest, len(files))}
+	for i, f := range files {
+		html, err := service.DecodeHTML(f.data, f.contentType)
+		if err != nil {
+			h.respondError(c, "批量转换失败: ", fmt.Errorf("%s: %w", f.name, err))
+			return
+		}
+		req.Items[i] = query.Request(html)
+	}
+
+	result, err := h.service.ConvertBatch(c.Request.Context(), &req)
+	if err != nil {
+		h.respondError(c, "批量转换失败: ", err)
+		return
+	}
+	for i := range result.Results {
+		result.Results[i].Filename = files[result.Results[i].Index].name
+	}
+
+	c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+}
+
+// convertUpload 解码并转换上传的HTML
+func (h *ConvertHandler) convertUpload(ctx context.Context, query *model.ConvertQuery, f upload) (*model.ConvertResponse, error) {
+	html, err := service.DecodeHTML(f.data, f.contentType)
+	if err != nil {
+		return nil, err
+	}
+	req := query.Request(html)
+	return h.service.Convert(ctx, &req)
+}
+
+// readUploads 读取multipart上传的全部同名文件，总大小超过maxSize字节时返回错误
+func readUploads(c *gin.Context, field string, maxSize int) ([]upload, error) {
+	// 为multipart边界和其他表单字段预留1MB
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(maxSize)+1<<20)
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, fmt.Errorf("解析上传文件失败: %w", err)
+	}
+	headers := form.File[field]
+	if len(headers) == 0 {
+		return nil, fmt.Errorf("缺少上传文件 %s", field)
+	}
+
+	files := make([]upload, 0, len(headers))
+	total := int64(0)
+	for _, header := range headers {
+		if total += header.Size; total > int64(maxSize) {
+			return nil, fmt.Errorf("上传文件超过大小限制 %d 字节", maxSize)
+		}
+
+		f, err := header.Open()
+		if err != nil {
+			return nil, fmt.Errorf("读取上传文件 %s 失败: %w", header.Filename, err)
+		}
+		data, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("读取上传文件 %s 失败: %w", header.Filename, err)
+		}
+		files = append(files, upload{
+			name:        header.Filename,
+			contentType: header.Header.Get("Content-Type"),
+			data:        data,
+		})
+	}
+	return files, nil
+}
+
+// respondConvert 返回单个转换结果
+//
+// Accept 优先 text/markdown 时直接返回Markdown，统计信息放在 X-* 响应头中，
+// 分块、大纲和清单只在JSON响应中返回。
+func respondConvert(c *gin.Context, result *model.ConvertResponse) {
+	c.Header("Vary", "Accept")
+	if c.NegotiateFormat(binding.MIMEJSON, mimeMarkdown) != mimeMarkdown {
+		c.JSON(http.StatusOK, model.NewSuccessResponse(result))
+		return
+	}
+
+	if stats := result.Stats; stats != nil {
+		c.Header("X-Input-Size", strconv.Itoa(stats.InputSize))
+		c.Header("X-Output-Size", strconv.Itoa(stats.OutputSize))
+		c.Header("X-Processing-Time-Ms", strconv.FormatFloat(float64(stats.ProcessingTime)/float64(time.Millisecond), 'f', 3, 64))
+		c.Header("X-Tokens", strconv.Itoa(stats.Tokens))
+		c.Header("X-Tokenizer", stats.Tokenizer)
+		c.Header("X-Truncated", strconv.FormatBool(stats.Truncated))
+		c.Header("X-Links", strconv.Itoa(stats.Links))
+		c.Header("X-Images", strconv.Itoa(stats.Images))
+		c.Header("X-Words", strconv.Itoa(stats.Words))
+		c.Header("X-Reading-Seconds", strconv.Itoa(stats.ReadingSeconds))
+		if stats.Language != "" {
+			c.Header("X-Language", stats.Language)
+		}
+	}
+	c.Data(http.StatusOK, mimeMarkdown+"; charset=utf-8", []byte(result.Markdown))
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/relaxcloud-cn/html2md/internal/auth"
+	"github.com/relaxcloud-cn/html2md/internal/config"
+	"github.com/relaxcloud-cn/html2md/internal/model"
+	"github.com/relaxcloud-cn/html2md/internal/service"
+)
+
+// newTestRouter 注册原始HTML和文件上传接口，scopes不为空时以该授权范围的调用方访问
+func newTestRouter(t *testing.T, scopes ...string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg, err := config.NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewConvertHandler(service.NewConvertService(cfg), nil, nil)
+
+	r := gin.New()
+	if len(scopes) > 0 {
+		r.Use(func(c *gin.Context) {
+			principal := &auth.Principal{Name: "ci", Scopes: scopes}
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		})
+	}
+	r.POST("/raw", h.ConvertRaw)
+	r.POST("/upload", h.ConvertUpload)
+	return r
+}
+
+// uploadFile 上传的文件
+type uploadFile struct {
+	name        string
+	contentType string
+	data        string
+}
+
+// multipartBody 构造字段名均为 file 的multipart请求体
+func multipartBody(t *testing.T, files ...uploadFile) (*bytes.Buffer, string) {
+	t.Helper()
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	for _, f := range files {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="file"; filename="`+f.name+`"`)
+		header.Set("Content-Type", f.contentType)
+		part, err := w.CreatePart(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(f.data))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return body, w.FormDataContentType()
+}
+
+// decodeData 解码成功响应的data字段
+func decodeData(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	resp := struct {
+		Code int             `json:"code"`
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %s: %v", w.Body, err)
+	}
+	if err := json.Unmarshal(resp.Data, v); err != nil {
+		t.Fatalf("invalid data %s: %v", resp.Data, err)
+	}
+}
+
+func TestConvertRaw(t *testing.T) {
+	r := newTestRouter(t)
+
+	t.Run("json", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/raw?toc=top", strings.NewReader("<h1>Title</h1><p>Body</p>"))
+		req.Header.Set("Content-Type", "text/html")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body)
+		}
+		var result model.ConvertResponse
+		decodeData(t, w, &result)
+		if !strings.HasPrefix(result.Markdown, "- [Title](#title)") || !strings.Contains(result.Markdown, "# Title\n\nBody") {
+			t.Errorf("Markdown = %q", result.Markdown)
+		}
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		// "中文" 的GBK编码
+		body := "<h1>\xd6\xd0\xce\xc4</h1>"
+		req := httptest.NewRequest(http.MethodPost, "/raw", strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/html; charset=gbk")
+		req.Header.Set("Accept", "text/markdown")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body)
+		}
+		if got := w.Body.String(); got != "# 中文" {
+			t.Errorf("body = %q", got)
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, mimeMarkdown) {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		// 输入大小按解码后的UTF-8计算
+		if w.Header().Get("X-Input-Size") != "15" || w.Header().Get("X-Tokens") == "" || w.Header().Get("Vary") != "Accept" {
+			t.Errorf("headers = %v", w.Header())
+		}
+	})
+
+	t.Run("unsupported media type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/raw", strings.NewReader(`{"html":"<p>x</p>"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("status = %d, want 415", w.Code)
+		}
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/raw?max_tokens=many", strings.NewReader("<p>x</p>"))
+		req.Header.Set("Content-Type", "text/html")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", w.Code)
+		}
+	})
+}
+
+func TestConvertRawTooLarge(t *testing.T) {
+	t.Setenv("CONVERTER_MAX_INPUT_SIZE", "16")
+	r := newTestRouter(t)
+	req := httptest.NewRequest(http.MethodPost, "/raw", strings.NewReader("<p>"+strings.Repeat("x", 16)+"</p>"))
+	req.Header.Set("Content-Type", "text/html")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestConvertUpload(t *testing.T) {
+	t.Run("single file", func(t *testing.T) {
+		r := newTestRouter(t)
+		body, contentType := multipartBody(t, uploadFile{"a.html", "text/html; charset=iso-8859-1", "<p>caf\xe9</p>"})
+		req := httptest.NewRequest(http.MethodPost, "/upload", body)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Accept", "text/markdown")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || w.Body.String() != "café" {
+			t.Errorf("status = %d, body %q", w.Code, w.Body)
+		}
+	})
+
+	t.Run("multiple files", func(t *testing.T) {
+		r := newTestRouter(t, auth.ScopeConvert, auth.ScopeBatch)
+		body, contentType := multipartBody(t,
+			uploadFile{"a.html", "text/html", "<h1>A</h1>"},
+			uploadFile{"b.html", "text/html", "<h2>B</h2>"},
+		)
+		req := httptest.NewRequest(http.MethodPost, "/upload", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body)
+		}
+		var result model.BatchConvertResponse
+		decodeData(t, w, &result)
+		if len(result.Results) != 2 {
+			t.Fatalf("results = %+v", result.Results)
+		}
+		for i, want := range []struct{ name, md string }{{"a.html", "# A"}, {"b.html", "## B"}} {
+			item := result.Results[i]
+			if item.Filename != want.name || !item.Success || item.Result.Markdown != want.md {
+				t.Errorf("results[%d] = %+v, want %s %q", i, item, want.name, want.md)
+			}
+		}
+	})
+
+	t.Run("multiple files without batch scope", func(t *testing.T) {
+		r := newTestRouter(t, auth.ScopeConvert)
+		body, contentType := multipartBody(t,
+			uploadFile{"a.html", "text/html", "<p>a</p>"},
+			uploadFile{"b.html", "text/html", "<p>b</p>"},
+		)
+		req := httptest.NewRequest(http.MethodPost, "/upload", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want 403", w.Code)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		r := newTestRouter(t)
+		body, contentType := multipartBody(t)
+		req := httptest.NewRequest(http.MethodPost, "/upload", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", w.Code)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		t.Setenv("CONVERTER_MAX_ARCHIVE_SIZE", "32")
+		r := newTestRouter(t)
+		body, contentType := multipartBody(t,
+			uploadFile{"a.html", "text/html", "<p>" + strings.Repeat("a", 20) + "</p>"},
+			uploadFile{"b.html", "text/html", "<p>" + strings.Repeat("b", 20) + "</p>"},
+		)
+		req := httptest.NewRequest(http.MethodPost, "/upload", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", w.Code)
+		}
+	})
+}
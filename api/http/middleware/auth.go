@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/relaxcloud-cn/html2md/internal/auth"
+	"github.com/relaxcloud-cn/html2md/internal/model"
+)
+
+// PrincipalKey gin上下文中保存调用方的键
+const PrincipalKey = "auth.principal"
+
+// Auth API密钥认证中间件，scope为空时只要求密钥有效
+//
+// 密钥通过 Authorization: Bearer <key> 或 X-API-Key 请求头传递。
+func Auth(authenticator *auth.Authenticator, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := auth.ExtractKey(c.GetHeader("Authorization"), c.GetHeader("X-API-Key"))
+
+		principal, err := authenticator.Authenticate(key, scope)
+		switch {
+		case errors.Is(err, auth.ErrForbidden):
+			c.AbortWithStatusJSON(http.StatusForbidden, model.NewErrorResponse(
+				model.CodeForbidden,
+				model.MsgForbidden+": "+err.Error(),
+				nil,
+			))
+			return
+		case err != nil:
+			c.Header("WWW-Authenticate", `Bearer realm="html2md"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewErrorResponse(
+				model.CodeUnauthorized,
+				model.MsgUnauthorized+": "+err.Error(),
+				nil,
+			))
+			return
+		}
+
+		if principal != nil {
+			c.Set(PrincipalKey, principal)
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/relaxcloud-cn/html2md/internal/auth"
+	"github.com/relaxcloud-cn/html2md/internal/config"
+)
+
+func TestAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authenticator := auth.New(config.AuthConfig{
+		Enabled: true,
+		Keys: []config.APIKeyConfig{
+			{Name: "reader", Hash: config.HashAPIKey("convert-key"), Scopes: []string{auth.ScopeConvert}},
+		},
+	})
+
+	r := gin.New()
+	r.POST("/convert", Auth(authenticator, auth.ScopeConvert), func(c *gin.Context) {
+		c.String(http.StatusOK, auth.FromContext(c.Request.Context()).Name)
+	})
+	r.POST("/batch", Auth(authenticator, auth.ScopeBatch), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		value      string
+		wantStatus int
+		wantBody   string
+	}{
+		{"no key", "/convert", "", "", http.StatusUnauthorized, ""},
+		{"invalid key", "/convert", "X-API-Key", "wrong", http.StatusUnauthorized, ""},
+		{"bearer", "/convert", "Authorization", "Bearer convert-key", http.StatusOK, "reader"},
+		{"x-api-key", "/convert", "X-API-Key", "convert-key", http.StatusOK, "reader"},
+		{"missing scope", "/batch", "X-API-Key", "convert-key", http.StatusForbidden, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 response missing WWW-Authenticate")
+			}
+		})
+	}
+}
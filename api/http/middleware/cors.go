@@ -2,22 +2,94 @@ package middleware
 
 import (
 	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+
+	"github.com/relaxcloud-cn/html2md/internal/config"
 )
 
-// CORS 跨域中间件
-func CORS() gin.HandlerFunc {
-	return cors.New(cors.Config{
-		AllowOrigins:     []string{"*"}, // 在生产环境中应该限制具体的域名
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+// CORS 跨域中间件，策略来自配置并随配置热加载更新
+func CORS(cfg *config.Manager) gin.HandlerFunc {
+	var handler atomic.Pointer[gin.HandlerFunc]
+	update := func(c config.CORSConfig) {
+		h := newCORSHandler(c)
+		handler.Store(&h)
+	}
+
+	update(cfg.Get().CORS)
+	cfg.OnReload(func(_, next *config.Config) {
+		update(next.CORS)
 	})
+
+	return func(c *gin.Context) {
+		(*handler.Load())(c)
+	}
+}
+
+// newCORSHandler 根据跨域配置创建处理器，未配置源站时不允许任何跨域请求
+func newCORSHandler(c config.CORSConfig) gin.HandlerFunc {
+	if len(c.AllowOrigins) == 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	corsConfig := cors.Config{
+		AllowMethods:     c.AllowMethods,
+		AllowHeaders:     c.AllowHeaders,
+		ExposeHeaders:    c.ExposeHeaders,
+		AllowCredentials: c.AllowCredentials,
+		MaxAge:           c.MaxAge,
+	}
+	if slices.Contains(c.AllowOrigins, "*") {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOriginFunc = originMatcher(c.AllowOrigins)
+	}
+
+	return cors.New(corsConfig)
+}
+
+// originMatcher 创建源站匹配函数，https://*.example.com 匹配 example.com 的任意子域名（不含其本身）
+func originMatcher(patterns []string) func(origin string) bool {
+	exact := make(map[string]bool)
+	type wildcard struct{ scheme, suffix, port string }
+	var wildcards []wildcard
+
+	for _, pattern := range patterns {
+		u, err := url.Parse(pattern)
+		if err != nil {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(u.Hostname(), "*."); ok {
+			wildcards = append(wildcards, wildcard{u.Scheme, "." + strings.ToLower(suffix), u.Port()})
+			continue
+		}
+		exact[strings.ToLower(pattern)] = true
+	}
+
+	return func(origin string) bool {
+		if exact[strings.ToLower(origin)] {
+			return true
+		}
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		host := strings.ToLower(u.Hostname())
+		for _, w := range wildcards {
+			if u.Scheme == w.scheme && u.Port() == w.port && strings.HasSuffix(host, w.suffix) && len(host) > len(w.suffix) {
+				return true
+			}
+		}
+		return false
+	}
 }
 
 // Logger 日志中间件
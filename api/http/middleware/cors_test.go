@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/relaxcloud-cn/html2md/internal/config"
+)
+
+func TestOriginMatcher(t *testing.T) {
+	match := originMatcher([]string{
+		"https://app.example.com",
+		"https://*.example.org",
+		"http://*.local.test:8080",
+	})
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.com", true},
+		{"HTTPS://APP.EXAMPLE.COM", true},
+		{"http://app.example.com", false},
+		{"https://app.example.com:8443", false},
+		{"https://evil.example.com", false},
+		{"https://a.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"https://badexample.org", false},
+		{"http://a.example.org", false},
+		{"https://a.example.org.evil.com", false},
+		{"http://dev.local.test:8080", true},
+		{"http://dev.local.test", false},
+		{"null", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := match(tt.origin); got != tt.want {
+			t.Errorf("match(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestCORSHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		cfg        config.CORSConfig
+		origin     string
+		wantOrigin string
+		wantStatus int
+	}{
+		{
+			name:       "no origins configured",
+			cfg:        config.CORSConfig{},
+			origin:     "https://app.example.com",
+			wantOrigin: "",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "wildcard",
+			cfg:        config.CORSConfig{AllowOrigins: []string{"*"}, AllowMethods: []string{"POST"}},
+			origin:     "https://app.example.com",
+			wantOrigin: "*",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "allowed origin",
+			cfg:        config.CORSConfig{AllowOrigins: []string{"https://*.example.com"}, AllowMethods: []string{"POST"}},
+			origin:     "https://app.example.com",
+			wantOrigin: "https://app.example.com",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rejected origin",
+			cfg:        config.CORSConfig{AllowOrigins: []string{"https://*.example.com"}, AllowMethods: []string{"POST"}},
+			origin:     "https://example.net",
+			wantOrigin: "",
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(newCORSHandler(tt.cfg))
+			r.POST("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set("Origin", tt.origin)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+		})
+	}
+}
@@ -11,6 +11,10 @@
 // @host localhost:8080
 // @BasePath /
 // @schemes http https
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API密钥，也可通过 Authorization: Bearer <key> 传递
 package api
 
 import (
@@ -22,12 +26,15 @@ import (
 
 	"github.com/relaxcloud-cn/html2md/api/http/handler"
 	"github.com/relaxcloud-cn/html2md/api/http/middleware"
+	"github.com/relaxcloud-cn/html2md/internal/auth"
+	"github.com/relaxcloud-cn/html2md/internal/config"
 	"github.com/relaxcloud-cn/html2md/internal/model"
+	"github.com/relaxcloud-cn/html2md/internal/ratelimit"
 	"github.com/relaxcloud-cn/html2md/internal/service"
 )
 
-// NewRouter 创建HTTP路由器
-func NewRouter() *gin.Engine {
+// NewRouter 创建HTTP路由器，转换服务和异步任务队列与GRPC接口共用
+func NewRouter(cfg *config.Manager, convertService *service.ConvertService, authenticator *auth.Authenticator, limiter *ratelimit.Limiter, jobs *service.JobQueue) *gin.Engine {
 	// 在生产环境中设置为release模式
 	// gin.SetMode(gin.ReleaseMode)
 
@@ -36,36 +43,61 @@ func NewRouter() *gin.Engine {
 	// 添加中间件
 	r.Use(middleware.Logger())
 	r.Use(middleware.Recovery())
-	r.Use(middleware.CORS())
+	r.Use(middleware.CORS(cfg))
 
-	// 创建服务
-	convertService := service.NewConvertService()
-	convertHandler := handler.NewConvertHandler(convertService)
+	// 创建处理器
+	convertHandler := handler.NewConvertHandler(convertService, jobs, limiter)
+
+	// 认证中间件
+	requireKey := middleware.Auth(authenticator, "")
+	requireConvert := middleware.Auth(authenticator, auth.ScopeConvert)
+	requireBatch := middleware.Auth(authenticator, auth.ScopeBatch)
+
+	// 文档和演示页面按配置决定是否公开
+	docs := r.Group("/")
+	if !authenticator.PublicDocs() {
+		docs.Use(requireKey)
+	}
 
 	// 首页重定向到Swagger文档
-	r.GET("/", func(c *gin.Context) {
+	docs.GET("/", func(c *gin.Context) {
 		c.Redirect(http.StatusMovedPermanently, "/docs/index.html")
 	})
 
 	// Swagger文档
-	r.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	docs.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// API路由组
 	v1 := r.Group("/api/v1")
 	{
 		// 转换相关接口
-		v1.POST("/convert", convertHandler.Convert)
-		v1.POST("/convert/batch", convertHandler.ConvertBatch)
-		v1.GET("/convert/simple", convertHandler.ConvertSimple)
+		v1.POST("/convert", requireConvert, convertHandler.Convert)
+		v1.POST("/convert/batch", requireBatch, convertHandler.ConvertBatch)
+		v1.GET("/convert/simple", requireConvert, convertHandler.ConvertSimple)
+		v1.POST("/convert/raw", requireConvert, convertHandler.ConvertRaw)
+		v1.POST("/convert/upload", requireConvert, convertHandler.ConvertUpload)
+		v1.POST("/convert/site", requireBatch, convertHandler.ConvertSite)
+		v1.POST("/convert/epub", requireBatch, convertHandler.ConvertEPUB)
+		v1.POST("/convert/email", requireConvert, convertHandler.ConvertEmail)
+
+		// 异步任务接口，任务只对提交它的密钥可见
+		v1.POST("/jobs", requireBatch, convertHandler.SubmitJob)
+		v1.GET("/jobs/:id", requireKey, convertHandler.GetJob)
+		v1.GET("/jobs/:id/result", requireKey, convertHandler.GetJobResult)
+		v1.DELETE("/jobs/:id", requireKey, convertHandler.CancelJob)
 
 		// 系统接口
-		v1.GET("/health", convertHandler.Health)
-		v1.GET("/info", convertHandler.GetConverterInfo)
-
-		// 演示接口
-		v1.GET("/demo", demoHandler)
+		if authenticator.PublicHealth() {
+			v1.GET("/health", convertHandler.Health)
+		} else {
+			v1.GET("/health", requireKey, convertHandler.Health)
+		}
+		v1.GET("/info", requireKey, convertHandler.GetConverterInfo)
 	}
 
+	// 演示接口
+	docs.GET("/api/v1/demo", demoHandler)
+
 	// 错误处理
 	r.NoRoute(func(c *gin.Context) {
 		c.JSON(http.StatusNotFound, model.NewErrorResponse(
@@ -127,7 +159,16 @@ func demoHandler(c *gin.Context) {
         <ul>
             <li><strong>POST /api/v1/convert</strong> - 转换HTML为Markdown</li>
             <li><strong>GET /api/v1/convert/simple</strong> - 简单转换（GET方式）</li>
+            <li><strong>POST /api/v1/convert/raw</strong> - 转换原始HTML请求体（text/html）</li>
+            <li><strong>POST /api/v1/convert/upload</strong> - 转换上传的HTML文件</li>
             <li><strong>POST /api/v1/convert/batch</strong> - 批量转换</li>
+            <li><strong>POST /api/v1/convert/site</strong> - 整站压缩包转换</li>
+            <li><strong>POST /api/v1/convert/epub</strong> - EPUB电子书转换</li>
+            <li><strong>POST /api/v1/convert/email</strong> - 邮件和MHTML转换</li>
+            <li><strong>POST /api/v1/jobs</strong> - 提交异步转换任务</li>
+            <li><strong>GET /api/v1/jobs/{id}</strong> - 查询任务状态和进度</li>
+            <li><strong>GET /api/v1/jobs/{id}/result</strong> - 获取任务结果</li>
+            <li><strong>DELETE /api/v1/jobs/{id}</strong> - 取消任务</li>
             <li><strong>GET /api/v1/health</strong> - 健康检查</li>
             <li><strong>GET /api/v1/info</strong> - 转换器信息</li>
             <li><strong>GET /docs/index.html</strong> - Swagger API文档</li>
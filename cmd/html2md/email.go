@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+	"github.com/relaxcloud-cn/html2md/pkg/email"
+	"github.com/relaxcloud-cn/html2md/pkg/site"
+)
+
+// runEmail 转换邮件或MHTML存档: html2md email [-o 输出文件] <文件.eml|文件.mhtml|->
+func runEmail(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("html2md email", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "用法: html2md email [选项] <文件.eml|文件.mhtml|->\n\n")
+		fmt.Fprintf(stderr, "转换邮件（.eml）或MHTML网页存档，选取HTML正文（没有时使用纯文本正文），\n")
+		fmt.Fprintf(stderr, "删除跟踪图片和隐藏元素并展开布局表格，\n")
+		fmt.Fprintf(stderr, "正文中 cid: 及按原始地址引用的图片写入输出文件所在目录的 images/ 下或内嵌为data URI。\n\n选项:\n")
+		fs.PrintDefaults()
+	}
+
+	var (
+		output       string
+		frontMatter  bool
+		inlineImages bool
+		opts         convertOptions
+	)
+	fs.StringVar(&output, "o", "", "输出文件路径，图片写入该文件所在目录；默认输出到标准输出且不写入图片")
+	fs.BoolVar(&frontMatter, "front-matter", false, "在开头输出由From、To、Subject、Date组成的YAML front matter")
+	fs.BoolVar(&inlineImages, "inline-images", false, "将引用的图片内嵌为data URI")
+	opts.addFlags(fs, "")
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	// email子命令总是启用email预处理配置
+	conv, err := opts.newConverter(converter.WithEmailOptions(converter.EmailOptions{Quotes: opts.quotes, StripSignature: opts.stripSignature}))
+	if err != nil {
+		fmt.Fprintf(stderr, "html2md: %v\n", err)
+		return exitUsage
+	}
+
+	input := stdin
+	if name := fs.Arg(0); name != stdinMarker {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "html2md: 打开 %s 失败: %v\n", name, err)
+			return exitFailed
+		}
+		defer f.Close()
+		input = f
+	}
+
+	var dst site.Writer
+	if output != "" {
+		dst = site.DirWriter{Root: filepath.Dir(output)}
+	}
+
+	result, err := email.Convert(context.Background(), input, dst, email.Options{
+		Converter:    conv,
+		FrontMatter:  frontMatter,
+		InlineImages: inlineImages,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "html2md: %v\n", err)
+		return exitFailed
+	}
+
+	// 目录和截断只作用于正文，front matter保持在开头
+	head, body := "", result.Markdown
+	if i := strings.Index(body, "\n---\n\n"); frontMatter && strings.HasPrefix(body, "---\n") && i >= 0 {
+		head, body = body[:i+len("\n---\n\n")], body[i+len("\n---\n\n"):]
+	}
+	markdown, err := opts.process(body)
+	markdown = head + ensureNewline(markdown)
+	if err == nil {
+		if output == "" {
+			_, err = io.WriteString(stdout, markdown)
+		} else {
+			err = os.WriteFile(output, []byte(markdown), 0o644)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "html2md: 写入结果失败: %v\n", err)
+		return exitFailed
+	}
+	if len(result.Images) > 0 {
+		fmt.Fprintf(stderr, "已写入图片 %d 个\n", len(result.Images))
+	}
+	return exitOK
+}
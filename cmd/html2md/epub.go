@@ -0,0 +1,107 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/relaxcloud-cn/html2md/pkg/epub"
+	"github.com/relaxcloud-cn/html2md/pkg/site"
+)
+
+// runEPUB 转换EPUB电子书: html2md epub [-o 输出文件 | -split -out-dir 输出目录] <文件.epub>
+func runEPUB(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("html2md epub", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "用法: html2md epub [选项] <文件.epub>\n\n")
+		fmt.Fprintf(stderr, "按阅读顺序转换EPUB电子书的各章节，改写章节间的链接并复制引用的图片。\n")
+		fmt.Fprintf(stderr, "默认合并为附带目录的单个Markdown文档，-split 时每章输出一个文件。\n\n选项:\n")
+		fs.PrintDefaults()
+	}
+
+	var (
+		output       string
+		outDir       string
+		maxInputSize int
+		split        bool
+		opts         convertOptions
+	)
+	fs.StringVar(&output, "o", "", "合并输出的文件路径，图片写入该文件所在目录；默认输出到标准输出且不复制图片")
+	fs.StringVar(&outDir, "out-dir", "", "分章输出的目录，与 -split 一起使用")
+	fs.BoolVar(&split, "split", false, "每章输出一个Markdown文件，并生成目录 index.md")
+	opts.addFlags(fs, "email-")
+	fs.IntVar(&maxInputSize, "max-input-size", 10*1024*1024, "单个章节或图片的最大字节数，0表示不限制")
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	if split && (outDir == "" || output != "") {
+		fmt.Fprintln(stderr, "html2md: -split 需要使用 -out-dir 指定输出目录")
+		return exitUsage
+	}
+	if !split && outDir != "" {
+		fmt.Fprintln(stderr, "html2md: -out-dir 只能与 -split 一起使用，合并输出请使用 -o")
+		return exitUsage
+	}
+
+	conv, err := opts.newConverter()
+	if err != nil {
+		fmt.Fprintf(stderr, "html2md: %v\n", err)
+		return exitUsage
+	}
+
+	zr, err := zip.OpenReader(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "html2md: 打开 %s 失败: %v\n", fs.Arg(0), err)
+		return exitFailed
+	}
+	defer zr.Close()
+
+	var dst site.Writer
+	switch {
+	case split:
+		dst = processWriter{site.DirWriter{Root: outDir}, &opts}
+	case output != "":
+		dst = site.DirWriter{Root: filepath.Dir(output)}
+	}
+
+	result, err := epub.Convert(context.Background(), zr, dst, epub.Options{
+		Converter:   conv,
+		Split:       split,
+		MaxFileSize: int64(maxInputSize),
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "html2md: %v\n", err)
+		return exitFailed
+	}
+
+	if !split {
+		markdown, err := opts.process(result.Markdown)
+		if err == nil {
+			if output == "" {
+				_, err = io.WriteString(stdout, markdown)
+			} else {
+				err = os.WriteFile(output, []byte(markdown), 0o644)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "html2md: 写入结果失败: %v\n", err)
+			return exitFailed
+		}
+	}
+
+	fmt.Fprintf(stderr, "《%s》已转换 %d 个章节，复制图片 %d 个\n", result.Title, len(result.Chapters), len(result.Images))
+	return exitOK
+}
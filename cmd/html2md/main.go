@@ -0,0 +1,322 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/relaxcloud-cn/html2md/pkg/chunk"
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+)
+
+// 退出码
+const (
+	exitOK      = 0 // 全部转换成功
+	exitFailed  = 1 // 部分或全部文件转换失败
+	exitUsage   = 2 // 参数错误
+	stdinMarker = "-"
+)
+
+// options 命令行参数
+type options struct {
+	convertOptions
+
+	output       string
+	outDir       string
+	maxInputSize int
+	stats        bool
+	quiet        bool
+
+	chunking  chunk.Options
+	inventory bool
+}
+
+// chunked 是否设置了分块大小
+func (o *options) chunked() bool {
+	return o.chunking.TargetSize != 0 || o.chunking.MaxSize != 0
+}
+
+// structured 是否输出包含分块或链接清单的JSON，而非Markdown
+func (o *options) structured() bool {
+	return o.chunked() || o.inventory
+}
+
+// jsonResult 结构化输出，字段与服务端的转换响应相同
+type jsonResult struct {
+	Markdown  string               `json:"markdown"`
+	Chunks    []chunk.Chunk        `json:"chunks,omitempty"`
+	Inventory *converter.Inventory `json:"inventory,omitempty"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run 执行命令行，返回退出码
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "site":
+			return runSite(args[1:], stdout, stderr)
+		case "watch":
+			return runWatch(args[1:], stderr)
+		case "epub":
+			return runEPUB(args[1:], stdout, stderr)
+		case "email":
+			return runEmail(args[1:], stdin, stdout, stderr)
+		}
+	}
+
+	fs := flag.NewFlagSet("html2md", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "用法: html2md [选项] [文件或通配符...]\n")
+		fmt.Fprintf(stderr, "      html2md site [选项] <输入目录>\n")
+		fmt.Fprintf(stderr, "      html2md watch [选项] <文件或目录...>\n")
+		fmt.Fprintf(stderr, "      html2md epub [选项] <文件.epub>\n")
+		fmt.Fprintf(stderr, "      html2md email [选项] <文件.eml|文件.mhtml>\n\n")
+		fmt.Fprintf(stderr, "将HTML转换为Markdown。未指定输入或输入为 - 时从标准输入读取。\n")
+		fmt.Fprintf(stderr, "site 子命令递归转换整站导出目录，watch 子命令监听变化并持续转换，epub 子命令转换电子书，email 子命令转换邮件和MHTML存档，详见各子命令的 -h。\n\n选项:\n")
+		fs.PrintDefaults()
+	}
+
+	var opts options
+	fs.StringVar(&opts.output, "o", "", "输出文件路径（仅限单个输入），默认输出到标准输出")
+	fs.StringVar(&opts.outDir, "out-dir", "", "输出目录，每个输入文件生成同名的 .md 文件，输出JSON时为 .json 文件")
+	opts.addFlags(fs, "email-")
+	fs.StringVar(&opts.chunking.Unit, "chunk-unit", "", "分块的计量单位: chars（默认）, tokens")
+	fs.IntVar(&opts.chunking.TargetSize, "chunk-size", 0, "按标题层级分块的目标大小，设置时输出包含 chunks 的JSON")
+	fs.IntVar(&opts.chunking.MaxSize, "chunk-max-size", 0, "分块的最大大小，默认等于 -chunk-size")
+	fs.IntVar(&opts.chunking.Overlap, "chunk-overlap", 0, "同一小节内相邻块的重叠大小")
+	fs.BoolVar(&opts.inventory, "inventory", false, "输出包含链接和图片清单（inventory）的JSON")
+	fs.IntVar(&opts.maxInputSize, "max-input-size", 10*1024*1024, "单个输入的最大字节数，0表示不限制")
+	fs.BoolVar(&opts.stats, "stats", false, "在标准错误输出中打印每个文件的转换统计")
+	fs.BoolVar(&opts.quiet, "q", false, "不输出处理进度")
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if opts.output != "" && opts.outDir != "" {
+		fmt.Fprintln(stderr, "html2md: -o 和 -out-dir 不能同时使用")
+		return exitUsage
+	}
+	conv, err := opts.newConverter()
+	if err != nil {
+		fmt.Fprintf(stderr, "html2md: %v\n", err)
+		return exitUsage
+	}
+	if opts.chunked() {
+		opts.chunking.Tokenizer = opts.tok
+		if err := opts.chunking.Validate(); err != nil {
+			fmt.Fprintf(stderr, "html2md: %v\n", err)
+			return exitUsage
+		}
+	}
+
+	inputs, err := expandInputs(fs.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "html2md: %v\n", err)
+		return exitUsage
+	}
+	if opts.output != "" && len(inputs) > 1 {
+		fmt.Fprintln(stderr, "html2md: 多个输入时请使用 -out-dir 指定输出目录")
+		return exitUsage
+	}
+
+	failed := 0
+	for _, input := range inputs {
+		if err := convertOne(conv, input, &opts, stdin, stdout, stderr); err != nil {
+			fmt.Fprintf(stderr, "html2md: %s: %v\n", displayName(input), err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		if len(inputs) > 1 {
+			fmt.Fprintf(stderr, "html2md: %d/%d 个文件转换失败\n", failed, len(inputs))
+		}
+		return exitFailed
+	}
+	return exitOK
+}
+
+// convertOne 转换单个输入并写入目标位置
+func convertOne(conv *converter.Converter, input string, opts *options, stdin io.Reader, stdout, stderr io.Writer) error {
+	html, err := readInput(input, stdin, opts.maxInputSize)
+	if err != nil {
+		return err
+	}
+
+	result, err := conv.ConvertString(string(html))
+	if err != nil {
+		return err
+	}
+
+	markdown, err := opts.process(result.Markdown)
+	if err != nil {
+		return err
+	}
+	output := ensureNewline(markdown)
+	if opts.structured() {
+		if output, err = structuredOutput(markdown, result, opts); err != nil {
+			return err
+		}
+	}
+
+	target := opts.output
+	if opts.outDir != "" {
+		target = filepath.Join(opts.outDir, outputName(input))
+		if opts.structured() {
+			target = strings.TrimSuffix(target, ".md") + ".json"
+		}
+	}
+
+	if target == "" {
+		if _, err := io.WriteString(stdout, output); err != nil {
+			return fmt.Errorf("写入标准输出失败: %w", err)
+		}
+	} else {
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("创建输出目录失败: %w", err)
+		}
+		if err := os.WriteFile(target, []byte(output), 0o644); err != nil {
+			return fmt.Errorf("写入输出文件失败: %w", err)
+		}
+		if !opts.quiet {
+			fmt.Fprintf(stderr, "%s -> %s\n", displayName(input), target)
+		}
+	}
+
+	if opts.stats {
+		analysis := chunk.Analyze(markdown)
+		lang := analysis.Language
+		if lang == "" {
+			lang = "未知"
+		}
+		fmt.Fprintf(stderr, "%s: 输入 %d 字节，输出 %d 字节，%d tokens（%s），%d 个单词，语言 %s，%d 个链接，%d 张图片，耗时 %s\n",
+			displayName(input), result.Stats.InputSize, len(markdown), opts.tok.Count(markdown), opts.tok.Name(),
+			analysis.Words, lang, result.Stats.Links, result.Stats.Images, result.Stats.ProcessingTime)
+	}
+
+	return nil
+}
+
+// structuredOutput 生成包含分块和链接清单的JSON，分块基于处理后的Markdown，清单来自完整的转换结果
+func structuredOutput(markdown string, result *converter.Result, opts *options) (string, error) {
+	out := jsonResult{Markdown: markdown}
+	if opts.chunked() {
+		chunks, err := chunk.Split(markdown, opts.chunking)
+		if err != nil {
+			return "", err
+		}
+		out.Chunks = chunks
+	}
+	if opts.inventory {
+		out.Inventory = &result.Inventory
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// readInput 读取文件或标准输入，超过大小限制时返回错误
+func readInput(input string, stdin io.Reader, maxSize int) ([]byte, error) {
+	var r io.Reader = stdin
+	if input != stdinMarker {
+		f, err := os.Open(input)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	if maxSize > 0 {
+		r = io.LimitReader(r, int64(maxSize)+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("读取失败: %w", err)
+	}
+	if maxSize > 0 && len(data) > maxSize {
+		return nil, fmt.Errorf("输入超过大小限制 %d 字节", maxSize)
+	}
+	return data, nil
+}
+
+// expandInputs 展开通配符，未指定输入时读取标准输入
+func expandInputs(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return []string{stdinMarker}, nil
+	}
+
+	var inputs []string
+	for _, arg := range args {
+		if arg == stdinMarker || !strings.ContainsAny(arg, "*?[") {
+			inputs = append(inputs, arg)
+			continue
+		}
+
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("无效的通配符 %q: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("没有匹配 %q 的文件", arg)
+		}
+		inputs = append(inputs, matches...)
+	}
+	return inputs, nil
+}
+
+// outputName 计算输入在输出目录中的相对路径
+//
+// 相对路径输入保留目录结构，避免不同目录下的同名文件互相覆盖；
+// 绝对路径或指向上级目录的输入只保留文件名。
+func outputName(input string) string {
+	if input == stdinMarker {
+		return "stdin.md"
+	}
+	name := filepath.Clean(input)
+	if !filepath.IsLocal(name) {
+		name = filepath.Base(name)
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name)) + ".md"
+}
+
+// displayName 错误报告中使用的输入名称
+func displayName(input string) string {
+	if input == stdinMarker {
+		return "<stdin>"
+	}
+	return input
+}
+
+// splitList 解析逗号分隔的列表
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ensureNewline 保证输出以换行结尾，便于在管道中拼接
+func ensureNewline(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
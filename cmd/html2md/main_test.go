@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.html")
+	if err := os.WriteFile(page, []byte("<h1>Title</h1><p><b>bold</b></p>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules := filepath.Join(dir, "rules.yaml")
+	if err := os.WriteFile(rules, []byte("- selector: div.ad\n  action: drop\n- selector: span.kbd\n  action: template\n  template: \"<kbd>{{ .Text }}</kbd>\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	badRules := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(badRules, []byte(`[{"selector": "p", "action": "nope"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{"stdin", nil, "<h1>Hello</h1>", exitOK, "# Hello\n", ""},
+		{"stdin marker", []string{"-"}, "<em>x</em>", exitOK, "*x*\n", ""},
+		{"file", []string{page}, "", exitOK, "# Title\n\n**bold**\n", ""},
+		{"missing file", []string{filepath.Join(dir, "missing.html")}, "", exitFailed, "", "missing.html"},
+		{"unknown plugin", []string{"-plugins", "base,nope"}, "", exitUsage, "", "nope"},
+		{"output and out-dir", []string{"-o", "a.md", "-out-dir", dir}, "", exitUsage, "", "-o"},
+		{"negative max tokens", []string{"-max-tokens", "-1"}, "", exitUsage, "", "max-tokens"},
+		{"input too large", []string{"-max-input-size", "4"}, "<p>hello</p>", exitFailed, "", "大小限制"},
+		{"no glob match", []string{filepath.Join(dir, "*.htm")}, "", exitUsage, "", "没有匹配"},
+		{"base url", []string{"-base-url", "https://example.com/docs/"}, `<a href="a.html">A</a>`, exitOK, "[A](https://example.com/docs/a.html)\n", ""},
+		{"rules file", []string{"-rules", rules}, `<p>x <span class="kbd">Ctrl</span></p><div class="ad">ad</div>`, exitOK, "x <kbd>Ctrl</kbd>\n", ""},
+		{"invalid rules file", []string{"-rules", badRules}, "", exitUsage, "", "nope"},
+		{"missing rules file", []string{"-rules", filepath.Join(dir, "missing.yaml")}, "", exitUsage, "", "规则文件"},
+		{"email options", []string{"-email-quotes", "strip"}, `<p>Hi</p><blockquote type="cite">old</blockquote>`, exitOK, "Hi\n", ""},
+		{"toc", []string{"-toc", "top"}, "<h1>A</h1><h2>B</h2>", exitOK, "- [A](#a)\n  - [B](#b)\n\n# A\n\n## B\n", ""},
+		{"invalid chunk size", []string{"-chunk-size", "-1"}, "", exitUsage, "", "负数"},
+		{"help", []string{"-h"}, "", exitOK, "", "用法"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(append([]string{"-q"}, tt.args...), strings.NewReader(tt.stdin), &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d; stderr: %s", code, tt.wantCode, stderr.String())
+			}
+			if tt.wantStdout != "" && stdout.String() != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRunOutDir(t *testing.T) {
+	src := t.TempDir()
+	out := t.TempDir()
+	for name, content := range map[string]string{
+		"a.html":     "<h1>A</h1>",
+		"sub/a.html": "<h1>Sub A</h1>",
+	} {
+		target := filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 相对路径输入在输出目录中保留目录结构
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(src); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-q", "-out-dir", out, "a.html", "sub/a.html"}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code = %d; stderr: %s", code, stderr.String())
+	}
+
+	for name, want := range map[string]string{"a.md": "# A\n", "sub/a.md": "# Sub A\n"} {
+		got, err := os.ReadFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestRunStructured(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	html := `<h1>Title</h1><p>` + strings.Repeat("word ", 20) + `</p><h2>Next</h2><p>see <a href="/docs">docs</a></p>`
+	code := run([]string{"-chunk-size", "64", "-inventory"}, strings.NewReader(html), &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("exit code = %d; stderr: %s", code, stderr.String())
+	}
+
+	var result jsonResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON %s: %v", stdout.String(), err)
+	}
+	if !strings.HasPrefix(result.Markdown, "# Title") || len(result.Chunks) < 2 {
+		t.Errorf("result = %+v", result)
+	}
+	if result.Inventory == nil || len(result.Inventory.Links) != 1 || result.Inventory.Links[0].URL != "/docs" {
+		t.Errorf("inventory = %+v", result.Inventory)
+	}
+}
+
+func TestOutputName(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"-", "stdin.md"},
+		{"page.html", "page.md"},
+		{"docs/page.htm", filepath.Join("docs", "page.md")},
+		{"./docs/../page.html", "page.md"},
+		{"../outside/page.html", "page.md"},
+		{"/abs/path/page.html", "page.md"},
+	}
+	for _, tt := range tests {
+		if got := outputName(tt.input); got != tt.want {
+			t.Errorf("outputName(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	got := splitList(" base, ,commonmark,table ")
+	want := []string{"base", "commonmark", "table"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("splitList = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/relaxcloud-cn/html2md/pkg/chunk"
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+	"github.com/relaxcloud-cn/html2md/pkg/site"
+	"github.com/relaxcloud-cn/html2md/pkg/tokenizer"
+)
+
+// convertOptions 各子命令共用的转换选项，与服务端转换请求的同名字段对应
+type convertOptions struct {
+	plugins        string
+	profiles       string
+	inferStyles    bool
+	baseURL        string
+	rulesPath      string
+	quotes         string
+	stripSignature bool
+	tokenizer      string
+	maxTokens      int
+	toc            string
+
+	tok tokenizer.Tokenizer
+}
+
+// addFlags 注册共用的转换选项，emailPrefix 为邮件选项的参数名前缀，email 子命令中为空
+func (o *convertOptions) addFlags(fs *flag.FlagSet, emailPrefix string) {
+	fs.StringVar(&o.plugins, "plugins", "base,commonmark", "启用的插件，逗号分隔: "+supportedPlugins())
+	fs.StringVar(&o.profiles, "profiles", "", "启用的预处理配置，逗号分隔: "+strings.Join(converter.SupportedProfiles(), ", "))
+	fs.BoolVar(&o.inferStyles, "infer-styles", false, "按内联样式推断加粗、斜体、代码和标题，并删除隐藏元素")
+	fs.StringVar(&o.baseURL, "base-url", "", "补全相对链接和图片地址的基准URL")
+	fs.StringVar(&o.rulesPath, "rules", "", "自定义转换规则文件（YAML或JSON格式的规则列表）")
+	fs.StringVar(&o.quotes, emailPrefix+"quotes", "", "邮件引用历史的处理方式: keep（默认）, collapse（多层合并为一层）, strip（删除）")
+	fs.BoolVar(&o.stripSignature, emailPrefix+"strip-signature", false, "删除邮件签名")
+	fs.StringVar(&o.tokenizer, "tokenizer", "", "计算token数的分词器: "+strings.Join(tokenizer.Names(), ", ")+"，默认 "+tokenizer.DefaultName())
+	fs.IntVar(&o.maxTokens, "max-tokens", 0, "输出的最大token数，超出时在块边界截断，0表示不限制")
+	fs.StringVar(&o.toc, "toc", "", "插入目录的位置: top（文档开头）, placeholder（替换 [TOC] 占位行）")
+}
+
+// email 是否设置了邮件选项，设置时与服务端一样自动启用email预处理配置
+func (o *convertOptions) email() bool {
+	return o.quotes != "" || o.stripSignature
+}
+
+// newConverter 验证选项并创建转换器，返回的错误均为参数错误
+func (o *convertOptions) newConverter(extra ...converter.Option) (*converter.Converter, error) {
+	if o.maxTokens < 0 {
+		return nil, errors.New("-max-tokens 不能为负数")
+	}
+	if err := chunk.ValidateTOC(o.toc); err != nil {
+		return nil, err
+	}
+	tok, err := tokenizer.Get(o.tokenizer)
+	if err != nil {
+		return nil, err
+	}
+	o.tok = tok
+
+	rules, err := loadRules(o.rulesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []converter.Option{
+		converter.WithPlugins(splitList(o.plugins)...),
+		converter.WithProfiles(splitList(o.profiles)...),
+		converter.WithStyleInference(o.inferStyles),
+		converter.WithDomain(o.baseURL),
+		converter.WithRules(rules...),
+	}
+	if o.email() {
+		opts = append(opts, converter.WithEmailOptions(converter.EmailOptions{Quotes: o.quotes, StripSignature: o.stripSignature}))
+	}
+	return converter.New(append(opts, extra...)...)
+}
+
+// process 按选项插入目录并截断到 -max-tokens
+func (o *convertOptions) process(markdown string) (string, error) {
+	markdown, err := chunk.InsertTOC(markdown, o.toc)
+	if err != nil {
+		return "", err
+	}
+	if o.maxTokens > 0 {
+		markdown, _ = chunk.Truncate(markdown, o.maxTokens, o.tok)
+	}
+	return markdown, nil
+}
+
+// loadRules 读取规则文件，path为空时返回nil
+func loadRules(path string) ([]converter.Rule, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取规则文件失败: %w", err)
+	}
+	var rules []converter.Rule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("解析规则文件 %s 失败: %w", path, err)
+	}
+	return rules, nil
+}
+
+// processWriter 写入Markdown文件前按转换选项处理，用于整站和电子书分章输出
+type processWriter struct {
+	site.Writer
+	opts *convertOptions
+}
+
+// WriteFile 处理 .md 文件的内容后写入，其他文件原样写入
+func (w processWriter) WriteFile(name string, data []byte) error {
+	if !strings.HasSuffix(name, ".md") {
+		return w.Writer.WriteFile(name, data)
+	}
+	markdown, err := w.opts.process(string(data))
+	if err != nil {
+		return err
+	}
+	return w.Writer.WriteFile(name, []byte(ensureNewline(markdown)))
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+	"github.com/relaxcloud-cn/html2md/pkg/site"
+)
+
+// runSite 转换整站导出目录: html2md site -out-dir <输出目录> <输入目录>
+func runSite(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("html2md site", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "用法: html2md site [选项] <输入目录>\n\n")
+		fmt.Fprintf(stderr, "递归转换目录中的HTML文件，保持目录结构，改写站内链接并复制引用的资源文件。\n\n选项:\n")
+		fs.PrintDefaults()
+	}
+
+	var (
+		outDir       string
+		reportPath   string
+		maxInputSize int
+		strict       bool
+		quiet        bool
+		opts         convertOptions
+	)
+	fs.StringVar(&outDir, "out-dir", "", "输出目录（必填）")
+	opts.addFlags(fs, "email-")
+	fs.IntVar(&maxInputSize, "max-input-size", 10*1024*1024, "单个文件的最大字节数，0表示不限制")
+	fs.StringVar(&reportPath, "report", "", "将JSON格式的转换报告写入该文件，- 表示标准输出")
+	fs.BoolVar(&strict, "strict", false, "存在无法解析的站内链接时以非零退出码结束")
+	fs.BoolVar(&quiet, "q", false, "不输出无法解析的链接列表")
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() != 1 || outDir == "" {
+		fs.Usage()
+		return exitUsage
+	}
+
+	conv, err := opts.newConverter()
+	if err != nil {
+		fmt.Fprintf(stderr, "html2md: %v\n", err)
+		return exitUsage
+	}
+
+	root := fs.Arg(0)
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		fmt.Fprintf(stderr, "html2md: %s 不是目录\n", root)
+		return exitUsage
+	}
+
+	report, err := site.Convert(context.Background(), os.DirFS(root), processWriter{site.DirWriter{Root: outDir}, &opts}, site.Options{
+		Converter:   conv,
+		MaxFileSize: int64(maxInputSize),
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "html2md: %v\n", err)
+		return exitFailed
+	}
+
+	for _, f := range report.Files {
+		if f.Error != "" {
+			fmt.Fprintf(stderr, "html2md: %s: %v\n", f.Source, f.Error)
+		}
+	}
+	if !quiet {
+		for _, link := range report.BrokenLinks {
+			fmt.Fprintf(stderr, "%s: 无法解析的链接 %q: %s\n", link.Source, link.Href, link.Reason)
+		}
+	}
+	fmt.Fprintf(stderr, "已转换 %d 个页面，失败 %d 个，复制资源 %d 个，无法解析的链接 %d 个\n",
+		report.Converted, report.Failed, len(report.Assets), len(report.BrokenLinks))
+
+	if reportPath != "" {
+		if err := writeReport(reportPath, report, stdout); err != nil {
+			fmt.Fprintf(stderr, "html2md: 写入报告失败: %v\n", err)
+			return exitFailed
+		}
+	}
+
+	if report.Failed > 0 || (strict && len(report.BrokenLinks) > 0) {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// writeReport 输出JSON格式的转换报告
+func writeReport(target string, report *site.Report, stdout io.Writer) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if target == stdinMarker {
+		_, err = stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(target, data, 0o644)
+}
+
+// supportedPlugins 支持的插件列表，用于帮助信息
+func supportedPlugins() string {
+	return strings.Join(converter.SupportedPlugins(), ", ")
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunSite(t *testing.T) {
+	src := t.TempDir()
+	out := t.TempDir()
+	for name, content := range map[string]string{
+		"index.html":  `<h1>Home</h1><p><a href="docs/a.html">A</a></p>`,
+		"docs/a.html": `<h1>A</h1><h2>Usage</h2><div class="ad">ad</div>`,
+		"rules.yaml":  "- selector: div.ad\n  action: drop\n",
+	} {
+		target := filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-q", "-out-dir", out, "-toc", "top", "-rules", filepath.Join(src, "rules.yaml"), src}
+	if code := runSite(args, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code = %d; stderr: %s", code, stderr.String())
+	}
+
+	got, err := os.ReadFile(filepath.Join(out, "docs", "a.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "- [A](#a)\n  - [Usage](#usage)\n\n# A\n\n## Usage\n"; string(got) != want {
+		t.Errorf("docs/a.md = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+	"github.com/relaxcloud-cn/html2md/pkg/site"
+)
+
+// runWatch 监听输入路径并持续转换: html2md watch -out-dir <输出目录> <文件或目录...>
+func runWatch(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("html2md watch", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "用法: html2md watch [选项] <文件或目录...>\n\n")
+		fmt.Fprintf(stderr, "启动时转换全部HTML文件，之后监听变化：只重新转换内容发生变化的文件，删除输入时同步删除对应的输出。\n")
+		fmt.Fprintf(stderr, "目录会被递归监听，输出保持相对目录结构。按 Ctrl+C 退出。\n\n选项:\n")
+		fs.PrintDefaults()
+	}
+
+	var (
+		outDir       string
+		maxInputSize int
+		debounce     time.Duration
+		quiet        bool
+		opts         convertOptions
+	)
+	fs.StringVar(&outDir, "out-dir", "", "输出目录（必填）")
+	opts.addFlags(fs, "email-")
+	fs.IntVar(&maxInputSize, "max-input-size", 10*1024*1024, "单个文件的最大字节数，0表示不限制")
+	fs.DurationVar(&debounce, "debounce", 300*time.Millisecond, "文件变化后等待的时间，合并连续的写入")
+	fs.BoolVar(&quiet, "q", false, "不输出处理进度")
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() == 0 || outDir == "" {
+		fs.Usage()
+		return exitUsage
+	}
+
+	conv, err := opts.newConverter()
+	if err != nil {
+		fmt.Fprintf(stderr, "html2md: %v\n", err)
+		return exitUsage
+	}
+
+	w := &watcher{
+		conv:         conv,
+		opts:         &opts,
+		outDir:       outDir,
+		maxInputSize: maxInputSize,
+		quiet:        quiet,
+		stderr:       stderr,
+		hashes:       make(map[string][sha256.Size]byte),
+	}
+	for _, arg := range fs.Args() {
+		info, err := os.Stat(arg)
+		if err != nil {
+			fmt.Fprintf(stderr, "html2md: %v\n", err)
+			return exitUsage
+		}
+		w.roots = append(w.roots, watchRoot{path: filepath.Clean(arg), dir: info.IsDir()})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := w.run(ctx, debounce); err != nil {
+		fmt.Fprintf(stderr, "html2md: %v\n", err)
+		return exitFailed
+	}
+	return exitOK
+}
+
+// watchRoot 命令行指定的监听路径
+type watchRoot struct {
+	path string
+	dir  bool
+}
+
+// watcher 监听输入并增量转换
+type watcher struct {
+	conv         *converter.Converter
+	opts         *convertOptions // 目录和截断选项
+	roots        []watchRoot
+	outDir       string
+	maxInputSize int
+	quiet        bool
+	stderr       io.Writer
+
+	fsw    *fsnotify.Watcher
+	hashes map[string][sha256.Size]byte // 源文件 -> 最近一次转换的内容哈希
+}
+
+// run 完成初次转换后持续监听，直到ctx取消
+func (w *watcher) run(ctx context.Context, debounce time.Duration) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("创建文件监听失败: %w", err)
+	}
+	defer fsw.Close()
+	w.fsw = fsw
+
+	for _, root := range w.roots {
+		if root.dir {
+			w.syncDir(root.path)
+			continue
+		}
+		// 编辑器常以重命名方式保存文件，因此监听所在目录而非文件本身
+		if err := fsw.Add(filepath.Dir(root.path)); err != nil {
+			return fmt.Errorf("监听 %s 失败: %w", root.path, err)
+		}
+		w.convert(root.path)
+	}
+	fmt.Fprintf(w.stderr, "正在监听 %d 个路径，按 Ctrl+C 退出\n", len(w.roots))
+
+	pending := make(map[string]bool)
+	var timer <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod || isHidden(event.Name) {
+				continue
+			}
+			pending[filepath.Clean(event.Name)] = true
+			timer = time.After(debounce)
+
+		case <-timer:
+			timer = nil
+			names := make([]string, 0, len(pending))
+			for name := range pending {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			clear(pending)
+			for _, name := range names {
+				w.sync(name)
+			}
+
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(w.stderr, "html2md: 文件监听错误: %v\n", err)
+		}
+	}
+}
+
+// sync 根据路径的当前状态转换、删除或开始监听
+func (w *watcher) sync(name string) {
+	info, err := os.Stat(name)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		w.remove(name)
+	case err != nil:
+		fmt.Fprintf(w.stderr, "html2md: %v\n", err)
+	case info.IsDir():
+		if w.inDirRoot(name) {
+			w.syncDir(name)
+		}
+	default:
+		if _, ok := w.target(name); ok {
+			w.convert(name)
+		}
+	}
+}
+
+// syncDir 递归监听目录并转换其中的HTML文件
+func (w *watcher) syncDir(dir string) {
+	err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != dir && isHidden(name) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if err := w.fsw.Add(name); err != nil {
+				return fmt.Errorf("监听 %s 失败: %w", name, err)
+			}
+			return nil
+		}
+		if site.IsHTML(name) {
+			w.convert(name)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(w.stderr, "html2md: %v\n", err)
+	}
+}
+
+// convert 转换单个文件，内容与上次转换相同时跳过
+func (w *watcher) convert(name string) {
+	target, ok := w.target(name)
+	if !ok {
+		return
+	}
+
+	data, err := readInput(name, nil, w.maxInputSize)
+	if err != nil {
+		fmt.Fprintf(w.stderr, "html2md: %s: %v\n", name, err)
+		return
+	}
+	sum := sha256.Sum256(data)
+	if last, ok := w.hashes[name]; ok && last == sum {
+		return
+	}
+
+	result, err := w.conv.ConvertString(string(data))
+	if err != nil {
+		fmt.Fprintf(w.stderr, "html2md: %s: %v\n", name, err)
+		return
+	}
+	markdown, err := w.opts.process(result.Markdown)
+	if err != nil {
+		fmt.Fprintf(w.stderr, "html2md: %s: %v\n", name, err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		fmt.Fprintf(w.stderr, "html2md: 创建输出目录失败: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(target, []byte(ensureNewline(markdown)), 0o644); err != nil {
+		fmt.Fprintf(w.stderr, "html2md: 写入输出文件失败: %v\n", err)
+		return
+	}
+
+	w.hashes[name] = sum
+	if !w.quiet {
+		fmt.Fprintf(w.stderr, "%s -> %s\n", name, target)
+	}
+}
+
+// remove 删除已不存在的输入对应的输出，name为目录时删除其下全部输出
+func (w *watcher) remove(name string) {
+	prefix := name + string(filepath.Separator)
+	for src := range w.hashes {
+		if src != name && !strings.HasPrefix(src, prefix) {
+			continue
+		}
+		delete(w.hashes, src)
+
+		target, ok := w.target(src)
+		if !ok {
+			continue
+		}
+		if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(w.stderr, "html2md: 删除输出文件失败: %v\n", err)
+			continue
+		}
+		if !w.quiet {
+			fmt.Fprintf(w.stderr, "%s 已删除，移除 %s\n", src, target)
+		}
+	}
+}
+
+// target 计算输入文件对应的输出路径，不属于任何监听路径或不是HTML文件时返回false
+func (w *watcher) target(name string) (string, bool) {
+	for _, root := range w.roots {
+		if !root.dir {
+			if name == root.path {
+				return filepath.Join(w.outDir, outputName(name)), true
+			}
+			continue
+		}
+		rel, err := filepath.Rel(root.path, name)
+		if err != nil || !filepath.IsLocal(rel) || !site.IsHTML(name) {
+			continue
+		}
+		return filepath.Join(w.outDir, strings.TrimSuffix(rel, filepath.Ext(rel))+".md"), true
+	}
+	return "", false
+}
+
+// inDirRoot 判断目录是否位于某个被监听的目录之内
+func (w *watcher) inDirRoot(dir string) bool {
+	for _, root := range w.roots {
+		if !root.dir {
+			continue
+		}
+		if rel, err := filepath.Rel(root.path, dir); err == nil && filepath.IsLocal(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHidden 判断是否为隐藏文件，编辑器的临时文件通常以.开头
+func isHidden(name string) bool {
+	return strings.HasPrefix(filepath.Base(name), ".")
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+)
+
+func newTestWatcher(t *testing.T, outDir string, roots ...watchRoot) *watcher {
+	t.Helper()
+	conv, err := converter.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &watcher{
+		conv:   conv,
+		opts:   &convertOptions{},
+		roots:  roots,
+		outDir: outDir,
+		quiet:  true,
+		stderr: io.Discard,
+		hashes: make(map[string][sha256.Size]byte),
+	}
+}
+
+func TestWatcherTarget(t *testing.T) {
+	w := newTestWatcher(t, "out",
+		watchRoot{path: "site", dir: true},
+		watchRoot{path: filepath.Join("single", "page.htm")},
+	)
+
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{filepath.Join("site", "index.html"), filepath.Join("out", "index.md"), true},
+		{filepath.Join("site", "docs", "a.html"), filepath.Join("out", "docs", "a.md"), true},
+		{filepath.Join("site", "logo.png"), "", false},
+		{filepath.Join("other", "a.html"), "", false},
+		{filepath.Join("single", "page.htm"), filepath.Join("out", "single", "page.md"), true},
+		{filepath.Join("single", "other.html"), "", false},
+	}
+	for _, tt := range tests {
+		got, ok := w.target(tt.name)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("target(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestWatcherRun(t *testing.T) {
+	src := t.TempDir()
+	out := t.TempDir()
+	writeFile(t, filepath.Join(src, "a.html"), "<h1>A</h1>")
+
+	w := newTestWatcher(t, out, watchRoot{path: src, dir: true})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.run(ctx, 20*time.Millisecond) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}()
+
+	// 启动时转换已有文件
+	waitForFile(t, filepath.Join(out, "a.md"), "# A\n")
+
+	// 修改、新建子目录中的文件和删除文件都同步到输出
+	writeFile(t, filepath.Join(src, "a.html"), "<h1>A2</h1>")
+	waitForFile(t, filepath.Join(out, "a.md"), "# A2\n")
+
+	if err := os.Mkdir(filepath.Join(src, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(src, "sub", "b.html"), "<h2>B</h2>")
+	waitForFile(t, filepath.Join(out, "sub", "b.md"), "## B\n")
+
+	if err := os.Remove(filepath.Join(src, "a.html")); err != nil {
+		t.Fatal(err)
+	}
+	waitForFile(t, filepath.Join(out, "a.md"), "")
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitForFile 等待文件内容变为want，want为空时等待文件被删除
+func waitForFile(t *testing.T, name, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, err := os.ReadFile(name)
+		if want == "" && os.IsNotExist(err) {
+			return
+		}
+		if err == nil && string(data) == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s = %q (err %v), want %q", name, data, err, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatcherOptions(t *testing.T) {
+	src := t.TempDir()
+	out := t.TempDir()
+	page := filepath.Join(src, "page.html")
+	if err := os.WriteFile(page, []byte(`<h1>A</h1><p><span style="font-weight:bold">b</span></p>`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &convertOptions{plugins: "base,commonmark", inferStyles: true, toc: "top"}
+	conv, err := opts.newConverter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newTestWatcher(t, out, watchRoot{path: page})
+	w.conv, w.opts = conv, opts
+	w.convert(page)
+
+	got, err := os.ReadFile(filepath.Join(out, "page.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "- [A](#a)\n\n# A\n\n**b**\n"; string(got) != want {
+		t.Errorf("page.md = %q, want %q", got, want)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net"
@@ -17,7 +18,13 @@ import (
 	pb "github.com/relaxcloud-cn/html2md/api/grpc/proto"
 	"github.com/relaxcloud-cn/html2md/api/grpc/server"
 	httpApi "github.com/relaxcloud-cn/html2md/api/http"
+	"github.com/relaxcloud-cn/html2md/internal/auth"
+	"github.com/relaxcloud-cn/html2md/internal/cache"
 	"github.com/relaxcloud-cn/html2md/internal/config"
+	"github.com/relaxcloud-cn/html2md/internal/jobstore"
+	"github.com/relaxcloud-cn/html2md/internal/logger"
+	"github.com/relaxcloud-cn/html2md/internal/ratelimit"
+	"github.com/relaxcloud-cn/html2md/internal/service"
 )
 
 // @title HTML2Markdown API
@@ -33,16 +40,25 @@ import (
 // @BasePath /
 
 func main() {
-	// 加载配置
-	cfg, err := config.LoadConfig()
+	configPath := flag.String("config", "", "配置文件路径（YAML/JSON/TOML），也可通过环境变量 "+config.ConfigFileEnv+" 指定")
+	flag.Parse()
+
+	// 加载并验证配置
+	cfgManager, err := config.NewManager(config.ResolvePath(*configPath))
 	if err != nil {
 		log.Fatalf("加载配置失败: %v", err)
 	}
+	cfg := cfgManager.Get()
 
-	// 验证配置
-	if err := cfg.Validate(); err != nil {
-		log.Fatalf("配置验证失败: %v", err)
+	// 初始化日志
+	if err := logger.Setup(cfg.Log.Format, cfg.Log.Output, cfg.Log.File, cfg.Log.Level); err != nil {
+		log.Fatalf("初始化日志失败: %v", err)
 	}
+	cfgManager.OnReload(func(_, next *config.Config) {
+		if err := logger.SetLevel(next.Log.Level); err != nil {
+			log.Printf("更新日志级别失败: %v", err)
+		}
+	})
 
 	log.Printf("HTML2Markdown 服务启动中...")
 	log.Printf("环境: %s", cfg.Server.Environment)
@@ -50,17 +66,52 @@ func main() {
 	log.Printf("HTTP 服务地址: %s", cfg.GetHTTPAddress())
 	log.Printf("GRPC 服务地址: %s", cfg.GetGRPCAddress())
 
+	// 创建认证器，HTTP和GRPC共用
+	authenticator := auth.New(cfg.Auth)
+	if authenticator.Enabled() {
+		log.Printf("API密钥认证已启用，共 %d 个密钥", len(cfg.Auth.Keys))
+	}
+
+	// 创建限流器，HTTP和GRPC共用
+	limiter, err := newLimiter(cfgManager)
+	if err != nil {
+		log.Fatalf("初始化限流失败: %v", err)
+	}
+
 	// 创建上下文和等待组
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	var wg sync.WaitGroup
 
+	// 创建转换服务，HTTP、GRPC和异步任务共用
+	convertService := service.NewConvertService(cfgManager)
+
+	// 创建异步任务队列，HTTP和GRPC共用
+	store, err := newJobStore(cfg)
+	if err != nil {
+		log.Fatalf("初始化任务存储失败: %v", err)
+	}
+	jobs, err := service.NewJobQueue(ctx, cfgManager, convertService, store)
+	if err != nil {
+		log.Fatalf("恢复异步任务失败: %v", err)
+	}
+
+	// 监听配置文件变化
+	if cfgManager.Path() != "" {
+		log.Printf("配置文件: %s", cfgManager.Path())
+		go func() {
+			if err := cfgManager.Watch(ctx); err != nil {
+				log.Printf("配置热加载不可用: %v", err)
+			}
+		}()
+	}
+
 	// 启动HTTP服务器
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if err := startHTTPServer(ctx, cfg); err != nil {
+		if err := startHTTPServer(ctx, cfgManager, convertService, authenticator, limiter, jobs); err != nil {
 			log.Printf("HTTP服务器错误: %v", err)
 		}
 	}()
@@ -69,7 +120,7 @@ func main() {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if err := startGRPCServer(ctx, cfg); err != nil {
+		if err := startGRPCServer(ctx, cfgManager, convertService, authenticator, limiter, jobs); err != nil {
 			log.Printf("GRPC服务器错误: %v", err)
 		}
 	}()
@@ -101,12 +152,48 @@ func main() {
 	case <-time.After(30 * time.Second):
 		log.Println("服务器关闭超时")
 	}
+
+	if err := jobs.Close(); err != nil {
+		log.Printf("关闭任务存储失败: %v", err)
+	}
+}
+
+// newLimiter 按配置的存储后端创建限流器
+func newLimiter(cfgManager *config.Manager) (*ratelimit.Limiter, error) {
+	cfg := cfgManager.Get()
+	if cfg.RateLimit.Backend != "redis" {
+		return ratelimit.New(cfgManager, ratelimit.NewMemoryStore()), nil
+	}
+
+	client, err := cache.NewRedisClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("限流使用Redis共享存储: %s", cfg.GetRedisAddress())
+	return ratelimit.New(cfgManager, ratelimit.NewRedisStore(client)), nil
+}
+
+// newJobStore 按配置创建异步任务存储
+func newJobStore(cfg *config.Config) (jobstore.Store, error) {
+	maxSize := int64(cfg.Jobs.MaxStoreSize)
+	if cfg.Jobs.Store == "memory" {
+		return jobstore.NewMemoryStore(maxSize), nil
+	}
+
+	store, err := jobstore.OpenBoltStore(cfg.Jobs.StorePath, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("任务存储: %s", cfg.Jobs.StorePath)
+	return store, nil
 }
 
 // startHTTPServer 启动HTTP服务器
-func startHTTPServer(ctx context.Context, cfg *config.Config) error {
+func startHTTPServer(ctx context.Context, cfgManager *config.Manager, convertService *service.ConvertService, authenticator *auth.Authenticator, limiter *ratelimit.Limiter, jobs *service.JobQueue) error {
+	cfg := cfgManager.Get()
+
 	// 创建路由器
-	router := httpApi.NewRouter()
+	router := httpApi.NewRouter(cfgManager, convertService, authenticator, limiter, jobs)
 
 	// 创建HTTP服务器
 	server := &http.Server{
@@ -150,7 +237,9 @@ func startHTTPServer(ctx context.Context, cfg *config.Config) error {
 }
 
 // startGRPCServer 启动GRPC服务器
-func startGRPCServer(ctx context.Context, cfg *config.Config) error {
+func startGRPCServer(ctx context.Context, cfgManager *config.Manager, convertService *service.ConvertService, authenticator *auth.Authenticator, limiter *ratelimit.Limiter, jobs *service.JobQueue) error {
+	cfg := cfgManager.Get()
+
 	// 创建监听器
 	lis, err := net.Listen("tcp", cfg.GetGRPCAddress())
 	if err != nil {
@@ -161,10 +250,18 @@ func startGRPCServer(ctx context.Context, cfg *config.Config) error {
 	grpcServer := grpc.NewServer(
 		grpc.MaxRecvMsgSize(cfg.Server.GRPC.MaxRecvMsgSize),
 		grpc.MaxSendMsgSize(cfg.Server.GRPC.MaxSendMsgSize),
+		grpc.ChainUnaryInterceptor(
+			server.AuthUnaryInterceptor(authenticator),
+			server.RateLimitUnaryInterceptor(limiter),
+		),
+		grpc.ChainStreamInterceptor(
+			server.AuthStreamInterceptor(authenticator),
+			server.RateLimitStreamInterceptor(limiter),
+		),
 	)
 
 	// 注册服务
-	convertServer := server.NewConvertServer()
+	convertServer := server.NewConvertServer(convertService, jobs)
 	pb.RegisterConvertServiceServer(grpcServer, convertServer)
 
 	// 在goroutine中启动服务器
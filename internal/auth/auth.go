@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/relaxcloud-cn/html2md/internal/config"
+)
+
+// 授权范围
+const (
+	ScopeConvert = config.ScopeConvert
+	ScopeBatch   = config.ScopeBatch
+	ScopeAdmin   = config.ScopeAdmin
+)
+
+// 认证错误，HTTP对应401/403，GRPC对应Unauthenticated/PermissionDenied
+var (
+	ErrMissingKey = errors.New("缺少API密钥")
+	ErrInvalidKey = errors.New("API密钥无效")
+	ErrForbidden  = errors.New("API密钥无权访问该接口")
+)
+
+// Principal 已认证的调用方
+type Principal struct {
+	Name   string   // 密钥名称
+	Scopes []string // 授权范围
+}
+
+// HasScope 判断调用方是否拥有指定授权范围，admin拥有全部范围
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, ScopeAdmin) || slices.Contains(p.Scopes, scope)
+}
+
+// Authenticator API密钥认证器，HTTP中间件和GRPC拦截器共用
+type Authenticator struct {
+	enabled      bool
+	publicHealth bool
+	publicDocs   bool
+	keys         map[string]*Principal // 密钥哈希 -> 调用方
+}
+
+// New 根据配置创建认证器
+func New(cfg config.AuthConfig) *Authenticator {
+	a := &Authenticator{
+		enabled:      cfg.Enabled,
+		publicHealth: cfg.PublicHealth,
+		publicDocs:   cfg.PublicDocs,
+		keys:         make(map[string]*Principal, len(cfg.Keys)),
+	}
+	for _, key := range cfg.Keys {
+		a.keys[key.Hash] = &Principal{Name: key.Name, Scopes: key.Scopes}
+	}
+	return a
+}
+
+// Enabled 是否启用认证
+func (a *Authenticator) Enabled() bool {
+	return a.enabled
+}
+
+// PublicHealth 健康检查是否无需认证
+func (a *Authenticator) PublicHealth() bool {
+	return !a.enabled || a.publicHealth
+}
+
+// PublicDocs 文档和演示页面是否无需认证
+func (a *Authenticator) PublicDocs() bool {
+	return !a.enabled || a.publicDocs
+}
+
+// Authenticate 校验API密钥并检查授权范围，scope为空时只要求密钥有效
+//
+// 认证未启用时返回nil调用方和nil错误。
+func (a *Authenticator) Authenticate(key, scope string) (*Principal, error) {
+	if !a.enabled {
+		return nil, nil
+	}
+	if key == "" {
+		return nil, ErrMissingKey
+	}
+
+	principal, ok := a.keys[config.HashAPIKey(key)]
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	if scope != "" && !principal.HasScope(scope) {
+		return principal, ErrForbidden
+	}
+
+	return principal, nil
+}
+
+// ExtractKey 从Authorization或X-API-Key的值中提取API密钥
+//
+// Authorization仅接受Bearer方案，两者同时存在时优先使用Authorization。
+func ExtractKey(authorization, apiKey string) string {
+	if scheme, token, ok := strings.Cut(strings.TrimSpace(authorization), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return strings.TrimSpace(apiKey)
+}
+
+type principalKey struct{}
+
+// WithPrincipal 将调用方保存到上下文
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext 从上下文获取调用方，未认证时返回nil
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
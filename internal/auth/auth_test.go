@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/relaxcloud-cn/html2md/internal/config"
+)
+
+func newTestAuthenticator() *Authenticator {
+	return New(config.AuthConfig{
+		Enabled: true,
+		Keys: []config.APIKeyConfig{
+			{Name: "reader", Hash: config.HashAPIKey("convert-key"), Scopes: []string{ScopeConvert}},
+			{Name: "bulk", Hash: config.HashAPIKey("batch-key"), Scopes: []string{ScopeConvert, ScopeBatch}},
+			{Name: "ops", Hash: config.HashAPIKey("admin-key"), Scopes: []string{ScopeAdmin}},
+		},
+	})
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := newTestAuthenticator()
+
+	tests := []struct {
+		name    string
+		key     string
+		scope   string
+		want    string
+		wantErr error
+	}{
+		{"missing key", "", ScopeConvert, "", ErrMissingKey},
+		{"unknown key", "nope", ScopeConvert, "", ErrInvalidKey},
+		{"convert scope", "convert-key", ScopeConvert, "reader", nil},
+		{"convert key lacks batch", "convert-key", ScopeBatch, "reader", ErrForbidden},
+		{"convert key lacks admin", "convert-key", ScopeAdmin, "reader", ErrForbidden},
+		{"batch scope", "batch-key", ScopeBatch, "bulk", nil},
+		{"admin implies convert", "admin-key", ScopeConvert, "ops", nil},
+		{"admin implies batch", "admin-key", ScopeBatch, "ops", nil},
+		{"any valid key without scope", "convert-key", "", "reader", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := a.Authenticate(tt.key, tt.scope)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			var got string
+			if p != nil {
+				got = p.Name
+			}
+			if got != tt.want {
+				t.Errorf("principal = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateDisabled(t *testing.T) {
+	a := New(config.AuthConfig{})
+	p, err := a.Authenticate("", ScopeAdmin)
+	if p != nil || err != nil {
+		t.Errorf("Authenticate = %v, %v, want nil, nil", p, err)
+	}
+	if !a.PublicHealth() || !a.PublicDocs() {
+		t.Error("disabled authenticator should make health and docs public")
+	}
+}
+
+func TestPublicEndpoints(t *testing.T) {
+	tests := []struct {
+		cfg                  config.AuthConfig
+		wantHealth, wantDocs bool
+	}{
+		{config.AuthConfig{Enabled: true}, false, false},
+		{config.AuthConfig{Enabled: true, PublicHealth: true}, true, false},
+		{config.AuthConfig{Enabled: true, PublicDocs: true}, false, true},
+	}
+	for _, tt := range tests {
+		a := New(tt.cfg)
+		if a.PublicHealth() != tt.wantHealth || a.PublicDocs() != tt.wantDocs {
+			t.Errorf("%+v: health=%v docs=%v, want %v %v", tt.cfg, a.PublicHealth(), a.PublicDocs(), tt.wantHealth, tt.wantDocs)
+		}
+	}
+}
+
+func TestExtractKey(t *testing.T) {
+	tests := []struct {
+		authorization, apiKey, want string
+	}{
+		{"Bearer abc", "", "abc"},
+		{"bearer  abc ", "", "abc"},
+		{"Bearer abc", "xyz", "abc"},
+		{"", " xyz ", "xyz"},
+		{"Basic dXNlcjpwYXNz", "xyz", "xyz"},
+		{"Basic dXNlcjpwYXNz", "", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := ExtractKey(tt.authorization, tt.apiKey); got != tt.want {
+			t.Errorf("ExtractKey(%q, %q) = %q, want %q", tt.authorization, tt.apiKey, got, tt.want)
+		}
+	}
+}
+
+func TestPrincipalContext(t *testing.T) {
+	if FromContext(context.Background()) != nil {
+		t.Error("FromContext on empty context should be nil")
+	}
+	p := &Principal{Name: "ci"}
+	if got := FromContext(WithPrincipal(context.Background(), p)); got != p {
+		t.Errorf("FromContext = %v, want %v", got, p)
+	}
+}
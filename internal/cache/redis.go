@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/relaxcloud-cn/html2md/internal/config"
+)
+
+// NewRedisClient 创建Redis客户端并检查连接，作为多实例共享的缓存后端
+func NewRedisClient(cfg *config.Config) (*redis.Client, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.GetRedisAddress(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("连接Redis失败 %s: %w", cfg.GetRedisAddress(), err)
+	}
+
+	return client, nil
+}
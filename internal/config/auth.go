@@ -0,0 +1,64 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// API密钥授权范围
+const (
+	ScopeConvert = "convert" // 单个转换
+	ScopeBatch   = "batch"   // 批量转换
+	ScopeAdmin   = "admin"   // 管理权限，包含全部范围
+)
+
+// apiKeyHashPrefix 密钥哈希前缀
+const apiKeyHashPrefix = "sha256:"
+
+// HashAPIKey 计算API密钥的哈希值，格式与配置文件中的hash字段一致
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return apiKeyHashPrefix + hex.EncodeToString(sum[:])
+}
+
+// validate 验证认证配置
+func (a *AuthConfig) validate() error {
+	if a.Enabled && len(a.Keys) == 0 {
+		return fmt.Errorf("auth is enabled but no API keys are configured")
+	}
+
+	validScopes := map[string]bool{
+		ScopeConvert: true, ScopeBatch: true, ScopeAdmin: true,
+	}
+	names := make(map[string]bool, len(a.Keys))
+	for i, key := range a.Keys {
+		if key.Name == "" {
+			return fmt.Errorf("API key #%d: name is required", i)
+		}
+		if names[key.Name] {
+			return fmt.Errorf("API key %s: duplicate name", key.Name)
+		}
+		names[key.Name] = true
+
+		digest, ok := strings.CutPrefix(key.Hash, apiKeyHashPrefix)
+		if !ok {
+			return fmt.Errorf("API key %s: hash must start with %q", key.Name, apiKeyHashPrefix)
+		}
+		if raw, err := hex.DecodeString(digest); err != nil || len(raw) != sha256.Size {
+			return fmt.Errorf("API key %s: invalid sha256 hash", key.Name)
+		}
+
+		if len(key.Scopes) == 0 {
+			return fmt.Errorf("API key %s: at least one scope is required", key.Name)
+		}
+		for _, scope := range key.Scopes {
+			if !validScopes[scope] {
+				return fmt.Errorf("API key %s: invalid scope %q", key.Name, scope)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import "testing"
+
+func TestAuthConfigValidate(t *testing.T) {
+	valid := APIKeyConfig{Name: "ci", Hash: HashAPIKey("secret"), Scopes: []string{ScopeConvert}}
+
+	tests := []struct {
+		name    string
+		cfg     AuthConfig
+		wantErr bool
+	}{
+		{"disabled without keys", AuthConfig{}, false},
+		{"enabled without keys", AuthConfig{Enabled: true}, true},
+		{"valid key", AuthConfig{Enabled: true, Keys: []APIKeyConfig{valid}}, false},
+		{"missing name", AuthConfig{Keys: []APIKeyConfig{{Hash: valid.Hash, Scopes: valid.Scopes}}}, true},
+		{"duplicate name", AuthConfig{Keys: []APIKeyConfig{valid, valid}}, true},
+		{"missing hash prefix", AuthConfig{Keys: []APIKeyConfig{{Name: "ci", Hash: "abcd", Scopes: valid.Scopes}}}, true},
+		{"short hash", AuthConfig{Keys: []APIKeyConfig{{Name: "ci", Hash: "sha256:abcd", Scopes: valid.Scopes}}}, true},
+		{"no scopes", AuthConfig{Keys: []APIKeyConfig{{Name: "ci", Hash: valid.Hash}}}, true},
+		{"unknown scope", AuthConfig{Keys: []APIKeyConfig{{Name: "ci", Hash: valid.Hash, Scopes: []string{"root"}}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,109 +3,302 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
 )
 
 // Config 应用配置
 type Config struct {
 	// 服务器配置
-	Server ServerConfig `json:"server"`
+	Server ServerConfig `json:"server" yaml:"server"`
 
 	// 日志配置
-	Log LogConfig `json:"log"`
+	Log LogConfig `json:"log" yaml:"log"`
 
 	// 转换器配置
-	Converter ConverterConfig `json:"converter"`
+	Converter ConverterConfig `json:"converter" yaml:"converter"`
+
+	// 认证配置
+	Auth AuthConfig `json:"auth" yaml:"auth"`
+
+	// 限流配置
+	RateLimit RateLimitConfig `json:"rate_limit" yaml:"rate_limit"`
+
+	// Redis配置，作为多实例共享的缓存后端
+	Redis RedisConfig `json:"redis" yaml:"redis"`
+
+	// 跨域配置
+	CORS CORSConfig `json:"cors" yaml:"cors"`
+
+	// 异步任务配置
+	Jobs JobsConfig `json:"jobs" yaml:"jobs"`
+
+	// 按URL抓取页面的配置
+	Fetch FetchConfig `json:"fetch" yaml:"fetch"`
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
 	// HTTP服务器配置
-	HTTP HTTPConfig `json:"http"`
+	HTTP HTTPConfig `json:"http" yaml:"http"`
 
 	// GRPC服务器配置
-	GRPC GRPCConfig `json:"grpc"`
+	GRPC GRPCConfig `json:"grpc" yaml:"grpc"`
 
 	// 通用配置
-	Environment string        `json:"environment"` // 环境: development, production
-	Name        string        `json:"name"`        // 服务名称
-	Version     string        `json:"version"`     // 服务版本
-	Timeout     time.Duration `json:"timeout"`     // 请求超时时间
+	Environment string        `json:"environment" yaml:"environment"` // 环境: development, production
+	Name        string        `json:"name" yaml:"name"`               // 服务名称
+	Version     string        `json:"version" yaml:"version"`         // 服务版本
+	Timeout     time.Duration `json:"timeout" yaml:"timeout"`         // 请求超时时间
 }
 
 // HTTPConfig HTTP服务器配置
 type HTTPConfig struct {
-	Port         int           `json:"port"`          // 端口
-	Host         string        `json:"host"`          // 主机地址
-	ReadTimeout  time.Duration `json:"read_timeout"`  // 读取超时
-	WriteTimeout time.Duration `json:"write_timeout"` // 写入超时
-	IdleTimeout  time.Duration `json:"idle_timeout"`  // 空闲超时
+	Port         int           `json:"port" yaml:"port"`                   // 端口
+	Host         string        `json:"host" yaml:"host"`                   // 主机地址
+	ReadTimeout  time.Duration `json:"read_timeout" yaml:"read_timeout"`   // 读取超时
+	WriteTimeout time.Duration `json:"write_timeout" yaml:"write_timeout"` // 写入超时
+	IdleTimeout  time.Duration `json:"idle_timeout" yaml:"idle_timeout"`   // 空闲超时
 }
 
 // GRPCConfig GRPC服务器配置
 type GRPCConfig struct {
-	Port           int           `json:"port"`              // 端口
-	Host           string        `json:"host"`              // 主机地址
-	MaxRecvMsgSize int           `json:"max_recv_msg_size"` // 最大接收消息大小
-	MaxSendMsgSize int           `json:"max_send_msg_size"` // 最大发送消息大小
-	Timeout        time.Duration `json:"timeout"`           // 超时时间
+	Port           int           `json:"port" yaml:"port"`                           // 端口
+	Host           string        `json:"host" yaml:"host"`                           // 主机地址
+	MaxRecvMsgSize int           `json:"max_recv_msg_size" yaml:"max_recv_msg_size"` // 最大接收消息大小
+	MaxSendMsgSize int           `json:"max_send_msg_size" yaml:"max_send_msg_size"` // 最大发送消息大小
+	Timeout        time.Duration `json:"timeout" yaml:"timeout"`                     // 超时时间
 }
 
 // LogConfig 日志配置
 type LogConfig struct {
-	Level  string `json:"level"`  // 日志级别: debug, info, warn, error
-	Format string `json:"format"` // 日志格式: json, text
-	Output string `json:"output"` // 输出: stdout, stderr, file
-	File   string `json:"file"`   // 日志文件路径
+	Level  string `json:"level" yaml:"level"`   // 日志级别: debug, info, warn, error
+	Format string `json:"format" yaml:"format"` // 日志格式: json, text
+	Output string `json:"output" yaml:"output"` // 输出: stdout, stderr, file
+	File   string `json:"file" yaml:"file"`     // 日志文件路径
 }
 
 // ConverterConfig 转换器配置
 type ConverterConfig struct {
-	MaxInputSize int           `json:"max_input_size"` // 最大输入大小（字节）
-	MaxBatchSize int           `json:"max_batch_size"` // 最大批量转换数量
-	Timeout      time.Duration `json:"timeout"`        // 转换超时时间
-	EnableCache  bool          `json:"enable_cache"`   // 是否启用缓存
+	MaxInputSize    int                    `json:"max_input_size" yaml:"max_input_size"`       // 最大输入大小（字节）
+	MaxBatchSize    int                    `json:"max_batch_size" yaml:"max_batch_size"`       // 最大批量转换数量
+	Timeout         time.Duration          `json:"timeout" yaml:"timeout"`                     // 转换超时时间
+	EnableCache     bool                   `json:"enable_cache" yaml:"enable_cache"`           // 是否启用缓存
+	DefaultPlugins  []string               `json:"default_plugins" yaml:"default_plugins"`     // 默认启用的插件
+	MaxArchiveSize  int                    `json:"max_archive_size" yaml:"max_archive_size"`   // 压缩包最大大小（字节）
+	MaxArchiveFiles int                    `json:"max_archive_files" yaml:"max_archive_files"` // 压缩包最多包含的文件数
+	Rules           []converter.Rule       `json:"rules" yaml:"rules"`                         // 自定义转换规则，请求中的规则优先
+	WasmPlugins     []converter.WasmPlugin `json:"wasm_plugins" yaml:"wasm_plugins"`           // WebAssembly插件，可通过名称在请求或默认插件中启用
+}
+
+// AuthConfig 认证配置
+type AuthConfig struct {
+	Enabled      bool           `json:"enabled" yaml:"enabled"`             // 是否启用API密钥认证
+	PublicHealth bool           `json:"public_health" yaml:"public_health"` // 健康检查是否无需认证
+	PublicDocs   bool           `json:"public_docs" yaml:"public_docs"`     // 文档和演示页面是否无需认证
+	Keys         []APIKeyConfig `json:"keys" yaml:"keys"`                   // API密钥列表
+}
+
+// APIKeyConfig API密钥配置，只保存密钥的哈希值
+type APIKeyConfig struct {
+	Name   string   `json:"name" yaml:"name"`     // 密钥名称，用于日志和限流标识
+	Hash   string   `json:"hash" yaml:"hash"`     // 密钥哈希: sha256:<hex>
+	Scopes []string `json:"scopes" yaml:"scopes"` // 授权范围: convert, batch, admin
+}
+
+// RateLimitConfig 限流配置，按API密钥或客户端IP分别计算
+type RateLimitConfig struct {
+	Enabled    bool       `json:"enabled" yaml:"enabled"`         // 是否启用限流
+	Backend    string     `json:"backend" yaml:"backend"`         // 令牌桶存储: memory, redis
+	Requests   RateBudget `json:"requests" yaml:"requests"`       // 单个转换请求数
+	BatchItems RateBudget `json:"batch_items" yaml:"batch_items"` // 批量转换项目数
+	InputBytes RateBudget `json:"input_bytes" yaml:"input_bytes"` // 输入HTML字节数
+}
+
+// RateBudget 令牌桶配额
+type RateBudget struct {
+	PerMinute int `json:"per_minute" yaml:"per_minute"` // 每分钟配额，0表示不限制
+	Burst     int `json:"burst" yaml:"burst"`           // 突发容量，0表示与每分钟配额相同
+}
+
+// RedisConfig Redis配置
+type RedisConfig struct {
+	Host     string `json:"host" yaml:"host"`         // 主机地址
+	Port     int    `json:"port" yaml:"port"`         // 端口
+	Password string `json:"password" yaml:"password"` // 密码
+	DB       int    `json:"db" yaml:"db"`             // 数据库编号
 }
 
 // LoadConfig 加载配置
-func LoadConfig() (*Config, error) {
-	config := &Config{
+//
+// 优先级从低到高依次为：内置默认值、配置文件（path为空时跳过）、环境变量。
+func LoadConfig(path string) (*Config, error) {
+	config := defaultConfig()
+
+	if path != "" {
+		if err := loadFile(path, config); err != nil {
+			return nil, err
+		}
+	}
+
+	applyEnv(config)
+	applyEnvironmentDefaults(config)
+
+	return config, nil
+}
+
+// defaultConfig 内置默认配置
+func defaultConfig() *Config {
+	return &Config{
 		Server: ServerConfig{
 			HTTP: HTTPConfig{
-				Port:         getEnvAsInt("HTTP_PORT", 8080),
-				Host:         getEnvAsString("HTTP_HOST", "0.0.0.0"),
-				ReadTimeout:  getEnvAsDuration("HTTP_READ_TIMEOUT", "30s"),
-				WriteTimeout: getEnvAsDuration("HTTP_WRITE_TIMEOUT", "30s"),
-				IdleTimeout:  getEnvAsDuration("HTTP_IDLE_TIMEOUT", "60s"),
+				Port:         8080,
+				Host:         "0.0.0.0",
+				ReadTimeout:  30 * time.Second,
+				WriteTimeout: 30 * time.Second,
+				IdleTimeout:  60 * time.Second,
 			},
 			GRPC: GRPCConfig{
-				Port:           getEnvAsInt("GRPC_PORT", 9090),
-				Host:           getEnvAsString("GRPC_HOST", "0.0.0.0"),
-				MaxRecvMsgSize: getEnvAsInt("GRPC_MAX_RECV_MSG_SIZE", 4*1024*1024), // 4MB
-				MaxSendMsgSize: getEnvAsInt("GRPC_MAX_SEND_MSG_SIZE", 4*1024*1024), // 4MB
-				Timeout:        getEnvAsDuration("GRPC_TIMEOUT", "30s"),
+				Port:           9090,
+				Host:           "0.0.0.0",
+				MaxRecvMsgSize: 4 * 1024 * 1024, // 4MB
+				MaxSendMsgSize: 4 * 1024 * 1024, // 4MB
+				Timeout:        30 * time.Second,
 			},
-			Environment: getEnvAsString("ENVIRONMENT", "development"),
-			Name:        getEnvAsString("SERVICE_NAME", "html2md"),
-			Version:     getEnvAsString("SERVICE_VERSION", "1.0.0"),
-			Timeout:     getEnvAsDuration("SERVICE_TIMEOUT", "30s"),
+			Environment: "development",
+			Name:        "html2md",
+			Version:     "1.0.0",
+			Timeout:     30 * time.Second,
 		},
 		Log: LogConfig{
-			Level:  getEnvAsString("LOG_LEVEL", "info"),
-			Format: getEnvAsString("LOG_FORMAT", "json"),
-			Output: getEnvAsString("LOG_OUTPUT", "stdout"),
-			File:   getEnvAsString("LOG_FILE", ""),
+			Level:  "info",
+			Format: "json",
+			Output: "stdout",
 		},
 		Converter: ConverterConfig{
-			MaxInputSize: getEnvAsInt("CONVERTER_MAX_INPUT_SIZE", 10*1024*1024), // 10MB
-			MaxBatchSize: getEnvAsInt("CONVERTER_MAX_BATCH_SIZE", 100),
-			Timeout:      getEnvAsDuration("CONVERTER_TIMEOUT", "30s"),
-			EnableCache:  getEnvAsBool("CONVERTER_ENABLE_CACHE", false),
+			MaxInputSize:    10 * 1024 * 1024, // 10MB
+			MaxBatchSize:    100,
+			Timeout:         30 * time.Second,
+			DefaultPlugins:  []string{"base", "commonmark"},
+			MaxArchiveSize:  100 * 1024 * 1024, // 100MB
+			MaxArchiveFiles: 10000,
+		},
+		Auth: AuthConfig{
+			PublicHealth: true,
+			PublicDocs:   true,
+		},
+		RateLimit: RateLimitConfig{
+			Backend:    "memory",
+			Requests:   RateBudget{PerMinute: 100},
+			BatchItems: RateBudget{PerMinute: 1000},
+			InputBytes: RateBudget{PerMinute: 100 * 1024 * 1024}, // 100MB
+		},
+		Redis: RedisConfig{
+			Host: "localhost",
+			Port: 6379,
+		},
+		Jobs: JobsConfig{
+			Workers:        2,
+			QueueSize:      100,
+			TTL:            time.Hour,
+			WebhookTimeout: 10 * time.Second,
+			WebhookRetries: 3,
+			Store:          "bolt",
+			StorePath:      "data/jobs.db",
+			MaxStoreSize:   1024 * 1024 * 1024, // 1GB
+		},
+		Fetch: FetchConfig{
+			Enabled:      true,
+			Timeout:      15 * time.Second,
+			MaxSize:      10 * 1024 * 1024, // 10MB
+			MaxRedirects: 5,
+			UserAgent:    "html2md/1.0 (+https://github.com/relaxcloud-cn/html2md)",
 		},
 	}
+}
 
-	return config, nil
+// applyEnv 使用环境变量覆盖配置，未设置的环境变量保留原值
+func applyEnv(c *Config) {
+	c.Server.HTTP.Port = getEnvAsInt("HTTP_PORT", c.Server.HTTP.Port)
+	c.Server.HTTP.Host = getEnvAsString("HTTP_HOST", c.Server.HTTP.Host)
+	c.Server.HTTP.ReadTimeout = getEnvAsDuration("HTTP_READ_TIMEOUT", c.Server.HTTP.ReadTimeout)
+	c.Server.HTTP.WriteTimeout = getEnvAsDuration("HTTP_WRITE_TIMEOUT", c.Server.HTTP.WriteTimeout)
+	c.Server.HTTP.IdleTimeout = getEnvAsDuration("HTTP_IDLE_TIMEOUT", c.Server.HTTP.IdleTimeout)
+
+	c.Server.GRPC.Port = getEnvAsInt("GRPC_PORT", c.Server.GRPC.Port)
+	c.Server.GRPC.Host = getEnvAsString("GRPC_HOST", c.Server.GRPC.Host)
+	c.Server.GRPC.MaxRecvMsgSize = getEnvAsInt("GRPC_MAX_RECV_MSG_SIZE", c.Server.GRPC.MaxRecvMsgSize)
+	c.Server.GRPC.MaxSendMsgSize = getEnvAsInt("GRPC_MAX_SEND_MSG_SIZE", c.Server.GRPC.MaxSendMsgSize)
+	c.Server.GRPC.Timeout = getEnvAsDuration("GRPC_TIMEOUT", c.Server.GRPC.Timeout)
+
+	c.Server.Environment = getEnvAsString("ENVIRONMENT", c.Server.Environment)
+	c.Server.Name = getEnvAsString("SERVICE_NAME", c.Server.Name)
+	c.Server.Version = getEnvAsString("SERVICE_VERSION", c.Server.Version)
+	c.Server.Timeout = getEnvAsDuration("SERVICE_TIMEOUT", c.Server.Timeout)
+
+	c.Log.Level = getEnvAsString("LOG_LEVEL", c.Log.Level)
+	c.Log.Format = getEnvAsString("LOG_FORMAT", c.Log.Format)
+	c.Log.Output = getEnvAsString("LOG_OUTPUT", c.Log.Output)
+	c.Log.File = getEnvAsString("LOG_FILE", c.Log.File)
+
+	c.Converter.MaxInputSize = getEnvAsInt("CONVERTER_MAX_INPUT_SIZE", c.Converter.MaxInputSize)
+	c.Converter.MaxBatchSize = getEnvAsInt("CONVERTER_MAX_BATCH_SIZE", c.Converter.MaxBatchSize)
+	c.Converter.Timeout = getEnvAsDuration("CONVERTER_TIMEOUT", c.Converter.Timeout)
+	c.Converter.EnableCache = getEnvAsBool("CONVERTER_ENABLE_CACHE", c.Converter.EnableCache)
+	c.Converter.DefaultPlugins = getEnvAsStringSlice("CONVERTER_DEFAULT_PLUGINS", c.Converter.DefaultPlugins)
+	c.Converter.MaxArchiveSize = getEnvAsInt("CONVERTER_MAX_ARCHIVE_SIZE", c.Converter.MaxArchiveSize)
+	c.Converter.MaxArchiveFiles = getEnvAsInt("CONVERTER_MAX_ARCHIVE_FILES", c.Converter.MaxArchiveFiles)
+
+	c.Auth.Enabled = getEnvAsBool("AUTH_ENABLED", c.Auth.Enabled)
+	c.Auth.PublicHealth = getEnvAsBool("AUTH_PUBLIC_HEALTH", c.Auth.PublicHealth)
+	c.Auth.PublicDocs = getEnvAsBool("AUTH_PUBLIC_DOCS", c.Auth.PublicDocs)
+	// API_KEY 为明文密钥，加载时即转为哈希，拥有全部权限
+	if key := os.Getenv("API_KEY"); key != "" {
+		c.Auth.Keys = append(c.Auth.Keys, APIKeyConfig{
+			Name:   "env",
+			Hash:   HashAPIKey(key),
+			Scopes: []string{ScopeAdmin},
+		})
+	}
+
+	c.RateLimit.Enabled = getEnvAsBool("RATE_LIMIT_ENABLED", c.RateLimit.Enabled)
+	c.RateLimit.Backend = getEnvAsString("RATE_LIMIT_BACKEND", c.RateLimit.Backend)
+	c.RateLimit.Requests.PerMinute = getEnvAsInt("RATE_LIMIT", c.RateLimit.Requests.PerMinute)
+	c.RateLimit.Requests.Burst = getEnvAsInt("RATE_LIMIT_BURST", c.RateLimit.Requests.Burst)
+	c.RateLimit.BatchItems.PerMinute = getEnvAsInt("RATE_LIMIT_BATCH_ITEMS", c.RateLimit.BatchItems.PerMinute)
+	c.RateLimit.InputBytes.PerMinute = getEnvAsInt("RATE_LIMIT_INPUT_BYTES", c.RateLimit.InputBytes.PerMinute)
+
+	c.Redis.Host = getEnvAsString("REDIS_HOST", c.Redis.Host)
+	c.Redis.Port = getEnvAsInt("REDIS_PORT", c.Redis.Port)
+	c.Redis.Password = getEnvAsString("REDIS_PASSWORD", c.Redis.Password)
+	c.Redis.DB = getEnvAsInt("REDIS_DB", c.Redis.DB)
+
+	c.CORS.AllowOrigins = getEnvAsStringSlice("CORS_ORIGINS", c.CORS.AllowOrigins)
+	c.CORS.AllowMethods = getEnvAsStringSlice("CORS_ALLOW_METHODS", c.CORS.AllowMethods)
+	c.CORS.AllowHeaders = getEnvAsStringSlice("CORS_ALLOW_HEADERS", c.CORS.AllowHeaders)
+	c.CORS.ExposeHeaders = getEnvAsStringSlice("CORS_EXPOSE_HEADERS", c.CORS.ExposeHeaders)
+	c.CORS.AllowCredentials = getEnvAsBool("CORS_ALLOW_CREDENTIALS", c.CORS.AllowCredentials)
+	c.CORS.MaxAge = getEnvAsDuration("CORS_MAX_AGE", c.CORS.MaxAge)
+
+	c.Jobs.Workers = getEnvAsInt("JOBS_WORKERS", c.Jobs.Workers)
+	c.Jobs.QueueSize = getEnvAsInt("JOBS_QUEUE_SIZE", c.Jobs.QueueSize)
+	c.Jobs.TTL = getEnvAsDuration("JOBS_TTL", c.Jobs.TTL)
+	c.Jobs.WebhookSecret = getEnvAsString("JOBS_WEBHOOK_SECRET", c.Jobs.WebhookSecret)
+	c.Jobs.WebhookTimeout = getEnvAsDuration("JOBS_WEBHOOK_TIMEOUT", c.Jobs.WebhookTimeout)
+	c.Jobs.WebhookRetries = getEnvAsInt("JOBS_WEBHOOK_RETRIES", c.Jobs.WebhookRetries)
+	c.Jobs.Store = getEnvAsString("JOBS_STORE", c.Jobs.Store)
+	c.Jobs.StorePath = getEnvAsString("JOBS_STORE_PATH", c.Jobs.StorePath)
+	c.Jobs.MaxStoreSize = getEnvAsInt("JOBS_MAX_STORE_SIZE", c.Jobs.MaxStoreSize)
+	c.Fetch.Enabled = getEnvAsBool("FETCH_ENABLED", c.Fetch.Enabled)
+	c.Fetch.Timeout = getEnvAsDuration("FETCH_TIMEOUT", c.Fetch.Timeout)
+	c.Fetch.MaxSize = getEnvAsInt("FETCH_MAX_SIZE", c.Fetch.MaxSize)
+	c.Fetch.MaxRedirects = getEnvAsInt("FETCH_MAX_REDIRECTS", c.Fetch.MaxRedirects)
+	c.Fetch.UserAgent = getEnvAsString("FETCH_USER_AGENT", c.Fetch.UserAgent)
+	c.Fetch.AllowNetworks = getEnvAsStringSlice("FETCH_ALLOW_NETWORKS", c.Fetch.AllowNetworks)
 }
 
 // Validate 验证配置
@@ -127,6 +320,21 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid log level: %s", c.Log.Level)
 	}
 
+	// 验证日志格式和输出
+	if c.Log.Format != "json" && c.Log.Format != "text" {
+		return fmt.Errorf("invalid log format: %s", c.Log.Format)
+	}
+
+	validLogOutputs := map[string]bool{
+		"stdout": true, "stderr": true, "file": true,
+	}
+	if !validLogOutputs[c.Log.Output] {
+		return fmt.Errorf("invalid log output: %s", c.Log.Output)
+	}
+	if c.Log.Output == "file" && c.Log.File == "" {
+		return fmt.Errorf("log file path is required when log output is file")
+	}
+
 	// 验证环境
 	validEnvs := map[string]bool{
 		"development": true, "production": true, "testing": true,
@@ -144,6 +352,63 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("max batch size must be positive")
 	}
 
+	if c.Converter.MaxArchiveSize <= 0 {
+		return fmt.Errorf("max archive size must be positive")
+	}
+
+	if c.Converter.MaxArchiveFiles <= 0 {
+		return fmt.Errorf("max archive files must be positive")
+	}
+
+	if err := converter.ValidateWasmPlugins(c.Converter.WasmPlugins); err != nil {
+		return fmt.Errorf("invalid wasm plugins: %w", err)
+	}
+
+	// 默认插件可以包含WebAssembly插件，其余名称必须是内置插件
+	builtin := slices.DeleteFunc(slices.Clone(c.Converter.DefaultPlugins), func(name string) bool {
+		return slices.ContainsFunc(c.Converter.WasmPlugins, func(p converter.WasmPlugin) bool { return p.Name == name })
+	})
+	if err := converter.ValidatePlugins(builtin); err != nil {
+		return fmt.Errorf("invalid default plugins: %w", err)
+	}
+
+	if err := converter.ValidateRules(c.Converter.Rules); err != nil {
+		return fmt.Errorf("invalid converter rules: %w", err)
+	}
+
+	if err := c.Auth.validate(); err != nil {
+		return err
+	}
+
+	// 验证限流配置
+	if c.RateLimit.Backend != "memory" && c.RateLimit.Backend != "redis" {
+		return fmt.Errorf("invalid rate limit backend: %s", c.RateLimit.Backend)
+	}
+	for name, budget := range map[string]RateBudget{
+		"requests":    c.RateLimit.Requests,
+		"batch_items": c.RateLimit.BatchItems,
+		"input_bytes": c.RateLimit.InputBytes,
+	} {
+		if budget.PerMinute < 0 || budget.Burst < 0 {
+			return fmt.Errorf("rate limit %s must not be negative", name)
+		}
+	}
+	if c.RateLimit.Enabled && c.RateLimit.Backend == "redis" && c.Redis.Host == "" {
+		return fmt.Errorf("redis host is required for redis rate limit backend")
+	}
+
+	if err := c.CORS.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Jobs.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Fetch.validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -152,6 +417,11 @@ func (c *Config) GetHTTPAddress() string {
 	return fmt.Sprintf("%s:%d", c.Server.HTTP.Host, c.Server.HTTP.Port)
 }
 
+// GetRedisAddress 获取Redis地址
+func (c *Config) GetRedisAddress() string {
+	return fmt.Sprintf("%s:%d", c.Redis.Host, c.Redis.Port)
+}
+
 // GetGRPCAddress 获取GRPC服务器地址
 func (c *Config) GetGRPCAddress() string {
 	return fmt.Sprintf("%s:%d", c.Server.GRPC.Host, c.Server.GRPC.Port)
@@ -193,23 +463,25 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 }
 
 // getEnvAsDuration 获取环境变量（时间）
-func getEnvAsDuration(key string, defaultValue string) time.Duration {
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
 			return duration
 		}
 	}
-	if duration, err := time.ParseDuration(defaultValue); err == nil {
-		return duration
-	}
-	return time.Second * 30 // 回退默认值
+	return defaultValue
 }
 
-// getEnvAsStringSlice 获取环境变量（字符串切片）
+// getEnvAsStringSlice 获取环境变量（逗号分隔的字符串切片）
 func getEnvAsStringSlice(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
-		// 简单的逗号分割，实际项目中可能需要更复杂的解析
-		return []string{value}
+		var result []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+		return result
 	}
 	return defaultValue
 }
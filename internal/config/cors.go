@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CORSConfig 跨域配置
+type CORSConfig struct {
+	AllowOrigins     []string      `json:"allow_origins" yaml:"allow_origins"`         // 允许的源站，支持 * 和 https://*.example.com 形式的子域名通配
+	AllowMethods     []string      `json:"allow_methods" yaml:"allow_methods"`         // 允许的请求方法
+	AllowHeaders     []string      `json:"allow_headers" yaml:"allow_headers"`         // 允许的请求头
+	ExposeHeaders    []string      `json:"expose_headers" yaml:"expose_headers"`       // 暴露给浏览器的响应头
+	AllowCredentials bool          `json:"allow_credentials" yaml:"allow_credentials"` // 是否允许携带凭证
+	MaxAge           time.Duration `json:"max_age" yaml:"max_age"`                     // 预检请求缓存时间
+}
+
+// applyEnvironmentDefaults 为配置文件和环境变量都未设置的跨域配置项填充默认值
+//
+// 开发和测试环境默认允许所有源站；生产环境默认不允许跨域，需显式配置源站。
+func applyEnvironmentDefaults(c *Config) {
+	if c.CORS.AllowOrigins == nil && !c.IsProduction() {
+		c.CORS.AllowOrigins = []string{"*"}
+	}
+	if c.CORS.AllowMethods == nil {
+		c.CORS.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	}
+	if c.CORS.AllowHeaders == nil {
+		c.CORS.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "X-API-Key"}
+	}
+	if c.CORS.ExposeHeaders == nil {
+		c.CORS.ExposeHeaders = []string{"Content-Length", "Retry-After"}
+	}
+	if c.CORS.MaxAge == 0 {
+		c.CORS.MaxAge = 12 * time.Hour
+	}
+}
+
+// validate 验证跨域配置
+func (c *CORSConfig) validate() error {
+	for _, origin := range c.AllowOrigins {
+		if origin == "*" {
+			// 浏览器不接受 Access-Control-Allow-Origin: * 与携带凭证同时使用
+			if c.AllowCredentials {
+				return fmt.Errorf("CORS: wildcard origin \"*\" cannot be combined with allow_credentials")
+			}
+			continue
+		}
+		if err := validateOriginPattern(origin); err != nil {
+			return fmt.Errorf("CORS: invalid origin %q: %w", origin, err)
+		}
+	}
+
+	if c.MaxAge < 0 {
+		return fmt.Errorf("CORS: max age must not be negative")
+	}
+
+	return nil
+}
+
+// validateOriginPattern 验证源站格式: scheme://host[:port]，host可以 *. 开头匹配任意子域名
+func validateOriginPattern(origin string) error {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+	if u.Host == "" || u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("origin must be scheme://host[:port]")
+	}
+
+	host := strings.TrimPrefix(u.Hostname(), "*.")
+	if host == "" || strings.Contains(host, "*") {
+		return fmt.Errorf("wildcard is only allowed as the leftmost label, e.g. https://*.example.com")
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import "testing"
+
+func TestCORSConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     CORSConfig
+		wantErr bool
+	}{
+		{"empty", CORSConfig{}, false},
+		{"wildcard", CORSConfig{AllowOrigins: []string{"*"}}, false},
+		{"wildcard with credentials", CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}, true},
+		{"exact origin with credentials", CORSConfig{AllowOrigins: []string{"https://app.example.com"}, AllowCredentials: true}, false},
+		{"subdomain wildcard", CORSConfig{AllowOrigins: []string{"https://*.example.com"}}, false},
+		{"port", CORSConfig{AllowOrigins: []string{"http://localhost:3000"}}, false},
+		{"missing scheme", CORSConfig{AllowOrigins: []string{"app.example.com"}}, true},
+		{"ftp scheme", CORSConfig{AllowOrigins: []string{"ftp://app.example.com"}}, true},
+		{"path", CORSConfig{AllowOrigins: []string{"https://app.example.com/"}}, true},
+		{"inner wildcard", CORSConfig{AllowOrigins: []string{"https://app.*.example.com"}}, true},
+		{"bare wildcard host", CORSConfig{AllowOrigins: []string{"https://*."}}, true},
+		{"negative max age", CORSConfig{MaxAge: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCORSEnvironmentDefaults(t *testing.T) {
+	tests := []struct {
+		environment string
+		want        []string
+	}{
+		{"development", []string{"*"}},
+		{"production", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.environment, func(t *testing.T) {
+			c := defaultConfig()
+			c.Server.Environment = tt.environment
+			applyEnvironmentDefaults(c)
+			if len(c.CORS.AllowOrigins) != len(tt.want) || (len(tt.want) > 0 && c.CORS.AllowOrigins[0] != tt.want[0]) {
+				t.Errorf("allow_origins = %v, want %v", c.CORS.AllowOrigins, tt.want)
+			}
+			if c.CORS.MaxAge == 0 || len(c.CORS.AllowMethods) == 0 {
+				t.Error("methods and max age should always get defaults")
+			}
+		})
+	}
+
+	// 显式配置为空列表时不覆盖
+	c := defaultConfig()
+	c.CORS.AllowOrigins = []string{}
+	applyEnvironmentDefaults(c)
+	if len(c.CORS.AllowOrigins) != 0 {
+		t.Errorf("explicit empty allow_origins overridden with %v", c.CORS.AllowOrigins)
+	}
+}
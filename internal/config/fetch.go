@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// FetchConfig 按URL抓取页面的配置
+type FetchConfig struct {
+	Enabled       bool          `json:"enabled" yaml:"enabled"`               // 是否允许转换请求使用 url 抓取页面
+	Timeout       time.Duration `json:"timeout" yaml:"timeout"`               // 整个抓取的超时时间，包括重定向和读取响应
+	MaxSize       int           `json:"max_size" yaml:"max_size"`             // 页面的最大字节数
+	MaxRedirects  int           `json:"max_redirects" yaml:"max_redirects"`   // 最多跟随的重定向次数
+	UserAgent     string        `json:"user_agent" yaml:"user_agent"`         // User-Agent请求头
+	AllowNetworks []string      `json:"allow_networks" yaml:"allow_networks"` // 允许访问的内网地址段（CIDR或IP），默认阻止回环、私有和链路本地地址，同样适用于任务回调
+}
+
+// AllowedPrefixes 解析允许访问的地址段，单个IP视为只包含该地址的地址段
+func (f *FetchConfig) AllowedPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(f.AllowNetworks))
+	for _, network := range f.AllowNetworks {
+		network = strings.TrimSpace(network)
+		if strings.Contains(network, "/") {
+			prefix, err := netip.ParsePrefix(network)
+			if err != nil {
+				return nil, fmt.Errorf("invalid fetch allow network %q: %w", network, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(network)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fetch allow network %q: %w", network, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// validate 验证抓取配置
+func (f *FetchConfig) validate() error {
+	if f.Timeout <= 0 {
+		return fmt.Errorf("fetch timeout must be positive")
+	}
+	if f.MaxSize <= 0 {
+		return fmt.Errorf("fetch max size must be positive")
+	}
+	if f.MaxRedirects < 0 {
+		return fmt.Errorf("fetch max redirects must not be negative")
+	}
+	_, err := f.AllowedPrefixes()
+	return err
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv 指定配置文件路径的环境变量
+const ConfigFileEnv = "CONFIG_FILE"
+
+// ResolvePath 解析配置文件路径，命令行参数优先于环境变量
+func ResolvePath(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return os.Getenv(ConfigFileEnv)
+}
+
+// loadFile 读取配置文件并覆盖到config上，文件中未出现的字段保留原值
+//
+// 根据扩展名识别格式：.yaml/.yml、.json、.toml。
+// JSON是YAML的子集，TOML先解析为通用结构再转成YAML，
+// 这样三种格式共用yaml标签和"30s"形式的时间解析。
+func loadFile(path string, config *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml", ".json":
+	case ".toml":
+		var raw map[string]interface{}
+		if err := toml.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("解析TOML配置文件失败: %w", err)
+		}
+		if data, err = yaml.Marshal(raw); err != nil {
+			return fmt.Errorf("解析TOML配置文件失败: %w", err)
+		}
+	default:
+		return fmt.Errorf("不支持的配置文件格式: %s", ext)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("解析配置文件失败 %s: %w", path, err)
+	}
+
+	return nil
+}
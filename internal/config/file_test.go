@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeConfig 在临时目录中写入配置文件并返回路径
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"yaml", "config.yaml", "log:\n  level: debug\nconverter:\n  timeout: 5s\n"},
+		{"yml", "config.yml", "log:\n  level: debug\nconverter:\n  timeout: 5s\n"},
+		{"json", "config.json", `{"log": {"level": "debug"}, "converter": {"timeout": "5s"}}`},
+		{"toml", "config.toml", "[log]\nlevel = \"debug\"\n[converter]\ntimeout = \"5s\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadConfig(writeConfig(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if cfg.Log.Level != "debug" {
+				t.Errorf("log.level = %q, want debug", cfg.Log.Level)
+			}
+			if cfg.Converter.Timeout != 5*time.Second {
+				t.Errorf("converter.timeout = %v, want 5s", cfg.Converter.Timeout)
+			}
+			// 文件中未出现的字段保留默认值
+			if cfg.Server.HTTP.Port != 8080 {
+				t.Errorf("server.http.port = %d, want default 8080", cfg.Server.HTTP.Port)
+			}
+		})
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"unknown extension", "config.ini", "level=debug"},
+		{"unknown field", "config.yaml", "log:\n  levle: debug\n"},
+		{"invalid toml", "config.toml", "[log\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadConfig(writeConfig(t, tt.file, tt.content)); err == nil {
+				t.Fatal("LoadConfig succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestLoadConfigEnvOverridesFile(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "warn")
+	cfg, err := LoadConfig(writeConfig(t, "config.yaml", "log:\n  level: debug\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Log.Level != "warn" {
+		t.Errorf("log.level = %q, want env value warn", cfg.Log.Level)
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	t.Setenv(ConfigFileEnv, "/etc/html2md/env.yaml")
+	if got := ResolvePath("/tmp/flag.yaml"); got != "/tmp/flag.yaml" {
+		t.Errorf("ResolvePath(flag) = %q, want flag value", got)
+	}
+	if got := ResolvePath(""); got != "/etc/html2md/env.yaml" {
+		t.Errorf("ResolvePath(\"\") = %q, want env value", got)
+	}
+}
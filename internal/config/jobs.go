@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// JobsConfig 异步转换任务配置
+type JobsConfig struct {
+	Workers        int           `json:"workers" yaml:"workers"`                 // 同时执行任务的工作协程数
+	QueueSize      int           `json:"queue_size" yaml:"queue_size"`           // 等待执行的任务数上限，队列已满时拒绝提交
+	TTL            time.Duration `json:"ttl" yaml:"ttl"`                         // 任务结束后保留状态和结果的时间
+	WebhookSecret  string        `json:"webhook_secret" yaml:"webhook_secret"`   // 回调签名密钥，为空时不签名
+	WebhookTimeout time.Duration `json:"webhook_timeout" yaml:"webhook_timeout"` // 单次回调请求的超时时间
+	WebhookRetries int           `json:"webhook_retries" yaml:"webhook_retries"` // 回调失败后的重试次数
+	Store          string        `json:"store" yaml:"store"`                     // 任务存储: bolt（单文件持久化）, memory
+	StorePath      string        `json:"store_path" yaml:"store_path"`           // bolt存储文件路径
+	MaxStoreSize   int           `json:"max_store_size" yaml:"max_store_size"`   // 任务请求和结果占用的最大字节数，同时限制bolt数据库的大小，0表示不限制
+}
+
+// validate 验证异步任务配置
+func (j *JobsConfig) validate() error {
+	if j.Workers <= 0 {
+		return fmt.Errorf("jobs workers must be positive")
+	}
+	if j.QueueSize <= 0 {
+		return fmt.Errorf("jobs queue size must be positive")
+	}
+	if j.TTL <= 0 {
+		return fmt.Errorf("jobs ttl must be positive")
+	}
+	if j.WebhookTimeout <= 0 {
+		return fmt.Errorf("jobs webhook timeout must be positive")
+	}
+	if j.WebhookRetries < 0 {
+		return fmt.Errorf("jobs webhook retries must not be negative")
+	}
+	if j.Store != "bolt" && j.Store != "memory" {
+		return fmt.Errorf("invalid jobs store: %s", j.Store)
+	}
+	if j.Store == "bolt" && j.StorePath == "" {
+		return fmt.Errorf("jobs store path is required for bolt store")
+	}
+	if j.MaxStoreSize < 0 {
+		return fmt.Errorf("jobs max store size must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce 配置文件变更的防抖间隔，编辑器保存时通常会触发多次写事件
+const reloadDebounce = 300 * time.Millisecond
+
+// Manager 配置管理器，持有当前生效的配置并支持运行时热加载
+type Manager struct {
+	path      string
+	current   atomic.Pointer[Config]
+	mu        sync.Mutex
+	listeners []func(old, new *Config)
+}
+
+// NewManager 加载并验证配置，path为空时仅使用环境变量
+func NewManager(path string) (*Manager, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	m := &Manager{path: path}
+	m.current.Store(cfg)
+	return m, nil
+}
+
+// Get 获取当前生效的配置，返回值只读
+func (m *Manager) Get() *Config {
+	return m.current.Load()
+}
+
+// Path 配置文件路径
+func (m *Manager) Path() string {
+	return m.path
+}
+
+// OnReload 注册配置热加载回调
+func (m *Manager) OnReload(fn func(old, new *Config)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, fn)
+}
+
+// Reload 重新读取配置文件并应用可热加载的配置项
+//
+// 只有日志级别、转换器配置、限流配额、跨域策略、任务保留时间等可安全变更的配置会在运行时生效，
+// 端口、监听地址等其余变更会被记录并提示需要重启。
+// 新配置未通过Validate时拒绝本次加载，继续使用原配置。
+func (m *Manager) Reload() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	loaded, err := LoadConfig(m.path)
+	if err != nil {
+		return err
+	}
+	if err := loaded.Validate(); err != nil {
+		return fmt.Errorf("配置验证失败: %w", err)
+	}
+
+	old := m.Get()
+	next := *old
+	applyReloadable(&next, loaded)
+	if err := next.Validate(); err != nil {
+		return fmt.Errorf("配置验证失败: %w", err)
+	}
+
+	for _, change := range diffConfig(&next, loaded) {
+		log.Printf("配置项 %s 需要重启服务才能生效", change)
+	}
+
+	changes := diffConfig(old, &next)
+	if len(changes) == 0 {
+		log.Printf("配置文件已重新加载，无可热加载的变更")
+		return nil
+	}
+
+	m.current.Store(&next)
+	for _, change := range changes {
+		log.Printf("配置项已更新: %s", change)
+	}
+
+	for _, fn := range m.listeners {
+		fn(old, &next)
+	}
+
+	return nil
+}
+
+// applyReloadable 将src中可热加载的配置项复制到dst
+func applyReloadable(dst, src *Config) {
+	dst.Log.Level = src.Log.Level
+	dst.Converter = src.Converter
+
+	// 限流存储后端需要重启才能切换
+	backend := dst.RateLimit.Backend
+	dst.RateLimit = src.RateLimit
+	dst.RateLimit.Backend = backend
+
+	dst.CORS = src.CORS
+
+	// 工作协程数、队列长度和任务存储需要重启才能生效
+	jobs := src.Jobs
+	jobs.Workers, jobs.QueueSize = dst.Jobs.Workers, dst.Jobs.QueueSize
+	jobs.Store, jobs.StorePath, jobs.MaxStoreSize = dst.Jobs.Store, dst.Jobs.StorePath, dst.Jobs.MaxStoreSize
+	dst.Jobs = jobs
+
+	dst.Fetch = src.Fetch
+}
+
+// Watch 监听配置文件变化并自动热加载，直到ctx取消
+//
+// 监听的是配置文件所在目录而非文件本身，以兼容编辑器通过重命名替换文件的保存方式。
+// Kubernetes ConfigMap 通过替换目录中的 ..data 符号链接更新文件，不会产生配置文件本身的事件，
+// 因此目录中有其他事件时重新解析配置文件的符号链接，指向的文件变化时同样重新加载。
+func (m *Manager) Watch(ctx context.Context) error {
+	if m.path == "" {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("创建配置文件监听失败: %w", err)
+	}
+	defer watcher.Close()
+
+	target := filepath.Clean(m.path)
+	if err := watcher.Add(filepath.Dir(target)); err != nil {
+		return fmt.Errorf("监听配置文件失败: %w", err)
+	}
+
+	log.Printf("正在监听配置文件: %s", target)
+	resolved, _ := filepath.EvalSymlinks(target)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) == target {
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+			} else {
+				real, err := filepath.EvalSymlinks(target)
+				if err != nil || real == resolved {
+					continue
+				}
+				resolved = real
+			}
+			debounce = time.After(reloadDebounce)
+
+		case <-debounce:
+			debounce = nil
+			if err := m.Reload(); err != nil {
+				log.Printf("配置热加载被拒绝: %v", err)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("配置文件监听错误: %v", err)
+		}
+	}
+}
+
+// diffConfig 比较两份配置，返回"路径: 旧值 -> 新值"形式的差异列表
+func diffConfig(old, new *Config) []string {
+	before := flattenConfig(reflect.ValueOf(*old), "")
+	after := flattenConfig(reflect.ValueOf(*new), "")
+
+	var changes []string
+	for key, value := range after {
+		if before[key] == value {
+			continue
+		}
+		if isRedacted(key) {
+			// 密钥、密码等敏感配置只记录路径，不输出任何值或哈希
+			changes = append(changes, key+": 已变更")
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s -> %s", key, before[key], value))
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// redactedSections 变更日志中不输出值的配置段
+var redactedSections = []string{"auth"}
+
+// isRedacted 判断配置路径是否属于不输出值的配置段，或是密码等敏感配置项
+func isRedacted(key string) bool {
+	if strings.HasSuffix(key, "password") || strings.HasSuffix(key, "secret") {
+		return true
+	}
+	for _, section := range redactedSections {
+		if key == section || strings.HasPrefix(key, section+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// flattenConfig 按json标签将配置展开为"a.b.c"路径到值的映射，结果包含敏感配置的明文，只用于比较
+func flattenConfig(v reflect.Value, prefix string) map[string]string {
+	result := make(map[string]string)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = strings.ToLower(field.Name)
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		value := v.Field(i)
+		if value.Kind() == reflect.Struct {
+			for k, val := range flattenConfig(value, name) {
+				result[k] = val
+			}
+			continue
+		}
+		result[name] = fmt.Sprintf("%v", value.Interface())
+	}
+	return result
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffConfigRedactsSecrets(t *testing.T) {
+	old := defaultConfig()
+	next := defaultConfig()
+	next.Log.Level = "debug"
+	next.Redis.Password = "redis-password"
+	next.Jobs.WebhookSecret = "webhook-secret"
+	next.Auth.Keys = []APIKeyConfig{{Name: "ci", Hash: HashAPIKey("ci-key"), Scopes: []string{ScopeConvert}}}
+
+	changes := diffConfig(old, next)
+	joined := strings.Join(changes, "\n")
+
+	for _, secret := range []string{"redis-password", "webhook-secret", HashAPIKey("ci-key")} {
+		if strings.Contains(joined, secret) {
+			t.Errorf("diff leaks %q:\n%s", secret, joined)
+		}
+	}
+
+	want := []string{"auth.keys: 已变更", "jobs.webhook_secret: 已变更", "log.level: info -> debug", "redis.password: 已变更"}
+	if !slices.Equal(changes, want) {
+		t.Errorf("diff = %q, want %q", changes, want)
+	}
+}
+
+func TestIsRedacted(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"auth", true},
+		{"auth.keys", true},
+		{"auth.enabled", true},
+		{"authority", false},
+		{"redis.password", true},
+		{"jobs.webhook_secret", true},
+		{"log.level", false},
+	}
+	for _, tt := range tests {
+		if got := isRedacted(tt.key); got != tt.want {
+			t.Errorf("isRedacted(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestManagerReload(t *testing.T) {
+	path := writeConfig(t, "config.yaml", "log:\n  level: info\n")
+	m, err := NewManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+	m.OnReload(func(old, new *Config) { calls++ })
+
+	// 可热加载的配置立即生效，端口变更需重启
+	if err := os.WriteFile(path, []byte("log:\n  level: debug\nserver:\n  http:\n    port: 9999\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := m.Get().Log.Level; got != "debug" {
+		t.Errorf("log.level = %q, want debug", got)
+	}
+	if got := m.Get().Server.HTTP.Port; got != 8080 {
+		t.Errorf("server.http.port = %d, want unchanged 8080", got)
+	}
+	if calls != 1 {
+		t.Errorf("listeners called %d times, want 1", calls)
+	}
+
+	// 未通过验证的配置被拒绝，保留原配置
+	if err := os.WriteFile(path, []byte("log:\n  level: verbose\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Reload(); err == nil {
+		t.Fatal("Reload accepted invalid config")
+	}
+	if got := m.Get().Log.Level; got != "debug" {
+		t.Errorf("log.level = %q after rejected reload, want debug", got)
+	}
+	if calls != 1 {
+		t.Errorf("listeners called %d times after rejected reload, want 1", calls)
+	}
+}
+
+func TestManagerWatchConfigMap(t *testing.T) {
+	// 模拟ConfigMap的目录结构: config.yaml -> ..data/config.yaml, ..data -> ..v1
+	dir := t.TempDir()
+	writeVersion := func(version, level string) {
+		t.Helper()
+		if err := os.Mkdir(filepath.Join(dir, version), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, version, "config.yaml"), []byte("log:\n  level: "+level+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeVersion("..v1", "info")
+	for link, target := range map[string]string{"..data": "..v1", "config.yaml": "..data/config.yaml"} {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, err := NewManager(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Watch(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	// 更新时创建新版本目录，再以重命名的方式原子地替换 ..data
+	writeVersion("..v2", "debug")
+	if err := os.Symlink("..v2", filepath.Join(dir, "..data_tmp")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for m.Get().Log.Level != "debug" {
+		if time.Now().After(deadline) {
+			t.Fatalf("log.level = %q, want debug after ..data swap", m.Get().Log.Level)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
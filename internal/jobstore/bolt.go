@@ -0,0 +1,319 @@
+package jobstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/relaxcloud-cn/html2md/internal/model"
+)
+
+// bolt存储中的桶，键均为任务ID
+var (
+	bucketJobs     = []byte("jobs")     // 任务状态
+	bucketRequests = []byte("requests") // 未结束任务的请求
+	bucketResults  = []byte("results")  // 成功任务的结果
+
+	dataBuckets = [][]byte{bucketRequests, bucketResults} // 计入容量限制的桶
+)
+
+// boltOptions 打开数据库的选项，文件被其他进程占用时等待1秒
+var boltOptions = &bolt.Options{Timeout: time.Second}
+
+// BoltStore 基于bbolt单文件数据库的任务存储，服务重启后任务和结果仍然可用
+//
+// 除请求和结果的字节数外，数据库已使用的大小（含任务状态和空闲页面）同样不能超过上限。
+// 删除数据后释放的页面由后续写入复用，运行期间文件不会缩小，
+// 打开时空闲页面超过一半则压缩数据库文件。
+type BoltStore struct {
+	db    *bolt.DB
+	quota quota
+}
+
+// OpenBoltStore 打开或创建bolt任务存储，maxSize为最大字节数，0表示不限制
+//
+// 数据库文件被其他进程占用时等待1秒后返回错误。
+func OpenBoltStore(path string, maxSize int64) (*BoltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("创建任务存储目录失败: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, boltOptions)
+	if err != nil {
+		return nil, fmt.Errorf("打开任务存储失败: %w", err)
+	}
+	if db, err = compact(path, db); err != nil {
+		return nil, fmt.Errorf("打开任务存储失败: %w", err)
+	}
+
+	s := &BoltStore{db: db, quota: quota{max: maxSize}}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(bucketJobs); err != nil {
+			return err
+		}
+		for _, name := range dataBuckets {
+			b, err := tx.CreateBucketIfNotExists(name)
+			if err != nil {
+				return err
+			}
+			// 重新统计已占用的容量，上限调小后允许暂时超出
+			if err := b.ForEach(func(_, v []byte) error {
+				s.quota.used += int64(len(v))
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("初始化任务存储失败: %w", err)
+	}
+	return s, nil
+}
+
+// Create 保存新提交的任务及其请求
+func (s *BoltStore) Create(rec *Record, req *model.JobRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("编码任务请求失败: %w", err)
+	}
+	if err := s.quota.reserve(int64(len(data))); err != nil {
+		return err
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		if err := s.fits(tx, int64(len(data))); err != nil {
+			return err
+		}
+		if err := putRecord(tx, rec); err != nil {
+			return err
+		}
+		return tx.Bucket(bucketRequests).Put([]byte(rec.Job.ID), data)
+	})
+	if err != nil {
+		s.quota.release(int64(len(data)))
+		if errors.Is(err, ErrFull) {
+			return err
+		}
+		return fmt.Errorf("保存任务失败: %w", err)
+	}
+	return nil
+}
+
+// Update 更新任务状态
+func (s *BoltStore) Update(rec *Record) error {
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		return putRecord(tx, rec)
+	}); err != nil {
+		return fmt.Errorf("保存任务状态失败: %w", err)
+	}
+	return nil
+}
+
+// Finish 保存结束的任务状态和结果，并删除请求
+func (s *BoltStore) Finish(rec *Record, result *model.JobResult) error {
+	var data []byte
+	if result != nil {
+		var err error
+		if data, err = json.Marshal(result); err != nil {
+			return fmt.Errorf("编码任务结果失败: %w", err)
+		}
+	}
+
+	var delta int64
+	reserved := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		id := []byte(rec.Job.ID)
+		requests := tx.Bucket(bucketRequests)
+		delta = int64(len(data) - len(requests.Get(id)))
+		if err := s.quota.reserve(delta); err != nil {
+			return err
+		}
+		reserved = true
+		if err := s.fits(tx, delta); err != nil {
+			return err
+		}
+		if err := putRecord(tx, rec); err != nil {
+			return err
+		}
+		if err := requests.Delete(id); err != nil {
+			return err
+		}
+		if data == nil {
+			return nil
+		}
+		return tx.Bucket(bucketResults).Put(id, data)
+	})
+	if err != nil {
+		if !reserved {
+			return err
+		}
+		s.quota.release(delta)
+		if errors.Is(err, ErrFull) {
+			return err
+		}
+		return fmt.Errorf("保存任务结果失败: %w", err)
+	}
+	return nil
+}
+
+// Request 读取未结束任务的请求
+func (s *BoltStore) Request(id string) (*model.JobRequest, error) {
+	var req model.JobRequest
+	if err := s.get(bucketRequests, id, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// Result 读取成功任务的结果
+func (s *BoltStore) Result(id string) (*model.JobResult, error) {
+	var result model.JobResult
+	if err := s.get(bucketResults, id, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Delete 删除任务的状态、请求和结果
+func (s *BoltStore) Delete(id string) error {
+	var size int64
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		key := []byte(id)
+		for _, name := range dataBuckets {
+			b := tx.Bucket(name)
+			size += int64(len(b.Get(key)))
+			if err := b.Delete(key); err != nil {
+				return err
+			}
+		}
+		return tx.Bucket(bucketJobs).Delete(key)
+	})
+	if err != nil {
+		return fmt.Errorf("删除任务失败: %w", err)
+	}
+	s.quota.release(size)
+	return nil
+}
+
+// List 读取全部任务状态
+func (s *BoltStore) List() ([]*Record, error) {
+	var records []*Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketJobs).ForEach(func(_, v []byte) error {
+			var rec Record
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return err
+			}
+			records = append(records, &rec)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取任务列表失败: %w", err)
+	}
+	return records, nil
+}
+
+// Close 关闭数据库文件
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// fits 检查写入n字节后数据库已使用的大小是否仍在上限内
+//
+// 写时复制会同时重写所在的叶子页面，按两倍数据量加上分支和空闲列表页面估算需要的空间。
+// 空闲页面足够时复用而不增长，因此上限调小后仍可在已有空间内写入；
+// 空闲页面不连续时实际增长可能超出估算，数据库会略超出上限，重启时压缩。
+func (s *BoltStore) fits(tx *bolt.Tx, n int64) error {
+	if s.quota.max == 0 || n <= 0 {
+		return nil
+	}
+	stats := s.db.Stats()
+	pageSize := int64(s.db.Info().PageSize)
+	need := 2*n + 4*pageSize
+	free := int64(stats.FreePageN) * pageSize
+	if size := tx.Size(); need > free && size+need-free > s.quota.max {
+		return fmt.Errorf("%w: 数据库已使用 %d 字节，上限 %d 字节", ErrFull, size, s.quota.max)
+	}
+	return nil
+}
+
+// compact 空闲页面超过数据库大小的一半时压缩数据库文件，返回重新打开的数据库
+//
+// 先写入临时文件再替换原文件，压缩失败时继续使用原文件。
+func compact(path string, db *bolt.DB) (*bolt.DB, error) {
+	var size int64
+	if err := db.View(func(tx *bolt.Tx) error {
+		size = tx.Size()
+		return nil
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	free := int64(db.Stats().FreePageN) * int64(db.Info().PageSize)
+	if free*2 <= size {
+		return db, nil
+	}
+
+	tmp := path + ".compact"
+	if err := compactTo(tmp, db); err != nil {
+		os.Remove(tmp)
+		log.Printf("压缩任务存储失败，继续使用原文件: %v", err)
+		return db, nil
+	}
+	if err := db.Close(); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		log.Printf("压缩任务存储失败，继续使用原文件: %v", err)
+	} else {
+		log.Printf("已压缩任务存储，释放 %d 字节空闲空间", free)
+	}
+	return bolt.Open(path, 0o600, boltOptions)
+}
+
+// compactTo 将数据库复制到新文件
+func compactTo(path string, src *bolt.DB) error {
+	dst, err := bolt.Open(path, 0o600, boltOptions)
+	if err != nil {
+		return err
+	}
+	if err := bolt.Compact(dst, src, 0); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// get 读取并解码桶中的值
+func (s *BoltStore) get(bucket []byte, id string, v any) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucket).Get([]byte(id))
+		if data == nil {
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		if err := json.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("解码任务数据失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// putRecord 在事务中保存任务状态
+func putRecord(tx *bolt.Tx, rec *Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(bucketJobs).Put([]byte(rec.Job.ID), data)
+}
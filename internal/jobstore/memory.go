@@ -0,0 +1,137 @@
+package jobstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/relaxcloud-cn/html2md/internal/model"
+)
+
+// MemoryStore 进程内任务存储，服务重启后任务丢失
+type MemoryStore struct {
+	quota quota
+
+	mu       sync.Mutex
+	records  map[string]Record
+	requests map[string][]byte
+	results  map[string][]byte
+}
+
+// NewMemoryStore 创建内存任务存储，maxSize为请求和结果的最大字节数，0表示不限制
+func NewMemoryStore(maxSize int64) *MemoryStore {
+	return &MemoryStore{
+		quota:    quota{max: maxSize},
+		records:  make(map[string]Record),
+		requests: make(map[string][]byte),
+		results:  make(map[string][]byte),
+	}
+}
+
+// Create 保存新提交的任务及其请求
+func (s *MemoryStore) Create(rec *Record, req *model.JobRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("编码任务请求失败: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.quota.reserve(int64(len(data))); err != nil {
+		return err
+	}
+	s.records[rec.Job.ID] = *rec
+	s.requests[rec.Job.ID] = data
+	return nil
+}
+
+// Update 更新任务状态
+func (s *MemoryStore) Update(rec *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[rec.Job.ID] = *rec
+	return nil
+}
+
+// Finish 保存结束的任务状态和结果，并删除请求
+func (s *MemoryStore) Finish(rec *Record, result *model.JobResult) error {
+	var data []byte
+	if result != nil {
+		var err error
+		if data, err = json.Marshal(result); err != nil {
+			return fmt.Errorf("编码任务结果失败: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := rec.Job.ID
+	if err := s.quota.reserve(int64(len(data) - len(s.requests[id]))); err != nil {
+		return err
+	}
+	s.records[id] = *rec
+	delete(s.requests, id)
+	if data != nil {
+		s.results[id] = data
+	}
+	return nil
+}
+
+// Request 读取未结束任务的请求
+func (s *MemoryStore) Request(id string) (*model.JobRequest, error) {
+	s.mu.Lock()
+	data, ok := s.requests[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	var req model.JobRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("解码任务请求失败: %w", err)
+	}
+	return &req, nil
+}
+
+// Result 读取成功任务的结果
+func (s *MemoryStore) Result(id string) (*model.JobResult, error) {
+	s.mu.Lock()
+	data, ok := s.results[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	var result model.JobResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("解码任务结果失败: %w", err)
+	}
+	return &result, nil
+}
+
+// Delete 删除任务的状态、请求和结果
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quota.release(int64(len(s.requests[id]) + len(s.results[id])))
+	delete(s.records, id)
+	delete(s.requests, id)
+	delete(s.results, id)
+	return nil
+}
+
+// List 读取全部任务状态
+func (s *MemoryStore) List() ([]*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := make([]*Record, 0, len(s.records))
+	for _, rec := range s.records {
+		records = append(records, &rec)
+	}
+	return records, nil
+}
+
+// Close 关闭存储
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,71 @@
+// Package jobstore 异步转换任务的持久化存储
+package jobstore
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/relaxcloud-cn/html2md/internal/model"
+)
+
+// 任务存储的错误
+var (
+	ErrNotFound = errors.New("任务数据不存在")
+	ErrFull     = errors.New("任务存储空间不足")
+)
+
+// Record 持久化的任务状态
+type Record struct {
+	Job   model.Job `json:"job"`
+	Owner string    `json:"owner,omitempty"` // 提交任务的API密钥名称，未启用认证时为空
+}
+
+// Store 任务存储
+//
+// 请求和结果按编码后的字节数计入容量限制，任务状态不计入；bolt存储还限制数据库文件已使用的大小。
+type Store interface {
+	// Create 保存新提交的任务及其请求，超出容量时返回 ErrFull
+	Create(rec *Record, req *model.JobRequest) error
+	// Update 更新任务状态
+	Update(rec *Record) error
+	// Finish 保存结束的任务状态并删除请求，result不为空时一并保存结果
+	//
+	// 结果超出容量时返回 ErrFull，不做任何修改。
+	Finish(rec *Record, result *model.JobResult) error
+	// Request 读取未结束任务的请求
+	Request(id string) (*model.JobRequest, error)
+	// Result 读取成功任务的结果
+	Result(id string) (*model.JobResult, error)
+	// Delete 删除任务的状态、请求和结果
+	Delete(id string) error
+	// List 读取全部任务状态，用于重启后恢复
+	List() ([]*Record, error)
+	// Close 关闭存储
+	Close() error
+}
+
+// quota 请求和结果占用的容量
+type quota struct {
+	mu   sync.Mutex
+	max  int64 // 0表示不限制
+	used int64
+}
+
+// reserve 占用n字节（可为负数），超出上限时返回 ErrFull 且不占用
+func (q *quota) reserve(n int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if n > 0 && q.max > 0 && q.used+n > q.max {
+		return fmt.Errorf("%w: 已使用 %d 字节，上限 %d 字节", ErrFull, q.used, q.max)
+	}
+	q.used += n
+	return nil
+}
+
+// release 释放n字节
+func (q *quota) release(n int64) {
+	q.mu.Lock()
+	q.used -= n
+	q.mu.Unlock()
+}
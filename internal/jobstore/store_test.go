@@ -0,0 +1,235 @@
+package jobstore
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/relaxcloud-cn/html2md/internal/model"
+)
+
+func newRecord(id, status string) *Record {
+	return &Record{Job: model.Job{ID: id, Type: model.JobConvert, Status: status, CreatedAt: time.Now()}, Owner: "ci"}
+}
+
+func newRequest(html string) *model.JobRequest {
+	return &model.JobRequest{Type: model.JobConvert, Convert: &model.ConvertRequest{HTML: html}}
+}
+
+func openBolt(t *testing.T, path string, maxSize int64) *BoltStore {
+	t.Helper()
+	s, err := OpenBoltStore(path, maxSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// dbSize 数据库已使用的字节数
+func dbSize(t *testing.T, s *BoltStore) int64 {
+	t.Helper()
+	var size int64
+	if err := s.db.View(func(tx *bolt.Tx) error {
+		size = tx.Size()
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return size
+}
+
+func TestStore(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(*testing.T) Store { return NewMemoryStore(0) },
+		"bolt":   func(t *testing.T) Store { return openBolt(t, filepath.Join(t.TempDir(), "jobs.db"), 0) },
+	}
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			s := open(t)
+			defer s.Close()
+
+			rec := newRecord("a", model.JobQueued)
+			if err := s.Create(rec, newRequest("<p>a</p>")); err != nil {
+				t.Fatal(err)
+			}
+			req, err := s.Request("a")
+			if err != nil || req.Convert.HTML != "<p>a</p>" {
+				t.Fatalf("Request() = %+v, %v", req, err)
+			}
+
+			rec.Job.Status = model.JobRunning
+			if err := s.Update(rec); err != nil {
+				t.Fatal(err)
+			}
+			rec.Job.Status = model.JobSucceeded
+			result := &model.JobResult{Convert: &model.ConvertResponse{Markdown: "a"}}
+			if err := s.Finish(rec, result); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.Request("a"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Request() after Finish error = %v, want ErrNotFound", err)
+			}
+			got, err := s.Result("a")
+			if err != nil || got.Convert.Markdown != "a" {
+				t.Errorf("Result() = %+v, %v", got, err)
+			}
+
+			records, err := s.List()
+			if err != nil || len(records) != 1 || records[0].Job.Status != model.JobSucceeded || records[0].Owner != "ci" {
+				t.Errorf("List() = %+v, %v", records, err)
+			}
+
+			if err := s.Delete("a"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.Result("a"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Result() after Delete error = %v, want ErrNotFound", err)
+			}
+			if records, _ := s.List(); len(records) != 0 {
+				t.Errorf("List() after Delete = %+v", records)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreQuota(t *testing.T) {
+	html := strings.Repeat("x", 100)
+	size := len(`{"type":"convert","convert":{"html":""}}`) + len(html)
+	s := NewMemoryStore(int64(2 * size))
+	for _, id := range []string{"a", "b"} {
+		if err := s.Create(newRecord(id, model.JobQueued), newRequest(html)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Create(newRecord("c", model.JobQueued), newRequest(html)); !errors.Is(err, ErrFull) {
+		t.Errorf("Create() over quota error = %v, want ErrFull", err)
+	}
+	if err := s.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Create(newRecord("c", model.JobQueued), newRequest(html)); err != nil {
+		t.Errorf("Create() after Delete error = %v", err)
+	}
+}
+
+func TestMemoryStoreFinishFull(t *testing.T) {
+	s := NewMemoryStore(200)
+	rec := newRecord("a", model.JobRunning)
+	if err := s.Create(rec, newRequest("<p>a</p>")); err != nil {
+		t.Fatal(err)
+	}
+	rec.Job.Status = model.JobSucceeded
+	big := &model.JobResult{Convert: &model.ConvertResponse{Markdown: strings.Repeat("x", 300)}}
+	if err := s.Finish(rec, big); !errors.Is(err, ErrFull) {
+		t.Fatalf("Finish() error = %v, want ErrFull", err)
+	}
+	// 超出容量时不做任何修改
+	if _, err := s.Request("a"); err != nil {
+		t.Errorf("Request() after failed Finish error = %v", err)
+	}
+	if err := s.Finish(rec, nil); err != nil {
+		t.Errorf("Finish() without result error = %v", err)
+	}
+}
+
+func TestBoltStoreReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	s := openBolt(t, path, 0)
+	if err := s.Create(newRecord("a", model.JobRunning), newRequest("<p>a</p>")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s = openBolt(t, path, 0)
+	defer s.Close()
+	records, err := s.List()
+	if err != nil || len(records) != 1 || records[0].Job.Status != model.JobRunning {
+		t.Fatalf("List() after reopen = %+v, %v", records, err)
+	}
+	if req, err := s.Request("a"); err != nil || req.Convert.HTML != "<p>a</p>" {
+		t.Errorf("Request() after reopen = %+v, %v", req, err)
+	}
+}
+
+func TestBoltStoreSizeLimit(t *testing.T) {
+	const maxSize = 256 * 1024
+	s := openBolt(t, filepath.Join(t.TempDir(), "jobs.db"), maxSize)
+	defer s.Close()
+
+	html := strings.Repeat("x", 16*1024)
+	var ids []string
+	for i := 0; ; i++ {
+		id := string(rune('a'+i%26)) + strings.Repeat("z", i/26)
+		err := s.Create(newRecord(id, model.JobQueued), newRequest(html))
+		if errors.Is(err, ErrFull) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+		if i > 100 {
+			t.Fatal("Create() never reported ErrFull")
+		}
+	}
+	if len(ids) == 0 {
+		t.Fatal("no job fits the store")
+	}
+	if size := dbSize(t, s); size > maxSize {
+		t.Errorf("database size = %d, limit %d", size, maxSize)
+	}
+
+	// 删除后释放的页面可以复用
+	for _, id := range ids {
+		if err := s.Delete(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range 3 {
+		if err := s.Create(newRecord(ids[i], model.JobQueued), newRequest(html)); err != nil {
+			t.Fatalf("Create() after Delete error = %v", err)
+		}
+	}
+	if size := dbSize(t, s); size > maxSize {
+		t.Errorf("database size after reuse = %d, limit %d", size, maxSize)
+	}
+}
+
+func TestBoltStoreCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.db")
+	s := openBolt(t, path, 0)
+	html := strings.Repeat("x", 64*1024)
+	for i := range 20 {
+		id := string(rune('a' + i))
+		if err := s.Create(newRecord(id, model.JobQueued), newRequest(html)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range 19 {
+		if err := s.Delete(string(rune('a' + i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := dbSize(t, s)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s = openBolt(t, path, 0)
+	defer s.Close()
+	if after := dbSize(t, s); after*2 > before {
+		t.Errorf("database size after reopen = %d, before %d, want compacted", after, before)
+	}
+	if req, err := s.Request("t"); err != nil || req.Convert.HTML != html {
+		t.Errorf("Request() after compaction error = %v", err)
+	}
+	if matches, _ := filepath.Glob(path + ".*"); len(matches) != 0 {
+		t.Errorf("temporary files left: %v", matches)
+	}
+}
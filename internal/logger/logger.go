@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// level 全局日志级别，支持运行时调整
+var level = new(slog.LevelVar)
+
+// Setup 初始化全局日志
+//
+// 标准库log包的输出同样会经过该处理器，以info级别记录。
+func Setup(format, output, file, lvl string) error {
+	var w io.Writer
+	switch output {
+	case "stderr":
+		w = os.Stderr
+	case "file":
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("打开日志文件失败: %w", err)
+		}
+		w = f
+	default:
+		w = os.Stdout
+	}
+
+	if err := SetLevel(lvl); err != nil {
+		return err
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if format == "text" {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	log.SetFlags(0)
+	return nil
+}
+
+// SetLevel 调整全局日志级别: debug, info, warn, error
+func SetLevel(lvl string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.ToUpper(lvl))); err != nil {
+		return fmt.Errorf("无效的日志级别: %s", lvl)
+	}
+	level.Set(l)
+	return nil
+}
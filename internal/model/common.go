@@ -24,6 +24,9 @@ const (
 	CodeUnauthorized   = 401
 	CodeForbidden      = 403
 	CodeNotFound       = 404
+	CodeConflict       = 409
+	CodeTooLarge       = 413
+	CodeUnsupported    = 415
 	CodeTooManyRequest = 429
 
 	// 服务端错误码
@@ -39,6 +42,8 @@ const (
 	MsgUnauthorized   = "未授权访问"
 	MsgForbidden      = "禁止访问"
 	MsgNotFound       = "资源不存在"
+	MsgConflict       = "资源状态冲突"
+	MsgTooLarge       = "请求超过单次限流配额"
 	MsgTooManyRequest = "请求过于频繁"
 	MsgInternalError  = "内部服务器错误"
 	MsgServiceError   = "服务暂时不可用"
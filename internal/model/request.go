@@ -1,8 +1,27 @@
 package model
 
+import (
+	"strings"
+
+	"github.com/relaxcloud-cn/html2md/pkg/chunk"
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+)
+
 // ConvertRequest HTML转Markdown请求参数
 type ConvertRequest struct {
-	HTML string `json:"html" binding:"required" example:"<h1>Hello World</h1>"` // HTML内容
+	HTML        string                  `json:"html" binding:"required_without=URL" example:"<h1>Hello World</h1>"` // HTML内容，与 url 二选一
+	URL         string                  `json:"url,omitempty" example:"https://example.com/post.html"`              // 抓取并转换该页面，与 html 二选一
+	BaseURL     string                  `json:"base_url,omitempty" example:"https://example.com/blog/"`             // 补全相对链接和图片地址的基准URL，使用 url 时默认为页面的最终地址
+	Rules       []converter.Rule        `json:"rules,omitempty"`                                                    // 自定义转换规则，优先于服务端配置的规则
+	Plugins     []string                `json:"plugins,omitempty" example:"table"`                                  // 在默认插件之外额外启用的插件，包括WebAssembly插件
+	Profiles    []string                `json:"profiles,omitempty" example:"email"`                                 // 启用的预处理配置，如 email
+	Email       *converter.EmailOptions `json:"email,omitempty"`                                                    // email预处理配置的选项，设置时自动启用该配置
+	InferStyles bool                    `json:"infer_styles,omitempty"`                                             // 按内联样式推断加粗、斜体、代码和标题，并删除隐藏元素
+	Chunking    *chunk.Options          `json:"chunking,omitempty"`                                                 // 按标题层级将结果切分为块
+	Tokenizer   string                  `json:"tokenizer,omitempty" example:"cl100k_base"`                          // 计算token数的分词器，为空时使用默认分词器
+	MaxTokens   int                     `json:"max_tokens,omitempty" example:"4000"`                                // 结果的最大token数，超出时在块边界截断，为0时不限制
+	TOC         string                  `json:"toc,omitempty" example:"placeholder"`                                // 插入目录的位置: top, placeholder（替换 [TOC] 占位行），为空时不插入
+	Inventory   bool                    `json:"inventory,omitempty"`                                                // 在响应中返回链接和图片清单
 }
 
 // HealthRequest 健康检查请求
@@ -12,5 +31,64 @@ type HealthRequest struct {
 
 // BatchConvertRequest 批量转换请求
 type BatchConvertRequest struct {
-	Items []ConvertRequest `json:"items" binding:"required,min=1,max=100"` // 批量转换项目，最多100个
+	Items []ConvertRequest `json:"items" binding:"required,min=1"` // 批量转换项目，数量上限由converter.max_batch_size配置
+}
+
+// JobRequest 异步转换任务请求，按任务类型填写对应的参数
+type JobRequest struct {
+	Type    string               `json:"type" binding:"required,oneof=convert batch site epub" example:"batch"` // 任务类型: convert, batch, site（整站压缩包）, epub
+	Convert *ConvertRequest      `json:"convert,omitempty"`                                                     // convert任务的转换参数
+	Batch   *BatchConvertRequest `json:"batch,omitempty"`                                                       // batch任务的批量转换参数
+	Archive []byte               `json:"archive,omitempty" swaggertype:"string" format:"base64"`                // site任务的zip压缩包或epub任务的EPUB文件，base64编码
+	Split   bool                 `json:"split,omitempty"`                                                       // epub任务每章输出一个Markdown文件，结果为zip压缩包
+	Webhook string               `json:"webhook,omitempty" example:"https://example.com/hooks/html2md"`         // 任务成功或失败后回调的地址，POST任务状态
+}
+
+// ConvertQuery 原始HTML和文件上传转换的查询参数，含义与 ConvertRequest 的同名字段相同
+//
+// 自定义规则、分块等复杂选项只能通过JSON接口设置。
+type ConvertQuery struct {
+	Plugins     []string `form:"plugins" example:"table"`         // 额外启用的插件，可重复或以逗号分隔
+	Profiles    []string `form:"profiles" example:"email"`        // 启用的预处理配置，可重复或以逗号分隔
+	InferStyles bool     `form:"infer_styles"`                    // 按内联样式推断格式
+	Tokenizer   string   `form:"tokenizer" example:"cl100k_base"` // 计算token数的分词器
+	MaxTokens   int      `form:"max_tokens" example:"4000"`       // 结果的最大token数
+	TOC         string   `form:"toc" example:"placeholder"`       // 插入目录的位置: top, placeholder
+	Inventory   bool     `form:"inventory"`                       // 在响应中返回链接和图片清单
+}
+
+// Request 使用查询参数和HTML内容构造转换请求
+func (q *ConvertQuery) Request(html string) ConvertRequest {
+	return ConvertRequest{
+		HTML:        html,
+		Plugins:     splitList(q.Plugins),
+		Profiles:    splitList(q.Profiles),
+		InferStyles: q.InferStyles,
+		Tokenizer:   q.Tokenizer,
+		MaxTokens:   q.MaxTokens,
+		TOC:         q.TOC,
+		Inventory:   q.Inventory,
+	}
+}
+
+// splitList 展开以逗号分隔的列表参数并去掉空项
+func splitList(values []string) []string {
+	var list []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// EmailConvertQuery 邮件转换的查询参数
+type EmailConvertQuery struct {
+	FrontMatter    bool   `form:"front_matter"`                                                        // 输出由邮件头组成的YAML front matter
+	InlineImages   bool   `form:"inline_images"`                                                       // 将引用的图片内嵌为data URI
+	Output         string `form:"output" binding:"omitempty,oneof=json zip" example:"json"`            // 输出方式: json（默认）, zip
+	Quotes         string `form:"quotes" binding:"omitempty,oneof=keep collapse strip" example:"keep"` // 引用历史: keep（默认）, collapse, strip
+	StripSignature bool   `form:"strip_signature"`                                                     // 删除签名
 }
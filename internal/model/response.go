@@ -1,18 +1,94 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"github.com/relaxcloud-cn/html2md/pkg/chunk"
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+	"github.com/relaxcloud-cn/html2md/pkg/email"
+	"github.com/relaxcloud-cn/html2md/pkg/epub"
+)
 
 // ConvertResponse HTML转Markdown响应数据
 type ConvertResponse struct {
-	Markdown string           `json:"markdown" example:"# Hello World"` // 转换后的Markdown内容
-	Stats    *ConversionStats `json:"stats,omitempty"`                  // 转换统计信息
+	Markdown  string               `json:"markdown" example:"# Hello World"` // 转换后的Markdown内容
+	Stats     *ConversionStats     `json:"stats,omitempty"`                  // 转换统计信息
+	Chunks    []chunk.Chunk        `json:"chunks,omitempty"`                 // 按请求的分块选项切分的块
+	Outline   []chunk.Heading      `json:"outline,omitempty"`                // 标题大纲，偏移相对于返回的Markdown
+	Inventory *converter.Inventory `json:"inventory,omitempty"`              // 链接和图片清单，请求设置 inventory 时返回
+}
+
+// EPUBResponse EPUB合并转换响应数据
+type EPUBResponse struct {
+	Title    string         `json:"title" example:"培训手册"`      // 书名
+	Markdown string         `json:"markdown" example:"# 培训手册"` // 附带目录的合并Markdown文档
+	Chapters []epub.Chapter `json:"chapters"`                  // 按阅读顺序排列的章节
+}
+
+// EmailResponse 邮件转换响应数据
+type EmailResponse struct {
+	Header   email.Header `json:"header"`                  // 邮件头
+	Markdown string       `json:"markdown" example:"# 周报"` // 转换后的Markdown内容
 }
 
 // ConversionStats 转换统计信息
 type ConversionStats struct {
-	InputSize      int           `json:"input_size" example:"1024"`                            // 输入HTML大小（字节）
-	OutputSize     int           `json:"output_size" example:"512"`                            // 输出Markdown大小（字节）
-	ProcessingTime time.Duration `json:"processing_time" swaggertype:"string" example:"100ms"` // 处理时间
+	InputSize       int           `json:"input_size" example:"1024"`                            // 输入HTML大小（字节）
+	OutputSize      int           `json:"output_size" example:"512"`                            // 输出Markdown大小（字节）
+	ProcessingTime  time.Duration `json:"processing_time" swaggertype:"string" example:"100ms"` // 处理时间
+	Tokens          int           `json:"tokens" example:"180"`                                 // 输出Markdown的token数
+	Tokenizer       string        `json:"tokenizer" example:"cl100k_base"`                      // 计算token数的分词器
+	Truncated       bool          `json:"truncated,omitempty"`                                  // 是否因 max_tokens 被截断
+	TruncatedBytes  int           `json:"truncated_bytes,omitempty" example:"2048"`             // 截去的字节数
+	TruncatedTokens int           `json:"truncated_tokens,omitempty" example:"600"`             // 截去部分的token数
+	Links           int           `json:"links" example:"12"`                                   // 链接数
+	Images          int           `json:"images" example:"3"`                                   // 图片数
+
+	chunk.Analysis // 单词数、字符数、阅读时间、块的数量和语言
+}
+
+// 异步任务类型
+const (
+	JobConvert = "convert" // 单个转换
+	JobBatch   = "batch"   // 批量转换
+	JobSite    = "site"    // 整站压缩包转换
+	JobEPUB    = "epub"    // EPUB电子书转换
+)
+
+// 异步任务状态
+const (
+	JobQueued    = "queued"    // 排队中
+	JobRunning   = "running"   // 执行中
+	JobSucceeded = "succeeded" // 成功，可获取结果
+	JobFailed    = "failed"    // 失败
+	JobCanceled  = "canceled"  // 已取消
+)
+
+// Job 异步转换任务的状态
+type Job struct {
+	ID         string      `json:"id" example:"9f86d081884c7d659a2feaa0c55ad015"` // 任务ID
+	Type       string      `json:"type" example:"batch"`                          // 任务类型
+	Status     string      `json:"status" example:"running"`                      // 任务状态: queued, running, succeeded, failed, canceled
+	Progress   JobProgress `json:"progress"`                                      // 执行进度
+	Error      string      `json:"error,omitempty" example:"转换失败"`                // 失败原因
+	CreatedAt  time.Time   `json:"created_at" example:"2023-12-01T12:00:00Z"`     // 提交时间
+	StartedAt  *time.Time  `json:"started_at,omitempty"`                          // 开始执行时间
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`                         // 结束时间
+	ExpiresAt  *time.Time  `json:"expires_at,omitempty"`                          // 状态和结果的保留期限，任务结束后设置
+}
+
+// JobProgress 异步任务的执行进度
+type JobProgress struct {
+	Done  int `json:"done" example:"40"`   // 已处理的项目、页面或章节数
+	Total int `json:"total" example:"100"` // 总数，EPUB任务在解析完章节前为0
+}
+
+// JobResult 异步任务的结果，按任务类型填写其中一项
+type JobResult struct {
+	Convert *ConvertResponse      `json:"convert,omitempty"` // convert任务的结果
+	Batch   *BatchConvertResponse `json:"batch,omitempty"`   // batch任务的结果
+	EPUB    *EPUBResponse         `json:"epub,omitempty"`    // epub任务的结果
+	Archive []byte                `json:"archive,omitempty"` // site任务和分章输出的epub任务的zip压缩包
 }
 
 // HealthResponse 健康检查响应数据
@@ -40,10 +116,11 @@ type BatchConvertResponse struct {
 
 // BatchConvertItem 批量转换项目结果
 type BatchConvertItem struct {
-	Index   int              `json:"index" example:"0"`              // 项目索引
-	Success bool             `json:"success" example:"true"`         // 是否成功
-	Result  *ConvertResponse `json:"result,omitempty"`               // 转换结果（成功时）
-	Error   string           `json:"error,omitempty" example:"转换失败"` // 错误信息（失败时）
+	Index    int              `json:"index" example:"0"`                       // 项目索引
+	Filename string           `json:"filename,omitempty" example:"index.html"` // 上传的文件名，仅文件上传转换时返回
+	Success  bool             `json:"success" example:"true"`                  // 是否成功
+	Result   *ConvertResponse `json:"result,omitempty"`                        // 转换结果（成功时）
+	Error    string           `json:"error,omitempty" example:"转换失败"`          // 错误信息（失败时）
 }
 
 // BatchSummary 批量转换摘要
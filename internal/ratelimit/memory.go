@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// memoryCleanupInterval 清理空闲令牌桶的间隔
+const memoryCleanupInterval = time.Minute
+
+// memoryBucket 内存令牌桶状态
+type memoryBucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // 令牌补满的时间，之后可安全删除
+}
+
+// MemoryStore 进程内令牌桶存储，适用于单实例部署
+type MemoryStore struct {
+	mu          sync.Mutex
+	buckets     map[string]*memoryBucket
+	lastCleanup time.Time
+}
+
+// NewMemoryStore 创建内存令牌桶存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:     make(map[string]*memoryBucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Take 原子地从所有令牌桶中扣减令牌
+func (s *MemoryStore) Take(_ context.Context, buckets []Bucket) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.cleanup(now)
+
+	tokens := make([]float64, len(buckets))
+	var wait float64
+	for i, b := range buckets {
+		tokens[i] = b.Burst
+		if state, ok := s.buckets[b.Key]; ok {
+			elapsed := now.Sub(state.last).Seconds()
+			tokens[i] = math.Min(b.Burst, state.tokens+elapsed*b.Rate)
+		}
+		if tokens[i] < b.Cost {
+			wait = math.Max(wait, (b.Cost-tokens[i])/b.Rate)
+		}
+	}
+	if wait > 0 {
+		return time.Duration(wait * float64(time.Second)), nil
+	}
+
+	for i, b := range buckets {
+		remaining := tokens[i] - b.Cost
+		refill := time.Duration((b.Burst - remaining) / b.Rate * float64(time.Second))
+		s.buckets[b.Key] = &memoryBucket{
+			tokens: remaining,
+			last:   now,
+			full:   now.Add(refill),
+		}
+	}
+	return 0, nil
+}
+
+// cleanup 定期删除已补满的令牌桶，避免客户端数量增长导致内存泄漏
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Sub(s.lastCleanup) < memoryCleanupInterval {
+		return
+	}
+	s.lastCleanup = now
+	for key, state := range s.buckets {
+		if now.After(state.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/relaxcloud-cn/html2md/internal/auth"
+	"github.com/relaxcloud-cn/html2md/internal/config"
+)
+
+// Bucket 一次扣减中的单个令牌桶
+type Bucket struct {
+	Key   string  // 令牌桶标识，格式为 {客户端}:配额名称，花括号部分是Redis Cluster的哈希标签，同一客户端的令牌桶位于同一槽位
+	Rate  float64 // 每秒补充的令牌数
+	Burst float64 // 桶容量
+	Cost  float64 // 本次消耗的令牌数
+}
+
+// Store 令牌桶存储
+type Store interface {
+	// Take 原子地从所有令牌桶中扣减令牌
+	//
+	// 任一令牌桶不足时不扣减任何令牌，返回需要等待的时间；全部满足时返回0。
+	Take(ctx context.Context, buckets []Bucket) (time.Duration, error)
+}
+
+// Cost 一次调用消耗的配额
+type Cost struct {
+	Requests   int // 单个转换请求数
+	BatchItems int // 批量转换项目数
+	InputBytes int // 输入HTML字节数
+}
+
+// ErrCostTooLarge 单次请求消耗的配额超过令牌桶容量，等待也无法通过
+var ErrCostTooLarge = errors.New("单次请求超过限流配额的突发容量")
+
+// LimitError 配额不足，Wait为建议的重试等待时间
+type LimitError struct {
+	Wait time.Duration
+}
+
+// Error 实现error接口
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("请求过于频繁，请在%d秒后重试", RetryAfterSeconds(e.Wait))
+}
+
+// Limiter 按客户端限流，HTTP和GRPC共用
+type Limiter struct {
+	config *config.Manager
+	store  Store
+}
+
+// New 创建限流器，配额从当前配置读取，支持热加载
+func New(cfg *config.Manager, store Store) *Limiter {
+	return &Limiter{
+		config: cfg,
+		store:  store,
+	}
+}
+
+// Allow 检查客户端是否还有足够配额并扣减
+//
+// client 为API密钥名称或客户端IP。配额不足时返回*LimitError，
+// 单项消耗超过桶容量时返回ErrCostTooLarge且不扣减任何配额。
+// 存储后端出错时放行请求，避免限流故障导致服务不可用。
+func (l *Limiter) Allow(ctx context.Context, client string, cost Cost) error {
+	cfg := l.config.Get().RateLimit
+	if !cfg.Enabled {
+		return nil
+	}
+
+	var buckets []Bucket
+	var tooLarge bool
+	add := func(name string, budget config.RateBudget, n int) {
+		if budget.PerMinute <= 0 || n <= 0 {
+			return
+		}
+		burst := budget.Burst
+		if burst <= 0 {
+			burst = budget.PerMinute
+		}
+		if n > burst {
+			tooLarge = true
+			return
+		}
+		buckets = append(buckets, Bucket{
+			Key:   "{" + client + "}:" + name,
+			Rate:  float64(budget.PerMinute) / 60,
+			Burst: float64(burst),
+			Cost:  float64(n),
+		})
+	}
+	add("requests", cfg.Requests, cost.Requests)
+	add("batch_items", cfg.BatchItems, cost.BatchItems)
+	add("input_bytes", cfg.InputBytes, cost.InputBytes)
+
+	if tooLarge {
+		return ErrCostTooLarge
+	}
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	wait, err := l.store.Take(ctx, buckets)
+	if err != nil {
+		log.Printf("限流存储错误，放行客户端 %s 的请求: %v", client, err)
+		return nil
+	}
+	if wait > 0 {
+		return &LimitError{Wait: wait}
+	}
+	return nil
+}
+
+// Meter 绑定到调用方的限流器，用于扣减处理过程中才能确定的配额，如按URL抓取的页面字节数
+type Meter struct {
+	limiter *Limiter
+	client  string
+}
+
+// Meter 创建绑定到调用方的计量器
+func (l *Limiter) Meter(client string) *Meter {
+	return &Meter{limiter: l, client: client}
+}
+
+// Charge 扣减配额，返回值同Limiter.Allow，nil计量器不限流
+func (m *Meter) Charge(ctx context.Context, cost Cost) error {
+	if m == nil {
+		return nil
+	}
+	return m.limiter.Allow(ctx, m.client, cost)
+}
+
+type meterKey struct{}
+
+// WithMeter 将计量器保存到上下文
+func WithMeter(ctx context.Context, m *Meter) context.Context {
+	return context.WithValue(ctx, meterKey{}, m)
+}
+
+// MeterFromContext 从上下文获取计量器，未设置时返回nil
+func MeterFromContext(ctx context.Context) *Meter {
+	m, _ := ctx.Value(meterKey{}).(*Meter)
+	return m
+}
+
+// Charge 使用上下文中的计量器扣减配额，未设置计量器时不限流
+func Charge(ctx context.Context, cost Cost) error {
+	return MeterFromContext(ctx).Charge(ctx, cost)
+}
+
+// RetryAfterSeconds 将等待时间转换为Retry-After秒数，至少为1秒
+func RetryAfterSeconds(wait time.Duration) int {
+	return max(1, int(math.Ceil(wait.Seconds())))
+}
+
+// ClientID 限流的客户端标识，已认证的请求按API密钥计算，否则按客户端IP计算
+func ClientID(ctx context.Context, ip string) string {
+	if principal := auth.FromContext(ctx); principal != nil {
+		return "key:" + principal.Name
+	}
+	return "ip:" + ip
+}
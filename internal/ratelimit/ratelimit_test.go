@@ -0,0 +1,181 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relaxcloud-cn/html2md/internal/auth"
+	"github.com/relaxcloud-cn/html2md/internal/config"
+)
+
+// newTestLimiter 创建使用内存存储的限流器，配额为每分钟60个请求、10个批量项目、1000字节，突发容量与每分钟配额相同
+func newTestLimiter(t *testing.T) *Limiter {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `rate_limit:
+  enabled: true
+  requests:
+    per_minute: 60
+  batch_items:
+    per_minute: 10
+  input_bytes:
+    per_minute: 1000
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.NewManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(cfg, NewMemoryStore())
+}
+
+func TestLimiterAllow(t *testing.T) {
+	tests := []struct {
+		name  string
+		costs []Cost
+		want  []error // nil表示放行，errLimited表示配额不足
+	}{
+		{
+			name:  "within budget",
+			costs: []Cost{{Requests: 1, InputBytes: 400}, {Requests: 1, InputBytes: 600}},
+			want:  []error{nil, nil},
+		},
+		{
+			name:  "bytes exhausted",
+			costs: []Cost{{Requests: 1, InputBytes: 800}, {Requests: 1, InputBytes: 300}},
+			want:  []error{nil, errLimited},
+		},
+		{
+			name:  "batch items exhausted",
+			costs: []Cost{{BatchItems: 10}, {BatchItems: 1}},
+			want:  []error{nil, errLimited},
+		},
+		{
+			name:  "batch larger than burst",
+			costs: []Cost{{BatchItems: 11}, {BatchItems: 10}},
+			want:  []error{ErrCostTooLarge, nil},
+		},
+		{
+			name:  "input larger than burst",
+			costs: []Cost{{Requests: 1, InputBytes: 1001}, {Requests: 1, InputBytes: 1000}},
+			want:  []error{ErrCostTooLarge, nil},
+		},
+		{
+			name:  "zero cost",
+			costs: []Cost{{}, {}},
+			want:  []error{nil, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLimiter(t)
+			for i, cost := range tt.costs {
+				assertLimit(t, i, l.Allow(context.Background(), "ip:1.2.3.4", cost), tt.want[i])
+			}
+		})
+	}
+}
+
+// errLimited 测试中表示期望返回*LimitError
+var errLimited = errors.New("limited")
+
+func assertLimit(t *testing.T, step int, err, want error) {
+	t.Helper()
+	var limited *LimitError
+	switch {
+	case want == errLimited:
+		if !errors.As(err, &limited) || limited.Wait <= 0 {
+			t.Fatalf("step %d: err = %v, want *LimitError", step, err)
+		}
+	case !errors.Is(err, want):
+		t.Fatalf("step %d: err = %v, want %v", step, err, want)
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	cfg, err := config.NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := New(cfg, NewMemoryStore())
+	if err := l.Allow(context.Background(), "ip:1.2.3.4", Cost{BatchItems: 1 << 30}); err != nil {
+		t.Errorf("disabled limiter returned %v", err)
+	}
+}
+
+// failingStore 总是出错的令牌桶存储
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, []Bucket) (time.Duration, error) {
+	return 0, errors.New("CROSSSLOT Keys in request don't hash to the same slot")
+}
+
+func TestLimiterStoreError(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	// 存储出错时放行请求并记录日志
+	l := New(newTestLimiter(t).config, failingStore{})
+	if err := l.Allow(context.Background(), "key:ci", Cost{Requests: 1}); err != nil {
+		t.Errorf("Allow() error = %v, want nil", err)
+	}
+	if !strings.Contains(buf.String(), "key:ci") || !strings.Contains(buf.String(), "CROSSSLOT") {
+		t.Errorf("log = %q", buf.String())
+	}
+}
+
+func TestMeterCharge(t *testing.T) {
+	l := newTestLimiter(t)
+	ctx := WithMeter(context.Background(), l.Meter("key:ci"))
+
+	// 抓取后补扣的字节数与请求体字节数共用同一令牌桶
+	assertLimit(t, 0, l.Allow(ctx, "key:ci", Cost{Requests: 1, InputBytes: 500}), nil)
+	assertLimit(t, 1, Charge(ctx, Cost{InputBytes: 400}), nil)
+	assertLimit(t, 2, Charge(ctx, Cost{InputBytes: 400}), errLimited)
+	assertLimit(t, 3, Charge(ctx, Cost{InputBytes: 5000}), ErrCostTooLarge)
+
+	// 其他调用方不受影响
+	assertLimit(t, 4, l.Meter("key:other").Charge(ctx, Cost{InputBytes: 1000}), nil)
+
+	// 未设置计量器时不限流
+	assertLimit(t, 5, Charge(context.Background(), Cost{InputBytes: 1 << 30}), nil)
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	tests := []struct {
+		wait time.Duration
+		want int
+	}{
+		{0, 1},
+		{100 * time.Millisecond, 1},
+		{time.Second, 1},
+		{1500 * time.Millisecond, 2},
+		{time.Minute, 60},
+	}
+	for _, tt := range tests {
+		if got := RetryAfterSeconds(tt.wait); got != tt.want {
+			t.Errorf("RetryAfterSeconds(%v) = %d, want %d", tt.wait, got, tt.want)
+		}
+	}
+}
+
+func TestClientID(t *testing.T) {
+	if got := ClientID(context.Background(), "10.0.0.1"); got != "ip:10.0.0.1" {
+		t.Errorf("ClientID = %q, want ip:10.0.0.1", got)
+	}
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "ci"})
+	if got := ClientID(ctx, "10.0.0.1"); got != "key:ci" {
+		t.Errorf("ClientID = %q, want key:ci", got)
+	}
+}
@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix Redis中令牌桶键的前缀
+const redisKeyPrefix = "html2md:ratelimit:"
+
+// takeScript 在Redis中原子地检查并扣减多个令牌桶
+//
+// KEYS: 令牌桶键，带有相同的哈希标签以便在Redis Cluster中执行；ARGV: 每个令牌桶依次为 rate, burst, cost。
+// 使用Redis服务器时间，避免多个实例之间的时钟偏差。
+// 返回需要等待的秒数（字符串），"0"表示已扣减成功。
+var takeScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) + tonumber(t[2]) / 1000000
+local tokens = {}
+local wait = 0
+for i = 1, #KEYS do
+  local rate = tonumber(ARGV[i * 3 - 2])
+  local burst = tonumber(ARGV[i * 3 - 1])
+  local cost = tonumber(ARGV[i * 3])
+  local state = redis.call('HMGET', KEYS[i], 'tokens', 'ts')
+  local current = tonumber(state[1])
+  local ts = tonumber(state[2])
+  if current == nil or ts == nil then
+    current = burst
+  else
+    current = math.min(burst, current + math.max(0, now - ts) * rate)
+  end
+  tokens[i] = current
+  if current < cost then
+    wait = math.max(wait, (cost - current) / rate)
+  end
+end
+if wait > 0 then
+  return tostring(wait)
+end
+for i = 1, #KEYS do
+  local rate = tonumber(ARGV[i * 3 - 2])
+  local burst = tonumber(ARGV[i * 3 - 1])
+  local cost = tonumber(ARGV[i * 3])
+  local remaining = tokens[i] - cost
+  redis.call('HSET', KEYS[i], 'tokens', tostring(remaining), 'ts', tostring(now))
+  redis.call('PEXPIRE', KEYS[i], math.ceil((burst - remaining) / rate * 1000) + 1000)
+end
+return "0"
+`)
+
+// RedisStore 基于Redis的令牌桶存储，多个服务实例共享配额
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+// NewRedisStore 创建Redis令牌桶存储
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Take 原子地从所有令牌桶中扣减令牌
+func (s *RedisStore) Take(ctx context.Context, buckets []Bucket) (time.Duration, error) {
+	keys := make([]string, len(buckets))
+	args := make([]interface{}, 0, len(buckets)*3)
+	for i, b := range buckets {
+		keys[i] = redisKeyPrefix + b.Key
+		args = append(args, b.Rate, b.Burst, b.Cost)
+	}
+
+	result, err := takeScript.Run(ctx, s.client, keys, args...).Text()
+	if err != nil {
+		return 0, fmt.Errorf("执行限流脚本失败: %w", err)
+	}
+
+	wait, err := strconv.ParseFloat(result, 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析限流结果失败: %w", err)
+	}
+	return time.Duration(wait * float64(time.Second)), nil
+}
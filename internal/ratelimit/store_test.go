@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// testStores 返回待测试的令牌桶存储，Redis使用进程内的miniredis
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return map[string]Store{
+		"memory": NewMemoryStore(),
+		"redis":  NewRedisStore(client),
+	}
+}
+
+func TestStoreTake(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			// 每秒补充1个令牌，容量3
+			bucket := func(key string, cost float64) Bucket {
+				return Bucket{Key: key, Rate: 1, Burst: 3, Cost: cost}
+			}
+
+			steps := []struct {
+				buckets  []Bucket
+				wantWait bool
+			}{
+				{[]Bucket{bucket("a", 2)}, false},
+				{[]Bucket{bucket("a", 1)}, false},
+				{[]Bucket{bucket("a", 1)}, true},
+				// 其他客户端的令牌桶互不影响
+				{[]Bucket{bucket("b", 3)}, false},
+				// 任一令牌桶不足时不扣减其他令牌桶
+				{[]Bucket{bucket("c", 1), bucket("a", 1)}, true},
+				{[]Bucket{bucket("c", 3)}, false},
+			}
+
+			for i, step := range steps {
+				wait, err := store.Take(ctx, step.buckets)
+				if err != nil {
+					t.Fatalf("step %d: Take: %v", i, err)
+				}
+				if (wait > 0) != step.wantWait {
+					t.Fatalf("step %d: wait = %v, wantWait %v", i, wait, step.wantWait)
+				}
+				if wait > 0 && wait > 2*time.Second {
+					t.Errorf("step %d: wait = %v, want about 1s", i, wait)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStoreCleanup(t *testing.T) {
+	s := NewMemoryStore()
+	if _, err := s.Take(context.Background(), []Bucket{{Key: "a", Rate: 1000, Burst: 1, Cost: 1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 令牌补满后的令牌桶在下次清理时删除
+	s.cleanup(time.Now().Add(2 * memoryCleanupInterval))
+	if len(s.buckets) != 0 {
+		t.Errorf("buckets = %d after cleanup, want 0", len(s.buckets))
+	}
+}
+
+func TestRedisStoreKeys(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	l := New(newTestLimiter(t).config, NewRedisStore(client))
+	if err := l.Allow(context.Background(), "key:ci", Cost{Requests: 1, BatchItems: 1, InputBytes: 10}); err != nil {
+		t.Fatal(err)
+	}
+	// 同一客户端的令牌桶使用相同的哈希标签，Redis Cluster中不会返回CROSSSLOT
+	want := []string{
+		"html2md:ratelimit:{key:ci}:batch_items",
+		"html2md:ratelimit:{key:ci}:input_bytes",
+		"html2md:ratelimit:{key:ci}:requests",
+	}
+	if keys := mr.Keys(); !slices.Equal(keys, want) {
+		t.Errorf("keys = %q, want %q", keys, want)
+	}
+}
@@ -1,35 +1,402 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
 	"runtime"
+	"slices"
+	"sync/atomic"
 	"time"
 
+	"github.com/relaxcloud-cn/html2md/internal/config"
 	"github.com/relaxcloud-cn/html2md/internal/model"
+	"github.com/relaxcloud-cn/html2md/internal/ratelimit"
+	"github.com/relaxcloud-cn/html2md/pkg/chunk"
 	"github.com/relaxcloud-cn/html2md/pkg/converter"
+	"github.com/relaxcloud-cn/html2md/pkg/email"
+	"github.com/relaxcloud-cn/html2md/pkg/epub"
+	"github.com/relaxcloud-cn/html2md/pkg/fetch"
+	"github.com/relaxcloud-cn/html2md/pkg/tokenizer"
 )
 
+// 请求参数超出限制时返回的错误，调用方据此返回4xx而非5xx
+var (
+	ErrInputTooLarge    = converter.ErrInputTooLarge
+	ErrBatchTooLarge    = errors.New("批量转换数量超过限制")
+	ErrInvalidMaxTokens = errors.New("max_tokens 不能为负数")
+	ErrInvalidSource    = errors.New("html 和 url 只能设置一个")
+	ErrFetchDisabled    = errors.New("未启用按URL抓取页面")
+	ErrFetchFailed      = fetch.ErrFetch
+)
+
+// IsBadRequest 判断错误是否由请求参数引起
+func IsBadRequest(err error) bool {
+	return errors.Is(err, ErrInputTooLarge) || errors.Is(err, ErrBatchTooLarge) ||
+		errors.Is(err, ErrInvalidArchive) || errors.Is(err, epub.ErrInvalidEPUB) ||
+		errors.Is(err, email.ErrInvalidMessage) || errors.Is(err, email.ErrNoContent) ||
+		errors.Is(err, converter.ErrInvalidHTML) || errors.Is(err, converter.ErrInvalidRule) ||
+		errors.Is(err, converter.ErrInvalidPlugin) || errors.Is(err, converter.ErrInvalidProfile) ||
+		errors.Is(err, chunk.ErrInvalidOptions) || errors.Is(err, tokenizer.ErrUnknownTokenizer) ||
+		errors.Is(err, ErrInvalidMaxTokens) || errors.Is(err, chunk.ErrInvalidTOC) ||
+		errors.Is(err, ErrInvalidJob) || errors.Is(err, ErrInvalidCharset) ||
+		errors.Is(err, ErrInvalidSource) || errors.Is(err, ErrFetchDisabled) ||
+		errors.Is(err, fetch.ErrInvalidURL) || errors.Is(err, fetch.ErrBlocked) ||
+		errors.Is(err, fetch.ErrTooLarge) || errors.Is(err, fetch.ErrUnsupported)
+}
+
 // ConvertService 转换服务
 type ConvertService struct {
-	converter *converter.Converter
+	config    *config.Manager
+	converter atomic.Pointer[converter.Converter]
+	plugins   atomic.Pointer[converter.PluginHost]
+	fetcher   atomic.Pointer[fetch.Fetcher]
 	startTime time.Time
 }
 
 // NewConvertService 创建转换服务
-func NewConvertService() *ConvertService {
-	return &ConvertService{
-		converter: converter.New(),
+func NewConvertService(cfg *config.Manager) *ConvertService {
+	s := &ConvertService{
+		config:    cfg,
 		startTime: time.Now(),
 	}
+
+	s.plugins.Store(newPluginHost(cfg.Get()))
+	s.converter.Store(newConverter(cfg.Get(), s.plugins.Load()))
+	s.fetcher.Store(newFetcher(cfg.Get()))
+	cfg.OnReload(func(prev, next *config.Config) {
+		s.fetcher.Store(newFetcher(next))
+		if reflect.DeepEqual(prev.Converter.WasmPlugins, next.Converter.WasmPlugins) {
+			s.converter.Store(newConverter(next, s.plugins.Load()))
+			return
+		}
+		host := newPluginHost(next)
+		s.converter.Store(newConverter(next, host))
+		// 旧插件在进行中的调用结束后释放
+		if err := s.plugins.Swap(host).Close(); err != nil {
+			log.Printf("释放WebAssembly插件失败: %v", err)
+		}
+	})
+
+	return s
+}
+
+// newPluginHost 加载配置的WebAssembly插件，加载失败时不启用任何插件
+func newPluginHost(cfg *config.Config) *converter.PluginHost {
+	if len(cfg.Converter.WasmPlugins) == 0 {
+		return nil
+	}
+	host, err := converter.NewPluginHost(context.Background(), cfg.Converter.WasmPlugins)
+	if err != nil {
+		log.Printf("加载WebAssembly插件失败，不启用插件: %v", err)
+		return nil
+	}
+	log.Printf("已加载WebAssembly插件: %v", host.Names())
+	return host
 }
 
-// Convert 转换HTML为Markdown
-func (s *ConvertService) Convert(req *model.ConvertRequest) (*model.ConvertResponse, error) {
-	return s.converter.Convert(req)
+// newFetcher 按配置创建页面抓取器，未启用抓取时返回nil
+func newFetcher(cfg *config.Config) *fetch.Fetcher {
+	if !cfg.Fetch.Enabled {
+		return nil
+	}
+	// 配置已通过校验，地址段可以正常解析
+	allow, _ := cfg.Fetch.AllowedPrefixes()
+	return fetch.New(fetch.Options{
+		Timeout:      cfg.Fetch.Timeout,
+		MaxSize:      int64(cfg.Fetch.MaxSize),
+		MaxRedirects: cfg.Fetch.MaxRedirects,
+		UserAgent:    cfg.Fetch.UserAgent,
+		Allow:        allow,
+	})
+}
+
+// newConverter 按配置创建转换器，插件或规则配置无效时回退到默认插件
+func newConverter(cfg *config.Config, host *converter.PluginHost) *converter.Converter {
+	conv, err := converter.New(
+		converter.WithPlugins(cfg.Converter.DefaultPlugins...),
+		converter.WithPluginHost(host),
+		converter.WithRules(cfg.Converter.Rules...),
+	)
+	if err != nil {
+		log.Printf("转换器插件配置无效，使用默认插件: %v", err)
+		conv, _ = converter.New()
+	}
+	return conv
+}
+
+// Convert 转换HTML为Markdown，请求使用 url 时先抓取页面
+func (s *ConvertService) Convert(ctx context.Context, req *model.ConvertRequest) (*model.ConvertResponse, error) {
+	conv, err := s.prepare(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL != "" {
+		if conv, err = s.fetchSource(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+	result, err := conv.ConvertString(req.HTML)
+	if err != nil {
+		return nil, err
+	}
+	return newConvertResponse(req, result)
 }
 
-// ConvertBatch 批量转换HTML为Markdown
-func (s *ConvertService) ConvertBatch(req *model.BatchConvertRequest) (*model.BatchConvertResponse, error) {
-	return s.converter.ConvertBatch(req)
+// MaxInputSize 单个HTML输入的大小上限（字节）
+func (s *ConvertService) MaxInputSize() int {
+	return s.config.Get().Converter.MaxInputSize
+}
+
+// prepare 校验单个转换请求并获取对应的转换器
+func (s *ConvertService) prepare(req *model.ConvertRequest) (*converter.Converter, error) {
+	if limit := s.config.Get().Converter.MaxInputSize; len(req.HTML) > limit {
+		return nil, fmt.Errorf("%w: %d > %d 字节", ErrInputTooLarge, len(req.HTML), limit)
+	}
+	if err := validateSource(req); err != nil {
+		return nil, err
+	}
+	if err := validateOutputOptions(req); err != nil {
+		return nil, err
+	}
+	return s.converterFor(req)
+}
+
+// fetchSource 抓取请求的 url，填入HTML和基准URL并返回对应的转换器
+//
+// 页面字符集按Content-Type响应头、BOM或meta标签识别。
+func (s *ConvertService) fetchSource(ctx context.Context, req *model.ConvertRequest) (*converter.Converter, error) {
+	fetcher := s.fetcher.Load()
+	if fetcher == nil {
+		return nil, ErrFetchDisabled
+	}
+	page, err := fetcher.Fetch(ctx, req.URL)
+	if err != nil {
+		return nil, err
+	}
+	// 请求体中没有HTML，抓取到的页面在此计入调用方的输入字节配额
+	if err := ratelimit.Charge(ctx, ratelimit.Cost{InputBytes: len(page.Body)}); err != nil {
+		return nil, err
+	}
+	html, err := DecodeHTML(page.Body, page.ContentType)
+	if err != nil {
+		return nil, err
+	}
+	if limit := s.config.Get().Converter.MaxInputSize; len(html) > limit {
+		return nil, fmt.Errorf("%w: %s %d > %d 字节", ErrInputTooLarge, page.URL, len(html), limit)
+	}
+
+	req.HTML = html
+	if req.BaseURL == "" {
+		req.BaseURL = page.BaseURL
+	}
+	return s.converterFor(req)
+}
+
+// ConvertBatch 批量转换HTML为Markdown，使用 url 的项目依次抓取，抓取失败记录在该项的结果中
+func (s *ConvertService) ConvertBatch(ctx context.Context, req *model.BatchConvertRequest) (*model.BatchConvertResponse, error) {
+	converters, err := s.prepareBatch(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.runBatch(ctx, req, converters, nil)
+}
+
+// prepareBatch 校验批量转换请求并获取每一项的转换器
+func (s *ConvertService) prepareBatch(req *model.BatchConvertRequest) ([]*converter.Converter, error) {
+	cfg := s.config.Get()
+	if len(req.Items) > cfg.Converter.MaxBatchSize {
+		return nil, fmt.Errorf("%w: %d > %d", ErrBatchTooLarge, len(req.Items), cfg.Converter.MaxBatchSize)
+	}
+	// 规则或插件无效属于请求错误，在转换前统一校验
+	converters := make([]*converter.Converter, len(req.Items))
+	for i := range req.Items {
+		item := &req.Items[i]
+		if len(item.HTML) > cfg.Converter.MaxInputSize {
+			return nil, fmt.Errorf("%w: 第%d项 %d > %d 字节", ErrInputTooLarge, i, len(item.HTML), cfg.Converter.MaxInputSize)
+		}
+		if err := validateSource(item); err != nil {
+			return nil, fmt.Errorf("第%d项: %w", i, err)
+		}
+		if err := validateOutputOptions(item); err != nil {
+			return nil, fmt.Errorf("第%d项: %w", i, err)
+		}
+		conv, err := s.converterFor(item)
+		if err != nil {
+			return nil, fmt.Errorf("第%d项: %w", i, err)
+		}
+		converters[i] = conv
+	}
+	return converters, nil
+}
+
+// runBatch 依次转换批量请求的每一项，单项失败记录在结果中；ctx取消时中止并返回错误
+//
+// progress 在每项转换后调用，可为空。
+func (s *ConvertService) runBatch(ctx context.Context, req *model.BatchConvertRequest, converters []*converter.Converter, progress func(done, total int)) (*model.BatchConvertResponse, error) {
+	startTime := time.Now()
+
+	results := make([]model.BatchConvertItem, len(req.Items))
+	summary := &model.BatchSummary{Total: len(req.Items)}
+	for i, item := range req.Items {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if progress != nil && i > 0 {
+			progress(i, len(req.Items))
+		}
+		conv := converters[i]
+		var err error
+		if item.URL != "" {
+			conv, err = s.fetchSource(ctx, &item)
+		}
+		var result *converter.Result
+		if err == nil {
+			result, err = conv.ConvertString(item.HTML)
+		}
+		var resp *model.ConvertResponse
+		if err == nil {
+			resp, err = newConvertResponse(&item, result)
+		}
+		if err != nil {
+			results[i] = model.BatchConvertItem{Index: i, Error: err.Error()}
+			summary.Failed++
+			continue
+		}
+		results[i] = model.BatchConvertItem{Index: i, Success: true, Result: resp}
+		summary.Success++
+	}
+
+	if progress != nil {
+		progress(len(req.Items), len(req.Items))
+	}
+
+	summary.TotalTime = time.Since(startTime)
+	if summary.Total > 0 {
+		summary.AverageTime = summary.TotalTime / time.Duration(summary.Total)
+	}
+
+	return &model.BatchConvertResponse{
+		Results: results,
+		Summary: summary,
+	}, nil
+}
+
+// converterFor 获取转换器，请求携带规则、插件或预处理配置时创建对应的转换器
+//
+// 请求中的插件在默认插件之外额外启用，请求中的规则优先于服务端配置的规则。
+func (s *ConvertService) converterFor(req *model.ConvertRequest) (*converter.Converter, error) {
+	if len(req.Rules) == 0 && len(req.Plugins) == 0 && len(req.Profiles) == 0 && req.Email == nil && !req.InferStyles && req.BaseURL == "" {
+		return s.converter.Load(), nil
+	}
+
+	cfg := s.config.Get()
+	plugins := slices.Clone(cfg.Converter.DefaultPlugins)
+	for _, name := range req.Plugins {
+		if !slices.Contains(plugins, name) {
+			plugins = append(plugins, name)
+		}
+	}
+	opts := []converter.Option{
+		converter.WithPlugins(plugins...),
+		converter.WithPluginHost(s.plugins.Load()),
+		converter.WithRules(req.Rules...),
+		converter.WithRules(cfg.Converter.Rules...),
+		converter.WithProfiles(req.Profiles...),
+		converter.WithStyleInference(req.InferStyles),
+		converter.WithDomain(req.BaseURL),
+	}
+	if req.Email != nil {
+		opts = append(opts, converter.WithEmailOptions(*req.Email))
+	}
+	return converter.New(opts...)
+}
+
+// validateSource 验证转换的来源，html 和 url 只能设置一个
+func validateSource(req *model.ConvertRequest) error {
+	if req.URL == "" {
+		return nil
+	}
+	if req.HTML != "" {
+		return ErrInvalidSource
+	}
+	return fetch.ValidateURL(req.URL)
+}
+
+// validateOutputOptions 验证处理转换结果的选项，在转换前调用以便尽早返回请求错误
+func validateOutputOptions(req *model.ConvertRequest) error {
+	if req.MaxTokens < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidMaxTokens, req.MaxTokens)
+	}
+	if _, err := tokenizer.Get(req.Tokenizer); err != nil {
+		return err
+	}
+	if err := chunk.ValidateTOC(req.TOC); err != nil {
+		return err
+	}
+	if req.Chunking != nil {
+		return req.Chunking.Validate()
+	}
+	return nil
+}
+
+// newConvertResponse 按请求的选项处理转换结果并生成接口响应
+//
+// 依次插入目录、按 max_tokens 截断，再生成大纲、统计token数和分块，偏移均相对于返回的Markdown；
+// 链接和图片清单来自完整的转换结果，不受截断影响。
+func newConvertResponse(req *model.ConvertRequest, result *converter.Result) (*model.ConvertResponse, error) {
+	tok, err := tokenizer.Get(req.Tokenizer)
+	if err != nil {
+		return nil, err
+	}
+	resp := toConvertResponse(result)
+	stats := resp.Stats
+	if resp.Markdown, err = chunk.InsertTOC(resp.Markdown, req.TOC); err != nil {
+		return nil, err
+	}
+	stats.OutputSize = len(resp.Markdown)
+	stats.Tokenizer = tok.Name()
+	stats.Tokens = tok.Count(resp.Markdown)
+	if req.MaxTokens > 0 && stats.Tokens > req.MaxTokens {
+		markdown, kept := chunk.Truncate(resp.Markdown, req.MaxTokens, tok)
+		stats.Truncated = true
+		stats.TruncatedBytes = len(resp.Markdown) - kept
+		stats.TruncatedTokens = tok.Count(resp.Markdown[kept:])
+		resp.Markdown = markdown
+		stats.OutputSize = len(markdown)
+		stats.Tokens = tok.Count(markdown)
+	}
+	resp.Outline = chunk.Outline(resp.Markdown)
+	stats.Analysis = chunk.Analyze(resp.Markdown)
+	if req.Inventory {
+		resp.Inventory = &result.Inventory
+	}
+	if req.Chunking != nil {
+		opts := *req.Chunking
+		opts.Tokenizer = tok
+		chunks, err := chunk.Split(resp.Markdown, opts)
+		if err != nil {
+			return nil, err
+		}
+		resp.Chunks = chunks
+	}
+	return resp, nil
+}
+
+// toConvertResponse 将转换库的结果转为接口响应
+func toConvertResponse(result *converter.Result) *model.ConvertResponse {
+	return &model.ConvertResponse{
+		Markdown: result.Markdown,
+		Stats: &model.ConversionStats{
+			InputSize:      result.Stats.InputSize,
+			OutputSize:     result.Stats.OutputSize,
+			ProcessingTime: result.Stats.ProcessingTime,
+			Links:          result.Stats.Links,
+			Images:         result.Stats.Images,
+		},
+	}
 }
 
 // Health 健康检查
@@ -57,5 +424,9 @@ func (s *ConvertService) Health() (*model.HealthResponse, error) {
 
 // GetConverterInfo 获取转换器信息
 func (s *ConvertService) GetConverterInfo() map[string]interface{} {
-	return s.converter.GetConverterInfo()
+	info := s.converter.Load().GetConverterInfo()
+	info["default_plugins"] = s.config.Get().Converter.DefaultPlugins
+	info["supported_tokenizers"] = tokenizer.Names()
+	info["default_tokenizer"] = tokenizer.DefaultName()
+	return info
 }
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// ErrInvalidCharset 无法识别或转换HTML的字符集
+var ErrInvalidCharset = errors.New("不支持的字符集")
+
+// DecodeHTML 将HTML解码为UTF-8
+//
+// 字符集依次按BOM、contentType的charset参数和meta标签识别，都没有时按UTF-8处理，结果不含BOM。
+func DecodeHTML(data []byte, contentType string) (string, error) {
+	r, err := charset.NewReader(bytes.NewReader(data), contentType)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidCharset, err)
+	}
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidCharset, err)
+	}
+	// UTF-8的BOM不经过转换，需要单独去除
+	return strings.TrimPrefix(string(decoded), "\ufeff"), nil
+}
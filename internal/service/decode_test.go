@@ -0,0 +1,33 @@
+package service
+
+import "testing"
+
+func TestDecodeHTML(t *testing.T) {
+	// "中文" 的GBK编码
+	gbk := []byte{0xd6, 0xd0, 0xce, 0xc4}
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		want        string
+	}{
+		{"utf-8 default", []byte("<p>中文</p>"), "", "<p>中文</p>"},
+		{"content type charset", append(append([]byte("<p>"), gbk...), "</p>"...), "text/html; charset=gbk", "<p>中文</p>"},
+		{"meta charset", append(append([]byte(`<meta charset="gb2312"><p>`), gbk...), "</p>"...), "text/html", `<meta charset="gb2312"><p>中文</p>`},
+		{"http-equiv", append(append([]byte(`<meta http-equiv="Content-Type" content="text/html; charset=GBK"><p>`), gbk...), "</p>"...), "", `<meta http-equiv="Content-Type" content="text/html; charset=GBK"><p>中文</p>`},
+		{"bom", append([]byte{0xef, 0xbb, 0xbf}, "<p>中文</p>"...), "text/html; charset=gbk", "<p>中文</p>"},
+		{"utf-16 bom", []byte{0xff, 0xfe, '<', 0, 'p', 0, '>', 0, 0x2d, 0x4e, 0x87, 0x65}, "", "<p>中文"},
+		{"latin1", []byte("<p>caf\xe9</p>"), "text/html; charset=iso-8859-1", "<p>café</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeHTML(tt.data, tt.contentType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("DecodeHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
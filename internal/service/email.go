@@ -0,0 +1,75 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/relaxcloud-cn/html2md/internal/model"
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+	"github.com/relaxcloud-cn/html2md/pkg/email"
+	"github.com/relaxcloud-cn/html2md/pkg/site"
+)
+
+// EmailMarkdownName 打包输出时Markdown文档在压缩包中的名称
+const EmailMarkdownName = "message.md"
+
+// EmailOptions 邮件转换选项
+type EmailOptions struct {
+	FrontMatter  bool // 在开头输出由邮件头组成的front matter
+	InlineImages bool // 将引用的图片内嵌为data URI
+	Archive      bool // 将Markdown文档和图片打包为zip
+
+	Cleanup converter.EmailOptions // 引用历史和签名的处理方式
+}
+
+// EmailResult 邮件转换结果
+type EmailResult struct {
+	*email.Result
+	Archive []byte // 打包输出时包含 message.md 和 images/ 下图片的zip压缩包
+}
+
+// ConvertEmail 转换邮件（.eml）或MHTML存档
+//
+// 正文始终经过email预处理。不打包输出时图片不随结果返回，引用的图片改写为 images/ 下的相对路径，
+// 需要完整结果时使用内嵌图片或打包输出。
+func (s *ConvertService) ConvertEmail(ctx context.Context, data []byte, opts EmailOptions) (*EmailResult, error) {
+	cfg := s.config.Get()
+	if len(data) > cfg.Converter.MaxArchiveSize {
+		return nil, fmt.Errorf("%w: 邮件 %d > %d 字节", ErrInputTooLarge, len(data), cfg.Converter.MaxArchiveSize)
+	}
+	conv, err := s.converterFor(&model.ConvertRequest{Email: &opts.Cleanup})
+	if err != nil {
+		return nil, err
+	}
+	emailOpts := email.Options{
+		Converter:    conv,
+		FrontMatter:  opts.FrontMatter,
+		InlineImages: opts.InlineImages,
+		MaxSize:      int64(cfg.Converter.MaxInputSize),
+	}
+
+	if !opts.Archive {
+		result, err := email.Convert(ctx, bytes.NewReader(data), nil, emailOpts)
+		if err != nil {
+			return nil, err
+		}
+		return &EmailResult{Result: result}, nil
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w := site.NewZipWriter(zw)
+	result, err := email.Convert(ctx, bytes.NewReader(data), w, emailOpts)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.WriteFile(EmailMarkdownName, []byte(result.Markdown)); err != nil {
+		return nil, fmt.Errorf("写入 %s 失败: %w", EmailMarkdownName, err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("生成压缩包失败: %w", err)
+	}
+	return &EmailResult{Result: result, Archive: buf.Bytes()}, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/relaxcloud-cn/html2md/pkg/epub"
+	"github.com/relaxcloud-cn/html2md/pkg/site"
+)
+
+// EPUBResult EPUB转换结果
+type EPUBResult struct {
+	*epub.Result
+	Archive []byte // 分章输出时包含各章节、目录和图片的zip压缩包
+}
+
+// ConvertEPUB 转换EPUB电子书
+//
+// split为false时返回合并的Markdown文档，图片不随结果返回；
+// 为true时每章输出一个Markdown文件，连同目录和图片打包为zip。
+func (s *ConvertService) ConvertEPUB(ctx context.Context, archive *zip.Reader, split bool) (*EPUBResult, error) {
+	return s.convertEPUB(ctx, archive, split, nil)
+}
+
+// convertEPUB 转换EPUB电子书，progress在每个章节转换后调用，可为空
+func (s *ConvertService) convertEPUB(ctx context.Context, archive *zip.Reader, split bool, progress func(done, total int)) (*EPUBResult, error) {
+	cfg := s.config.Get()
+	opts := epub.Options{
+		Converter:   s.converter.Load(),
+		Split:       split,
+		MaxFileSize: int64(cfg.Converter.MaxInputSize),
+		MaxTotal:    int64(cfg.Converter.MaxArchiveSize) * archiveExpandRatio,
+		Progress:    progress,
+	}
+
+	if !split {
+		result, err := epub.Convert(ctx, archive, nil, opts)
+		if err != nil {
+			return nil, archiveError(err)
+		}
+		return &EPUBResult{Result: result}, nil
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	result, err := epub.Convert(ctx, archive, site.NewZipWriter(zw), opts)
+	if err != nil {
+		return nil, archiveError(err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("生成压缩包失败: %w", err)
+	}
+	return &EPUBResult{Result: result, Archive: buf.Bytes()}, nil
+}
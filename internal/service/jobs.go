@@ -0,0 +1,574 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/relaxcloud-cn/html2md/internal/auth"
+	"github.com/relaxcloud-cn/html2md/internal/config"
+	"github.com/relaxcloud-cn/html2md/internal/jobstore"
+	"github.com/relaxcloud-cn/html2md/internal/model"
+	"github.com/relaxcloud-cn/html2md/internal/ratelimit"
+	"github.com/relaxcloud-cn/html2md/pkg/fetch"
+)
+
+// 回调请求头
+const (
+	WebhookTimestampHeader = "X-Html2md-Timestamp" // 发送时间（Unix秒）
+	WebhookSignatureHeader = "X-Html2md-Signature" // 签名: sha256=<hex>，配置了 jobs.webhook_secret 时设置
+)
+
+// 异步任务的错误
+var (
+	ErrInvalidJob  = errors.New("无效的任务参数")
+	ErrJobNotFound = errors.New("任务不存在或已过期")
+	ErrJobNotReady = errors.New("任务没有可获取的结果")
+	ErrQueueFull   = errors.New("任务队列已满")
+	ErrStoreFull   = jobstore.ErrFull
+)
+
+// sweepInterval 清理过期任务的间隔
+const sweepInterval = time.Minute
+
+// job 队列中的任务
+type job struct {
+	info   model.Job
+	owner  string             // 提交任务的API密钥名称，未启用认证时为空
+	meter  *ratelimit.Meter   // 提交者的限流计量器，抓取页面的字节数计入其配额；重启后恢复的任务为nil
+	cancel context.CancelFunc // 取消执行中的任务
+}
+
+// JobQueue 异步转换任务队列，HTTP和GRPC共用
+//
+// 任务按提交顺序由固定数量的工作协程执行，结束后的状态和结果保留 jobs.ttl 后删除。
+// 请求和结果保存在任务存储中，内存中只保留任务状态。
+// 启用认证时任务只对提交它的API密钥可见。
+// 回调与抓取页面使用相同的地址检查，默认不能发往内网地址，也不跟随重定向。
+type JobQueue struct {
+	service *ConvertService
+	config  *config.Manager
+	store   jobstore.Store
+	webhook atomic.Pointer[fetch.Fetcher]
+	queue   chan *job
+	workers sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+// NewJobQueue 创建任务队列，从存储中恢复任务后启动工作协程，ctx取消时停止执行
+//
+// 未结束的任务（包括上次停止时正在执行的任务）按提交顺序重新排队，
+// 已结束且未过期的任务的状态和结果仍可获取。
+// 工作协程数、队列长度和任务存储在创建时确定，修改配置后需要重启。
+func NewJobQueue(ctx context.Context, cfg *config.Manager, svc *ConvertService, store jobstore.Store) (*JobQueue, error) {
+	records, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(records, func(a, b *jobstore.Record) int {
+		return a.Job.CreatedAt.Compare(b.Job.CreatedAt)
+	})
+
+	jobsCfg := cfg.Get().Jobs
+	q := &JobQueue{
+		service: svc,
+		config:  cfg,
+		store:   store,
+		jobs:    make(map[string]*job),
+	}
+	q.webhook.Store(newWebhookFetcher(cfg.Get()))
+	cfg.OnReload(func(_, next *config.Config) {
+		q.webhook.Store(newWebhookFetcher(next))
+	})
+
+	var pending []*job
+	now := time.Now()
+	for _, rec := range records {
+		j := &job{info: rec.Job, owner: rec.Owner}
+		switch j.info.Status {
+		case model.JobQueued, model.JobRunning:
+			j.info.Status = model.JobQueued
+			j.info.StartedAt = nil
+			j.info.Progress.Done = 0
+			pending = append(pending, j)
+		default:
+			if j.expired(now) {
+				if err := store.Delete(j.info.ID); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		q.jobs[j.info.ID] = j
+	}
+	if len(records) > 0 {
+		log.Printf("已从任务存储恢复 %d 个任务，其中 %d 个重新排队", len(q.jobs), len(pending))
+	}
+
+	// 恢复的任务可能超出队列长度，全部保留
+	q.queue = make(chan *job, max(jobsCfg.QueueSize, len(pending)))
+	for _, j := range pending {
+		q.queue <- j
+	}
+
+	q.workers.Add(jobsCfg.Workers)
+	for range jobsCfg.Workers {
+		go func() {
+			defer q.workers.Done()
+			q.work(ctx)
+		}()
+	}
+	go q.sweep(ctx)
+	return q, nil
+}
+
+// Close 等待工作协程退出后关闭任务存储，需在创建时的ctx取消后调用
+func (q *JobQueue) Close() error {
+	q.workers.Wait()
+	return q.store.Close()
+}
+
+// Submit 校验并提交任务，参数错误在提交时返回，不进入队列
+func (q *JobQueue) Submit(ctx context.Context, req *model.JobRequest) (*model.Job, error) {
+	total, err := q.validate(req)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &job{
+		info: model.Job{
+			ID:        newJobID(),
+			Type:      req.Type,
+			Status:    model.JobQueued,
+			Progress:  model.JobProgress{Total: total},
+			CreatedAt: time.Now(),
+		},
+		owner: jobOwner(ctx),
+		meter: ratelimit.MeterFromContext(ctx),
+	}
+	if err := q.store.Create(j.record(), req); err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	select {
+	case q.queue <- j:
+	default:
+		q.mu.Unlock()
+		q.delete(j.info.ID)
+		return nil, fmt.Errorf("%w: 最多 %d 个等待执行的任务", ErrQueueFull, cap(q.queue))
+	}
+	q.jobs[j.info.ID] = j
+	info := j.info
+	q.mu.Unlock()
+	return &info, nil
+}
+
+// Get 获取任务状态
+func (q *JobQueue) Get(ctx context.Context, id string) (*model.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, err := q.lookup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	info := j.info
+	return &info, nil
+}
+
+// Result 获取成功任务的结果，任务未结束或失败时返回 ErrJobNotReady
+func (q *JobQueue) Result(ctx context.Context, id string) (*model.Job, *model.JobResult, error) {
+	q.mu.Lock()
+	j, err := q.lookup(ctx, id)
+	if err != nil {
+		q.mu.Unlock()
+		return nil, nil, err
+	}
+	info := j.info
+	q.mu.Unlock()
+
+	switch info.Status {
+	case model.JobSucceeded:
+		result, err := q.store.Result(id)
+		if errors.Is(err, jobstore.ErrNotFound) {
+			// 读取前任务已被删除
+			return nil, nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return &info, result, nil
+	case model.JobFailed:
+		return &info, nil, fmt.Errorf("%w: 任务失败: %s", ErrJobNotReady, info.Error)
+	default:
+		return &info, nil, fmt.Errorf("%w: 任务状态为 %s", ErrJobNotReady, info.Status)
+	}
+}
+
+// Cancel 取消排队或执行中的任务；已结束的任务删除其状态和结果
+func (q *JobQueue) Cancel(ctx context.Context, id string) (*model.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, err := q.lookup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch j.info.Status {
+	case model.JobQueued, model.JobRunning:
+		q.end(j, model.JobCanceled)
+		q.save(j, nil)
+		if j.cancel != nil {
+			j.cancel()
+		}
+	default:
+		delete(q.jobs, id)
+		q.delete(id)
+	}
+	info := j.info
+	return &info, nil
+}
+
+// lookup 查找调用方可见的未过期任务，调用前需持有锁
+func (q *JobQueue) lookup(ctx context.Context, id string) (*job, error) {
+	j, ok := q.jobs[id]
+	if !ok || j.owner != jobOwner(ctx) || j.expired(time.Now()) {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+	return j, nil
+}
+
+// validate 校验任务参数，返回进度的总数
+func (q *JobQueue) validate(req *model.JobRequest) (int, error) {
+	if req.Webhook != "" {
+		_, err := q.webhook.Load().Check(req.Webhook)
+		if errors.Is(err, fetch.ErrBlocked) {
+			return 0, fmt.Errorf("%w: webhook 不能是内网地址", ErrInvalidJob)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: webhook 必须是 http 或 https 地址", ErrInvalidJob)
+		}
+	}
+
+	switch req.Type {
+	case model.JobConvert:
+		if req.Convert == nil {
+			return 0, fmt.Errorf("%w: convert 任务缺少 convert 参数", ErrInvalidJob)
+		}
+		_, err := q.service.prepare(req.Convert)
+		return 1, err
+	case model.JobBatch:
+		if req.Batch == nil || len(req.Batch.Items) == 0 {
+			return 0, fmt.Errorf("%w: batch 任务缺少 batch.items 参数", ErrInvalidJob)
+		}
+		_, err := q.service.prepareBatch(req.Batch)
+		return len(req.Batch.Items), err
+	case model.JobSite, model.JobEPUB:
+		if len(req.Archive) == 0 {
+			return 0, fmt.Errorf("%w: %s 任务缺少 archive 参数", ErrInvalidJob, req.Type)
+		}
+		archive, err := q.service.OpenArchive(req.Archive)
+		if err != nil {
+			return 0, err
+		}
+		if req.Type == model.JobEPUB {
+			// 章节数在解析后才能确定
+			return 0, nil
+		}
+		return CountPages(archive), nil
+	default:
+		return 0, fmt.Errorf("%w: 未知的任务类型 %q", ErrInvalidJob, req.Type)
+	}
+}
+
+// work 依次执行队列中的任务，直到ctx取消
+func (q *JobQueue) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-q.queue:
+			q.run(ctx, j)
+		}
+	}
+}
+
+// run 执行任务并记录结果，排队时已取消的任务直接跳过
+//
+// 队列停止时中断的任务在存储中保持执行中状态，下次启动时重新排队。
+func (q *JobQueue) run(ctx context.Context, j *job) {
+	jobCtx, cancel := context.WithCancel(ratelimit.WithMeter(ctx, j.meter))
+	defer cancel()
+
+	q.mu.Lock()
+	if j.info.Status != model.JobQueued {
+		q.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	j.info.Status = model.JobRunning
+	j.info.StartedAt = &now
+	j.cancel = cancel
+	if err := q.store.Update(j.record()); err != nil {
+		log.Printf("保存任务 %s 状态失败: %v", j.info.ID, err)
+	}
+	q.mu.Unlock()
+
+	req, err := q.store.Request(j.info.ID)
+	var result *model.JobResult
+	if err == nil {
+		result, err = q.execute(jobCtx, j, req)
+	}
+
+	q.mu.Lock()
+	j.cancel = nil
+	if j.info.Status != model.JobRunning || ctx.Err() != nil {
+		// 执行期间已被取消或队列已停止，丢弃结果
+		q.mu.Unlock()
+		return
+	}
+	if err != nil {
+		j.info.Error = err.Error()
+		q.end(j, model.JobFailed)
+		q.save(j, nil)
+	} else {
+		q.end(j, model.JobSucceeded)
+		q.save(j, result)
+	}
+	info := j.info
+	q.mu.Unlock()
+
+	if req != nil && req.Webhook != "" {
+		go q.notify(ctx, req.Webhook, info)
+	}
+}
+
+// execute 按任务类型执行转换
+func (q *JobQueue) execute(ctx context.Context, j *job, req *model.JobRequest) (*model.JobResult, error) {
+	progress := func(done, total int) {
+		q.mu.Lock()
+		j.info.Progress = model.JobProgress{Done: done, Total: total}
+		q.mu.Unlock()
+	}
+
+	switch req.Type {
+	case model.JobConvert:
+		resp, err := q.service.Convert(ctx, req.Convert)
+		if err != nil {
+			return nil, err
+		}
+		progress(1, 1)
+		return &model.JobResult{Convert: resp}, nil
+	case model.JobBatch:
+		// 排队期间配置可能已变更，按当前配置重新校验
+		converters, err := q.service.prepareBatch(req.Batch)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := q.service.runBatch(ctx, req.Batch, converters, progress)
+		if err != nil {
+			return nil, err
+		}
+		return &model.JobResult{Batch: resp}, nil
+	case model.JobSite:
+		archive, err := q.service.OpenArchive(req.Archive)
+		if err != nil {
+			return nil, err
+		}
+		result, err := q.service.convertSite(ctx, archive, progress)
+		if err != nil {
+			return nil, err
+		}
+		return &model.JobResult{Archive: result.Archive}, nil
+	case model.JobEPUB:
+		archive, err := q.service.OpenArchive(req.Archive)
+		if err != nil {
+			return nil, err
+		}
+		result, err := q.service.convertEPUB(ctx, archive, req.Split, progress)
+		if err != nil {
+			return nil, err
+		}
+		return &model.JobResult{
+			EPUB: &model.EPUBResponse{
+				Title:    result.Title,
+				Markdown: result.Markdown,
+				Chapters: result.Chapters,
+			},
+			Archive: result.Archive,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: 未知的任务类型 %q", ErrInvalidJob, req.Type)
+	}
+}
+
+// end 将任务标记为结束并设置保留期限，调用前需持有锁
+func (q *JobQueue) end(j *job, status string) {
+	now := time.Now()
+	expires := now.Add(q.config.Get().Jobs.TTL)
+	j.info.Status = status
+	j.info.FinishedAt = &now
+	j.info.ExpiresAt = &expires
+}
+
+// save 将结束的任务保存到存储，调用前需持有锁
+//
+// 结果超出存储容量时任务改为失败。
+func (q *JobQueue) save(j *job, result *model.JobResult) {
+	err := q.store.Finish(j.record(), result)
+	if errors.Is(err, jobstore.ErrFull) {
+		j.info.Status = model.JobFailed
+		j.info.Error = "保存结果失败: " + err.Error()
+		err = q.store.Finish(j.record(), nil)
+	}
+	if err != nil {
+		log.Printf("保存任务 %s 失败: %v", j.info.ID, err)
+	}
+}
+
+// delete 从存储中删除任务
+func (q *JobQueue) delete(id string) {
+	if err := q.store.Delete(id); err != nil {
+		log.Printf("删除任务 %s 失败: %v", id, err)
+	}
+}
+
+// record 任务的存储记录
+func (j *job) record() *jobstore.Record {
+	return &jobstore.Record{Job: j.info, Owner: j.owner}
+}
+
+// expired 判断任务的保留期限是否已过
+func (j *job) expired(now time.Time) bool {
+	return j.info.ExpiresAt != nil && now.After(*j.info.ExpiresAt)
+}
+
+// sweep 定期从内存和存储中删除过期的任务，直到ctx取消
+func (q *JobQueue) sweep(ctx context.Context) {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			var expired []string
+			q.mu.Lock()
+			for id, j := range q.jobs {
+				if j.expired(now) {
+					delete(q.jobs, id)
+					expired = append(expired, id)
+				}
+			}
+			q.mu.Unlock()
+			for _, id := range expired {
+				q.delete(id)
+			}
+		}
+	}
+}
+
+// notify 向回调地址POST任务状态，失败时按1秒起的指数退避重试
+func (q *JobQueue) notify(ctx context.Context, target string, info model.Job) {
+	body, err := json.Marshal(info)
+	if err != nil {
+		log.Printf("任务 %s 回调失败: %v", info.ID, err)
+		return
+	}
+
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		cfg := q.config.Get().Jobs
+		err := q.post(ctx, target, body, cfg)
+		if err == nil {
+			return
+		}
+		if attempt >= cfg.WebhookRetries {
+			log.Printf("任务 %s 回调失败，已重试 %d 次: %v", info.ID, attempt, err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post 发送一次回调请求，响应状态码不是2xx时返回错误
+func (q *JobQueue) post(ctx context.Context, target string, body []byte, cfg config.JobsConfig) error {
+	ctx, cancel := context.WithTimeout(ctx, cfg.WebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "html2md-webhook")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	if cfg.WebhookSecret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(cfg.WebhookSecret, timestamp, body))
+	}
+
+	resp, err := q.webhook.Load().Client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("回调地址返回 %s", resp.Status)
+	}
+	return nil
+}
+
+// SignWebhook 计算回调签名: sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+//
+// 接收方应使用相同方式计算并以常量时间比较，同时校验时间戳以防止重放。
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newWebhookFetcher 创建发送回调的客户端，允许访问的地址段与抓取页面相同
+func newWebhookFetcher(cfg *config.Config) *fetch.Fetcher {
+	// 配置已通过校验，地址段可以正常解析
+	allow, _ := cfg.Fetch.AllowedPrefixes()
+	return fetch.New(fetch.Options{Allow: allow})
+}
+
+// jobOwner 任务的所有者，已认证时为API密钥名称
+func jobOwner(ctx context.Context) string {
+	if principal := auth.FromContext(ctx); principal != nil {
+		return principal.Name
+	}
+	return ""
+}
+
+// newJobID 生成随机的任务ID
+func newJobID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
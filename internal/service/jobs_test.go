@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relaxcloud-cn/html2md/internal/auth"
+	"github.com/relaxcloud-cn/html2md/internal/config"
+	"github.com/relaxcloud-cn/html2md/internal/jobstore"
+	"github.com/relaxcloud-cn/html2md/internal/model"
+	"github.com/relaxcloud-cn/html2md/pkg/fetch"
+)
+
+// newTestQueue 使用内存存储创建任务队列，配置通过环境变量设置
+func newTestQueue(t *testing.T, store jobstore.Store) *JobQueue {
+	t.Helper()
+	cfg, err := config.NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store == nil {
+		store = jobstore.NewMemoryStore(0)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	q, err := NewJobQueue(ctx, cfg, NewConvertService(cfg), store)
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		_ = q.Close()
+	})
+	return q
+}
+
+// waitJob 等待任务结束
+func waitJob(t *testing.T, q *JobQueue, ctx context.Context, id string) *model.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		info, err := q.Get(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.FinishedAt != nil {
+			return info
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("任务 %s 未在期限内结束", id)
+	return nil
+}
+
+func convertJob(html string) *model.JobRequest {
+	return &model.JobRequest{Type: model.JobConvert, Convert: &model.ConvertRequest{HTML: html}}
+}
+
+func TestJobQueueConvert(t *testing.T) {
+	q := newTestQueue(t, nil)
+	ctx := context.Background()
+
+	info, err := q.Submit(ctx, convertJob("<h1>Hello</h1>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != model.JobQueued || info.Progress.Total != 1 {
+		t.Errorf("Submit() = %+v", info)
+	}
+
+	done := waitJob(t, q, ctx, info.ID)
+	if done.Status != model.JobSucceeded || done.Progress.Done != 1 || done.ExpiresAt == nil {
+		t.Fatalf("job = %+v, want succeeded", done)
+	}
+	_, result, err := q.Result(ctx, info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Convert == nil || result.Convert.Markdown != "# Hello" {
+		t.Errorf("Result() = %+v", result)
+	}
+
+	// 已结束的任务取消时删除
+	if _, err := q.Cancel(ctx, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Get(ctx, info.ID); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Get() after cancel error = %v, want ErrJobNotFound", err)
+	}
+}
+
+func TestJobQueueFailed(t *testing.T) {
+	q := newTestQueue(t, nil)
+	ctx := context.Background()
+
+	// url 抓取在提交时只检查格式，执行时才失败
+	info, err := q.Submit(ctx, &model.JobRequest{Type: model.JobConvert, Convert: &model.ConvertRequest{URL: "http://127.0.0.1:1/"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := waitJob(t, q, ctx, info.ID)
+	if done.Status != model.JobFailed || done.Error == "" {
+		t.Fatalf("job = %+v, want failed", done)
+	}
+	if _, _, err := q.Result(ctx, info.ID); !errors.Is(err, ErrJobNotReady) {
+		t.Errorf("Result() error = %v, want ErrJobNotReady", err)
+	}
+}
+
+func TestJobQueueValidate(t *testing.T) {
+	q := newTestQueue(t, nil)
+	tests := []struct {
+		name string
+		req  *model.JobRequest
+	}{
+		{"unknown type", &model.JobRequest{Type: "video"}},
+		{"missing convert", &model.JobRequest{Type: model.JobConvert}},
+		{"empty batch", &model.JobRequest{Type: model.JobBatch, Batch: &model.BatchConvertRequest{}}},
+		{"missing archive", &model.JobRequest{Type: model.JobSite}},
+		{"invalid archive", &model.JobRequest{Type: model.JobSite, Archive: []byte("not a zip")}},
+		{"invalid plugin", &model.JobRequest{Type: model.JobConvert, Convert: &model.ConvertRequest{HTML: "<p>x</p>", Plugins: []string{"nope"}}}},
+	}
+	webhooks := []string{
+		"ftp://example.com/hook",
+		"/relative",
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://api.localhost./hook",
+		"http://10.0.0.1/hook",
+		"http://169.254.169.254/latest",
+		"http://[::1]/hook",
+		"http://[::ffff:192.168.1.1]/hook",
+		"http://0.0.0.0/hook",
+	}
+	for _, webhook := range webhooks {
+		req := convertJob("<p>x</p>")
+		req.Webhook = webhook
+		tests = append(tests, struct {
+			name string
+			req  *model.JobRequest
+		}{"webhook " + webhook, req})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := q.Submit(context.Background(), tt.req)
+			if err == nil {
+				t.Fatal("Submit() succeeded, want error")
+			}
+			if !IsBadRequest(err) {
+				t.Errorf("Submit() error = %v, want bad request", err)
+			}
+		})
+	}
+
+	req := convertJob("<p>x</p>")
+	req.Webhook = "https://example.com/hooks/html2md"
+	if _, err := q.Submit(context.Background(), req); err != nil {
+		t.Errorf("Submit() with public webhook error = %v", err)
+	}
+}
+
+func TestJobQueueOwner(t *testing.T) {
+	q := newTestQueue(t, nil)
+	alice := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "alice"})
+	bob := auth.WithPrincipal(context.Background(), &auth.Principal{Name: "bob"})
+
+	info, err := q.Submit(alice, convertJob("<p>x</p>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ctx := range []context.Context{bob, context.Background()} {
+		if _, err := q.Get(ctx, info.ID); !errors.Is(err, ErrJobNotFound) {
+			t.Errorf("Get() by other owner error = %v, want ErrJobNotFound", err)
+		}
+		if _, err := q.Cancel(ctx, info.ID); !errors.Is(err, ErrJobNotFound) {
+			t.Errorf("Cancel() by other owner error = %v, want ErrJobNotFound", err)
+		}
+	}
+	waitJob(t, q, alice, info.ID)
+}
+
+func TestJobQueueRestore(t *testing.T) {
+	store := jobstore.NewMemoryStore(0)
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	records := []struct {
+		job model.Job
+		req *model.JobRequest
+	}{
+		{model.Job{ID: "running", Type: model.JobConvert, Status: model.JobRunning, CreatedAt: now, StartedAt: &now}, convertJob("<h2>Again</h2>")},
+		{model.Job{ID: "expired", Type: model.JobConvert, Status: model.JobSucceeded, CreatedAt: past, FinishedAt: &past, ExpiresAt: &past}, convertJob("<p>x</p>")},
+	}
+	for _, r := range records {
+		if err := store.Create(&jobstore.Record{Job: r.job}, r.req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q := newTestQueue(t, store)
+	ctx := context.Background()
+	done := waitJob(t, q, ctx, "running")
+	if done.Status != model.JobSucceeded {
+		t.Fatalf("restored job = %+v, want succeeded", done)
+	}
+	if _, err := q.Get(ctx, "expired"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Get(expired) error = %v, want ErrJobNotFound", err)
+	}
+	if _, err := store.Request("expired"); !errors.Is(err, jobstore.ErrNotFound) {
+		t.Errorf("expired job still stored: %v", err)
+	}
+}
+
+func TestJobQueueWebhook(t *testing.T) {
+	t.Setenv("FETCH_ALLOW_NETWORKS", "127.0.0.1")
+	t.Setenv("JOBS_WEBHOOK_SECRET", "secret")
+
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer hook.Close()
+
+	q := newTestQueue(t, nil)
+	req := convertJob("<p>x</p>")
+	req.Webhook = hook.URL + "/hook"
+	info, err := q.Submit(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case r := <-received:
+		body := <-bodies
+		if r.Method != http.MethodPost || r.URL.Path != "/hook" {
+			t.Errorf("webhook request = %s %s", r.Method, r.URL.Path)
+		}
+		if !strings.Contains(string(body), info.ID) || !strings.Contains(string(body), model.JobSucceeded) {
+			t.Errorf("webhook body = %s", body)
+		}
+		timestamp := r.Header.Get(WebhookTimestampHeader)
+		if want := SignWebhook("secret", timestamp, body); r.Header.Get(WebhookSignatureHeader) != want {
+			t.Errorf("signature = %q, want %q", r.Header.Get(WebhookSignatureHeader), want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("未收到回调")
+	}
+}
+
+func TestJobQueueWebhookGuard(t *testing.T) {
+	t.Setenv("FETCH_ALLOW_NETWORKS", "127.0.0.1")
+	redirected := make(chan struct{}, 1)
+	target := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		redirected <- struct{}{}
+	}))
+	defer target.Close()
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer hook.Close()
+
+	q := newTestQueue(t, nil)
+	cfg := q.config.Get().Jobs
+	if err := q.post(context.Background(), hook.URL, []byte("{}"), cfg); err == nil {
+		t.Error("post() followed redirect, want error")
+	}
+	select {
+	case <-redirected:
+		t.Error("webhook redirect was followed")
+	default:
+	}
+
+	// 未放行时连接前拒绝回环地址，包括通过域名解析到的地址
+	t.Setenv("FETCH_ALLOW_NETWORKS", "")
+	q = newTestQueue(t, nil)
+	if err := q.post(context.Background(), target.URL, []byte("{}"), cfg); !errors.Is(err, fetch.ErrBlocked) {
+		t.Errorf("post() error = %v, want ErrBlocked", err)
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	// HMAC-SHA256("secret", "1700000000." + body)
+	got := SignWebhook("secret", "1700000000", []byte(`{"id":"1"}`))
+	want := "sha256=086f6aff7bd084c98679825129c5a64dbad88c760016d6d2c0fb123f27951d54"
+	if got != want {
+		t.Errorf("SignWebhook() = %q, want %q", got, want)
+	}
+}
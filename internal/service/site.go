@@ -0,0 +1,113 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/relaxcloud-cn/html2md/pkg/site"
+)
+
+// SiteReportName 结果压缩包中转换报告的文件名
+const SiteReportName = "html2md-report.json"
+
+// archiveExpandRatio 解压后的总大小最多为压缩包上限的倍数，防止压缩包炸弹
+const archiveExpandRatio = 10
+
+// ErrInvalidArchive 上传的压缩包无法解析
+var ErrInvalidArchive = errors.New("无效的zip压缩包")
+
+// SiteResult 整站转换结果
+type SiteResult struct {
+	Report  *site.Report
+	Archive []byte // 包含Markdown目录树和转换报告的zip压缩包
+}
+
+// MaxArchiveSize 当前允许上传的压缩包大小上限
+func (s *ConvertService) MaxArchiveSize() int {
+	return s.config.Get().Converter.MaxArchiveSize
+}
+
+// OpenArchive 校验并打开上传的zip压缩包，如整站导出或EPUB电子书
+func (s *ConvertService) OpenArchive(data []byte) (*zip.Reader, error) {
+	cfg := s.config.Get()
+	if len(data) > cfg.Converter.MaxArchiveSize {
+		return nil, fmt.Errorf("%w: 压缩包 %d > %d 字节", ErrInputTooLarge, len(data), cfg.Converter.MaxArchiveSize)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	files := 0
+	for _, f := range zr.File {
+		if !f.FileInfo().IsDir() {
+			files++
+		}
+	}
+	if files > cfg.Converter.MaxArchiveFiles {
+		return nil, fmt.Errorf("%w: 压缩包包含 %d 个文件，超过 %d", ErrBatchTooLarge, files, cfg.Converter.MaxArchiveFiles)
+	}
+	return zr, nil
+}
+
+// CountPages 统计压缩包中的HTML页面数，用于计算限流配额
+func CountPages(archive *zip.Reader) int {
+	pages := 0
+	for _, f := range archive.File {
+		if site.IsHTML(f.Name) {
+			pages++
+		}
+	}
+	return pages
+}
+
+// ConvertSite 转换压缩包中的整站HTML，返回Markdown目录树压缩包
+func (s *ConvertService) ConvertSite(ctx context.Context, archive *zip.Reader) (*SiteResult, error) {
+	return s.convertSite(ctx, archive, nil)
+}
+
+// convertSite 转换整站HTML，progress在每个页面处理后调用，可为空
+func (s *ConvertService) convertSite(ctx context.Context, archive *zip.Reader, progress func(done, total int)) (*SiteResult, error) {
+	cfg := s.config.Get()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	report, err := site.Convert(ctx, archive, site.NewZipWriter(zw), site.Options{
+		Converter:   s.converter.Load(),
+		MaxFiles:    cfg.Converter.MaxArchiveFiles,
+		MaxFileSize: int64(cfg.Converter.MaxInputSize),
+		MaxTotal:    int64(cfg.Converter.MaxArchiveSize) * archiveExpandRatio,
+		Progress:    progress,
+	})
+	if err != nil {
+		return nil, archiveError(err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("生成转换报告失败: %w", err)
+	}
+	if err := site.NewZipWriter(zw).WriteFile(SiteReportName, data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("生成压缩包失败: %w", err)
+	}
+
+	return &SiteResult{Report: report, Archive: buf.Bytes()}, nil
+}
+
+// archiveError 将读取压缩包内容时的错误转为请求错误
+func archiveError(err error) error {
+	if errors.Is(err, site.ErrLimitExceeded) {
+		return fmt.Errorf("%w: %v", ErrInputTooLarge, err)
+	}
+	if errors.Is(err, zip.ErrFormat) || errors.Is(err, zip.ErrAlgorithm) || errors.Is(err, zip.ErrChecksum) {
+		return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package chunk
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/relaxcloud-cn/html2md/pkg/langdetect"
+)
+
+// 估算阅读时间使用的阅读速度
+const (
+	wordsPerMinute    = 230 // 以空格分词的文字每分钟阅读的单词数
+	cjkCharsPerMinute = 400 // 中日文每分钟阅读的字数
+)
+
+var (
+	// linePrefix 行首的缩进、引用和列表标记
+	linePrefix = regexp.MustCompile(`^[ \t]*(?:>[ \t]*)*(?:(?:[-*+]|\d{1,9}[.)])[ \t]+)?`)
+	// listItem 列表项的第一行
+	listItem = regexp.MustCompile(`^[ \t]*(?:[-*+]|\d{1,9}[.)])[ \t]`)
+)
+
+// Analysis 文档统计
+type Analysis struct {
+	Words              int     `json:"words" example:"1200"`               // 单词数，中日文每个字计为一个单词，不含代码块
+	Chars              int     `json:"chars" example:"5400"`               // 字符数，不含空白、Markdown标记和代码块
+	ReadingSeconds     int     `json:"reading_seconds" example:"300"`      // 预计阅读时间（秒）
+	Paragraphs         int     `json:"paragraphs" example:"24"`            // 段落数，不含标题、列表、代码块和表格
+	Headings           int     `json:"headings" example:"8"`               // 标题数
+	CodeBlocks         int     `json:"code_blocks" example:"3"`            // 代码块数
+	Language           string  `json:"language,omitempty" example:"zh"`    // 正文的语言（ISO 639-1），无法识别时为空
+	LanguageConfidence float64 `json:"language_confidence" example:"0.97"` // 语言识别的置信度，0到1之间
+}
+
+// Analyze 统计Markdown的单词数、字符数、阅读时间和块的数量，并识别正文的语言
+func Analyze(markdown string) Analysis {
+	var a Analysis
+	var prose strings.Builder
+	for _, b := range parseBlocks(markdown, nil) {
+		text := markdown[b.start:b.end]
+		switch {
+		case b.level > 0:
+			a.Headings++
+			prose.WriteString(plainText(b.path[len(b.path)-1]))
+		case b.atomic && fenceLine.MatchString(text):
+			a.CodeBlocks++
+			continue
+		case b.atomic:
+			// 表格的单元格分隔符不计入字符数
+			for _, line := range strings.Split(text, "\n") {
+				prose.WriteString(plainText(strings.ReplaceAll(line, "|", " ")))
+				prose.WriteByte('\n')
+			}
+		default:
+			var lines []string
+			for _, line := range strings.Split(text, "\n") {
+				lines = append(lines, plainText(linePrefix.ReplaceAllString(line, "")))
+			}
+			// 分隔线等没有文字的块不计为段落
+			body := strings.Join(lines, "\n")
+			if !listItem.MatchString(text) && strings.IndexFunc(body, isWordRune) >= 0 {
+				a.Paragraphs++
+			}
+			prose.WriteString(body)
+			prose.WriteByte('\n')
+		}
+		prose.WriteByte('\n')
+	}
+
+	text := prose.String()
+	words, cjk := countWords(text)
+	a.Words = words
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			a.Chars++
+		}
+	}
+	minutes := float64(words-cjk)/wordsPerMinute + float64(cjk)/cjkCharsPerMinute
+	a.ReadingSeconds = int(math.Ceil(minutes * 60))
+	lang := langdetect.Detect(text)
+	a.Language, a.LanguageConfidence = lang.Language, lang.Confidence
+	return a
+}
+
+// countWords 统计单词数，中日文每个字计为一个单词，同时返回其中中日文的字数
+//
+// 单词由字母和数字组成，单词内部的撇号和连字符不拆分单词。
+func countWords(text string) (words, cjk int) {
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			words++
+			cjk++
+			inWord = false
+		case isWordRune(r):
+			if !inWord {
+				words++
+			}
+			inWord = true
+		case inWord && strings.ContainsRune("'’-", r):
+		default:
+			inWord = false
+		}
+	}
+	return words, cjk
+}
+
+// isWordRune 判断是否为组成单词的字母、数字或附加符号
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r)
+}
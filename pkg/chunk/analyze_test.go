@@ -0,0 +1,51 @@
+package chunk
+
+import "testing"
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want Analysis
+	}{
+		{
+			name: "empty",
+			md:   "",
+			want: Analysis{},
+		},
+		{
+			name: "blocks",
+			md: "# Getting started\n\nInstall the package and run the server.\n\n- first item\n- second item\n\n" +
+				"```go\nfmt.Println(\"ignored words here\")\n```\n\n| a | b |\n|---|---|\n| c | d |\n\n---\n\n> A quoted **line** with [a link](https://example.com).",
+			want: Analysis{
+				Words: 23, Chars: 100, ReadingSeconds: 6, Paragraphs: 2, Headings: 1, CodeBlocks: 1,
+				Language: "en", LanguageConfidence: 1,
+			},
+		},
+		{
+			name: "chinese",
+			md:   "## 安装\n\n下载程序后运行服务。",
+			want: Analysis{
+				Words: 11, Chars: 12, ReadingSeconds: 2, Paragraphs: 1, Headings: 1,
+				Language: "zh", LanguageConfidence: 1,
+			},
+		},
+		{
+			name: "contractions and hyphens",
+			md:   "It's a well-known fact.",
+			want: Analysis{Words: 4, Chars: 20, ReadingSeconds: 2, Paragraphs: 1, Language: "en", LanguageConfidence: 1},
+		},
+		{
+			name: "too little text for language",
+			md:   "OK OK OK",
+			want: Analysis{Words: 3, Chars: 6, ReadingSeconds: 1, Paragraphs: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Analyze(tt.md); got != tt.want {
+				t.Errorf("Analyze() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
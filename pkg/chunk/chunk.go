@@ -0,0 +1,344 @@
+// Package chunk 按标题层级将Markdown切分为块，用于检索增强生成（RAG）
+//
+// 标题开始新的小节，较小的相邻小节合并到目标大小，超过目标大小的小节在段落、行或词的边界拆分。
+// 代码块和表格不会被拆分，单个代码块或表格超过最大大小时独占一块。
+// 此外提供按token数截断、标题大纲、目录生成和文档统计等基于同一块结构的处理。
+package chunk
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/relaxcloud-cn/html2md/pkg/tokenizer"
+)
+
+// 大小的计量单位
+const (
+	UnitChars  = "chars"  // 字符数
+	UnitTokens = "tokens" // token数
+)
+
+// MinSize 目标大小和最大大小的下限，避免产生过多的块
+const MinSize = 32
+
+// ErrInvalidOptions 分块选项无效
+var ErrInvalidOptions = errors.New("无效的分块选项")
+
+// Options 分块选项
+type Options struct {
+	Unit       string `json:"unit,omitempty" example:"chars"`      // 计量单位: chars（默认）, tokens
+	TargetSize int    `json:"target_size,omitempty" example:"800"` // 目标大小，相邻内容合并到不超过该大小，为0时等于最大大小
+	MaxSize    int    `json:"max_size,omitempty" example:"1200"`   // 最大大小，为0时等于目标大小
+	Overlap    int    `json:"overlap,omitempty" example:"100"`     // 同一小节内相邻块的重叠大小
+
+	Tokenizer tokenizer.Tokenizer `json:"-"` // 计算token数的分词器，为nil时使用默认分词器
+}
+
+// Chunk Markdown中的一块
+type Chunk struct {
+	Index    int      `json:"index" example:"0"`                  // 序号
+	Headings []string `json:"headings" example:"安装,使用Docker"`     // 所在的标题路径，由上级到下级
+	Content  string   `json:"content" example:"## 使用Docker\n..."` // 内容，即Markdown中 [Start, End) 的部分
+	Start    int      `json:"start" example:"0"`                  // 在Markdown中的起始字节偏移，包括重叠部分
+	End      int      `json:"end" example:"812"`                  // 在Markdown中的结束字节偏移（不含）
+	Bytes    int      `json:"bytes" example:"812"`                // 字节数
+	Chars    int      `json:"chars" example:"640"`                // 字符数
+	Tokens   int      `json:"tokens" example:"210"`               // token数
+}
+
+// normalize 验证选项并补全默认值
+func (o Options) normalize() (Options, error) {
+	switch o.Unit {
+	case "":
+		o.Unit = UnitChars
+	case UnitChars, UnitTokens:
+	default:
+		return o, fmt.Errorf("%w: 计量单位 %q 无效，可选: chars, tokens", ErrInvalidOptions, o.Unit)
+	}
+	if o.Tokenizer == nil {
+		o.Tokenizer = tokenizer.Default()
+	}
+	if o.TargetSize < 0 || o.MaxSize < 0 || o.Overlap < 0 {
+		return o, fmt.Errorf("%w: 大小不能为负数", ErrInvalidOptions)
+	}
+	if o.TargetSize == 0 {
+		o.TargetSize = o.MaxSize
+	}
+	if o.MaxSize == 0 {
+		o.MaxSize = o.TargetSize
+	}
+	switch {
+	case o.TargetSize == 0:
+		return o, fmt.Errorf("%w: 需要设置 target_size 或 max_size", ErrInvalidOptions)
+	case o.TargetSize < MinSize:
+		return o, fmt.Errorf("%w: 大小不能小于 %d", ErrInvalidOptions, MinSize)
+	case o.TargetSize > o.MaxSize:
+		return o, fmt.Errorf("%w: target_size %d 大于 max_size %d", ErrInvalidOptions, o.TargetSize, o.MaxSize)
+	case o.Overlap >= o.TargetSize:
+		return o, fmt.Errorf("%w: overlap %d 须小于 target_size %d", ErrInvalidOptions, o.Overlap, o.TargetSize)
+	}
+	return o, nil
+}
+
+// Validate 验证分块选项，返回的错误可用 errors.Is(err, ErrInvalidOptions) 判断
+func (o Options) Validate() error {
+	_, err := o.normalize()
+	return err
+}
+
+// Split 将Markdown切分为块，选项无效时返回错误
+func Split(markdown string, opts Options) ([]Chunk, error) {
+	opts, err := opts.normalize()
+	if err != nil {
+		return nil, err
+	}
+	measure := utf8.RuneCountInString
+	if opts.Unit == UnitTokens {
+		measure = opts.Tokenizer.Count
+	}
+	s := &splitter{text: markdown, opts: opts, measure: measure, start: -1}
+	s.blocks = parseBlocks(markdown, measure)
+	s.split()
+	return s.chunks, nil
+}
+
+// block Markdown中以空行分隔的块，标题单独成块
+type block struct {
+	start, end int      // 字节范围，不含末尾换行
+	level      int      // 标题级别，非标题为0
+	atomic     bool     // 标题、代码块或表格，不能拆分
+	path       []string // 所在的标题路径，标题块包括其本身
+	size       int      // 按计量单位的大小
+}
+
+// splitter 分块状态
+type splitter struct {
+	text    string
+	opts    Options
+	measure func(string) int
+	blocks  []block
+	chunks  []Chunk
+
+	// 当前块
+	start, end int      // 字节范围，start为-1表示当前块为空
+	size       int      // 大小，按组成部分累加
+	own        bool     // 是否包含重叠部分之外的内容
+	body       bool     // 是否包含标题之外的内容
+	path       []string // 各组成部分标题路径的公共前缀
+}
+
+// split 按小节和大小切分全部块
+func (s *splitter) split() {
+	for i, b := range s.blocks {
+		// 标题开始新的小节，当前块容纳不下整个小节时在标题前结束
+		if b.level > 0 && s.own && s.size+s.sectionSize(i) > s.opts.TargetSize {
+			s.flush()
+		}
+		queue := s.pieces(b, s.opts.MaxSize)
+		for len(queue) > 0 {
+			piece := queue[0]
+			queue = queue[1:]
+			// 只有标题时尽量与随后的内容放在同一块，容纳不下时拆分内容，避免标题单独成块
+			limit := s.opts.TargetSize
+			if !s.body {
+				limit = s.opts.MaxSize
+				if room := limit - s.size; s.own && piece.size > room && room > 0 {
+					if parts := s.pieces(piece, room); len(parts) > 1 {
+						piece, queue = parts[0], append(parts[1:], queue...)
+					}
+				}
+			}
+			if s.own && s.size+piece.size > limit {
+				s.flush()
+				if b.level == 0 {
+					s.overlap(piece)
+				}
+			}
+			s.add(piece)
+		}
+	}
+	s.flush()
+}
+
+// sectionSize 从第i块开始到下一个标题之前的小节大小
+func (s *splitter) sectionSize(i int) int {
+	size := s.blocks[i].size
+	for _, b := range s.blocks[i+1:] {
+		if b.level > 0 {
+			break
+		}
+		size += b.size
+	}
+	return size
+}
+
+// add 将块的一部分加入当前块
+func (s *splitter) add(b block) {
+	if s.start < 0 {
+		s.start, s.path = b.start, b.path
+	} else {
+		s.path = commonPrefix(s.path, b.path)
+	}
+	s.end = b.end
+	s.size += b.size
+	s.own = true
+	s.body = s.body || b.level == 0
+}
+
+// flush 结束当前块
+func (s *splitter) flush() {
+	if s.start < 0 || !s.own {
+		return
+	}
+	// 在行或词的边界拆分时片段末尾可能带有空白
+	s.end = s.start + len(strings.TrimRightFunc(s.text[s.start:s.end], unicode.IsSpace))
+	content := s.text[s.start:s.end]
+	s.chunks = append(s.chunks, Chunk{
+		Index:    len(s.chunks),
+		Headings: append([]string{}, s.path...),
+		Content:  content,
+		Start:    s.start,
+		End:      s.end,
+		Bytes:    len(content),
+		Chars:    utf8.RuneCountInString(content),
+		Tokens:   s.opts.Tokenizer.Count(content),
+	})
+	s.start, s.size, s.own, s.body = -1, 0, false, false
+}
+
+// overlap 以上一块末尾的内容作为新块的开头，重叠部分不超过overlap且加上next后不超过最大大小
+//
+// 重叠部分从行首或空白之后开始，不从标题、代码块或表格的中间开始。
+func (s *splitter) overlap(next block) {
+	budget := min(s.opts.Overlap, s.opts.MaxSize-next.size)
+	if budget <= 0 || len(s.chunks) == 0 {
+		return
+	}
+	last := s.chunks[len(s.chunks)-1]
+	var candidates []int
+	for p := last.Start + 1; p < last.End; p++ {
+		if isBoundary(s.text, p) && !s.insideAtomic(p) {
+			candidates = append(candidates, p)
+		}
+	}
+	// 起点越靠后重叠部分越小，取满足大小限制的最靠前的起点
+	i := sort.Search(len(candidates), func(i int) bool {
+		return s.measure(s.text[candidates[i]:last.End]) <= budget
+	})
+	if i == len(candidates) {
+		return
+	}
+	start := candidates[i]
+	s.start, s.end, s.path = start, last.End, last.Headings
+	s.size = s.measure(s.text[start:last.End])
+}
+
+// isBoundary 判断位置是否位于行首或空白之后的非空白字符
+func isBoundary(text string, p int) bool {
+	if !utf8.RuneStart(text[p]) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(text[p:])
+	prev, _ := utf8.DecodeLastRuneInString(text[:p])
+	return !unicode.IsSpace(r) && unicode.IsSpace(prev)
+}
+
+// insideAtomic 判断位置是否位于标题、代码块或表格的内部
+func (s *splitter) insideAtomic(p int) bool {
+	i := sort.Search(len(s.blocks), func(i int) bool { return s.blocks[i].end > p })
+	return i < len(s.blocks) && s.blocks[i].atomic && s.blocks[i].start < p
+}
+
+// pieces 将超过limit的块依次在行、词和字符的边界拆分，代码块和表格保持完整
+func (s *splitter) pieces(b block, limit int) []block {
+	if b.atomic || b.size <= limit {
+		return []block{b}
+	}
+	var segments []block
+	for _, seg := range s.segments(b.start, b.end, "\n") {
+		if seg.size <= limit {
+			segments = append(segments, seg)
+			continue
+		}
+		for _, word := range s.segments(seg.start, seg.end, " ") {
+			if word.size <= limit {
+				segments = append(segments, word)
+			} else {
+				segments = append(segments, s.runeSegments(word, limit)...)
+			}
+		}
+	}
+
+	// 合并相邻的片段，每部分不超过limit，并为下一块开头的重叠部分留出空间
+	merge := min(s.opts.TargetSize-s.opts.Overlap, limit)
+	var pieces []block
+	for _, seg := range segments {
+		seg.path = b.path
+		if n := len(pieces); n > 0 && pieces[n-1].size+seg.size <= merge {
+			pieces[n-1].end = seg.end
+			pieces[n-1].size += seg.size
+			continue
+		}
+		pieces = append(pieces, seg)
+	}
+	return pieces
+}
+
+// segments 将 [start, end) 在sep之后拆分为片段
+func (s *splitter) segments(start, end int, sep string) []block {
+	var segs []block
+	for start < end {
+		i := strings.Index(s.text[start:end], sep)
+		next := end
+		if i >= 0 {
+			next = start + i + len(sep)
+		}
+		segs = append(segs, block{start: start, end: next, size: s.measure(s.text[start:next])})
+		start = next
+	}
+	return segs
+}
+
+// runeSegments 将没有空白的长片段按字符拆分为不超过limit的片段
+func (s *splitter) runeSegments(b block, limit int) []block {
+	var segs []block
+	for start := b.start; start < b.end; {
+		// 先倍增确定查找范围，再二分查找不超过limit的最长前缀，至少包含一个字符
+		window := min(limit, b.end-start)
+		for window < b.end-start && s.measure(s.text[start:runeAlign(s.text, start+window)]) <= limit {
+			window = min(window*2, b.end-start)
+		}
+		n := sort.Search(window, func(i int) bool {
+			return s.measure(s.text[start:runeAlign(s.text, start+i+1)]) > limit
+		})
+		end := runeAlign(s.text, start+n)
+		if end <= start {
+			_, width := utf8.DecodeRuneInString(s.text[start:])
+			end = start + width
+		}
+		segs = append(segs, block{start: start, end: end, size: s.measure(s.text[start:end])})
+		start = end
+	}
+	return segs
+}
+
+// runeAlign 将字节位置向前调整到字符的起始位置
+func runeAlign(text string, p int) int {
+	for p < len(text) && p > 0 && !utf8.RuneStart(text[p]) {
+		p--
+	}
+	return p
+}
+
+// commonPrefix 获取两个标题路径的公共前缀
+func commonPrefix(a, b []string) []string {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return a[:n]
+}
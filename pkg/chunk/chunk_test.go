@@ -0,0 +1,207 @@
+package chunk
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/relaxcloud-cn/html2md/pkg/tokenizer"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"target only", Options{TargetSize: 100}, false},
+		{"max only", Options{MaxSize: 100}, false},
+		{"tokens", Options{Unit: UnitTokens, TargetSize: 100, MaxSize: 200, Overlap: 20}, false},
+		{"minimum size", Options{TargetSize: MinSize}, false},
+		{"unknown unit", Options{Unit: "words", TargetSize: 100}, true},
+		{"no size", Options{}, true},
+		{"negative", Options{TargetSize: 100, Overlap: -1}, true},
+		{"below minimum", Options{TargetSize: MinSize - 1}, true},
+		{"target above max", Options{TargetSize: 200, MaxSize: 100}, true},
+		{"overlap equals target", Options{TargetSize: 100, Overlap: 100}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Validate() error = %v, want ErrInvalidOptions", err)
+			}
+			if _, err := Split("text", tt.opts); (err != nil) != tt.wantErr {
+				t.Errorf("Split() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSplitSections(t *testing.T) {
+	md := "# Guide\n\nIntro.\n\n## Install\n\nRun the installer.\n\n## Usage\n\nCall the API.\n\n### Flags\n\nUse -v."
+	tests := []struct {
+		name   string
+		target int
+		want   []Chunk
+	}{
+		{
+			name:   "small document in one chunk",
+			target: 1000,
+			want: []Chunk{
+				{Headings: []string{"Guide"}, Content: md},
+			},
+		},
+		{
+			name:   "sections merged up to target",
+			target: 50,
+			want: []Chunk{
+				{Headings: []string{"Guide"}, Content: "# Guide\n\nIntro.\n\n## Install\n\nRun the installer."},
+				{Headings: []string{"Guide", "Usage"}, Content: "## Usage\n\nCall the API.\n\n### Flags\n\nUse -v."},
+			},
+		},
+		{
+			name:   "one chunk per section",
+			target: 32,
+			want: []Chunk{
+				{Headings: []string{"Guide"}, Content: "# Guide\n\nIntro."},
+				{Headings: []string{"Guide", "Install"}, Content: "## Install\n\nRun the installer."},
+				{Headings: []string{"Guide", "Usage"}, Content: "## Usage\n\nCall the API."},
+				{Headings: []string{"Guide", "Usage", "Flags"}, Content: "### Flags\n\nUse -v."},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := Split(md, Options{TargetSize: tt.target})
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkChunks(t, md, chunks)
+			if len(chunks) != len(tt.want) {
+				t.Fatalf("got %d chunks, want %d: %+v", len(chunks), len(tt.want), chunks)
+			}
+			for i, c := range chunks {
+				if c.Content != tt.want[i].Content || !reflect.DeepEqual(c.Headings, tt.want[i].Headings) {
+					t.Errorf("chunk %d = %q %q, want %q %q", i, c.Headings, c.Content, tt.want[i].Headings, tt.want[i].Content)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitLimits(t *testing.T) {
+	paragraph := strings.TrimSpace(strings.Repeat("lorem ipsum dolor sit amet ", 20))
+	code := "```go\n" + strings.Repeat("fmt.Println(\"x\")\n", 20) + "```"
+	table := "| a | b |\n|---|---|\n" + strings.Repeat("| 1 | 2 |\n", 20)
+	tests := []struct {
+		name    string
+		md      string
+		opts    Options
+		atomic  string // 须完整出现在某一块中的内容
+		overlap bool
+	}{
+		{name: "long paragraph split at words", md: "# T\n\n" + paragraph, opts: Options{TargetSize: 100, MaxSize: 120}},
+		{name: "long word split at characters", md: strings.Repeat("x", 300), opts: Options{TargetSize: 64}},
+		{name: "multibyte text", md: strings.Repeat("中文内容", 60), opts: Options{TargetSize: 50}},
+		{name: "lines", md: strings.Repeat("line of text\n", 30), opts: Options{TargetSize: 64}},
+		{name: "code block kept whole", md: "intro\n\n" + code + "\n\nafter", opts: Options{TargetSize: 64}, atomic: code},
+		{name: "table kept whole", md: "intro\n\n" + strings.TrimSpace(table) + "\n\nafter", opts: Options{TargetSize: 64}, atomic: strings.TrimSpace(table)},
+		{name: "overlap", md: paragraph, opts: Options{TargetSize: 100, Overlap: 30}, overlap: true},
+		{name: "tokens", md: paragraph + "\n\n" + paragraph, opts: Options{Unit: UnitTokens, TargetSize: 40, Overlap: 10}, overlap: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := Split(tt.md, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(chunks) < 2 {
+				t.Fatalf("got %d chunks, want at least 2", len(chunks))
+			}
+			checkChunks(t, tt.md, chunks)
+			max := max(tt.opts.TargetSize, tt.opts.MaxSize)
+			for _, c := range chunks {
+				size := c.Chars
+				if tt.opts.Unit == UnitTokens {
+					size = c.Tokens
+				}
+				if size > max && (tt.atomic == "" || !strings.Contains(c.Content, tt.atomic)) {
+					t.Errorf("chunk %d size %d exceeds %d: %q", c.Index, size, max, c.Content)
+				}
+			}
+			if tt.atomic != "" && !containsChunk(chunks, tt.atomic) {
+				t.Errorf("no chunk contains the whole block %q", tt.atomic)
+			}
+			for i := 1; i < len(chunks); i++ {
+				overlapped := chunks[i].Start < chunks[i-1].End
+				if overlapped != tt.overlap {
+					t.Errorf("chunk %d starts at %d, previous ends at %d, overlap = %v", i, chunks[i].Start, chunks[i-1].End, tt.overlap)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitEmpty(t *testing.T) {
+	for _, md := range []string{"", "\n\n  \n"} {
+		chunks, err := Split(md, Options{TargetSize: 100})
+		if err != nil || len(chunks) != 0 {
+			t.Errorf("Split(%q) = %v, %v, want no chunks", md, chunks, err)
+		}
+	}
+}
+
+func TestSplitFencedHeading(t *testing.T) {
+	md := "# Real\n\n```sh\n# not a heading\n```"
+	chunks, err := Split(md, Options{TargetSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 1 || !reflect.DeepEqual(chunks[0].Headings, []string{"Real"}) {
+		t.Errorf("chunks = %+v, want one chunk under Real", chunks)
+	}
+}
+
+// checkChunks 检查块的序号、偏移和大小与内容一致
+func checkChunks(t *testing.T, md string, chunks []Chunk) {
+	t.Helper()
+	tok, err := tokenizer.Get("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range chunks {
+		if c.Index != i {
+			t.Errorf("chunk %d has index %d", i, c.Index)
+		}
+		if c.Start < 0 || c.End > len(md) || c.Start >= c.End || md[c.Start:c.End] != c.Content {
+			t.Errorf("chunk %d range [%d, %d) does not match content %q", i, c.Start, c.End, c.Content)
+			continue
+		}
+		if c.Bytes != len(c.Content) || c.Chars != utf8.RuneCountInString(c.Content) || c.Tokens != tok.Count(c.Content) {
+			t.Errorf("chunk %d sizes = %d/%d/%d, want %d/%d/%d", i, c.Bytes, c.Chars, c.Tokens,
+				len(c.Content), utf8.RuneCountInString(c.Content), tok.Count(c.Content))
+		}
+		if !utf8.ValidString(c.Content) || c.Content != strings.TrimRight(c.Content, " \n") {
+			t.Errorf("chunk %d content %q is not trimmed valid UTF-8", i, c.Content)
+		}
+		if i > 0 && c.End <= chunks[i-1].End {
+			t.Errorf("chunk %d ends at %d, not after previous %d", i, c.End, chunks[i-1].End)
+		}
+	}
+}
+
+// containsChunk 判断是否有块包含完整的内容
+func containsChunk(chunks []Chunk, s string) bool {
+	for _, c := range chunks {
+		if strings.Contains(c.Content, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package chunk
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// headingLine ATX标题行
+	headingLine = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	// fenceLine 围栏代码块的起止行
+	fenceLine = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+)
+
+// parseBlocks 将Markdown解析为以空行分隔的块，记录每块所在的标题路径
+//
+// 标题单独成块；标题、围栏代码块（可能包含空行）和包含表格行的块标记为不可拆分。measure为nil时不计算大小。
+func parseBlocks(text string, measure func(string) int) []block {
+	var blocks []block
+	var headings []heading
+	cur := block{start: -1}
+	fence := ""
+
+	finish := func() {
+		if cur.start >= 0 {
+			cur.path = headingPath(headings)
+			if measure != nil {
+				cur.size = measure(text[cur.start:cur.end])
+			}
+			blocks = append(blocks, cur)
+		}
+		cur = block{start: -1}
+	}
+
+	for start := 0; start < len(text); {
+		end := strings.IndexByte(text[start:], '\n')
+		next := len(text)
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += start
+			next = end + 1
+		}
+		line := strings.TrimSuffix(text[start:end], "\r")
+
+		switch {
+		case fence != "":
+			// 代码块内的内容原样保留，直到遇到同类且不短于起始标记的结束行
+			cur.end = end
+			if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+				finish()
+			}
+		case strings.TrimSpace(line) == "":
+			finish()
+		case fenceLine.MatchString(line):
+			finish()
+			fence = fenceLine.FindStringSubmatch(line)[1]
+			cur = block{start: start, end: end, atomic: true}
+		case headingLine.MatchString(line):
+			finish()
+			m := headingLine.FindStringSubmatch(line)
+			level := len(m[1])
+			for len(headings) > 0 && headings[len(headings)-1].level >= level {
+				headings = headings[:len(headings)-1]
+			}
+			headings = append(headings, heading{level: level, text: strings.TrimSpace(m[2])})
+			cur = block{start: start, end: end, level: level, atomic: true}
+			finish()
+		default:
+			if cur.start < 0 {
+				cur.start = start
+			}
+			cur.end = end
+			if strings.HasPrefix(strings.TrimSpace(line), "|") {
+				cur.atomic = true
+			}
+		}
+		start = next
+	}
+	finish()
+	return blocks
+}
+
+// heading 标题路径中的一级
+type heading struct {
+	level int
+	text  string
+}
+
+// headingPath 获取标题路径的文本
+func headingPath(headings []heading) []string {
+	path := make([]string, len(headings))
+	for i, h := range headings {
+		path[i] = h.text
+	}
+	return path
+}
@@ -0,0 +1,187 @@
+package chunk
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+)
+
+// 目录的插入位置
+const (
+	TOCTop         = "top"         // 文档开头
+	TOCPlaceholder = "placeholder" // 替换 [TOC] 占位行，没有占位行时插入文档开头
+)
+
+// ErrInvalidTOC 目录的插入位置无效
+var ErrInvalidTOC = errors.New("无效的目录位置")
+
+// tocPlaceholder 目录占位行，转换时方括号可能被转义
+var tocPlaceholder = regexp.MustCompile(`(?i)^ {0,3}\\?\[toc\\?\][ \t]*$`)
+
+// asciiPunct Markdown中可用反斜杠转义的字符
+const asciiPunct = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// Heading 文档大纲中的标题
+type Heading struct {
+	Level    int       `json:"level" example:"2"`       // 标题级别
+	Text     string    `json:"text" example:"使用Docker"` // 去除Markdown标记的标题文本
+	Slug     string    `json:"slug" example:"使用docker"` // 锚点，规则与GitHub一致，重复的标题依次追加 -1、-2 后缀
+	Offset   int       `json:"offset" example:"128"`    // 标题行在Markdown中的字符偏移
+	Children []Heading `json:"children,omitempty"`      // 下级标题
+}
+
+// Outline 获取Markdown的标题大纲，下级标题嵌套在最近的上级标题中，代码块中的 # 行不视为标题
+func Outline(markdown string) []Heading {
+	headings := flatHeadings(markdown)
+	tree, _ := nestHeadings(headings, 0)
+	return tree
+}
+
+// ValidateTOC 验证目录的插入位置，为空表示不插入
+func ValidateTOC(mode string) error {
+	switch mode {
+	case "", TOCTop, TOCPlaceholder:
+		return nil
+	}
+	return fmt.Errorf("%w: %q，可选: %s, %s", ErrInvalidTOC, mode, TOCTop, TOCPlaceholder)
+}
+
+// InsertTOC 按标题生成目录并插入Markdown，mode为空或文档没有标题时原样返回
+func InsertTOC(markdown, mode string) (string, error) {
+	if err := ValidateTOC(mode); err != nil || mode == "" {
+		return markdown, err
+	}
+	outline := Outline(markdown)
+	if len(outline) == 0 {
+		return markdown, nil
+	}
+	var b strings.Builder
+	writeTOC(&b, outline, 0)
+	toc := strings.TrimSuffix(b.String(), "\n")
+
+	if mode == TOCPlaceholder {
+		var out strings.Builder
+		last := 0
+		for _, blk := range parseBlocks(markdown, nil) {
+			if !blk.atomic && tocPlaceholder.MatchString(markdown[blk.start:blk.end]) {
+				out.WriteString(markdown[last:blk.start])
+				out.WriteString(toc)
+				last = blk.end
+			}
+		}
+		if last > 0 {
+			out.WriteString(markdown[last:])
+			return out.String(), nil
+		}
+	}
+	return toc + "\n\n" + markdown, nil
+}
+
+// writeTOC 将标题写为嵌套列表，每层缩进两个空格
+func writeTOC(b *strings.Builder, headings []Heading, depth int) {
+	for _, h := range headings {
+		fmt.Fprintf(b, "%s- [%s](#%s)\n", strings.Repeat("  ", depth), escapeLinkText(h.Text), h.Slug)
+		writeTOC(b, h.Children, depth+1)
+	}
+}
+
+// flatHeadings 按顺序获取全部标题，同一文档中的锚点唯一
+func flatHeadings(markdown string) []Heading {
+	slugger := converter.NewSlugger()
+	var headings []Heading
+	offset, pos := 0, 0
+	for _, blk := range parseBlocks(markdown, nil) {
+		if blk.level == 0 {
+			continue
+		}
+		offset += utf8.RuneCountInString(markdown[pos:blk.start])
+		pos = blk.start
+		text := plainText(blk.path[len(blk.path)-1])
+		headings = append(headings, Heading{
+			Level:  blk.level,
+			Text:   text,
+			Slug:   slugger.Slug(text),
+			Offset: offset,
+		})
+	}
+	return headings
+}
+
+// nestHeadings 将级别高于parent的连续标题组成树，返回树和未处理的标题
+func nestHeadings(headings []Heading, parent int) ([]Heading, []Heading) {
+	var tree []Heading
+	for len(headings) > 0 && headings[0].Level > parent {
+		h := headings[0]
+		h.Children, headings = nestHeadings(headings[1:], h.Level)
+		tree = append(tree, h)
+	}
+	return tree, headings
+}
+
+// plainText 去除标题中的强调、代码、链接和HTML标记，保留显示的文本
+func plainText(md string) string {
+	var b strings.Builder
+	for i := 0; i < len(md); {
+		c := md[i]
+		switch {
+		case c == '\\' && i+1 < len(md) && strings.IndexByte(asciiPunct, md[i+1]) >= 0:
+			b.WriteByte(md[i+1])
+			i += 2
+		case c == '`':
+			n := len(md[i:]) - len(strings.TrimLeft(md[i:], "`"))
+			fence := md[i : i+n]
+			if end := strings.Index(md[i+n:], fence); end >= 0 {
+				b.WriteString(strings.TrimSpace(md[i+n : i+n+end]))
+				i += n + end + n
+			} else {
+				b.WriteString(fence)
+				i += n
+			}
+		case c == '*' || c == '~' || c == '!' && strings.HasPrefix(md[i+1:], "["):
+			i++
+		case c == '_' && (i == 0 || i+1 == len(md) || !isWordByte(md, i-1) || !isWordByte(md, i+1)):
+			i++
+		case c == '[':
+			// 链接和图片保留文本，去除地址
+			text, rest, ok := strings.Cut(md[i+1:], "](")
+			if end := strings.IndexByte(rest, ')'); ok && end >= 0 {
+				b.WriteString(plainText(text))
+				i = len(md) - len(rest) + end + 1
+			} else {
+				b.WriteByte(c)
+				i++
+			}
+		case c == '<':
+			end := strings.IndexByte(md[i:], '>')
+			if end < 0 {
+				b.WriteByte(c)
+				i++
+				break
+			}
+			// 自动链接保留地址，HTML标签整体去除
+			if inner := md[i+1 : i+end]; strings.Contains(inner, ":") && !strings.ContainsAny(inner, " \t") {
+				b.WriteString(inner)
+			}
+			i += end + 1
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// isWordByte 判断位置i的字符是否为字母或数字，多字节字符视为文字
+func isWordByte(s string, i int) bool {
+	c := s[i]
+	return c >= utf8.RuneSelf || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// escapeLinkText 转义链接文本中的方括号和反斜杠
+func escapeLinkText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(s)
+}
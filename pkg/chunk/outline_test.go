@@ -0,0 +1,98 @@
+package chunk
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOutline(t *testing.T) {
+	md := "# Guide\n\nintro\n\n## Install\n\n### On `Linux`\n\n```sh\n# not a heading\n```\n\n## Install\n\n# 附录\n\n#### Deep"
+	want := []Heading{
+		{Level: 1, Text: "Guide", Slug: "guide", Offset: 0, Children: []Heading{
+			{Level: 2, Text: "Install", Slug: "install", Offset: 16, Children: []Heading{
+				{Level: 3, Text: "On Linux", Slug: "on-linux", Offset: 28},
+			}},
+			{Level: 2, Text: "Install", Slug: "install-1", Offset: 71},
+		}},
+		{Level: 1, Text: "附录", Slug: "附录", Offset: 83, Children: []Heading{
+			{Level: 4, Text: "Deep", Slug: "deep", Offset: 89},
+		}},
+	}
+	if got := Outline(md); !reflect.DeepEqual(got, want) {
+		t.Errorf("Outline() = %+v, want %+v", got, want)
+	}
+	if got := Outline("no headings\n\n```\n# code\n```"); got != nil {
+		t.Errorf("Outline() = %+v, want nil", got)
+	}
+}
+
+func TestOutlineStartsBelowTop(t *testing.T) {
+	got := Outline("### Three\n\n## Two\n\n### Three again")
+	if len(got) != 2 || got[0].Level != 3 || got[1].Level != 2 || len(got[1].Children) != 1 {
+		t.Errorf("Outline() = %+v, want sibling roots with nested child", got)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		md   string
+		want string
+	}{
+		{"Plain", "Plain"},
+		{"**Bold** and *em*", "Bold and em"},
+		{"~~old~~ new", "old new"},
+		{"Use `go test`", "Use go test"},
+		{"``a ` b``", "a ` b"},
+		{"[Link](https://example.com) text", "Link text"},
+		{"![Logo](logo.png) Brand", "Logo Brand"},
+		{"<https://example.com>", "https://example.com"},
+		{"Tag <span>inside</span>", "Tag inside"},
+		{`1\. Escaped \*stars\*`, "1. Escaped *stars*"},
+		{"snake_case_name", "snake_case_name"},
+		{"_under_ score", "under score"},
+		{"Unclosed [bracket", "Unclosed [bracket"},
+	}
+	for _, tt := range tests {
+		if got := plainText(tt.md); got != tt.want {
+			t.Errorf("plainText(%q) = %q, want %q", tt.md, got, tt.want)
+		}
+	}
+}
+
+func TestInsertTOC(t *testing.T) {
+	md := "# Guide\n\n## Install\n\n## Use [API]"
+	toc := "- [Guide](#guide)\n  - [Install](#install)\n  - [Use \\[API\\]](#use-api)"
+	tests := []struct {
+		name string
+		md   string
+		mode string
+		want string
+	}{
+		{"disabled", md, "", md},
+		{"top", md, TOCTop, toc + "\n\n" + md},
+		{"placeholder", "Intro\n\n[TOC]\n\n" + md, TOCPlaceholder, "Intro\n\n" + toc + "\n\n" + md},
+		{"escaped placeholder", "\\[toc\\]\n\n" + md, TOCPlaceholder, toc + "\n\n" + md},
+		{"missing placeholder", md, TOCPlaceholder, toc + "\n\n" + md},
+		{"placeholder in code", "```\n[TOC]\n```\n\n" + md, TOCPlaceholder, toc + "\n\n```\n[TOC]\n```\n\n" + md},
+		{"no headings", "just text", TOCTop, "just text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InsertTOC(tt.md, tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("InsertTOC() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := InsertTOC(md, "bottom"); !errors.Is(err, ErrInvalidTOC) {
+		t.Errorf("InsertTOC(bottom) error = %v, want ErrInvalidTOC", err)
+	}
+	if err := ValidateTOC(TOCTop); err != nil {
+		t.Errorf("ValidateTOC(top) = %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package chunk
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/relaxcloud-cn/html2md/pkg/tokenizer"
+)
+
+// TruncationMarker 截断位置追加的标记
+const TruncationMarker = "<!-- truncated -->"
+
+// Truncate 在块边界截断Markdown，使保留的内容加上截断标记后不超过maxTokens个token
+//
+// 返回截断后的Markdown和保留的原文字节数，未超出限制时原样返回。代码块和表格不会被截断，
+// 保留内容末尾的标题一并截去；第一段内容就超出限制时在其词的边界截断，仍容纳不下时只返回截断标记，
+// 截断标记也超出限制时返回空字符串。tok为nil时使用默认分词器。
+func Truncate(markdown string, maxTokens int, tok tokenizer.Tokenizer) (string, int) {
+	if tok == nil {
+		tok = tokenizer.Default()
+	}
+	if tok.Count(markdown) <= maxTokens {
+		return markdown, len(markdown)
+	}
+	fits := func(end int) bool {
+		return tok.Count(withMarker(markdown[:end])) <= maxTokens
+	}
+
+	// 保留的块越多token数越多，查找第一个超出限制的块数
+	blocks := parseBlocks(markdown, nil)
+	keep := sort.Search(len(blocks), func(i int) bool {
+		return !fits(blocks[i].end)
+	})
+	// 末尾的标题没有对应的内容
+	for keep > 0 && blocks[keep-1].level > 0 {
+		keep--
+	}
+	if keep > 0 {
+		end := blocks[keep-1].end
+		return withMarker(markdown[:end]), end
+	}
+
+	// 开头的标题和第一段内容都容纳不下时，在第一段内容的词边界截断
+	first := 0
+	for first < len(blocks) && blocks[first].level > 0 {
+		first++
+	}
+	if first < len(blocks) && !blocks[first].atomic {
+		b := blocks[first]
+		var ends []int
+		for p := b.start + 1; p < b.end; p++ {
+			if isBoundary(markdown, p) {
+				ends = append(ends, len(strings.TrimRightFunc(markdown[:p], unicode.IsSpace)))
+			}
+		}
+		i := sort.Search(len(ends), func(i int) bool { return !fits(ends[i]) })
+		if i > 0 {
+			end := ends[i-1]
+			return withMarker(markdown[:end]), end
+		}
+	}
+	if fits(0) {
+		return TruncationMarker, 0
+	}
+	return "", 0
+}
+
+// withMarker 在保留的内容后追加截断标记
+func withMarker(kept string) string {
+	if kept == "" {
+		return TruncationMarker
+	}
+	return kept + "\n\n" + TruncationMarker
+}
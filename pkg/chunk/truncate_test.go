@@ -0,0 +1,46 @@
+package chunk
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/relaxcloud-cn/html2md/pkg/tokenizer"
+)
+
+func TestTruncate(t *testing.T) {
+	tok, err := tokenizer.Get(tokenizer.EstimateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := "three four five six seven eight nine ten eleven twelve"
+	mark := tok.Count(TruncationMarker)
+	code := "```\n" + strings.Repeat("x = 1\n", 10) + "```"
+	tests := []struct {
+		name      string
+		md        string
+		maxTokens int
+		want      string
+	}{
+		{"within limit", "# T\n\nshort text", 100, "# T\n\nshort text"},
+		{"block boundary", "# T\n\none two\n\n" + words, mark + 4, "# T\n\none two\n\n" + TruncationMarker},
+		{"trailing heading dropped", "one two\n\n## Next\n\n" + words, mark + 5, "one two\n\n" + TruncationMarker},
+		{"first paragraph at word boundary", "one two " + words + " " + words, mark + 5, "one two three four five\n\n" + TruncationMarker},
+		{"code block not cut", "intro\n\n" + code, mark + 10, "intro\n\n" + TruncationMarker},
+		{"only marker", code, mark, TruncationMarker},
+		{"nothing fits", "one two three", 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, kept := Truncate(tt.md, tt.maxTokens, tok)
+			if got != tt.want {
+				t.Errorf("Truncate() = %q, want %q", got, tt.want)
+			}
+			if n := tok.Count(got); n > tt.maxTokens {
+				t.Errorf("result has %d tokens, limit %d", n, tt.maxTokens)
+			}
+			if kept > len(tt.md) || !strings.HasPrefix(got, tt.md[:kept]) {
+				t.Errorf("kept = %d does not match result %q", kept, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+// Package client HTML转Markdown服务的Go客户端
+//
+// 提供统一的 Client 接口以及HTTP（NewHTTPClient）和GRPC（NewGRPCClient）两种实现：
+// 自动解析HTTP接口的 {code,msg,data} 响应包装并将错误转为 *Error，
+// 对429和5xx错误按退避策略重试，批量转换超过单次上限时自动分批。
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"time"
+)
+
+// Client HTML转Markdown服务客户端
+//
+// 转换方法的opts为nil时使用服务端的默认设置。
+type Client interface {
+	// Convert 转换单个HTML
+	Convert(ctx context.Context, html string, opts *ConvertOptions) (*Result, error)
+
+	// ConvertURL 由服务端抓取页面并转换，相对链接按页面地址补全
+	ConvertURL(ctx context.Context, url string, opts *ConvertOptions) (*Result, error)
+
+	// ConvertBatch 批量转换，数量超过批量上限时自动分批请求并合并结果
+	ConvertBatch(ctx context.Context, htmls []string, opts *ConvertOptions) (*BatchResult, error)
+
+	// ConvertStream 流式转换，按输入顺序逐条返回结果
+	//
+	// GRPC客户端使用双向流，HTTP客户端按批量上限分批请求。
+	// 单条转换失败时 BatchItem.Success 为false；请求失败时返回错误并结束迭代。
+	ConvertStream(ctx context.Context, htmls iter.Seq[string], opts *ConvertOptions) iter.Seq2[BatchItem, error]
+
+	// Health 健康检查
+	Health(ctx context.Context) (*Health, error)
+
+	// Info 获取转换器信息
+	Info(ctx context.Context) (*Info, error)
+
+	// Close 释放连接
+	Close() error
+}
+
+// Result 转换结果
+type Result struct {
+	Markdown  string     `json:"markdown"`            // 转换后的Markdown内容
+	Stats     *Stats     `json:"stats,omitempty"`     // 转换统计信息
+	Chunks    []Chunk    `json:"chunks,omitempty"`    // 按 ConvertOptions.Chunking 切分的块
+	Outline   []Heading  `json:"outline,omitempty"`   // 标题大纲，偏移相对于返回的Markdown
+	Inventory *Inventory `json:"inventory,omitempty"` // 链接和图片清单，设置 ConvertOptions.Inventory 时返回
+}
+
+// Stats 转换统计信息
+type Stats struct {
+	InputSize       int           `json:"input_size"`                 // 输入HTML大小（字节）
+	OutputSize      int           `json:"output_size"`                // 输出Markdown大小（字节）
+	ProcessingTime  time.Duration `json:"processing_time"`            // 处理时间
+	Tokens          int           `json:"tokens"`                     // 输出Markdown的token数
+	Tokenizer       string        `json:"tokenizer"`                  // 计算token数的分词器
+	Truncated       bool          `json:"truncated,omitempty"`        // 是否因 MaxTokens 被截断
+	TruncatedBytes  int           `json:"truncated_bytes,omitempty"`  // 截去的字节数
+	TruncatedTokens int           `json:"truncated_tokens,omitempty"` // 截去部分的token数
+	Links           int           `json:"links"`                      // 链接数
+	Images          int           `json:"images"`                     // 图片数
+
+	Words              int     `json:"words"`               // 单词数，中日文每个字计为一个单词
+	Chars              int     `json:"chars"`               // 字符数，不含空白和Markdown标记
+	ReadingSeconds     int     `json:"reading_seconds"`     // 预计阅读时间（秒）
+	Paragraphs         int     `json:"paragraphs"`          // 段落数
+	Headings           int     `json:"headings"`            // 标题数
+	CodeBlocks         int     `json:"code_blocks"`         // 代码块数
+	Language           string  `json:"language"`            // 正文的语言（ISO 639-1）
+	LanguageConfidence float64 `json:"language_confidence"` // 语言识别的置信度
+}
+
+// Chunk Markdown中的一块
+type Chunk struct {
+	Index    int      `json:"index"`    // 序号
+	Headings []string `json:"headings"` // 所在的标题路径，由上级到下级
+	Content  string   `json:"content"`  // 内容
+	Start    int      `json:"start"`    // 在Markdown中的起始字节偏移，包括重叠部分
+	End      int      `json:"end"`      // 在Markdown中的结束字节偏移（不含）
+	Bytes    int      `json:"bytes"`    // 字节数
+	Chars    int      `json:"chars"`    // 字符数
+	Tokens   int      `json:"tokens"`   // token数
+}
+
+// Heading 标题大纲中的标题
+type Heading struct {
+	Level    int       `json:"level"`              // 标题级别
+	Text     string    `json:"text"`               // 去除Markdown标记的标题文本
+	Slug     string    `json:"slug"`               // 锚点
+	Offset   int       `json:"offset"`             // 标题行在Markdown中的字符偏移
+	Children []Heading `json:"children,omitempty"` // 下级标题
+}
+
+// Inventory 链接和图片清单，按在文档中出现的顺序排列
+type Inventory struct {
+	Links  []Link  `json:"links"`  // 链接
+	Images []Image `json:"images"` // 图片
+}
+
+// Link 转换结果中的链接
+type Link struct {
+	Text     string `json:"text"`               // 链接文本
+	URL      string `json:"url"`                // 链接地址
+	Title    string `json:"title,omitempty"`    // title属性
+	Internal bool   `json:"internal"`           // 是否为站内链接
+	Fragment string `json:"fragment,omitempty"` // 地址中 # 之后的部分
+}
+
+// Image 转换结果中的图片
+type Image struct {
+	Alt     string `json:"alt"`              // 替代文本
+	Src     string `json:"src"`              // 图片地址
+	Title   string `json:"title,omitempty"`  // title属性
+	Width   int    `json:"width,omitempty"`  // 像素宽度，未设置时为0
+	Height  int    `json:"height,omitempty"` // 像素高度，未设置时为0
+	DataURI bool   `json:"data_uri"`         // 是否为内嵌的data URI
+}
+
+// BatchItem 批量转换中单项的结果
+type BatchItem struct {
+	Index   int     `json:"index"`            // 在输入中的序号
+	Success bool    `json:"success"`          // 是否成功
+	Result  *Result `json:"result,omitempty"` // 转换结果（成功时）
+	Error   string  `json:"error,omitempty"`  // 错误信息（失败时）
+}
+
+// BatchResult 批量转换结果
+type BatchResult struct {
+	Results []BatchItem  `json:"results"` // 按输入顺序排列的结果
+	Summary BatchSummary `json:"summary"` // 汇总信息
+}
+
+// BatchSummary 批量转换摘要
+type BatchSummary struct {
+	Total       int           `json:"total"`        // 总数
+	Success     int           `json:"success"`      // 成功数
+	Failed      int           `json:"failed"`       // 失败数
+	TotalTime   time.Duration `json:"total_time"`   // 服务端总处理时间
+	AverageTime time.Duration `json:"average_time"` // 平均处理时间
+}
+
+// Health 服务健康状态
+type Health struct {
+	Status    string    `json:"status"`    // 服务状态
+	Timestamp time.Time `json:"timestamp"` // 检查时间
+	Version   string    `json:"version"`   // 服务版本
+	Uptime    string    `json:"uptime"`    // 运行时间
+}
+
+// Info 转换器信息
+type Info struct {
+	Version          string   `json:"version"`           // 转换器版本
+	SupportedPlugins []string `json:"supported_plugins"` // 支持的插件
+	Features         []string `json:"features"`          // 功能特性
+}
+
+// Error 服务端返回的错误
+//
+// Code 与HTTP接口的业务响应码一致（如400、401、429、500），GRPC状态码会映射为对应的值。
+type Error struct {
+	Code       int           // 响应码
+	Message    string        // 错误信息
+	RetryAfter time.Duration // 服务端建议的重试等待时间，未提供时为0
+}
+
+// Error 实现error接口
+func (e *Error) Error() string {
+	return fmt.Sprintf("html2md: %d %s", e.Code, e.Message)
+}
+
+// Temporary 判断错误是否可以重试（429和5xx）
+func (e *Error) Temporary() bool {
+	return e.Code == http.StatusTooManyRequests || e.Code >= http.StatusInternalServerError
+}
+
+// StatusCode 获取错误的响应码，不是服务端错误时返回0
+func StatusCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return 0
+}
+
+// mergeBatch 合并分批请求的结果，offset为该批首项在全部输入中的序号
+func mergeBatch(dst *BatchResult, part *BatchResult, offset int) {
+	for _, item := range part.Results {
+		item.Index += offset
+		dst.Results = append(dst.Results, item)
+	}
+	dst.Summary.Total += part.Summary.Total
+	dst.Summary.Success += part.Summary.Success
+	dst.Summary.Failed += part.Summary.Failed
+	dst.Summary.TotalTime += part.Summary.TotalTime
+	if dst.Summary.Total > 0 {
+		dst.Summary.AverageTime = dst.Summary.TotalTime / time.Duration(dst.Summary.Total)
+	}
+}
+
+// convertBatch 按批量上限分批调用convert并合并结果
+func convertBatch(ctx context.Context, htmls []string, size int, opts *ConvertOptions, convert func(context.Context, []string, *ConvertOptions) (*BatchResult, error)) (*BatchResult, error) {
+	result := &BatchResult{Results: make([]BatchItem, 0, len(htmls))}
+	for start := 0; start < len(htmls); start += size {
+		end := min(start+size, len(htmls))
+		part, err := convert(ctx, htmls[start:end], opts)
+		if err != nil {
+			return nil, fmt.Errorf("第%d-%d项转换失败: %w", start, end-1, err)
+		}
+		mergeBatch(result, part, start)
+	}
+	return result, nil
+}
@@ -0,0 +1,337 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/relaxcloud-cn/html2md/api/grpc/proto"
+)
+
+// GRPCClient 基于GRPC接口的客户端
+type GRPCClient struct {
+	conn   *grpc.ClientConn
+	client pb.ConvertServiceClient
+	opts   *options
+}
+
+var _ Client = (*GRPCClient)(nil)
+
+// NewGRPCClient 创建GRPC客户端，target如 localhost:9090
+//
+// 默认使用明文连接，可通过 WithDialOptions(grpc.WithTransportCredentials(...)) 启用TLS。
+func NewGRPCClient(target string, opts ...Option) (*GRPCClient, error) {
+	o := newOptions(opts)
+
+	dialOptions := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, o.dialOptions...)
+	conn, err := grpc.NewClient(target, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("创建GRPC连接失败: %w", err)
+	}
+
+	return &GRPCClient{
+		conn:   conn,
+		client: pb.NewConvertServiceClient(conn),
+		opts:   o,
+	}, nil
+}
+
+// Convert 转换单个HTML
+func (c *GRPCClient) Convert(ctx context.Context, html string, opts *ConvertOptions) (*Result, error) {
+	return c.convert(ctx, toPBRequest(html, "", opts))
+}
+
+// ConvertURL 由服务端抓取页面并转换
+func (c *GRPCClient) ConvertURL(ctx context.Context, url string, opts *ConvertOptions) (*Result, error) {
+	return c.convert(ctx, toPBRequest("", url, opts))
+}
+
+// convert 发送单个转换请求
+func (c *GRPCClient) convert(ctx context.Context, req *pb.ConvertRequest) (*Result, error) {
+	var resp *pb.ConvertResponse
+	err := c.call(ctx, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = c.client.Convert(ctx, req, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromPBResult(resp), nil
+}
+
+// ConvertBatch 批量转换，超过批量上限时自动分批
+func (c *GRPCClient) ConvertBatch(ctx context.Context, htmls []string, opts *ConvertOptions) (*BatchResult, error) {
+	return convertBatch(ctx, htmls, c.opts.batchSize, opts, c.convertBatch)
+}
+
+// convertBatch 发送单次批量请求
+func (c *GRPCClient) convertBatch(ctx context.Context, htmls []string, opts *ConvertOptions) (*BatchResult, error) {
+	req := &pb.BatchConvertRequest{Items: make([]*pb.ConvertRequest, len(htmls))}
+	for i, html := range htmls {
+		req.Items[i] = toPBRequest(html, "", opts)
+	}
+
+	var resp *pb.BatchConvertResponse
+	err := c.call(ctx, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = c.client.ConvertBatch(ctx, req, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BatchResult{Results: make([]BatchItem, len(resp.Results))}
+	for i, item := range resp.Results {
+		result.Results[i] = fromPBItem(item)
+	}
+	if s := resp.Summary; s != nil {
+		result.Summary = BatchSummary{
+			Total:       int(s.Total),
+			Success:     int(s.Success),
+			Failed:      int(s.Failed),
+			TotalTime:   s.TotalTime.AsDuration(),
+			AverageTime: s.AverageTime.AsDuration(),
+		}
+	}
+	return result, nil
+}
+
+// ConvertStream 使用双向流逐条转换
+//
+// 流建立后不再重试；中途出错时返回错误并结束迭代。
+func (c *GRPCClient) ConvertStream(ctx context.Context, htmls iter.Seq[string], opts *ConvertOptions) iter.Seq2[BatchItem, error] {
+	return func(yield func(BatchItem, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := c.client.ConvertStream(c.withKey(ctx))
+		if err != nil {
+			yield(BatchItem{}, fromStatus(err, nil))
+			return
+		}
+
+		// 发送与接收并行进行，避免输入较多时双方缓冲区写满互相等待
+		sendErr := make(chan error, 1)
+		go func() {
+			for html := range htmls {
+				if err := stream.Send(toPBRequest(html, "", opts)); err != nil {
+					// 服务端已结束流，具体原因由Recv返回
+					sendErr <- nil
+					return
+				}
+			}
+			sendErr <- stream.CloseSend()
+		}()
+
+		for {
+			item, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				if err := <-sendErr; err != nil {
+					yield(BatchItem{}, err)
+				}
+				return
+			}
+			if err != nil {
+				header, _ := stream.Header()
+				yield(BatchItem{}, fromStatus(err, header))
+				return
+			}
+			if !yield(fromPBItem(item), nil) {
+				return
+			}
+		}
+	}
+}
+
+// Health 健康检查
+func (c *GRPCClient) Health(ctx context.Context) (*Health, error) {
+	var resp *pb.HealthCheckResponse
+	err := c.call(ctx, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = c.client.HealthCheck(ctx, &pb.HealthCheckRequest{}, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Health{
+		Status:    resp.Status,
+		Timestamp: resp.Timestamp.AsTime(),
+		Version:   resp.Version,
+		Uptime:    resp.Uptime,
+	}, nil
+}
+
+// Info 获取转换器信息
+func (c *GRPCClient) Info(ctx context.Context) (*Info, error) {
+	var resp *pb.GetConverterInfoResponse
+	err := c.call(ctx, func(ctx context.Context, opts ...grpc.CallOption) (err error) {
+		resp, err = c.client.GetConverterInfo(ctx, &pb.GetConverterInfoRequest{}, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Info{
+		Version:          resp.Version,
+		SupportedPlugins: resp.SupportedPlugins,
+		Features:         resp.Features,
+	}, nil
+}
+
+// Close 关闭连接
+func (c *GRPCClient) Close() error {
+	return c.conn.Close()
+}
+
+// call 执行一元调用，附带API密钥并按配置重试
+func (c *GRPCClient) call(ctx context.Context, fn func(ctx context.Context, opts ...grpc.CallOption) error) error {
+	return c.opts.retry(ctx, func(ctx context.Context) error {
+		var header, trailer metadata.MD
+		err := fn(c.withKey(ctx), grpc.Header(&header), grpc.Trailer(&trailer))
+		if err != nil {
+			return fromStatus(err, metadata.Join(header, trailer))
+		}
+		return nil
+	})
+}
+
+// withKey 在metadata中附带API密钥
+func (c *GRPCClient) withKey(ctx context.Context) context.Context {
+	if c.opts.apiKey == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "x-api-key", c.opts.apiKey)
+}
+
+// grpcCodes GRPC状态码到响应码的映射
+var grpcCodes = map[codes.Code]int{
+	codes.InvalidArgument:   http.StatusBadRequest,
+	codes.Unauthenticated:   http.StatusUnauthorized,
+	codes.PermissionDenied:  http.StatusForbidden,
+	codes.NotFound:          http.StatusNotFound,
+	codes.ResourceExhausted: http.StatusTooManyRequests,
+	codes.Unimplemented:     http.StatusNotImplemented,
+	codes.Unavailable:       http.StatusServiceUnavailable,
+	codes.DeadlineExceeded:  http.StatusGatewayTimeout,
+}
+
+// fromStatus 将GRPC错误转为 *Error，上下文取消等客户端错误原样返回
+func fromStatus(err error, md metadata.MD) error {
+	s, ok := status.FromError(err)
+	if !ok || s.Code() == codes.Canceled {
+		return err
+	}
+
+	code, ok := grpcCodes[s.Code()]
+	if !ok {
+		code = http.StatusInternalServerError
+	}
+
+	e := &Error{Code: code, Message: s.Message()}
+	if values := md.Get("retry-after"); len(values) > 0 {
+		if seconds, err := strconv.Atoi(values[0]); err == nil && seconds > 0 {
+			e.RetryAfter = time.Duration(seconds) * time.Second
+		}
+	}
+	return e
+}
+
+// fromPBResult 转换protobuf转换结果
+func fromPBResult(resp *pb.ConvertResponse) *Result {
+	result := &Result{Markdown: resp.Markdown}
+	if s := resp.Stats; s != nil {
+		result.Stats = &Stats{
+			InputSize:       int(s.InputSize),
+			OutputSize:      int(s.OutputSize),
+			ProcessingTime:  s.ProcessingTime.AsDuration(),
+			Tokens:          int(s.Tokens),
+			Tokenizer:       s.Tokenizer,
+			Truncated:       s.Truncated,
+			TruncatedBytes:  int(s.TruncatedBytes),
+			TruncatedTokens: int(s.TruncatedTokens),
+			Links:           int(s.Links),
+			Images:          int(s.Images),
+
+			Words:              int(s.Words),
+			Chars:              int(s.Chars),
+			ReadingSeconds:     int(s.ReadingSeconds),
+			Paragraphs:         int(s.Paragraphs),
+			Headings:           int(s.Headings),
+			CodeBlocks:         int(s.CodeBlocks),
+			Language:           s.Language,
+			LanguageConfidence: s.LanguageConfidence,
+		}
+	}
+	for _, c := range resp.Chunks {
+		result.Chunks = append(result.Chunks, Chunk{
+			Index:    int(c.Index),
+			Headings: c.Headings,
+			Content:  c.Content,
+			Start:    int(c.Start),
+			End:      int(c.End),
+			Bytes:    int(c.Bytes),
+			Chars:    int(c.Chars),
+			Tokens:   int(c.Tokens),
+		})
+	}
+	result.Outline = fromPBOutline(resp.Outline)
+	if inv := resp.Inventory; inv != nil {
+		result.Inventory = &Inventory{Links: make([]Link, len(inv.Links)), Images: make([]Image, len(inv.Images))}
+		for i, l := range inv.Links {
+			result.Inventory.Links[i] = Link{Text: l.Text, URL: l.Url, Title: l.Title, Internal: l.Internal, Fragment: l.Fragment}
+		}
+		for i, img := range inv.Images {
+			result.Inventory.Images[i] = Image{
+				Alt:     img.Alt,
+				Src:     img.Src,
+				Title:   img.Title,
+				Width:   int(img.Width),
+				Height:  int(img.Height),
+				DataURI: img.DataUri,
+			}
+		}
+	}
+	return result
+}
+
+// fromPBOutline 转换protobuf标题大纲
+func fromPBOutline(headings []*pb.OutlineHeading) []Heading {
+	if len(headings) == 0 {
+		return nil
+	}
+	result := make([]Heading, len(headings))
+	for i, h := range headings {
+		result[i] = Heading{
+			Level:    int(h.Level),
+			Text:     h.Text,
+			Slug:     h.Slug,
+			Offset:   int(h.Offset),
+			Children: fromPBOutline(h.Children),
+		}
+	}
+	return result
+}
+
+// fromPBItem 转换protobuf批量结果项
+func fromPBItem(item *pb.BatchConvertItem) BatchItem {
+	result := BatchItem{
+		Index:   int(item.Index),
+		Success: item.Success,
+		Error:   item.Error,
+	}
+	if item.Result != nil {
+		result.Result = fromPBResult(item.Result)
+	}
+	return result
+}
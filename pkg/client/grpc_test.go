@@ -0,0 +1,224 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/relaxcloud-cn/html2md/api/grpc/proto"
+)
+
+// fakeConvertServer 记录请求的测试GRPC服务
+type fakeConvertServer struct {
+	pb.UnimplementedConvertServiceServer
+
+	mu       sync.Mutex
+	requests []*pb.ConvertRequest
+	keys     []string
+	err      error
+}
+
+func (s *fakeConvertServer) record(ctx context.Context, req *pb.ConvertRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.keys = append(s.keys, md.Get("x-api-key")...)
+}
+
+func (s *fakeConvertServer) Convert(ctx context.Context, req *pb.ConvertRequest) (*pb.ConvertResponse, error) {
+	s.record(ctx, req)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &pb.ConvertResponse{
+		Markdown: "# A",
+		Stats:    &pb.ConversionStats{Tokens: 2, Truncated: true, TruncatedTokens: 5},
+		Chunks:   []*pb.Chunk{{Index: 0, Headings: []string{"A"}, Content: "# A", End: 3}},
+		Outline: []*pb.OutlineHeading{{Level: 1, Text: "A", Slug: "a", Children: []*pb.OutlineHeading{
+			{Level: 2, Text: "B", Slug: "b", Offset: 4},
+		}}},
+		Inventory: &pb.Inventory{
+			Links:  []*pb.Link{{Text: "x", Url: "/x", Internal: true}},
+			Images: []*pb.Image{{Alt: "y", Src: "data:image/png;base64,", DataUri: true, Width: 10}},
+		},
+	}, nil
+}
+
+func (s *fakeConvertServer) ConvertStream(stream grpc.BidiStreamingServer[pb.ConvertRequest, pb.BatchConvertItem]) error {
+	for i := 0; ; i++ {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.record(stream.Context(), req)
+		item := &pb.BatchConvertItem{Index: int32(i), Success: true, Result: &pb.ConvertResponse{Markdown: req.Html}}
+		if err := stream.Send(item); err != nil {
+			return err
+		}
+	}
+}
+
+// newTestGRPCClient 在内存连接上启动测试服务并创建GRPC客户端
+func newTestGRPCClient(t *testing.T, s *fakeConvertServer) *GRPCClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterConvertServiceServer(srv, s)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}
+	c, err := NewGRPCClient("passthrough:///bufnet",
+		WithAPIKey("secret"),
+		WithRetry(0, 0, 0),
+		WithDialOptions(grpc.WithContextDialer(dialer)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestToPBRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		url  string
+		opts *ConvertOptions
+		want *pb.ConvertRequest
+	}{
+		{
+			name: "nil options",
+			html: "hello",
+			want: &pb.ConvertRequest{Html: "hello"},
+		},
+		{
+			name: "url",
+			url:  "https://example.com/a",
+			opts: &ConvertOptions{TOC: "placeholder"},
+			want: &pb.ConvertRequest{Url: "https://example.com/a", Toc: "placeholder"},
+		},
+		{
+			name: "all options",
+			html: "hello",
+			opts: &ConvertOptions{
+				Plugins:     []string{"table"},
+				Profiles:    []string{"email", "office"},
+				Email:       &EmailOptions{Quotes: "collapse", StripSignature: true},
+				Rules:       []Rule{{Selector: "aside", Action: "template", Template: "> {{.Text}}"}},
+				InferStyles: true,
+				Chunking:    &ChunkOptions{Unit: "tokens", TargetSize: 100, MaxSize: 200, Overlap: 10},
+				Tokenizer:   "o200k_base",
+				MaxTokens:   500,
+				TOC:         "top",
+				Inventory:   true,
+				BaseURL:     "https://example.com/",
+			},
+			want: &pb.ConvertRequest{
+				Html:        "hello",
+				Plugins:     []string{"table"},
+				Profiles:    []string{"email", "office"},
+				Email:       &pb.EmailOptions{Quotes: "collapse", StripSignature: true},
+				Rules:       []*pb.Rule{{Selector: "aside", Action: "template", Template: "> {{.Text}}"}},
+				InferStyles: true,
+				Chunking:    &pb.ChunkingOptions{Unit: "tokens", TargetSize: 100, MaxSize: 200, Overlap: 10},
+				Tokenizer:   "o200k_base",
+				MaxTokens:   500,
+				Toc:         "top",
+				Inventory:   true,
+				BaseUrl:     "https://example.com/",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toPBRequest(tt.html, tt.url, tt.opts)
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("toPBRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGRPCClientConvert(t *testing.T) {
+	s := &fakeConvertServer{}
+	c := newTestGRPCClient(t, s)
+
+	result, err := c.ConvertURL(context.Background(), "https://example.com/a", &ConvertOptions{Inventory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.requests[0]; got.Url != "https://example.com/a" || !got.Inventory {
+		t.Errorf("request = %v", got)
+	}
+	if !slices.Equal(s.keys, []string{"secret"}) {
+		t.Errorf("api keys = %v", s.keys)
+	}
+
+	if result.Markdown != "# A" || !result.Stats.Truncated || result.Stats.TruncatedTokens != 5 {
+		t.Errorf("result = %+v, stats = %+v", result, result.Stats)
+	}
+	if len(result.Chunks) != 1 || result.Chunks[0].End != 3 {
+		t.Errorf("chunks = %+v", result.Chunks)
+	}
+	want := []Heading{{Level: 1, Text: "A", Slug: "a", Children: []Heading{{Level: 2, Text: "B", Slug: "b", Offset: 4}}}}
+	if !reflect.DeepEqual(result.Outline, want) {
+		t.Errorf("outline = %+v, want %+v", result.Outline, want)
+	}
+	inv := result.Inventory
+	if inv == nil || len(inv.Links) != 1 || inv.Links[0].URL != "/x" || len(inv.Images) != 1 || !inv.Images[0].DataURI || inv.Images[0].Width != 10 {
+		t.Errorf("inventory = %+v", inv)
+	}
+}
+
+func TestGRPCClientConvertError(t *testing.T) {
+	s := &fakeConvertServer{err: status.Error(codes.ResourceExhausted, "请求过于频繁")}
+	c := newTestGRPCClient(t, s)
+
+	_, err := c.Convert(context.Background(), "hello", nil)
+	if code := StatusCode(err); code != 429 {
+		t.Errorf("StatusCode(%v) = %d, want 429", err, code)
+	}
+}
+
+func TestGRPCClientConvertStream(t *testing.T) {
+	s := &fakeConvertServer{}
+	c := newTestGRPCClient(t, s)
+	opts := &ConvertOptions{Profiles: []string{"email"}}
+
+	var got []string
+	for item, err := range c.ConvertStream(context.Background(), slices.Values([]string{"a", "b", "c"}), opts) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, item.Result.Markdown)
+	}
+
+	if !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("results = %v", got)
+	}
+	for _, req := range s.requests {
+		if !slices.Equal(req.Profiles, []string{"email"}) {
+			t.Errorf("request profiles = %v", req.Profiles)
+		}
+	}
+}
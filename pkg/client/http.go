@@ -0,0 +1,224 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPClient 基于HTTP接口的客户端
+type HTTPClient struct {
+	baseURL string
+	opts    *options
+	http    *http.Client
+}
+
+var _ Client = (*HTTPClient)(nil)
+
+// NewHTTPClient 创建HTTP客户端，baseURL如 http://localhost:8080
+func NewHTTPClient(baseURL string, opts ...Option) (*HTTPClient, error) {
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		return nil, fmt.Errorf("无效的服务地址: %s", baseURL)
+	}
+
+	o := newOptions(opts)
+	httpClient := o.httpClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+
+	return &HTTPClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		opts:    o,
+		http:    httpClient,
+	}, nil
+}
+
+// Convert 转换单个HTML
+func (c *HTTPClient) Convert(ctx context.Context, html string, opts *ConvertOptions) (*Result, error) {
+	return c.convert(ctx, newConvertRequest(html, "", opts))
+}
+
+// ConvertURL 由服务端抓取页面并转换
+func (c *HTTPClient) ConvertURL(ctx context.Context, url string, opts *ConvertOptions) (*Result, error) {
+	return c.convert(ctx, newConvertRequest("", url, opts))
+}
+
+// convert 发送单个转换请求
+func (c *HTTPClient) convert(ctx context.Context, req convertRequest) (*Result, error) {
+	var result Result
+	if err := c.do(ctx, http.MethodPost, "/api/v1/convert", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ConvertBatch 批量转换，超过批量上限时自动分批
+func (c *HTTPClient) ConvertBatch(ctx context.Context, htmls []string, opts *ConvertOptions) (*BatchResult, error) {
+	return convertBatch(ctx, htmls, c.opts.batchSize, opts, c.convertBatch)
+}
+
+// convertBatch 发送单次批量请求
+func (c *HTTPClient) convertBatch(ctx context.Context, htmls []string, opts *ConvertOptions) (*BatchResult, error) {
+	req := struct {
+		Items []convertRequest `json:"items"`
+	}{Items: make([]convertRequest, len(htmls))}
+	for i, html := range htmls {
+		req.Items[i] = newConvertRequest(html, "", opts)
+	}
+
+	var result BatchResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/convert/batch", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ConvertStream HTTP接口不支持流式调用，按批量上限分批请求后逐条返回
+func (c *HTTPClient) ConvertStream(ctx context.Context, htmls iter.Seq[string], opts *ConvertOptions) iter.Seq2[BatchItem, error] {
+	return func(yield func(BatchItem, error) bool) {
+		offset := 0
+		chunk := make([]string, 0, c.opts.batchSize)
+		flush := func() bool {
+			result, err := c.ConvertBatch(ctx, chunk, opts)
+			if err != nil {
+				yield(BatchItem{}, err)
+				return false
+			}
+			for _, item := range result.Results {
+				item.Index += offset
+				if !yield(item, nil) {
+					return false
+				}
+			}
+			offset += len(chunk)
+			chunk = chunk[:0]
+			return true
+		}
+
+		for html := range htmls {
+			chunk = append(chunk, html)
+			if len(chunk) == c.opts.batchSize && !flush() {
+				return
+			}
+		}
+		if len(chunk) > 0 {
+			flush()
+		}
+	}
+}
+
+// Health 健康检查
+func (c *HTTPClient) Health(ctx context.Context) (*Health, error) {
+	var health Health
+	if err := c.do(ctx, http.MethodGet, "/api/v1/health", nil, &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// Info 获取转换器信息
+func (c *HTTPClient) Info(ctx context.Context) (*Info, error) {
+	var info Info
+	if err := c.do(ctx, http.MethodGet, "/api/v1/info", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Close 关闭空闲连接
+func (c *HTTPClient) Close() error {
+	c.http.CloseIdleConnections()
+	return nil
+}
+
+// envelope 服务端统一响应格式
+type envelope struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// do 发送请求并将响应中的data解析到out，失败时按配置重试
+func (c *HTTPClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("编码请求失败: %w", err)
+		}
+	}
+
+	return c.opts.retry(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.opts.apiKey != "" {
+			req.Header.Set("X-API-Key", c.opts.apiKey)
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("读取响应失败: %w", err)
+		}
+
+		var env envelope
+		if err := json.Unmarshal(data, &env); err != nil || env.Code == 0 {
+			// 非服务端统一格式的响应，如网关返回的错误页面
+			if resp.StatusCode >= http.StatusBadRequest {
+				return &Error{Code: resp.StatusCode, Message: http.StatusText(resp.StatusCode), RetryAfter: retryAfter(resp.Header)}
+			}
+			return fmt.Errorf("无法解析响应: %s", truncate(data, 200))
+		}
+		if env.Code != http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+			code := env.Code
+			if code == http.StatusOK {
+				code = resp.StatusCode
+			}
+			return &Error{Code: code, Message: env.Msg, RetryAfter: retryAfter(resp.Header)}
+		}
+
+		if out == nil {
+			return nil
+		}
+		if err := json.Unmarshal(env.Data, out); err != nil {
+			return fmt.Errorf("解析响应数据失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// retryAfter 解析Retry-After响应头（秒）
+func retryAfter(h http.Header) time.Duration {
+	seconds, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// truncate 截断过长的内容用于错误信息
+func truncate(data []byte, n int) string {
+	if len(data) <= n {
+		return string(data)
+	}
+	return string(data[:n]) + "..."
+}
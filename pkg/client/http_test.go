@@ -0,0 +1,238 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer 记录请求并按handle返回统一格式响应的测试服务
+type fakeServer struct {
+	mu       sync.Mutex
+	requests []map[string]json.RawMessage
+	handle   func(w http.ResponseWriter, path string, body map[string]json.RawMessage) (int, any)
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]json.RawMessage
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&body)
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, body)
+	s.mu.Unlock()
+
+	code, data := s.handle(w, r.URL.Path, body)
+	w.Header().Set("Content-Type", "application/json")
+	if code != http.StatusOK {
+		w.WriteHeader(code)
+	}
+	json.NewEncoder(w).Encode(map[string]any{"code": code, "msg": http.StatusText(code), "data": data})
+}
+
+// newTestHTTPClient 启动测试服务并创建连接到它的HTTP客户端
+func newTestHTTPClient(t *testing.T, s *fakeServer, opts ...Option) *HTTPClient {
+	t.Helper()
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+
+	opts = append([]Option{WithRetry(0, 0, 0)}, opts...)
+	c, err := NewHTTPClient(ts.URL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestHTTPClientConvertOptions(t *testing.T) {
+	s := &fakeServer{handle: func(_ http.ResponseWriter, _ string, _ map[string]json.RawMessage) (int, any) {
+		return http.StatusOK, map[string]any{
+			"markdown":  "# A",
+			"stats":     map[string]any{"tokens": 2, "truncated": true, "truncated_bytes": 10},
+			"chunks":    []map[string]any{{"index": 0, "headings": []string{"A"}, "content": "# A", "end": 3}},
+			"outline":   []map[string]any{{"level": 1, "text": "A", "slug": "a", "children": []map[string]any{{"level": 2, "text": "B", "slug": "b"}}}},
+			"inventory": map[string]any{"links": []map[string]any{{"text": "x", "url": "/x", "internal": true}}, "images": []map[string]any{}},
+		}
+	}}
+	c := newTestHTTPClient(t, s)
+
+	tests := []struct {
+		name string
+		call func() (*Result, error)
+		want map[string]string
+	}{
+		{
+			name: "nil options",
+			call: func() (*Result, error) { return c.Convert(context.Background(), "hello", nil) },
+			want: map[string]string{"html": `"hello"`},
+		},
+		{
+			name: "all options",
+			call: func() (*Result, error) {
+				return c.Convert(context.Background(), "hello", &ConvertOptions{
+					Plugins:     []string{"table"},
+					Profiles:    []string{"email"},
+					Email:       &EmailOptions{Quotes: "strip", StripSignature: true},
+					Rules:       []Rule{{Selector: ".ad", Action: "drop"}},
+					InferStyles: true,
+					Chunking:    &ChunkOptions{Unit: "tokens", TargetSize: 100},
+					Tokenizer:   "cl100k_base",
+					MaxTokens:   50,
+					TOC:         "top",
+					Inventory:   true,
+					BaseURL:     "https://example.com/",
+				})
+			},
+			want: map[string]string{
+				"html":         `"hello"`,
+				"plugins":      `["table"]`,
+				"profiles":     `["email"]`,
+				"email":        `{"quotes":"strip","strip_signature":true}`,
+				"rules":        `[{"selector":".ad","action":"drop"}]`,
+				"infer_styles": `true`,
+				"chunking":     `{"unit":"tokens","target_size":100}`,
+				"tokenizer":    `"cl100k_base"`,
+				"max_tokens":   `50`,
+				"toc":          `"top"`,
+				"inventory":    `true`,
+				"base_url":     `"https://example.com/"`,
+			},
+		},
+		{
+			name: "url",
+			call: func() (*Result, error) {
+				return c.ConvertURL(context.Background(), "https://example.com/a", &ConvertOptions{TOC: "top"})
+			},
+			want: map[string]string{"url": `"https://example.com/a"`, "toc": `"top"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.requests = nil
+			result, err := tt.call()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Markdown != "# A" || !result.Stats.Truncated || result.Stats.TruncatedBytes != 10 {
+				t.Errorf("result = %+v, stats = %+v", result, result.Stats)
+			}
+			if len(result.Chunks) != 1 || result.Chunks[0].Headings[0] != "A" {
+				t.Errorf("chunks = %+v", result.Chunks)
+			}
+			if len(result.Outline) != 1 || len(result.Outline[0].Children) != 1 || result.Outline[0].Children[0].Slug != "b" {
+				t.Errorf("outline = %+v", result.Outline)
+			}
+			if result.Inventory == nil || len(result.Inventory.Links) != 1 || !result.Inventory.Links[0].Internal {
+				t.Errorf("inventory = %+v", result.Inventory)
+			}
+
+			body := s.requests[0]
+			if len(body) != len(tt.want) {
+				t.Errorf("request fields = %v, want %v", keys(body), keys(tt.want))
+			}
+			for k, want := range tt.want {
+				if got := string(body[k]); got != want {
+					t.Errorf("%s = %s, want %s", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPClientBatchOptions(t *testing.T) {
+	s := &fakeServer{handle: func(_ http.ResponseWriter, _ string, body map[string]json.RawMessage) (int, any) {
+		var items []map[string]any
+		json.Unmarshal(body["items"], &items)
+		results := make([]map[string]any, len(items))
+		for i, item := range items {
+			results[i] = map[string]any{"index": i, "success": true, "result": map[string]any{"markdown": item["html"]}}
+		}
+		return http.StatusOK, map[string]any{"results": results, "summary": map[string]any{"total": len(items), "success": len(items)}}
+	}}
+	c := newTestHTTPClient(t, s, WithBatchSize(2))
+	opts := &ConvertOptions{Plugins: []string{"table"}}
+
+	var got []BatchItem
+	for item, err := range c.ConvertStream(context.Background(), slices.Values([]string{"a", "b", "c"}), opts) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, item)
+	}
+
+	if len(s.requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(s.requests))
+	}
+	for i, item := range got {
+		if item.Index != i || item.Result.Markdown != []string{"a", "b", "c"}[i] {
+			t.Errorf("item %d = %+v", i, item)
+		}
+	}
+	for _, req := range s.requests {
+		var items []map[string]json.RawMessage
+		json.Unmarshal(req["items"], &items)
+		for _, item := range items {
+			if string(item["plugins"]) != `["table"]` {
+				t.Errorf("item plugins = %s", item["plugins"])
+			}
+		}
+	}
+}
+
+func TestHTTPClientErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       int
+		retryAfter string
+		retries    int
+		wantCalls  int
+		wantAfter  time.Duration
+	}{
+		{name: "bad request is not retried", code: http.StatusBadRequest, retries: 2, wantCalls: 1},
+		{name: "too large is not retried", code: http.StatusRequestEntityTooLarge, retries: 2, wantCalls: 1},
+		{name: "rate limited is retried", code: http.StatusTooManyRequests, retries: 1, wantCalls: 2},
+		{name: "retry after is returned", code: http.StatusTooManyRequests, retryAfter: "1", wantCalls: 1, wantAfter: time.Second},
+		{name: "server error is retried", code: http.StatusInternalServerError, retries: 2, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &fakeServer{handle: func(w http.ResponseWriter, _ string, _ map[string]json.RawMessage) (int, any) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				return tt.code, nil
+			}}
+			c := newTestHTTPClient(t, s, WithRetry(tt.retries, time.Millisecond, time.Millisecond))
+
+			_, err := c.Convert(context.Background(), "<p>x</p>", nil)
+			var e *Error
+			if !errors.As(err, &e) || e.Code != tt.code {
+				t.Fatalf("err = %v, want code %d", err, tt.code)
+			}
+			if e.RetryAfter != tt.wantAfter {
+				t.Errorf("RetryAfter = %v, want %v", e.RetryAfter, tt.wantAfter)
+			}
+			if len(s.requests) != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", len(s.requests), tt.wantCalls)
+			}
+		})
+	}
+}
+
+// keys 返回map的键，用于错误信息
+func keys[V any](m map[string]V) []string {
+	var ks []string
+	for k := range m {
+		ks = append(ks, k)
+	}
+	slices.Sort(ks)
+	return ks
+}
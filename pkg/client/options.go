@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// 默认配置
+const (
+	DefaultBatchSize  = 100 // 与服务端 converter.max_batch_size 的默认值一致
+	DefaultMaxRetries = 3
+	DefaultTimeout    = 30 * time.Second
+)
+
+// Option 客户端选项
+type Option func(*options)
+
+// options 客户端配置
+type options struct {
+	apiKey      string
+	timeout     time.Duration
+	batchSize   int
+	maxRetries  int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	httpClient  *http.Client
+	dialOptions []grpc.DialOption
+}
+
+// newOptions 应用选项并填充默认值
+func newOptions(opts []Option) *options {
+	o := &options{
+		timeout:    DefaultTimeout,
+		batchSize:  DefaultBatchSize,
+		maxRetries: DefaultMaxRetries,
+		minBackoff: 200 * time.Millisecond,
+		maxBackoff: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithAPIKey 设置API密钥
+func WithAPIKey(key string) Option {
+	return func(o *options) {
+		o.apiKey = key
+	}
+}
+
+// WithTimeout 设置单次请求的超时时间，0表示不限制
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithBatchSize 设置单次批量请求的最大数量，应不大于服务端的 converter.max_batch_size
+func WithBatchSize(size int) Option {
+	return func(o *options) {
+		if size > 0 {
+			o.batchSize = size
+		}
+	}
+}
+
+// WithRetry 设置重试次数和退避时间范围，maxRetries为0时不重试
+func WithRetry(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(o *options) {
+		o.maxRetries = maxRetries
+		o.minBackoff = minBackoff
+		o.maxBackoff = maxBackoff
+	}
+}
+
+// WithHTTPClient 设置HTTP客户端使用的 http.Client
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithDialOptions 追加GRPC连接选项，如 grpc.WithTransportCredentials 启用TLS
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	}
+}
+
+// retry 执行fn，遇到可重试的错误时按指数退避重试
+func (o *options) retry(ctx context.Context, fn func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		err := o.attempt(ctx, fn)
+		if err == nil || attempt >= o.maxRetries || !retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(o.backoff(attempt, err))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt 按超时设置执行一次请求
+func (o *options) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+	return fn(ctx)
+}
+
+// backoff 计算第attempt次重试前的等待时间，服务端给出Retry-After时以其为下限
+func (o *options) backoff(attempt int, err error) time.Duration {
+	delay := o.maxBackoff
+	if attempt < 30 && o.minBackoff<<attempt < o.maxBackoff {
+		delay = o.minBackoff << attempt
+	}
+	// 在[delay/2, delay]之间随机，避免多个客户端同时重试
+	if delay > 1 {
+		delay = delay/2 + rand.N(delay/2+1)
+	}
+
+	var e *Error
+	if errors.As(err, &e) && e.RetryAfter > delay {
+		delay = e.RetryAfter
+	}
+	return delay
+}
+
+// retryable 判断错误是否可以重试
+func retryable(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Temporary()
+}
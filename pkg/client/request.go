@@ -0,0 +1,111 @@
+package client
+
+import (
+	pb "github.com/relaxcloud-cn/html2md/api/grpc/proto"
+)
+
+// ConvertOptions 单次转换的选项，字段含义与服务端转换接口的同名参数相同
+//
+// 传入nil或零值时使用服务端的默认设置。批量和流式转换中的每一项使用相同的选项。
+type ConvertOptions struct {
+	Plugins     []string      // 在默认插件之外额外启用的插件，包括WebAssembly插件
+	Profiles    []string      // 启用的预处理配置，如 email
+	Email       *EmailOptions // email预处理配置的选项，设置时自动启用该配置
+	Rules       []Rule        // 自定义转换规则，优先于服务端配置的规则
+	InferStyles bool          // 按内联样式推断加粗、斜体、代码和标题，并删除隐藏元素
+	Chunking    *ChunkOptions // 按标题层级将结果切分为块，结果在 Result.Chunks 中
+	Tokenizer   string        // 计算token数的分词器，为空时使用服务端默认分词器
+	MaxTokens   int           // 结果的最大token数，超出时在块边界截断，为0时不限制
+	TOC         string        // 插入目录的位置: top, placeholder，为空时不插入
+	Inventory   bool          // 在 Result.Inventory 中返回链接和图片清单
+	BaseURL     string        // 补全相对链接和图片地址的基准URL，ConvertURL 时默认为页面的最终地址
+}
+
+// EmailOptions email预处理配置的选项
+type EmailOptions struct {
+	Quotes         string `json:"quotes,omitempty"`          // 引用历史: keep（默认）, collapse, strip
+	StripSignature bool   `json:"strip_signature,omitempty"` // 删除签名
+}
+
+// Rule 自定义转换规则
+type Rule struct {
+	Selector string `json:"selector"`           // CSS选择器
+	Action   string `json:"action"`             // 动作: drop, keep, unwrap, template
+	Template string `json:"template,omitempty"` // 动作为template时使用的模板
+}
+
+// ChunkOptions 分块选项
+type ChunkOptions struct {
+	Unit       string `json:"unit,omitempty"`        // 计量单位: chars（默认）, tokens
+	TargetSize int    `json:"target_size,omitempty"` // 目标大小，为0时等于最大大小
+	MaxSize    int    `json:"max_size,omitempty"`    // 最大大小，为0时等于目标大小
+	Overlap    int    `json:"overlap,omitempty"`     // 同一小节内相邻块的重叠大小
+}
+
+// convertRequest HTTP转换请求，与服务端的请求格式一致
+type convertRequest struct {
+	HTML        string        `json:"html,omitempty"`
+	URL         string        `json:"url,omitempty"`
+	BaseURL     string        `json:"base_url,omitempty"`
+	Rules       []Rule        `json:"rules,omitempty"`
+	Plugins     []string      `json:"plugins,omitempty"`
+	Profiles    []string      `json:"profiles,omitempty"`
+	Email       *EmailOptions `json:"email,omitempty"`
+	InferStyles bool          `json:"infer_styles,omitempty"`
+	Chunking    *ChunkOptions `json:"chunking,omitempty"`
+	Tokenizer   string        `json:"tokenizer,omitempty"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	TOC         string        `json:"toc,omitempty"`
+	Inventory   bool          `json:"inventory,omitempty"`
+}
+
+// newConvertRequest 使用HTML或URL和转换选项构造HTTP请求
+func newConvertRequest(html, url string, opts *ConvertOptions) convertRequest {
+	req := convertRequest{HTML: html, URL: url}
+	if opts != nil {
+		req.BaseURL = opts.BaseURL
+		req.Rules = opts.Rules
+		req.Plugins = opts.Plugins
+		req.Profiles = opts.Profiles
+		req.Email = opts.Email
+		req.InferStyles = opts.InferStyles
+		req.Chunking = opts.Chunking
+		req.Tokenizer = opts.Tokenizer
+		req.MaxTokens = opts.MaxTokens
+		req.TOC = opts.TOC
+		req.Inventory = opts.Inventory
+	}
+	return req
+}
+
+// toPBRequest 使用HTML或URL和转换选项构造GRPC请求
+func toPBRequest(html, url string, opts *ConvertOptions) *pb.ConvertRequest {
+	req := &pb.ConvertRequest{Html: html, Url: url}
+	if opts == nil {
+		return req
+	}
+
+	req.BaseUrl = opts.BaseURL
+	req.Plugins = opts.Plugins
+	req.Profiles = opts.Profiles
+	req.InferStyles = opts.InferStyles
+	req.Tokenizer = opts.Tokenizer
+	req.MaxTokens = int32(opts.MaxTokens)
+	req.Toc = opts.TOC
+	req.Inventory = opts.Inventory
+	for _, r := range opts.Rules {
+		req.Rules = append(req.Rules, &pb.Rule{Selector: r.Selector, Action: r.Action, Template: r.Template})
+	}
+	if opts.Email != nil {
+		req.Email = &pb.EmailOptions{Quotes: opts.Email.Quotes, StripSignature: opts.Email.StripSignature}
+	}
+	if opts.Chunking != nil {
+		req.Chunking = &pb.ChunkingOptions{
+			Unit:       opts.Chunking.Unit,
+			TargetSize: int32(opts.Chunking.TargetSize),
+			MaxSize:    int32(opts.Chunking.MaxSize),
+			Overlap:    int32(opts.Chunking.Overlap),
+		}
+	}
+	return req
+}
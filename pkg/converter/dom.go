@@ -0,0 +1,114 @@
+package converter
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// getAttr 获取属性值
+func getAttr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// attrValue 获取属性值，不存在时返回空字符串
+func attrValue(n *html.Node, key string) string {
+	val, _ := getAttr(n, key)
+	return val
+}
+
+// hasClass 判断元素是否包含指定的class
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attrValue(n, "class")) {
+		if strings.EqualFold(c, class) {
+			return true
+		}
+	}
+	return false
+}
+
+// styleProps 解析style属性，属性名转为小写，值去掉 !important
+func styleProps(n *html.Node) map[string]string {
+	style := attrValue(n, "style")
+	if style == "" {
+		return nil
+	}
+	props := make(map[string]string)
+	for _, decl := range strings.Split(style, ";") {
+		name, value, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+		props[strings.ToLower(strings.TrimSpace(name))] = strings.ToLower(value)
+	}
+	return props
+}
+
+// pixels 解析以像素为单位的长度，如 1、1px，其他单位返回false
+func pixels(value string) (float64, bool) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "px")
+	n, err := strconv.ParseFloat(value, 64)
+	return n, err == nil
+}
+
+// isElement 判断是否为指定标签的元素
+func isElement(n *html.Node, tags ...string) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	for _, tag := range tags {
+		if n.Data == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// removeNode 将节点从父节点中移除
+func removeNode(n *html.Node) {
+	if n.Parent != nil {
+		n.Parent.RemoveChild(n)
+	}
+}
+
+// unwrapNode 用子节点替换元素本身
+func unwrapNode(n *html.Node) {
+	parent := n.Parent
+	if parent == nil {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = n.FirstChild {
+		n.RemoveChild(c)
+		parent.InsertBefore(c, n)
+	}
+	parent.RemoveChild(n)
+}
+
+// newElement 创建元素节点
+func newElement(tag string) *html.Node {
+	return &html.Node{Type: html.ElementNode, Data: tag, DataAtom: atom.Lookup([]byte(tag))}
+}
+
+// filterNodes 深度优先遍历，fn返回false的节点连同子节点被移除，返回true时继续遍历其子节点
+//
+// fn可以移除当前节点之前或之后的兄弟节点，但不能移除当前节点本身。
+func filterNodes(n *html.Node, fn func(*html.Node) bool) {
+	for c := n.FirstChild; c != nil; {
+		keep := fn(c)
+		next := c.NextSibling
+		if keep {
+			filterNodes(c, fn)
+		} else {
+			n.RemoveChild(c)
+		}
+		c = next
+	}
+}
@@ -0,0 +1,315 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// 邮件引用历史的处理方式
+const (
+	QuotesKeep     = "keep"     // 保留
+	QuotesCollapse = "collapse" // 嵌套的多层引用合并为一层
+	QuotesStrip    = "strip"    // 删除引用历史及其署名行
+)
+
+// EmailOptions email预处理配置的选项
+type EmailOptions struct {
+	Quotes         string `json:"quotes,omitempty" yaml:"quotes,omitempty"`                   // 引用历史: keep（默认）, collapse, strip
+	StripSignature bool   `json:"strip_signature,omitempty" yaml:"strip_signature,omitempty"` // 删除签名
+}
+
+var (
+	// quoteSelector 常见邮件客户端的引用历史及其署名行
+	quoteSelector = cascadia.MustCompile(`blockquote[type=cite], .gmail_quote, .yahoo_quoted, .protonmail_quote, .moz-cite-prefix`)
+	// signatureSelector 常见邮件客户端的签名
+	signatureSelector = cascadia.MustCompile(`.gmail_signature, [data-smartmail=gmail_signature], #Signature, .moz-signature, #AppleMailSignature`)
+)
+
+// outlookHeaderID Outlook回复和转发时原邮件头所在元素的id，原邮件内容为其后的兄弟节点
+const outlookHeaderID = "divRplyFwdMsg"
+
+// blockTags 单元格中出现时表明表格用于排版的块级元素
+var blockTags = []string{"table", "div", "p", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "blockquote", "pre", "hr", "img"}
+
+// newEmailCleanup 创建email预处理
+func newEmailCleanup(opts EmailOptions) (func(doc *html.Node), error) {
+	switch opts.Quotes {
+	case "", QuotesKeep, QuotesCollapse, QuotesStrip:
+	default:
+		return nil, fmt.Errorf("%w: 引用处理方式 %q 无效，可选: keep, collapse, strip", ErrInvalidProfile, opts.Quotes)
+	}
+
+	return func(doc *html.Node) {
+		filterNodes(doc, func(n *html.Node) bool {
+			return n.Type != html.ElementNode || !(isHiddenElement(n) || isTrackingImage(n))
+		})
+		if opts.StripSignature {
+			stripSignatures(doc)
+		}
+		switch opts.Quotes {
+		case QuotesStrip:
+			stripQuotes(doc)
+		case QuotesCollapse:
+			collapseQuotes(doc)
+		}
+		unwrapLayoutTables(doc)
+	}, nil
+}
+
+// isHiddenElement 判断元素是否不可见，如预览文本（preheader）和仅供特定客户端显示的内容
+func isHiddenElement(n *html.Node) bool {
+	if _, ok := getAttr(n, "hidden"); ok || hasClass(n, "preheader") {
+		return true
+	}
+	props := styleProps(n)
+	switch {
+	case props["display"] == "none",
+		props["visibility"] == "hidden",
+		props["mso-hide"] == "all",
+		props["opacity"] == "0":
+		return true
+	}
+	if props["overflow"] == "hidden" {
+		for _, key := range []string{"max-height", "height", "max-width", "width"} {
+			if size, ok := pixels(props[key]); ok && size == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isTrackingImage 判断是否为跟踪像素或占位图片，宽或高不超过1像素
+func isTrackingImage(n *html.Node) bool {
+	if !isElement(n, "img") {
+		return false
+	}
+	props := styleProps(n)
+	for _, key := range []string{"width", "height"} {
+		for _, value := range []string{attrValue(n, key), props[key]} {
+			if size, ok := pixels(value); ok && size <= 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stripQuotes 删除引用历史
+func stripQuotes(doc *html.Node) {
+	filterNodes(doc, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+		if attrValue(n, "id") == outlookHeaderID {
+			removeOutlookQuote(n)
+			return false
+		}
+		return !quoteSelector.Match(n)
+	})
+}
+
+// removeOutlookQuote 删除Outlook原邮件头之后的原邮件内容和之前紧邻的分隔线，原邮件头本身由调用方删除
+func removeOutlookQuote(header *html.Node) {
+	for sib := header.NextSibling; sib != nil; sib = header.NextSibling {
+		removeNode(sib)
+	}
+	prev := header.PrevSibling
+	for prev != nil && prev.Type == html.TextNode && strings.TrimSpace(prev.Data) == "" {
+		prev = prev.PrevSibling
+	}
+	if prev != nil && (isElement(prev, "hr") || attrValue(prev, "id") == "appendonsend") {
+		removeNode(prev)
+	}
+}
+
+// collapseQuotes 将引用中嵌套的引用改为普通块，多层引用历史合并为一层
+func collapseQuotes(doc *html.Node) {
+	var visit func(n *html.Node, inQuote bool)
+	visit = func(n *html.Node, inQuote bool) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			quote := isElement(c, "blockquote")
+			if quote && inQuote {
+				c.Data, c.DataAtom, c.Attr = "div", atom.Div, nil
+			}
+			visit(c, inQuote || quote)
+		}
+	}
+	visit(doc, false)
+}
+
+// stripSignatures 删除签名元素，以及引用之外以 "-- " 分隔线开头的签名
+func stripSignatures(doc *html.Node) {
+	filterNodes(doc, func(n *html.Node) bool {
+		return n.Type != html.ElementNode || !signatureSelector.Match(n)
+	})
+
+	var delimiter *html.Node
+	var find func(n *html.Node)
+	find = func(n *html.Node) {
+		for c := n.FirstChild; c != nil && delimiter == nil; c = c.NextSibling {
+			switch {
+			case c.Type == html.ElementNode && (quoteSelector.Match(c) || attrValue(c, "id") == outlookHeaderID):
+				// 引用中的签名属于原邮件
+				return
+			case c.Type == html.TextNode && truncateSignature(c):
+				delimiter = c
+			default:
+				find(c)
+			}
+		}
+	}
+	find(doc)
+	if delimiter == nil {
+		return
+	}
+	// 删除分隔线之后同一元素内的内容，遇到引用时停止，保留回复下方的引用历史
+	for sib := delimiter.NextSibling; sib != nil; sib = delimiter.NextSibling {
+		if sib.Type == html.ElementNode && (quoteSelector.Match(sib) || attrValue(sib, "id") == outlookHeaderID) {
+			break
+		}
+		removeNode(sib)
+	}
+	if delimiter.Data == "" {
+		removeNode(delimiter)
+	}
+}
+
+// truncateSignature 文本节点包含独占一行的 "-- " 分隔线时，截去分隔线及其后的文本并返回true
+func truncateSignature(n *html.Node) bool {
+	lines := strings.SplitAfter(n.Data, "\n")
+	for i, line := range lines {
+		if strings.TrimRight(line, " \r\n\t\u00a0") != "--" {
+			continue
+		}
+		// 分隔线前后须为换行或元素边界
+		if i == 0 && !atLineStart(n) {
+			continue
+		}
+		if i == len(lines)-1 && !strings.HasSuffix(line, "\n") && !atLineEnd(n) {
+			continue
+		}
+		n.Data = strings.Join(lines[:i], "")
+		return true
+	}
+	return false
+}
+
+// atLineStart 判断文本节点前是否为换行或元素开头
+func atLineStart(n *html.Node) bool {
+	prev := n.PrevSibling
+	return prev == nil || isElement(prev, "br", "div", "p")
+}
+
+// atLineEnd 判断文本节点后是否为换行或元素结尾
+func atLineEnd(n *html.Node) bool {
+	next := n.NextSibling
+	return next == nil || isElement(next, "br", "div", "p")
+}
+
+// unwrapLayoutTables 将用于排版的表格展开为按行、单元格顺序排列的块
+func unwrapLayoutTables(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if isElement(c, "table") && isLayoutTable(c) {
+			first := unwrapTable(c)
+			if first != nil {
+				next = first
+			}
+		} else {
+			unwrapLayoutTables(c)
+		}
+		c = next
+	}
+}
+
+// isLayoutTable 判断表格是否用于排版而非展示数据
+//
+// 声明为presentation、嵌套表格、单元格中包含块级元素或只有一列的表格视为排版表格，
+// 包含表头或标题的表格视为数据表格。
+func isLayoutTable(table *html.Node) bool {
+	switch attrValue(table, "role") {
+	case "presentation", "none":
+		return true
+	}
+	rows := tableRows(table)
+	singleColumn := true
+	hasBlock := false
+	for _, row := range rows {
+		cells := 0
+		for cell := row.FirstChild; cell != nil; cell = cell.NextSibling {
+			if isElement(cell, "th") {
+				return false
+			}
+			if !isElement(cell, "td") {
+				continue
+			}
+			cells++
+			hasBlock = hasBlock || containsElement(cell, blockTags...)
+		}
+		singleColumn = singleColumn && cells <= 1
+	}
+	for c := table.FirstChild; c != nil; c = c.NextSibling {
+		if isElement(c, "caption", "thead") {
+			return false
+		}
+	}
+	return hasBlock || singleColumn
+}
+
+// unwrapTable 用单元格内容替换表格，每个单元格展开为一个div，返回第一个插入的节点
+func unwrapTable(table *html.Node) *html.Node {
+	parent := table.Parent
+	var first *html.Node
+	for _, row := range tableRows(table) {
+		for cell := row.FirstChild; cell != nil; {
+			next := cell.NextSibling
+			if isElement(cell, "td", "th") {
+				div := newElement("div")
+				for c := cell.FirstChild; c != nil; c = cell.FirstChild {
+					cell.RemoveChild(c)
+					div.AppendChild(c)
+				}
+				parent.InsertBefore(div, table)
+				if first == nil {
+					first = div
+				}
+			}
+			cell = next
+		}
+	}
+	parent.RemoveChild(table)
+	return first
+}
+
+// tableRows 获取表格的直接行，包括thead、tbody、tfoot中的行
+func tableRows(table *html.Node) []*html.Node {
+	var rows []*html.Node
+	for c := table.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case isElement(c, "tr"):
+			rows = append(rows, c)
+		case isElement(c, "thead", "tbody", "tfoot"):
+			for r := c.FirstChild; r != nil; r = r.NextSibling {
+				if isElement(r, "tr") {
+					rows = append(rows, r)
+				}
+			}
+		}
+	}
+	return rows
+}
+
+// containsElement 判断元素的后代中是否包含指定标签
+func containsElement(n *html.Node, tags ...string) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if isElement(c, tags...) || containsElement(c, tags...) {
+			return true
+		}
+	}
+	return false
+}
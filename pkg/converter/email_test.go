@@ -0,0 +1,121 @@
+package converter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEmailProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		opts EmailOptions
+		html string
+		want string
+	}{
+		{
+			name: "hidden preheader and tracking pixels",
+			html: `<div class="preheader">preview</div><span style="display:none">hidden</span>` +
+				`<div style="overflow:hidden;max-height:0">zero</div>` +
+				`<p>Hello<img src="t.gif" width="1" height="1"><img src="p.gif" style="width:0px"></p><img src="a.png" width="200" alt="A">`,
+			want: "Hello\n\n![A](a.png)",
+		},
+		{
+			name: "layout tables are unwrapped",
+			html: `<table role="presentation"><tr><td><p>One</p></td><td><p>Two</p></td></tr></table>` +
+				`<table><tr><td>single column</td></tr></table>`,
+			want: "One\n\nTwo\n\nsingle column",
+		},
+		{
+			name: "data tables are kept",
+			html: `<table><tr><th>H</th></tr><tr><td>1</td></tr></table>`,
+			want: "H1",
+		},
+		{
+			name: "quotes kept by default",
+			html: `<p>Reply</p><div class="gmail_quote"><blockquote type="cite"><p>Old</p><blockquote type="cite"><p>Older</p></blockquote></blockquote></div>`,
+			want: "Reply\n\n> Old\n> \n> > Older",
+		},
+		{
+			name: "quotes collapsed",
+			opts: EmailOptions{Quotes: QuotesCollapse},
+			html: `<p>Reply</p><blockquote type="cite"><p>Old</p><blockquote type="cite"><p>Older</p></blockquote></blockquote>`,
+			want: "Reply\n\n> Old\n> \n> Older",
+		},
+		{
+			name: "quotes stripped",
+			opts: EmailOptions{Quotes: QuotesStrip},
+			html: `<p>Reply</p><div class="moz-cite-prefix">On Monday Bob wrote:</div><blockquote type="cite"><p>Old</p></blockquote>`,
+			want: "Reply",
+		},
+		{
+			name: "outlook quote stripped",
+			opts: EmailOptions{Quotes: QuotesStrip},
+			html: `<div><p>Reply</p><hr><div id="divRplyFwdMsg">From: Bob</div><div>Old message</div><p>More old</p></div>`,
+			want: "Reply",
+		},
+		{
+			name: "signature kept by default",
+			html: `<p>Body</p><div class="gmail_signature">Alice</div>`,
+			want: "Body\n\nAlice",
+		},
+		{
+			name: "signature element stripped",
+			opts: EmailOptions{StripSignature: true},
+			html: `<p>Body</p><div class="gmail_signature">Alice</div><div id="Signature">Sent from phone</div>`,
+			want: "Body",
+		},
+		{
+			name: "signature delimiter stripped",
+			opts: EmailOptions{StripSignature: true},
+			html: "<div>Body<br>-- <br>Alice<br>555-1234</div>",
+			want: "Body",
+		},
+		{
+			name: "signature delimiter inside quote kept",
+			opts: EmailOptions{StripSignature: true},
+			html: "<div>Body</div><blockquote type=\"cite\">Old<br>-- <br>Bob</blockquote>",
+			want: "Body\n\n> Old  \n> --  \n> Bob",
+		},
+		{
+			name: "double dash inside text kept",
+			opts: EmailOptions{StripSignature: true},
+			html: "<p>a -- b</p>",
+			want: "a -- b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := New(WithEmailOptions(tt.opts))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := conv.ConvertString(tt.html)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Markdown != tt.want {
+				t.Errorf("Markdown = %q, want %q", result.Markdown, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmailProfileOptions(t *testing.T) {
+	if _, err := New(WithEmailOptions(EmailOptions{Quotes: "nope"})); !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("New() error = %v, want %v", err, ErrInvalidProfile)
+	}
+
+	// 未启用email配置时不删除隐藏元素
+	conv, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := conv.ConvertString(`<p>a</p><div class="preheader">preview</div>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Markdown != "a\n\npreview" {
+		t.Errorf("Markdown = %q", result.Markdown)
+	}
+}
@@ -1,127 +1,222 @@
+// Package converter HTML转Markdown转换库
+//
+// 服务端与命令行工具使用的同一套转换逻辑，可直接嵌入其他Go程序:
+//
+//	conv, err := converter.New(converter.WithPlugins("base", "commonmark", "table"))
+//	if err != nil {
+//		return err
+//	}
+//	result, err := conv.ConvertString("<h1>Hello</h1>")
 package converter
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
 	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/base"
 	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/commonmark"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/strikethrough"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/table"
+)
 
-	"github.com/relaxcloud-cn/html2md/internal/model"
+// 输入不合法时返回的错误，可用 errors.Is 判断
+var (
+	ErrInvalidHTML   = errors.New("无效的HTML输入")
+	ErrInputTooLarge = errors.New("输入内容超过大小限制")
+	ErrInvalidPlugin = errors.New("不支持的插件")
 )
 
-// Converter HTML转Markdown转换器
-type Converter struct {
-	// 使用html-to-markdown v2的转换器
+// pluginFactories 支持的插件及其构造函数
+var pluginFactories = map[string]func() converter.Plugin{
+	"base":          func() converter.Plugin { return base.NewBasePlugin() },
+	"commonmark":    func() converter.Plugin { return commonmark.NewCommonmarkPlugin() },
+	"table":         func() converter.Plugin { return table.NewTablePlugin() },
+	"strikethrough": func() converter.Plugin { return strikethrough.NewStrikethroughPlugin() },
 }
 
-// New 创建新的转换器实例
-func New() *Converter {
-	return &Converter{}
+// supportedPlugins 支持的插件，按推荐的启用顺序排列
+var supportedPlugins = []string{"base", "commonmark", "table", "strikethrough"}
+
+// DefaultPlugins 默认启用的插件
+var DefaultPlugins = []string{"base", "commonmark"}
+
+// Result 转换结果
+type Result struct {
+	Markdown  string    `json:"markdown"`  // 转换后的Markdown内容
+	Stats     Stats     `json:"stats"`     // 转换统计信息
+	Inventory Inventory `json:"inventory"` // 转换结果中的链接和图片
 }
 
-// Convert 转换HTML为Markdown
-func (c *Converter) Convert(req *model.ConvertRequest) (*model.ConvertResponse, error) {
-	startTime := time.Now()
+// Stats 转换统计信息
+type Stats struct {
+	InputSize      int           `json:"input_size"`      // 输入HTML大小（字节）
+	OutputSize     int           `json:"output_size"`     // 输出Markdown大小（字节）
+	ProcessingTime time.Duration `json:"processing_time"` // 处理时间
+	Links          int           `json:"links"`           // 链接数
+	Images         int           `json:"images"`          // 图片数
+}
+
+// Converter HTML转Markdown转换器，可在多个goroutine中并发使用
+type Converter struct {
+	opts options
+	conv *converter.Converter
+}
 
-	// 验证HTML内容
-	if err := c.ValidateHTML(req.HTML); err != nil {
+// New 创建转换器，插件名称、规则或预处理配置无效时返回错误
+func New(opts ...Option) (*Converter, error) {
+	o := options{plugins: DefaultPlugins}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := validatePlugins(o.plugins, o.host); err != nil {
 		return nil, err
 	}
-
-	// 创建转换器实例，使用默认的base和commonmark插件
-	conv := converter.NewConverter(
-		converter.WithPlugins(
-			base.NewBasePlugin(),
-			commonmark.NewCommonmarkPlugin(),
-		),
-	)
-
-	// 执行转换 - 使用转换器实例的ConvertString方法
-	markdown, err := conv.ConvertString(req.HTML)
+	// 样式推断生成的删除线需要strikethrough插件
+	if o.inferStyles && !slices.Contains(o.plugins, "strikethrough") {
+		o.plugins = append(slices.Clone(o.plugins), "strikethrough")
+	}
+	rules, err := compileRules(o.rules)
 	if err != nil {
-		return nil, fmt.Errorf("HTML转换失败: %w", err)
+		return nil, err
 	}
 
-	// 统计信息
-	processingTime := time.Since(startTime)
-	stats := &model.ConversionStats{
-		InputSize:      len(req.HTML),
-		OutputSize:     len(markdown),
-		ProcessingTime: processingTime,
+	profiles, err := newProfilePlugin(&o)
+	if err != nil {
+		return nil, err
 	}
 
-	response := &model.ConvertResponse{
-		Markdown: markdown,
-		Stats:    stats,
+	plugins := make([]converter.Plugin, 0, len(o.plugins)+3)
+	for _, name := range o.plugins {
+		if factory, ok := pluginFactories[name]; ok {
+			plugins = append(plugins, factory())
+		} else {
+			plugins = append(plugins, o.host.plugin(name))
+		}
+	}
+	if len(rules) > 0 {
+		plugins = append(plugins, &rulesPlugin{rules: rules})
 	}
+	plugins = append(plugins, profiles, &inventoryPlugin{domain: o.domain})
 
-	return response, nil
+	return &Converter{
+		opts: o,
+		conv: converter.NewConverter(converter.WithPlugins(plugins...)),
+	}, nil
 }
 
-// ConvertBatch 批量转换HTML为Markdown
-func (c *Converter) ConvertBatch(req *model.BatchConvertRequest) (*model.BatchConvertResponse, error) {
-	startTime := time.Now()
+// ValidatePlugins 验证插件名称是否为内置插件，返回的错误可用 errors.Is(err, ErrInvalidPlugin) 判断
+func ValidatePlugins(plugins []string) error {
+	return validatePlugins(plugins, nil)
+}
 
-	results := make([]model.BatchConvertItem, len(req.Items))
-	successCount := 0
-	failedCount := 0
-
-	for i, item := range req.Items {
-		result, err := c.Convert(&item)
-		if err != nil {
-			results[i] = model.BatchConvertItem{
-				Index:   i,
-				Success: false,
-				Error:   err.Error(),
-			}
-			failedCount++
-		} else {
-			results[i] = model.BatchConvertItem{
-				Index:   i,
-				Success: true,
-				Result:  result,
-			}
-			successCount++
+// validatePlugins 验证插件名称是否为内置插件或宿主中的WebAssembly插件
+func validatePlugins(plugins []string, host *PluginHost) error {
+	if len(plugins) == 0 {
+		return fmt.Errorf("至少需要启用一个插件")
+	}
+	for _, name := range plugins {
+		if _, ok := pluginFactories[name]; !ok && host.plugin(name) == nil {
+			return fmt.Errorf("%w: %s", ErrInvalidPlugin, name)
 		}
 	}
+	return nil
+}
 
-	totalTime := time.Since(startTime)
-	averageTime := totalTime / time.Duration(len(req.Items))
+// SupportedPlugins 获取支持的插件列表
+func SupportedPlugins() []string {
+	return append([]string(nil), supportedPlugins...)
+}
+
+// ConvertString 转换HTML字符串
+func (c *Converter) ConvertString(html string) (*Result, error) {
+	startTime := time.Now()
 
-	summary := &model.BatchSummary{
-		Total:       len(req.Items),
-		Success:     successCount,
-		Failed:      failedCount,
-		TotalTime:   totalTime,
-		AverageTime: averageTime,
+	if err := c.validate(html); err != nil {
+		return nil, err
+	}
+
+	ctx, renderErr := withRenderErrors(context.Background())
+	ctx, inventory := withInventory(ctx)
+	ctx = withTemplateBudget(ctx, len(html))
+	convertOpts := []converter.ConvertOptionFunc{converter.WithContext(ctx)}
+	if c.opts.domain != "" {
+		convertOpts = append(convertOpts, converter.WithDomain(c.opts.domain))
+	}
+	markdown, err := c.conv.ConvertString(html, convertOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("HTML转换失败: %w", err)
+	}
+	if *renderErr != nil {
+		return nil, *renderErr
 	}
 
-	return &model.BatchConvertResponse{
-		Results: results,
-		Summary: summary,
+	return &Result{
+		Markdown: markdown,
+		Stats: Stats{
+			InputSize:      len(html),
+			OutputSize:     len(markdown),
+			ProcessingTime: time.Since(startTime),
+			Links:          len(inventory.Links),
+			Images:         len(inventory.Images),
+		},
+		Inventory: *inventory,
 	}, nil
 }
 
-// GetSupportedPlugins 获取支持的插件列表
-func (c *Converter) GetSupportedPlugins() []string {
-	return []string{
-		"base",
-		"commonmark",
+// ConvertBytes 转换HTML并将Markdown写入w
+func (c *Converter) ConvertBytes(w io.Writer, html []byte) (*Stats, error) {
+	result, err := c.ConvertString(string(html))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, result.Markdown); err != nil {
+		return nil, fmt.Errorf("写入结果失败: %w", err)
 	}
+	return &result.Stats, nil
 }
 
-// ValidateHTML 验证HTML内容
-func (c *Converter) ValidateHTML(html string) error {
+// ConvertReader 读取r中的HTML并将Markdown写入w，设置了 WithMaxInputSize 时超出限制立即返回错误
+func (c *Converter) ConvertReader(w io.Writer, r io.Reader) (*Stats, error) {
+	if c.opts.maxInputSize > 0 {
+		r = io.LimitReader(r, int64(c.opts.maxInputSize)+1)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, fmt.Errorf("读取输入失败: %w", err)
+	}
+	return c.ConvertBytes(w, buf.Bytes())
+}
+
+// Plugins 获取当前启用的插件
+func (c *Converter) Plugins() []string {
+	return append([]string(nil), c.opts.plugins...)
+}
+
+// GetSupportedPlugins 获取支持的插件列表，包括已加载的WebAssembly插件
+func (c *Converter) GetSupportedPlugins() []string {
+	return append(SupportedPlugins(), c.opts.host.Names()...)
+}
+
+// validate 验证HTML内容
+func (c *Converter) validate(html string) error {
+	if c.opts.maxInputSize > 0 && len(html) > c.opts.maxInputSize {
+		return fmt.Errorf("%w: %d > %d 字节", ErrInputTooLarge, len(html), c.opts.maxInputSize)
+	}
+
 	if strings.TrimSpace(html) == "" {
-		return fmt.Errorf("HTML内容不能为空")
+		return fmt.Errorf("%w: HTML内容不能为空", ErrInvalidHTML)
 	}
 
 	// 基本的HTML标签检查
 	if !strings.Contains(html, "<") || !strings.Contains(html, ">") {
-		return fmt.Errorf("输入内容不是有效的HTML格式")
+		return fmt.Errorf("%w: 缺少HTML标签", ErrInvalidHTML)
 	}
 
 	return nil
@@ -130,8 +225,9 @@ func (c *Converter) ValidateHTML(html string) error {
 // GetConverterInfo 获取转换器信息
 func (c *Converter) GetConverterInfo() map[string]interface{} {
 	return map[string]interface{}{
-		"version":           "2.3.3", // html-to-markdown版本
-		"supported_plugins": c.GetSupportedPlugins(),
+		"version":            "2.3.3", // html-to-markdown版本
+		"supported_plugins":  c.GetSupportedPlugins(),
+		"supported_profiles": SupportedProfiles(),
 		"features": []string{
 			"CommonMark支持",
 			"代码块转换",
@@ -0,0 +1,241 @@
+package converter
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+		is      error
+		plugins []string
+	}{
+		{name: "defaults", plugins: DefaultPlugins},
+		{name: "all builtin plugins", opts: []Option{WithPlugins(SupportedPlugins()...)}, plugins: SupportedPlugins()},
+		{name: "unknown plugin", opts: []Option{WithPlugins("base", "nope")}, wantErr: true, is: ErrInvalidPlugin},
+		{name: "no plugins", opts: []Option{WithPlugins()}, wantErr: true},
+		{name: "unknown profile", opts: []Option{WithProfiles("nope")}, wantErr: true, is: ErrInvalidProfile},
+		{name: "invalid rule", opts: []Option{WithRules(Rule{Selector: "p", Action: "nope"})}, wantErr: true, is: ErrInvalidRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := New(tt.opts...)
+			if tt.wantErr {
+				if err == nil || (tt.is != nil && !errors.Is(err, tt.is)) {
+					t.Errorf("New() error = %v, want %v", err, tt.is)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(conv.Plugins(), tt.plugins) {
+				t.Errorf("Plugins() = %v, want %v", conv.Plugins(), tt.plugins)
+			}
+		})
+	}
+}
+
+func TestConvertString(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		html string
+		want string
+	}{
+		{
+			name: "commonmark",
+			html: "<h1>Title</h1><p>Some <strong>bold</strong> and <em>italic</em> text.</p>",
+			want: "# Title\n\nSome **bold** and *italic* text.",
+		},
+		{
+			name: "code block",
+			html: `<pre><code class="language-go">fmt.Println("hi")</code></pre>`,
+			want: "```go\nfmt.Println(\"hi\")\n```",
+		},
+		{
+			name: "table without plugin",
+			html: "<table><tr><th>A</th></tr><tr><td>1</td></tr></table>",
+			want: "A1",
+		},
+		{
+			name: "table plugin",
+			opts: []Option{WithPlugins("base", "commonmark", "table")},
+			html: "<table><tr><th>A</th></tr><tr><td>1</td></tr></table>",
+			want: "| A |\n|---|\n| 1 |",
+		},
+		{
+			name: "strikethrough without plugin",
+			html: "<p><del>old</del> new</p>",
+			want: "old new",
+		},
+		{
+			name: "strikethrough plugin",
+			opts: []Option{WithPlugins("base", "commonmark", "strikethrough")},
+			html: "<p><del>old</del> new</p>",
+			want: "~~old~~ new",
+		},
+		{
+			name: "relative link without domain",
+			html: `<a href="/docs">Docs</a>`,
+			want: "[Docs](/docs)",
+		},
+		{
+			name: "domain",
+			opts: []Option{WithDomain("https://example.com")},
+			html: `<a href="/docs">Docs</a><img src="a.png" alt="A">`,
+			want: "[Docs](https://example.com/docs)![A](https://example.com/a.png)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := New(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := conv.ConvertString(tt.html)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Markdown != tt.want {
+				t.Errorf("Markdown = %q, want %q", result.Markdown, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertStringStats(t *testing.T) {
+	conv, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := `<p><a href="/a">a</a> <a href="https://example.com/b">b</a> <img src="c.png"></p>`
+	result, err := conv.ConvertString(html)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := result.Stats
+	if stats.InputSize != len(html) || stats.OutputSize != len(result.Markdown) {
+		t.Errorf("sizes = %d/%d, want %d/%d", stats.InputSize, stats.OutputSize, len(html), len(result.Markdown))
+	}
+	if stats.Links != 2 || stats.Images != 1 {
+		t.Errorf("links/images = %d/%d, want 2/1", stats.Links, stats.Images)
+	}
+	if len(result.Inventory.Links) != stats.Links || len(result.Inventory.Images) != stats.Images {
+		t.Errorf("inventory = %+v", result.Inventory)
+	}
+}
+
+func TestConvertStringInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		html    string
+		wantErr error
+	}{
+		{name: "empty", html: "", wantErr: ErrInvalidHTML},
+		{name: "blank", html: " \n\t", wantErr: ErrInvalidHTML},
+		{name: "no tags", html: "plain text", wantErr: ErrInvalidHTML},
+		{name: "too large", opts: []Option{WithMaxInputSize(10)}, html: "<p>more than ten bytes</p>", wantErr: ErrInputTooLarge},
+		{name: "at limit", opts: []Option{WithMaxInputSize(9)}, html: "<p>ab</p>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := New(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = conv.ConvertString(tt.html)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Errorf("ConvertString() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConvertBytesAndReader(t *testing.T) {
+	conv, err := New(WithMaxInputSize(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := "<h2>Hi</h2>"
+
+	var w bytes.Buffer
+	stats, err := conv.ConvertBytes(&w, []byte(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.String() != "## Hi" || stats.OutputSize != w.Len() {
+		t.Errorf("ConvertBytes() = %q, stats = %+v", w.String(), stats)
+	}
+
+	w.Reset()
+	if _, err := conv.ConvertReader(&w, strings.NewReader(html)); err != nil {
+		t.Fatal(err)
+	}
+	if w.String() != "## Hi" {
+		t.Errorf("ConvertReader() = %q", w.String())
+	}
+
+	w.Reset()
+	_, err = conv.ConvertReader(&w, strings.NewReader("<p>"+strings.Repeat("x", 100)+"</p>"))
+	if !errors.Is(err, ErrInputTooLarge) {
+		t.Errorf("ConvertReader() error = %v, want %v", err, ErrInputTooLarge)
+	}
+	if w.Len() != 0 {
+		t.Errorf("ConvertReader() wrote %q on error", w.String())
+	}
+}
+
+func TestConvertConcurrent(t *testing.T) {
+	conv, err := New(WithPlugins(SupportedPlugins()...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				result, err := conv.ConvertString(`<p><a href="/x">x</a></p>`)
+				if err != nil || result.Markdown != "[x](/x)" || result.Stats.Links != 1 {
+					t.Errorf("ConvertString() = %+v, %v", result, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestValidatePlugins(t *testing.T) {
+	if err := ValidatePlugins(SupportedPlugins()); err != nil {
+		t.Errorf("ValidatePlugins(supported) = %v", err)
+	}
+	if err := ValidatePlugins([]string{"base", "wasm-missing"}); !errors.Is(err, ErrInvalidPlugin) {
+		t.Errorf("ValidatePlugins(unknown) = %v, want %v", err, ErrInvalidPlugin)
+	}
+	if err := ValidatePlugins(nil); err == nil {
+		t.Error("ValidatePlugins(nil) = nil, want error")
+	}
+
+	// 返回的切片是副本
+	plugins := SupportedPlugins()
+	plugins[0] = "changed"
+	if SupportedPlugins()[0] != "base" {
+		t.Error("SupportedPlugins() shares the underlying slice")
+	}
+}
@@ -0,0 +1,126 @@
+package converter
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	"golang.org/x/net/html"
+)
+
+// Inventory 转换结果中的链接和图片，按在文档中出现的顺序排列
+type Inventory struct {
+	Links  []Link  `json:"links"`  // 链接
+	Images []Image `json:"images"` // 图片
+}
+
+// Link 转换结果中的链接
+type Link struct {
+	Text     string `json:"text" example:"安装指南"`                                   // 链接文本
+	URL      string `json:"url" example:"https://example.com/docs/install#docker"` // 链接地址，设置了域名时为绝对地址
+	Title    string `json:"title,omitempty" example:"安装"`                          // title属性
+	Internal bool   `json:"internal"`                                              // 是否为站内链接：相对地址、页内锚点或与域名相同的主机
+	Fragment string `json:"fragment,omitempty" example:"docker"`                   // 地址中 # 之后的部分
+}
+
+// Image 转换结果中的图片
+type Image struct {
+	Alt     string `json:"alt" example:"架构图"`                          // 替代文本
+	Src     string `json:"src" example:"https://example.com/arch.png"` // 图片地址，设置了域名时为绝对地址
+	Title   string `json:"title,omitempty"`                            // title属性
+	Width   int    `json:"width,omitempty" example:"640"`              // width属性中的像素宽度，未设置或不是像素值时为0
+	Height  int    `json:"height,omitempty" example:"480"`             // height属性中的像素高度
+	DataURI bool   `json:"data_uri"`                                   // 是否为内嵌的data URI
+}
+
+// inventoryKey 在转换上下文中记录链接和图片
+type inventoryKey struct{}
+
+// withInventory 返回用于收集链接和图片的上下文
+func withInventory(ctx context.Context) (context.Context, *Inventory) {
+	inv := &Inventory{Links: []Link{}, Images: []Image{}}
+	return context.WithValue(ctx, inventoryKey{}, inv), inv
+}
+
+// inventoryPlugin 在预处理和自定义规则之后收集将要输出的链接和图片
+type inventoryPlugin struct {
+	domain string
+}
+
+// Name 插件名称
+func (p *inventoryPlugin) Name() string {
+	return "inventory"
+}
+
+// Init 注册收集步骤，在全部预处理之后执行
+func (p *inventoryPlugin) Init(conv *converter.Converter) error {
+	conv.Register.PreRenderer(p.preRender, converter.PriorityLate+10)
+	return nil
+}
+
+// preRender 收集链接和图片，代码块中的元素按文本输出，不计入
+func (p *inventoryPlugin) preRender(ctx converter.Context, doc *html.Node) {
+	inv, ok := ctx.Value(inventoryKey{}).(*Inventory)
+	if !ok {
+		return
+	}
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		if isElement(n, "pre", "code") {
+			return
+		}
+		switch {
+		case isElement(n, "a"):
+			if href := strings.TrimSpace(attrValue(n, "href")); href != "" {
+				inv.Links = append(inv.Links, p.link(ctx, n, href))
+			}
+		case isElement(n, "img"):
+			if src := strings.TrimSpace(attrValue(n, "src")); src != "" {
+				inv.Images = append(inv.Images, image(ctx, n, src))
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(doc)
+}
+
+// link 生成链接记录
+func (p *inventoryPlugin) link(ctx converter.Context, n *html.Node, href string) Link {
+	l := Link{
+		Text:  strings.TrimSpace(nodeText(n)),
+		URL:   ctx.AssembleAbsoluteURL(ctx, "a", href),
+		Title: attrValue(n, "title"),
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return l
+	}
+	l.Fragment = u.Fragment
+	l.Internal = u.Scheme == "" && u.Host == ""
+	if !l.Internal && p.domain != "" {
+		if d, err := url.Parse(p.domain); err == nil && d.Host != "" {
+			l.Internal = strings.EqualFold(u.Host, d.Host)
+		}
+	}
+	return l
+}
+
+// image 生成图片记录
+func image(ctx converter.Context, n *html.Node, src string) Image {
+	img := Image{
+		Alt:     attrValue(n, "alt"),
+		Src:     ctx.AssembleAbsoluteURL(ctx, "img", src),
+		Title:   attrValue(n, "title"),
+		DataURI: strings.HasPrefix(strings.ToLower(src), "data:"),
+	}
+	if w, ok := pixels(attrValue(n, "width")); ok && w > 0 {
+		img.Width = int(w)
+	}
+	if h, ok := pixels(attrValue(n, "height")); ok && h > 0 {
+		img.Height = int(h)
+	}
+	return img
+}
@@ -0,0 +1,90 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInventory(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		html   string
+		links  []Link
+		images []Image
+	}{
+		{
+			name: "links",
+			html: `<p><a href="/docs/install#docker" title="Install"> Install  guide </a>
+<a href="#top">Top</a> <a href="https://other.com/x">Other</a> <a href="">empty</a> <a>none</a></p>`,
+			links: []Link{
+				{Text: "Install guide", URL: "/docs/install#docker", Title: "Install", Internal: true, Fragment: "docker"},
+				{Text: "Top", URL: "#top", Internal: true, Fragment: "top"},
+				{Text: "Other", URL: "https://other.com/x"},
+			},
+			images: []Image{},
+		},
+		{
+			name: "domain",
+			opts: []Option{WithDomain("https://example.com")},
+			html: `<a href="/a">A</a> <a href="https://EXAMPLE.com/b">B</a> <a href="https://cdn.example.com/c">C</a><img src="img/x.png" alt="X">`,
+			links: []Link{
+				{Text: "A", URL: "https://example.com/a", Internal: true},
+				{Text: "B", URL: "https://EXAMPLE.com/b", Internal: true},
+				{Text: "C", URL: "https://cdn.example.com/c"},
+			},
+			images: []Image{{Alt: "X", Src: "https://example.com/img/x.png"}},
+		},
+		{
+			name:  "images",
+			html:  `<img src="a.png" alt="A" title="T" width="640" height="480px"><img src="b.png" width="50%"><img src="data:image/png;base64,AAAA" alt="D"><img alt="no src">`,
+			links: []Link{},
+			images: []Image{
+				{Alt: "A", Src: "a.png", Title: "T", Width: 640, Height: 480},
+				{Src: "b.png"},
+				{Alt: "D", Src: "data:image/png;base64,AAAA", DataURI: true},
+			},
+		},
+		{
+			name:   "code ignored",
+			html:   `<pre><a href="/x">x</a></pre><p><code><img src="c.png"></code><a href="/y">y</a></p>`,
+			links:  []Link{{Text: "y", URL: "/y", Internal: true}},
+			images: []Image{},
+		},
+		{
+			name:   "dropped by rules",
+			opts:   []Option{WithRules(Rule{Selector: "nav", Action: "drop"})},
+			html:   `<nav><a href="/home">Home</a></nav><p><a href="/kept">Kept</a></p>`,
+			links:  []Link{{Text: "Kept", URL: "/kept", Internal: true}},
+			images: []Image{},
+		},
+		{
+			name:   "hidden by style inference",
+			opts:   []Option{WithStyleInference(true)},
+			html:   `<p><a href="/a" style="display:none">A</a><img src="x.png" hidden><a href="/b">B</a></p>`,
+			links:  []Link{{Text: "B", URL: "/b", Internal: true}},
+			images: []Image{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := New(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := conv.ConvertString(tt.html)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(result.Inventory.Links, tt.links) {
+				t.Errorf("Links = %+v, want %+v", result.Inventory.Links, tt.links)
+			}
+			if !reflect.DeepEqual(result.Inventory.Images, tt.images) {
+				t.Errorf("Images = %+v, want %+v", result.Inventory.Images, tt.images)
+			}
+			if result.Stats.Links != len(tt.links) || result.Stats.Images != len(tt.images) {
+				t.Errorf("Stats links/images = %d/%d, want %d/%d", result.Stats.Links, result.Stats.Images, len(tt.links), len(tt.images))
+			}
+		})
+	}
+}
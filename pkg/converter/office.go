@@ -0,0 +1,349 @@
+package converter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// msoListLevel Word列表段落的层级和列表编号，如 mso-list:l0 level2 lfo1
+	msoListLevel = regexp.MustCompile(`level(\d+)(?:\s+lfo(\d+))?`)
+	// orderedMarker 有序列表的编号，如 1.、a)、(iv)
+	orderedMarker = regexp.MustCompile(`^\(?([0-9]+|[a-zA-Z]|[ivxlcdmIVXLCDM]+)[.)]$`)
+	// msoHeading Word标题段落的class，如 MsoHeading2
+	msoHeading = regexp.MustCompile(`^(?i)MsoHeading([1-6])$`)
+)
+
+// isOfficeHTML 判断文档是否由Word、Outlook或Google Docs生成
+func isOfficeHTML(n *html.Node) bool {
+	if n.Type == html.ElementNode {
+		switch {
+		case strings.Contains(n.Data, ":"),
+			strings.HasPrefix(attrValue(n, "class"), "Mso"),
+			strings.Contains(attrValue(n, "style"), "mso-"),
+			strings.HasPrefix(attrValue(n, "id"), "docs-internal-guid"):
+			return true
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if isOfficeHTML(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// newOfficeCleanup 创建Office文档预处理，force为false时只处理检测到的Office文档
+func newOfficeCleanup(force bool) func(doc *html.Node) {
+	return func(doc *html.Node) {
+		if !force && !isOfficeHTML(doc) {
+			return
+		}
+		// 隐藏的元素须在展开span之前删除，否则其内容会并入正文
+		filterNodes(doc, func(n *html.Node) bool {
+			return n.Type != html.ElementNode || !isOfficeHidden(n)
+		})
+		// 先重建列表，项目符号所在的span在清理时会被展开
+		rebuildWordLists(doc)
+		cleanupOfficeNodes(doc)
+		filterNodes(doc, func(n *html.Node) bool {
+			switch {
+			case isElement(n, "p") && isBlankParagraph(n):
+				return false
+			case isElement(n, "p"):
+				promoteWordHeading(n)
+			case isElement(n, "table"):
+				flattenTableCells(n)
+			}
+			return true
+		})
+	}
+}
+
+// isOfficeHidden 判断元素是否隐藏，Word和Outlook用 mso-hide:all 隐藏只在特定客户端显示的内容
+func isOfficeHidden(n *html.Node) bool {
+	return isStyleHidden(n) || styleProps(n)["mso-hide"] == "all"
+}
+
+// cleanupOfficeNodes 删除VML图形，展开Office命名空间元素、Google Docs的外层加粗和带样式的span
+func cleanupOfficeNodes(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		cleanupOfficeNodes(c)
+		if c.Type != html.ElementNode {
+			c = next
+			continue
+		}
+		switch {
+		case strings.HasPrefix(c.Data, "v:"):
+			// VML图形在非IE客户端中有对应的img
+			n.RemoveChild(c)
+		case strings.Contains(c.Data, ":"):
+			// o:p、智能标记等只包含文本
+			unwrapNode(c)
+		case c.DataAtom == atom.B && strings.HasPrefix(attrValue(c, "id"), "docs-internal-guid"):
+			unwrapNode(c)
+		case c.DataAtom == atom.Span:
+			replaceStyledSpan(c)
+		case (c.DataAtom == atom.Li || c.DataAtom == atom.Td || c.DataAtom == atom.Th) && onlyChild(c, "p") != nil:
+			// Google Docs的列表项和单元格内容包在段落中
+			unwrapNode(onlyChild(c, "p"))
+		}
+		c = next
+	}
+}
+
+// replaceStyledSpan 按样式将span替换为对应的语义元素，无对应语义时展开
+func replaceStyledSpan(span *html.Node) {
+	tags := styleTags(span, true)
+	if strings.TrimSpace(nodeText(span)) == "" {
+		unwrapNode(span)
+		return
+	}
+	if size, ok := fontSize(span); ok && size >= headingSizes[len(headingSizes)-1] {
+		// 保留标题字号供样式推断使用
+		span.Attr = []html.Attribute{{Key: "style", Val: "font-size:" + styleProps(span)["font-size"]}}
+		wrapChildren(span, tags)
+		return
+	}
+	if len(tags) == 0 {
+		unwrapNode(span)
+		return
+	}
+	replaceElement(span, tags)
+}
+
+// onlyChild 元素只包含一个指定标签的子元素（忽略空白文本）时返回该子元素
+func onlyChild(n *html.Node, tag string) *html.Node {
+	var child *html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode && strings.TrimSpace(c.Data) == "":
+		case c.Type == html.CommentNode:
+		case isElement(c, tag) && child == nil:
+			child = c
+		default:
+			return nil
+		}
+	}
+	return child
+}
+
+// isBlankParagraph 判断段落是否只包含空白，Word用这样的段落表示空行
+func isBlankParagraph(p *html.Node) bool {
+	return strings.TrimSpace(strings.ReplaceAll(nodeText(p), "\u00a0", " ")) == "" &&
+		!containsElement(p, "img", "br", "input")
+}
+
+// promoteWordHeading 将Word的标题段落转为标题元素
+func promoteWordHeading(p *html.Node) {
+	level := 0
+	for _, class := range strings.Fields(attrValue(p, "class")) {
+		switch {
+		case strings.EqualFold(class, "MsoTitle"):
+			level = 1
+		case strings.EqualFold(class, "MsoSubtitle"):
+			level = 2
+		case msoHeading.MatchString(class):
+			level, _ = strconv.Atoi(msoHeading.FindStringSubmatch(class)[1])
+		}
+	}
+	if n, err := strconv.Atoi(styleProps(p)["mso-outline-level"]); err == nil && n >= 1 && n <= 6 {
+		level = n
+	}
+	if level == 0 {
+		return
+	}
+	tag := "h" + strconv.Itoa(level)
+	p.Data, p.DataAtom = tag, atom.Lookup([]byte(tag))
+	unwrapSoleBold(p)
+}
+
+// wordListItem Word列表段落
+type wordListItem struct {
+	node    *html.Node
+	level   int
+	list    string // lfo编号，不同编号属于不同列表
+	ordered bool
+}
+
+// parseWordListItem 解析Word列表段落，不是列表段落时返回false
+func parseWordListItem(n *html.Node) (wordListItem, bool) {
+	if !isElement(n, "p", "h1", "h2", "h3", "h4", "h5", "h6") {
+		return wordListItem{}, false
+	}
+	m := msoListLevel.FindStringSubmatch(styleProps(n)["mso-list"])
+	if m == nil {
+		return wordListItem{}, false
+	}
+	level, _ := strconv.Atoi(m[1])
+	marker := strings.Trim(strings.ReplaceAll(removeListMarker(n), "\u00a0", " "), " \t\r\n")
+	return wordListItem{
+		node:    n,
+		level:   max(level, 1),
+		list:    m[2],
+		ordered: orderedMarker.MatchString(marker),
+	}, true
+}
+
+// removeListMarker 删除列表段落中由Word生成的项目符号或编号，返回其文本
+//
+// 符号位于 mso-list:Ignore 的span中，或位于 [if !supportLists] 条件注释之间。
+func removeListMarker(p *html.Node) string {
+	var marker strings.Builder
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		inMarker := false
+		for c := n.FirstChild; c != nil; {
+			next := c.NextSibling
+			switch {
+			case c.Type == html.CommentNode && strings.HasPrefix(c.Data, "[if !supportLists]"):
+				inMarker = true
+			case c.Type == html.CommentNode && strings.HasPrefix(c.Data, "[endif]"):
+				inMarker = false
+			case inMarker || (c.Type == html.ElementNode && styleProps(c)["mso-list"] == "ignore"):
+				marker.WriteString(nodeText(c))
+				n.RemoveChild(c)
+			default:
+				visit(c)
+			}
+			c = next
+		}
+	}
+	visit(p)
+	return marker.String()
+}
+
+// rebuildWordLists 将连续的Word列表段落重建为按层级嵌套的ul/ol
+func rebuildWordLists(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		first, ok := parseWordListItem(c)
+		if !ok {
+			rebuildWordLists(c)
+			c = c.NextSibling
+			continue
+		}
+
+		// 收集连续的列表段落，中间只允许空白文本
+		items := []wordListItem{first}
+		next := c.NextSibling
+		for sib := next; sib != nil; sib = sib.NextSibling {
+			if sib.Type == html.TextNode && strings.TrimSpace(sib.Data) == "" {
+				continue
+			}
+			item, ok := parseWordListItem(sib)
+			if !ok {
+				break
+			}
+			items = append(items, item)
+			next = sib.NextSibling
+		}
+		for _, list := range buildWordLists(items) {
+			n.InsertBefore(list, c)
+		}
+		for _, item := range items {
+			n.RemoveChild(item.node)
+		}
+		c = next
+	}
+}
+
+// buildWordLists 按层级构建嵌套列表，lfo编号变化时开始新的列表
+func buildWordLists(items []wordListItem) []*html.Node {
+	var roots []*html.Node
+	var stack []*html.Node // 每一层当前的列表元素
+	current := ""
+	for _, item := range items {
+		if len(stack) == 0 || (item.level == 1 && item.list != current) {
+			stack = stack[:0]
+			current = item.list
+		}
+		if len(stack) > item.level {
+			stack = stack[:item.level]
+		}
+		for len(stack) < item.level {
+			tag := "ul"
+			if item.ordered {
+				tag = "ol"
+			}
+			list := newElement(tag)
+			if len(stack) == 0 {
+				roots = append(roots, list)
+			} else {
+				parent := stack[len(stack)-1]
+				li := parent.LastChild
+				if li == nil {
+					li = newElement("li")
+					parent.AppendChild(li)
+				}
+				li.AppendChild(list)
+			}
+			stack = append(stack, list)
+		}
+
+		li := newElement("li")
+		for c := item.node.FirstChild; c != nil; c = item.node.FirstChild {
+			item.node.RemoveChild(c)
+			li.AppendChild(c)
+		}
+		stack[len(stack)-1].AppendChild(li)
+	}
+	return roots
+}
+
+// flattenTableCells 展开Word表格单元格中的段落，第一行全部加粗且没有表头时将其转为表头
+func flattenTableCells(table *html.Node) {
+	rows := tableRows(table)
+	for _, row := range rows {
+		for cell := row.FirstChild; cell != nil; cell = cell.NextSibling {
+			if !isElement(cell, "td", "th") {
+				continue
+			}
+			// 多个段落之间以空格分隔，单元格中的换行会使表格插件放弃转换
+			first := true
+			for c := cell.FirstChild; c != nil; {
+				next := c.NextSibling
+				if isElement(c, "p") {
+					if !first {
+						cell.InsertBefore(&html.Node{Type: html.TextNode, Data: " "}, c)
+					}
+					first = false
+					unwrapNode(c)
+				}
+				c = next
+			}
+		}
+	}
+
+	if len(rows) < 2 || containsElement(table, "th") {
+		return
+	}
+	header := rows[0]
+	cells := 0
+	for cell := header.FirstChild; cell != nil; cell = cell.NextSibling {
+		if !isElement(cell, "td") {
+			continue
+		}
+		b := onlyChild(cell, "b")
+		if b == nil {
+			b = onlyChild(cell, "strong")
+		}
+		if b == nil {
+			return
+		}
+		cells++
+	}
+	if cells == 0 {
+		return
+	}
+	for cell := header.FirstChild; cell != nil; cell = cell.NextSibling {
+		if isElement(cell, "td") {
+			cell.Data, cell.DataAtom = "th", atom.Th
+			unwrapSoleBold(cell)
+		}
+	}
+}
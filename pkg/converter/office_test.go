@@ -0,0 +1,86 @@
+package converter
+
+import "testing"
+
+func TestOfficeProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		html string
+		want string
+	}{
+		{
+			name: "word lists",
+			html: `<p class=MsoListParagraphCxSpFirst style='mso-list:l0 level1 lfo1'><![if !supportLists]><span style='mso-list:Ignore'>·<span>&nbsp;&nbsp;</span></span><![endif]>One</p>
+<p class=MsoListParagraphCxSpMiddle style='mso-list:l0 level2 lfo1'><![if !supportLists]><span style='mso-list:Ignore'>1.<span>&nbsp;</span></span><![endif]>Nested</p>
+<p class=MsoListParagraphCxSpLast style='mso-list:l0 level1 lfo1'><![if !supportLists]><span style='mso-list:Ignore'>·<span>&nbsp;</span></span><![endif]>Two</p>`,
+			want: "- One\n  \n  1. Nested\n- Two",
+		},
+		{
+			name: "word headings and blank paragraphs",
+			html: `<p class=MsoTitle>Report</p><p class=MsoNormal><o:p>&nbsp;</o:p></p><p class=MsoHeading2><b>Scope</b></p><p class=MsoNormal style='mso-outline-level:3'>Detail</p><p class=MsoNormal>Body<o:p></o:p></p>`,
+			want: "# Report\n\n## Scope\n\n### Detail\n\nBody",
+		},
+		{
+			name: "word table header",
+			opts: []Option{WithPlugins("base", "commonmark", "table")},
+			html: `<table class=MsoTableGrid><tr><td><p class=MsoNormal><b>Name</b></p></td><td><p class=MsoNormal><b>Qty</b></p></td></tr>` +
+				`<tr><td><p class=MsoNormal>Apple</p><p class=MsoNormal>Red</p></td><td><p class=MsoNormal>3</p></td></tr></table>`,
+			want: "| Name      | Qty |\n|-----------|-----|\n| Apple Red | 3   |",
+		},
+		{
+			name: "vml shapes removed",
+			html: `<p class=MsoNormal><v:shape id="s1"><v:imagedata src="a.png"/></v:shape><img src="a.png" alt="A"></p>`,
+			want: "![A](a.png)",
+		},
+		{
+			name: "google docs wrapper and spans",
+			html: `<b style="font-weight:normal;" id="docs-internal-guid-1"><p><span style="font-weight:700">Bold</span> <span style="font-style:italic">it</span> <span style="font-weight:400">plain</span></p></b>`,
+			want: "**Bold** *it* plain",
+		},
+		{
+			name: "google docs hidden span",
+			html: `<b id="docs-internal-guid-1"><p><span>Visible</span><span style="display:none">SECRET</span></p></b>`,
+			want: "Visible",
+		},
+		{
+			name: "google docs hidden span with style inference",
+			opts: []Option{WithStyleInference(true)},
+			html: `<b id="docs-internal-guid-1"><p><span>Visible</span><span style="display:none">SECRET</span></p></b>`,
+			want: "Visible",
+		},
+		{
+			name: "hidden elements with office profile",
+			opts: []Option{WithProfiles(ProfileOffice)},
+			html: `<p>vis<span style="visibility:hidden">hid</span><span aria-hidden="true">aria</span><span hidden>attr</span><span style="mso-hide:all">mso</span>ible</p>`,
+			want: "visible",
+		},
+		{
+			name: "mso-hide detected as office",
+			opts: []Option{WithEmailOptions(EmailOptions{})},
+			html: `<p>a<span style="mso-hide:all">mso</span><span style="display:none">none</span></p>`,
+			want: "a",
+		},
+		{
+			name: "plain html untouched",
+			html: `<p><span style="display:none">kept</span> <span style="font-weight:700">not bold</span></p>`,
+			want: "kept not bold",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := New(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := conv.ConvertString(tt.html)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Markdown != tt.want {
+				t.Errorf("Markdown = %q, want %q", result.Markdown, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package converter
+
+import "slices"
+
+// Option 转换器选项
+type Option func(*options)
+
+// options 转换器配置
+type options struct {
+	plugins      []string
+	domain       string
+	maxInputSize int
+	rules        []Rule
+	host         *PluginHost
+	profiles     []string
+	email        EmailOptions
+	inferStyles  bool
+}
+
+// WithPlugins 启用指定的插件，未设置时使用 DefaultPlugins
+func WithPlugins(names ...string) Option {
+	return func(o *options) {
+		o.plugins = names
+	}
+}
+
+// WithDomain 设置页面所在的域名，如 https://example.com，相对链接和图片地址将被补全为绝对地址
+func WithDomain(domain string) Option {
+	return func(o *options) {
+		o.domain = domain
+	}
+}
+
+// WithMaxInputSize 限制单次转换的输入大小（字节），超出时返回 ErrInputTooLarge，0表示不限制
+func WithMaxInputSize(n int) Option {
+	return func(o *options) {
+		o.maxInputSize = n
+	}
+}
+
+// WithRules 追加自定义转换规则，多次调用时先追加的规则优先
+func WithRules(rules ...Rule) Option {
+	return func(o *options) {
+		o.rules = append(o.rules, rules...)
+	}
+}
+
+// WithPluginHost 允许通过 WithPlugins 按名称启用宿主中加载的WebAssembly插件
+func WithPluginHost(host *PluginHost) Option {
+	return func(o *options) {
+		o.host = host
+	}
+}
+
+// WithProfiles 启用预处理配置，在转换前按来源清理HTML，可选配置见 SupportedProfiles
+func WithProfiles(names ...string) Option {
+	return func(o *options) {
+		for _, name := range names {
+			if !slices.Contains(o.profiles, name) {
+				o.profiles = append(o.profiles, name)
+			}
+		}
+	}
+}
+
+// WithEmailOptions 设置email预处理配置的引用和签名处理方式，并启用该配置
+func WithEmailOptions(opts EmailOptions) Option {
+	return func(o *options) {
+		o.email = opts
+		WithProfiles(ProfileEmail)(o)
+	}
+}
+
+// WithStyleInference 按内联样式推断语义，将样式表示的加粗、斜体、删除线、等宽字体和大字号标题转为对应的Markdown，
+// 并删除通过 display:none、visibility:hidden 或 aria-hidden 隐藏的元素。启用时自动加入删除线所需的strikethrough插件
+func WithStyleInference(enable bool) Option {
+	return func(o *options) {
+		o.inferStyles = enable
+	}
+}
@@ -0,0 +1,90 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	"golang.org/x/net/html"
+)
+
+// 预处理配置名称
+const (
+	ProfileOffice = "office" // Word、Outlook和Google Docs：重建列表、标题和表格，检测到这类文档时自动启用
+	ProfileEmail  = "email"  // 邮件：删除跟踪图片和隐藏元素，展开布局表格，按选项处理引用历史和签名
+)
+
+// ErrInvalidProfile 预处理配置名称或选项无效
+var ErrInvalidProfile = errors.New("不支持的预处理配置")
+
+// supportedProfiles 支持的预处理配置，按执行顺序排列
+var supportedProfiles = []string{ProfileOffice, ProfileEmail}
+
+// SupportedProfiles 获取支持的预处理配置
+func SupportedProfiles() []string {
+	return append([]string(nil), supportedProfiles...)
+}
+
+// ValidateProfiles 验证预处理配置名称，返回的错误可用 errors.Is(err, ErrInvalidProfile) 判断
+func ValidateProfiles(names []string) error {
+	for _, name := range names {
+		if !slices.Contains(supportedProfiles, name) {
+			return fmt.Errorf("%w: %s", ErrInvalidProfile, name)
+		}
+	}
+	return nil
+}
+
+// profilePlugin 在渲染前按预处理配置清理HTML
+type profilePlugin struct {
+	steps []func(doc *html.Node)
+}
+
+// newProfilePlugin 按固定顺序组合预处理配置
+//
+// office配置始终参与，未显式启用时只处理检测到的Office文档；其余配置需要显式启用。
+// 启用样式推断时在全部预处理配置之后执行。
+func newProfilePlugin(o *options) (*profilePlugin, error) {
+	if err := ValidateProfiles(o.profiles); err != nil {
+		return nil, err
+	}
+	p := &profilePlugin{}
+	for _, name := range supportedProfiles {
+		switch name {
+		case ProfileOffice:
+			p.steps = append(p.steps, newOfficeCleanup(slices.Contains(o.profiles, name)))
+		case ProfileEmail:
+			if !slices.Contains(o.profiles, name) {
+				continue
+			}
+			step, err := newEmailCleanup(o.email)
+			if err != nil {
+				return nil, err
+			}
+			p.steps = append(p.steps, step)
+		}
+	}
+	if o.inferStyles {
+		p.steps = append(p.steps, inferStyles)
+	}
+	return p, nil
+}
+
+// Name 插件名称
+func (p *profilePlugin) Name() string {
+	return "profiles"
+}
+
+// Init 注册预处理，在自定义规则和内置插件之前执行
+func (p *profilePlugin) Init(conv *converter.Converter) error {
+	conv.Register.PreRenderer(p.preRender, converter.PriorityEarly-20)
+	return nil
+}
+
+// preRender 依次执行预处理
+func (p *profilePlugin) preRender(ctx converter.Context, doc *html.Node) {
+	for _, step := range p.steps {
+		step(doc)
+	}
+}
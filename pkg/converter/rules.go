@@ -0,0 +1,470 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+	"unicode/utf8"
+
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/plugin/base"
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+// 规则动作
+const (
+	ActionDrop     = "drop"     // 删除元素及其内容
+	ActionKeep     = "keep"     // 原样保留为HTML
+	ActionUnwrap   = "unwrap"   // 去掉元素本身，只转换其内容
+	ActionTemplate = "template" // 使用 text/template 模板渲染
+)
+
+// ErrInvalidRule 规则配置无效或模板执行失败
+var ErrInvalidRule = errors.New("无效的转换规则")
+
+// Rule 自定义转换规则
+//
+// Selector 为CSS选择器，如 div.warning、span.kbd、aside, .ads。
+// 多条规则匹配同一元素时，排在前面的规则生效。
+//
+// 模板可使用的字段见 RuleData，另外提供以下函数:
+//
+//	trim    去除首尾空白
+//	quote   为每一行添加 "> " 前缀
+//	prefix  为每一行添加指定前缀，如 {{ prefix "    " .Children }}
+//	replace 替换字符串，如 {{ replace .Text "-" "_" }}
+//
+// 模板可能来自请求，因此执行受到限制: range 只能遍历 .Attrs 等字段且不能嵌套，不支持 define、block 和 template，
+// printf 的宽度和精度不能超过999；单次渲染的输出不能超过元素内容加64KB，
+// 一次转换中所有模板的输出不能超过1MB加4倍输入大小，总执行时间不能超过2秒。
+type Rule struct {
+	Selector string `json:"selector" yaml:"selector"`                     // CSS选择器
+	Action   string `json:"action" yaml:"action"`                         // 动作: drop, keep, unwrap, template
+	Template string `json:"template,omitempty" yaml:"template,omitempty"` // 动作为template时使用的模板
+}
+
+// RuleData 模板可访问的元素数据
+type RuleData struct {
+	Tag      string            // 标签名
+	Attrs    map[string]string // 属性
+	Text     string            // 合并空白后的纯文本内容（已按Markdown转义）
+	Children string            // 子节点转换后的Markdown
+}
+
+// blankLines 连续的多个空行，子节点渲染结果中的空行在最终输出时才会合并
+var blankLines = regexp.MustCompile(`\n\s*\n(\s*\n)+`)
+
+// 模板执行的限制
+var (
+	templateOutputLimit = 64 << 10        // 单次渲染在元素内容之外最多输出的字节数
+	templateBudgetBase  = 1 << 20         // 一次转换中模板输出的基础预算，另加4倍输入大小
+	templateValueLimit  = 16 << 20        // 模板函数返回值的最大字节数
+	templateTimeout     = 2 * time.Second // 一次转换中模板的总执行时间
+)
+
+// errTemplateLimit 模板输出或执行时间超过限制
+var errTemplateLimit = errors.New("超过模板执行限制")
+
+// templateFuncs 模板可用的函数，覆盖内置的printf以限制宽度和精度。
+// 输出为空的函数调用不会经过 templateWriter，因此每次调用前检查执行期限
+func templateFuncs(b *templateBudget) template.FuncMap {
+	return template.FuncMap{
+		"trim": func(s string) (string, error) {
+			return strings.TrimSpace(s), b.check()
+		},
+		"quote": func(s string) (string, error) {
+			if err := b.check(); err != nil {
+				return "", err
+			}
+			return prefixLines("> ", s)
+		},
+		"prefix": func(prefix, s string) (string, error) {
+			if err := b.check(); err != nil {
+				return "", err
+			}
+			return prefixLines(prefix, s)
+		},
+		"replace": func(s, old, new string) (string, error) {
+			if err := b.check(); err != nil {
+				return "", err
+			}
+			return replaceAll(s, old, new)
+		},
+		"printf": func(format string, args ...any) (string, error) {
+			if err := b.check(); err != nil {
+				return "", err
+			}
+			return printf(format, args...)
+		},
+	}
+}
+
+// compiledRule 已解析的规则
+type compiledRule struct {
+	Rule
+	selector cascadia.Selector
+	tmpl     *template.Template
+}
+
+// ValidateRules 验证规则，返回的错误可用 errors.Is(err, ErrInvalidRule) 判断
+func ValidateRules(rules []Rule) error {
+	_, err := compileRules(rules)
+	return err
+}
+
+// compileRules 解析选择器和模板
+func compileRules(rules []Rule) ([]compiledRule, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for i, rule := range rules {
+		if strings.TrimSpace(rule.Selector) == "" {
+			return nil, fmt.Errorf("%w: 第%d条规则缺少选择器", ErrInvalidRule, i+1)
+		}
+		sel, err := cascadia.ParseGroup(rule.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 第%d条规则的选择器 %q 无效: %v", ErrInvalidRule, i+1, rule.Selector, err)
+		}
+
+		c := compiledRule{Rule: rule, selector: sel.Match}
+		switch rule.Action {
+		case ActionDrop, ActionKeep, ActionUnwrap:
+			if rule.Template != "" {
+				return nil, fmt.Errorf("%w: 第%d条规则的动作 %s 不使用模板", ErrInvalidRule, i+1, rule.Action)
+			}
+		case ActionTemplate:
+			if rule.Template == "" {
+				return nil, fmt.Errorf("%w: 第%d条规则缺少模板", ErrInvalidRule, i+1)
+			}
+			tmpl, err := template.New(rule.Selector).Funcs(templateFuncs(nil)).Option("missingkey=error").Parse(rule.Template)
+			if err == nil {
+				err = checkTemplate(tmpl)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: 第%d条规则的模板无效: %v", ErrInvalidRule, i+1, err)
+			}
+			c.tmpl = tmpl
+		default:
+			return nil, fmt.Errorf("%w: 第%d条规则的动作 %q 不受支持，可选: drop, keep, unwrap, template", ErrInvalidRule, i+1, rule.Action)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// checkTemplate 拒绝执行时间不受输出限制约束的模板结构: 嵌套模板调用、嵌套的range和遍历整数等非字段值的range。
+// 不嵌套时每个range最多执行属性数量次，函数调用再由执行期限约束
+func checkTemplate(tmpl *template.Template) error {
+	if len(tmpl.Templates()) > 1 {
+		return errors.New("不支持define和block")
+	}
+
+	var check func(n parse.Node) error
+	inRange := false
+	checkBranch := func(n *parse.BranchNode) error {
+		if err := check(n.List); err != nil {
+			return err
+		}
+		return check(n.ElseList)
+	}
+	check = func(n parse.Node) error {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return nil
+			}
+			for _, c := range n.Nodes {
+				if err := check(c); err != nil {
+					return err
+				}
+			}
+		case *parse.TemplateNode:
+			return errors.New("不支持template")
+		case *parse.RangeNode:
+			if !isFieldPipe(n.Pipe) {
+				return fmt.Errorf("range只能遍历字段，如 .Attrs: %s", n.Pipe)
+			}
+			if inRange {
+				return errors.New("不支持嵌套的range")
+			}
+			inRange = true
+			defer func() { inRange = false }()
+			return checkBranch(&n.BranchNode)
+		case *parse.IfNode:
+			return checkBranch(&n.BranchNode)
+		case *parse.WithNode:
+			return checkBranch(&n.BranchNode)
+		}
+		return nil
+	}
+	return check(tmpl.Tree.Root)
+}
+
+// isFieldPipe 判断管道是否只访问字段，如 .Attrs 或 $.Attrs
+func isFieldPipe(pipe *parse.PipeNode) bool {
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode:
+		return true
+	case *parse.VariableNode:
+		return len(arg.Ident) > 1
+	}
+	return false
+}
+
+// templateBudget 一次转换中模板的剩余输出字节数和执行期限
+type templateBudget struct {
+	remaining int
+	deadline  time.Time
+}
+
+// check 超过执行期限时返回错误，nil表示不受限制
+func (b *templateBudget) check() error {
+	if b != nil && time.Now().After(b.deadline) {
+		return fmt.Errorf("%w: 模板执行超过 %s", errTemplateLimit, templateTimeout)
+	}
+	return nil
+}
+
+// templateBudgetKey 在转换上下文中记录模板预算
+type templateBudgetKey struct{}
+
+// withTemplateBudget 返回带有模板预算的上下文，预算随输入大小增加
+func withTemplateBudget(ctx context.Context, inputSize int) context.Context {
+	return context.WithValue(ctx, templateBudgetKey{}, &templateBudget{
+		remaining: templateBudgetBase + 4*inputSize,
+		deadline:  time.Now().Add(templateTimeout),
+	})
+}
+
+// templateWriter 限制单次渲染输出并扣减转换预算的缓冲区
+type templateWriter struct {
+	bytes.Buffer
+	limit  int
+	budget *templateBudget
+}
+
+// Write 超过单次限制、转换预算或执行期限时返回错误，模板随即停止执行
+func (w *templateWriter) Write(p []byte) (int, error) {
+	if w.Len()+len(p) > w.limit {
+		return 0, fmt.Errorf("%w: 单次输出超过 %d 字节", errTemplateLimit, w.limit)
+	}
+	if b := w.budget; b != nil {
+		if len(p) > b.remaining {
+			return 0, fmt.Errorf("%w: 模板总输出超过预算", errTemplateLimit)
+		}
+		if err := b.check(); err != nil {
+			return 0, err
+		}
+		b.remaining -= len(p)
+	}
+	return w.Buffer.Write(p)
+}
+
+// rulesPlugin 按规则处理匹配的元素
+type rulesPlugin struct {
+	rules []compiledRule
+}
+
+// renderErrorKey 在转换上下文中记录模板或插件执行错误
+type renderErrorKey struct{}
+
+// Name 插件名称
+func (p *rulesPlugin) Name() string {
+	return "rules"
+}
+
+// Init 注册规则处理，优先于内置插件执行
+func (p *rulesPlugin) Init(conv *converter.Converter) error {
+	conv.Register.PreRenderer(p.preRender, converter.PriorityEarly-10)
+	conv.Register.Renderer(p.render, converter.PriorityEarly-10)
+	return nil
+}
+
+// match 获取第一条匹配元素的规则
+func (p *rulesPlugin) match(n *html.Node) *compiledRule {
+	if n.Type != html.ElementNode {
+		return nil
+	}
+	for i := range p.rules {
+		if p.rules[i].selector.Match(n) {
+			return &p.rules[i]
+		}
+	}
+	return nil
+}
+
+// preRender 在渲染前删除drop规则匹配的元素，避免表格等插件直接读取其内容
+func (p *rulesPlugin) preRender(ctx converter.Context, doc *html.Node) {
+	var visit func(n *html.Node)
+	visit = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; {
+			next := c.NextSibling
+			if rule := p.match(c); rule != nil && rule.Action == ActionDrop {
+				n.RemoveChild(c)
+			} else {
+				visit(c)
+			}
+			c = next
+		}
+	}
+	visit(doc)
+}
+
+// render 按规则渲染元素，未匹配时交给后续渲染器
+func (p *rulesPlugin) render(ctx converter.Context, w converter.Writer, n *html.Node) converter.RenderStatus {
+	rule := p.match(n)
+	if rule == nil {
+		return converter.RenderTryNext
+	}
+
+	switch rule.Action {
+	case ActionDrop:
+		return converter.RenderSuccess
+	case ActionKeep:
+		return base.RenderAsHTML(ctx, w, n)
+	case ActionUnwrap:
+		return base.RenderAsPlaintextWrapper(ctx, w, n)
+	}
+
+	budget, _ := ctx.Value(templateBudgetKey{}).(*templateBudget)
+	if err := budget.check(); err != nil {
+		recordRenderError(ctx, fmt.Errorf("%w: 规则 %q 的模板执行失败: %w", ErrInvalidRule, rule.Selector, err))
+		return converter.RenderSuccess
+	}
+
+	// 复制模板以绑定本次转换的预算，解析树在副本之间共享
+	tmpl, err := rule.tmpl.Clone()
+	if err != nil {
+		recordRenderError(ctx, fmt.Errorf("%w: 规则 %q 的模板执行失败: %v", ErrInvalidRule, rule.Selector, err))
+		return converter.RenderSuccess
+	}
+	tmpl.Funcs(templateFuncs(budget))
+
+	data := newRuleData(ctx, n)
+	out := &templateWriter{limit: len(data.Children) + len(data.Text) + templateOutputLimit, budget: budget}
+	if err := tmpl.Execute(out, data); err != nil {
+		recordRenderError(ctx, fmt.Errorf("%w: 规则 %q 的模板执行失败: %v", ErrInvalidRule, rule.Selector, err))
+		return converter.RenderSuccess
+	}
+	writeFragment(ctx, w, n, out.Bytes())
+	return converter.RenderSuccess
+}
+
+// newRuleData 收集元素数据，子节点会先转换为Markdown
+func newRuleData(ctx converter.Context, n *html.Node) RuleData {
+	var children bytes.Buffer
+	ctx.RenderChildNodes(ctx, &children, n)
+
+	data := RuleData{
+		Tag:      n.Data,
+		Attrs:    make(map[string]string, len(n.Attr)),
+		Text:     string(ctx.EscapeContent([]byte(nodeText(n)))),
+		Children: strings.TrimSpace(blankLines.ReplaceAllString(children.String(), "\n\n")),
+	}
+	for _, a := range n.Attr {
+		data.Attrs[a.Key] = a.Val
+	}
+	return data
+}
+
+// writeFragment 写入自定义渲染结果，块级元素前后补充空行
+func writeFragment(ctx converter.Context, w converter.Writer, n *html.Node, fragment []byte) {
+	block := false
+	if tagType, _ := ctx.GetTagType(n.Data); tagType == converter.TagTypeBlock {
+		block = true
+	}
+	if block {
+		w.WriteString("\n\n")
+	}
+	w.Write(fragment)
+	if block {
+		w.WriteString("\n\n")
+	}
+}
+
+// withRenderErrors 返回用于收集渲染错误的上下文，只保留第一个错误
+func withRenderErrors(ctx context.Context) (context.Context, *error) {
+	var err error
+	return context.WithValue(ctx, renderErrorKey{}, &err), &err
+}
+
+// recordRenderError 记录渲染错误，转换结束后由 ConvertString 返回
+func recordRenderError(ctx context.Context, err error) {
+	if holder, ok := ctx.Value(renderErrorKey{}).(*error); ok && *holder == nil {
+		*holder = err
+	}
+}
+
+// nodeText 获取元素的纯文本内容，合并连续空白
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var visit func(*html.Node)
+	visit = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// prefixLines 为每一行添加前缀
+func prefixLines(prefix, s string) (string, error) {
+	if err := checkValueSize(len(s) + (strings.Count(s, "\n")+1)*len(prefix)); err != nil {
+		return "", err
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(prefix+line, " ")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// replaceAll 替换字符串，在分配内存前检查结果大小
+func replaceAll(s, old, new string) (string, error) {
+	n := strings.Count(s, old)
+	if old == "" {
+		n = utf8.RuneCountInString(s) + 1
+	}
+	if err := checkValueSize(len(s) + n*(len(new)-len(old))); err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(s, old, new), nil
+}
+
+// printfSpec 格式化动词及其前面的标志、宽度和精度
+var printfSpec = regexp.MustCompile(`%[^a-zA-Z%]*`)
+
+// printf 与内置printf相同，但拒绝来自参数的宽度和超过999的宽度或精度
+func printf(format string, args ...any) (string, error) {
+	for _, spec := range printfSpec.FindAllString(strings.ReplaceAll(format, "%%", ""), -1) {
+		if strings.Contains(spec, "*") || largeNumber.MatchString(spec) {
+			return "", fmt.Errorf("%w: printf的宽度或精度过大: %s", errTemplateLimit, spec)
+		}
+	}
+	s := fmt.Sprintf(format, args...)
+	return s, checkValueSize(len(s))
+}
+
+// largeNumber 4位及以上的数字
+var largeNumber = regexp.MustCompile(`\d{4,}`)
+
+// checkValueSize 检查模板函数返回值的大小
+func checkValueSize(n int) error {
+	if n > templateValueLimit {
+		return fmt.Errorf("%w: 函数结果超过 %d 字节", errTemplateLimit, templateValueLimit)
+	}
+	return nil
+}
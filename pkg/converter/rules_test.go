@@ -0,0 +1,194 @@
+package converter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []Rule
+		html  string
+		want  string
+	}{
+		{
+			name:  "drop",
+			rules: []Rule{{Selector: ".ads, nav", Action: ActionDrop}},
+			html:  `<nav>menu</nav><p>text</p><div class="ads">buy</div>`,
+			want:  "text",
+		},
+		{
+			name:  "keep",
+			rules: []Rule{{Selector: "kbd", Action: ActionKeep}},
+			html:  `<p>Press <kbd>Ctrl</kbd></p>`,
+			want:  "Press <kbd>Ctrl</kbd>",
+		},
+		{
+			name:  "unwrap",
+			rules: []Rule{{Selector: "strong", Action: ActionUnwrap}},
+			html:  `<p><strong>plain</strong></p>`,
+			want:  "plain",
+		},
+		{
+			name:  "template with functions",
+			rules: []Rule{{Selector: "div.warning", Action: ActionTemplate, Template: `{{ quote (printf "**Warning:** %s" .Children) }}`}},
+			html:  `<div class="warning"><p>careful</p></div>`,
+			want:  "> **Warning:** careful",
+		},
+		{
+			name:  "template attrs and replace",
+			rules: []Rule{{Selector: "span", Action: ActionTemplate, Template: `{{ replace .Text " " "_" }}{{ range $k, $v := .Attrs }}[{{ $k }}={{ $v }}]{{ end }}`}},
+			html:  `<p><span data-x="1">a b</span></p>`,
+			want:  "a_b[data-x=1]",
+		},
+		{
+			name: "first rule wins",
+			rules: []Rule{
+				{Selector: "p.keep", Action: ActionKeep},
+				{Selector: "p", Action: ActionDrop},
+			},
+			html: `<p class="keep">a</p><p>b</p>`,
+			want: `<p class="keep">a</p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := New(WithRules(tt.rules...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := conv.ConvertString(tt.html)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Markdown != tt.want {
+				t.Errorf("Markdown = %q, want %q", result.Markdown, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{name: "valid template", rule: Rule{Selector: "p", Action: ActionTemplate, Template: "{{ .Text }}"}},
+		{name: "range over attrs", rule: Rule{Selector: "p", Action: ActionTemplate, Template: "{{ range .Attrs }}{{ . }}{{ end }}"}},
+		{name: "range over root field", rule: Rule{Selector: "p", Action: ActionTemplate, Template: "{{ with .Tag }}{{ range $.Attrs }}{{ . }}{{ end }}{{ end }}"}},
+		{name: "missing selector", rule: Rule{Action: ActionDrop}, wantErr: true},
+		{name: "invalid selector", rule: Rule{Selector: "p[", Action: ActionDrop}, wantErr: true},
+		{name: "unknown action", rule: Rule{Selector: "p", Action: "nope"}, wantErr: true},
+		{name: "template on drop", rule: Rule{Selector: "p", Action: ActionDrop, Template: "x"}, wantErr: true},
+		{name: "missing template", rule: Rule{Selector: "p", Action: ActionTemplate}, wantErr: true},
+		{name: "parse error", rule: Rule{Selector: "p", Action: ActionTemplate, Template: "{{ .Text "}, wantErr: true},
+		{name: "range over int", rule: Rule{Selector: "p", Action: ActionTemplate, Template: "{{ range 50000000 }}x{{ end }}"}, wantErr: true},
+		{name: "range over variable", rule: Rule{Selector: "p", Action: ActionTemplate, Template: "{{ $n := 9 }}{{ range $n }}x{{ end }}"}, wantErr: true},
+		{name: "range over function", rule: Rule{Selector: "p", Action: ActionTemplate, Template: "{{ range len .Text }}x{{ end }}"}, wantErr: true},
+		{name: "nested range over int", rule: Rule{Selector: "p", Action: ActionTemplate, Template: "{{ if .Text }}{{ range 9 }}x{{ end }}{{ end }}"}, wantErr: true},
+		{name: "nested range", rule: Rule{Selector: "p", Action: ActionTemplate, Template: "{{ range .Attrs }}{{ range $.Attrs }}{{ range $.Attrs }}{{ end }}{{ end }}{{ end }}x"}, wantErr: true},
+		{name: "range in range else", rule: Rule{Selector: "p", Action: ActionTemplate, Template: "{{ range .Attrs }}{{ else }}{{ with .Tag }}{{ range $.Attrs }}{{ end }}{{ end }}{{ end }}"}, wantErr: true},
+		{name: "sequential range", rule: Rule{Selector: "p", Action: ActionTemplate, Template: "{{ range .Attrs }}{{ . }}{{ end }}{{ range $k, $v := .Attrs }}{{ $k }}{{ end }}"}},
+		{name: "define", rule: Rule{Selector: "p", Action: ActionTemplate, Template: `{{ define "x" }}{{ template "x" }}{{ end }}`}, wantErr: true},
+		{name: "block", rule: Rule{Selector: "p", Action: ActionTemplate, Template: `{{ block "x" . }}y{{ end }}`}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRules([]Rule{tt.rule})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("ValidateRules() error = %v, want %v", err, ErrInvalidRule)
+			}
+		})
+	}
+}
+
+func TestRuleTemplateLimits(t *testing.T) {
+	large := "<p>" + strings.Repeat("word ", 20000) + "</p>"
+
+	tests := []struct {
+		name     string
+		template string
+		html     string
+		wantErr  string
+	}{
+		{name: "children copied", template: "{{ .Children }}", html: "<div>" + large + "</div>"},
+		{name: "per call output", template: `{{ range .Attrs }}{{ printf "%999s" . }}{{ end }}`, html: `<div ` + attrs(100) + `>x</div>`, wantErr: "单次输出"},
+		{name: "duplicated children", template: "{{ .Children }}{{ .Children }}{{ .Children }}", html: "<div>" + large + "</div>", wantErr: "单次输出"},
+		{name: "printf width", template: `{{ printf "%0100000000d" 1 }}`, html: "<div>x</div>", wantErr: "printf"},
+		{name: "printf star width", template: `{{ printf "%*d" 100000000 1 }}`, html: "<div>x</div>", wantErr: "printf"},
+		{name: "printf percent", template: `{{ printf "100%% %s" .Text }}`, html: "<div>x</div>"},
+		{name: "replace empty", template: `{{ replace .Children "" .Children }}`, html: "<div>" + large + "</div>", wantErr: "函数结果"},
+		{name: "prefix", template: `{{ prefix .Children .Children }}`, html: "<div>" + strings.Repeat("<p>a b c</p>", 5000) + "</div>", wantErr: "函数结果"},
+		{name: "document budget", template: "{{ .Children }}{{ .Text }}", html: strings.Repeat("<div>", 30) + large + strings.Repeat("</div>", 30), wantErr: "预算"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := New(WithRules(Rule{Selector: "div", Action: ActionTemplate, Template: tt.template}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = conv.ConvertString(tt.html)
+			if (err != nil) != (tt.wantErr != "") {
+				t.Fatalf("ConvertString() error = %v, want %q", err, tt.wantErr)
+			}
+			if err != nil && (!errors.Is(err, ErrInvalidRule) || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("ConvertString() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRuleTemplateTimeout(t *testing.T) {
+	defer func(d time.Duration) { templateTimeout = d }(templateTimeout)
+	templateTimeout = 0
+
+	conv, err := New(WithRules(Rule{Selector: "span", Action: ActionTemplate, Template: "{{ .Text }}"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = conv.ConvertString("<p><span>a</span></p>")
+	if !errors.Is(err, ErrInvalidRule) || !errors.Is(err, errTemplateLimit) {
+		t.Errorf("ConvertString() error = %v, want template timeout", err)
+	}
+}
+
+func TestRuleTemplateFuncTimeout(t *testing.T) {
+	defer func(d time.Duration) { templateTimeout = d }(templateTimeout)
+	templateTimeout = 50 * time.Millisecond
+
+	// 函数结果不输出时执行期限仍然生效
+	conv, err := New(WithRules(Rule{Selector: "div", Action: ActionTemplate, Template: `{{ range .Attrs }}{{ $x := replace $.Children "a" "b" }}{{ end }}x`}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := "<div " + attrs(3000) + "><p>" + strings.Repeat("a ", 200000) + "</p></div>"
+	start := time.Now()
+	_, err = conv.ConvertString(html)
+	if !errors.Is(err, ErrInvalidRule) || !strings.Contains(err.Error(), "模板执行超过") {
+		t.Errorf("ConvertString() error = %v, want template timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("ConvertString() took %s", elapsed)
+	}
+}
+
+// attrs 生成n个属性
+func attrs(n int) string {
+	var b strings.Builder
+	for i := range n {
+		b.WriteString(" data-a")
+		b.WriteString(strings.Repeat("x", i))
+		b.WriteString(`="1"`)
+	}
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package converter
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Slugger 生成Markdown标题锚点，规则与GitHub一致
+//
+// 转为小写，去除标点符号，空格替换为连字符，保留中日韩等非拉丁文字；
+// 同一文档中重复的标题依次追加 -1、-2 后缀。
+type Slugger struct {
+	seen map[string]int
+}
+
+// NewSlugger 创建标题锚点生成器，每个文档使用独立的实例
+func NewSlugger() *Slugger {
+	return &Slugger{seen: make(map[string]int)}
+}
+
+// Slug 为标题文本生成文档内唯一的锚点
+func (s *Slugger) Slug(text string) string {
+	base := Slugify(text)
+	slug := base
+	for {
+		n, exists := s.seen[slug]
+		if !exists {
+			break
+		}
+		s.seen[slug] = n + 1
+		slug = base + "-" + strconv.Itoa(n+1)
+	}
+	s.seen[slug] = 0
+	return slug
+}
+
+// Slugify 将标题文本转换为锚点，不处理重复
+func Slugify(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsNumber(r), unicode.Is(unicode.Mn, r), r == '_', r == '-':
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,316 @@
+package converter
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// monospaceFonts 等宽字体名称中的关键字
+var monospaceFonts = []string{"monospace", "courier", "consolas", "menlo", "monaco", "lucida console", "source code pro", "fira code"}
+
+// fontSizeKeywords 字号关键字和font元素size属性对应的像素值
+var fontSizeKeywords = map[string]float64{
+	"x-small": 10, "small": 13, "medium": 16, "large": 18, "x-large": 24, "xx-large": 32, "xxx-large": 48,
+	"1": 10, "2": 13, "3": 16, "4": 18, "5": 24, "6": 32, "7": 48,
+}
+
+// headingSizes 推断为标题的最小字号（像素），依次对应h1至h3
+var headingSizes = []float64{32, 24, 20}
+
+// maxHeadingText 推断为标题的段落最多包含的字符数，更长的视为正文
+const maxHeadingText = 120
+
+// inferStyles 按内联样式推断语义
+//
+// 删除隐藏的元素，字号较大的短段落转为标题，加粗、斜体、删除线、上下标和等宽字体转为对应元素。
+func inferStyles(doc *html.Node) {
+	filterNodes(doc, func(n *html.Node) bool {
+		return n.Type != html.ElementNode || !isStyleHidden(n)
+	})
+	// 先推断标题，字号可能写在随后会被替换的span上
+	headings := inferHeadings(doc, nil)
+	inferInlineStyles(doc, false)
+	for _, h := range headings {
+		unwrapSoleBold(h)
+	}
+}
+
+// isStyleHidden 判断元素是否通过样式或属性隐藏
+func isStyleHidden(n *html.Node) bool {
+	if _, ok := getAttr(n, "hidden"); ok || attrValue(n, "aria-hidden") == "true" {
+		return true
+	}
+	props := styleProps(n)
+	return props["display"] == "none" || props["visibility"] == "hidden"
+}
+
+// inferHeadings 将字号较大的短段落和独占一行的span、font转为标题，返回转换后的标题
+//
+// 列表、表格、链接和已有标题中的段落保持不变。
+func inferHeadings(n *html.Node, headings []*html.Node) []*html.Node {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch {
+		case isElement(c, "li", "table", "a", "pre", "h1", "h2", "h3", "h4", "h5", "h6"):
+		case isElement(c, "p", "div") && !containsElement(c, blockTags...):
+			if level := headingLevel(c); level > 0 {
+				setHeading(c, level)
+				headings = append(headings, c)
+			} else {
+				headings = inferHeadings(c, headings)
+			}
+		case isElement(c, "span", "font"):
+			if level := headingLevel(c); level > 0 && ownsLine(c) {
+				// 标题是块级元素，前后的换行不再需要
+				if br := adjacent(c, true); br != nil && isElement(br, "br") {
+					removeNode(br)
+				}
+				if br := adjacent(c, false); br != nil && isElement(br, "br") {
+					next = br.NextSibling
+					removeNode(br)
+				}
+				setHeading(c, level)
+				headings = append(headings, c)
+			}
+		default:
+			headings = inferHeadings(c, headings)
+		}
+		c = next
+	}
+	return headings
+}
+
+// setHeading 将元素改为指定级别的标题
+func setHeading(n *html.Node, level int) {
+	tag := "h" + strconv.Itoa(level)
+	n.Data, n.DataAtom, n.Attr = tag, atom.Lookup([]byte(tag)), nil
+}
+
+// lineBreakTags 前后紧邻时使行内元素独占一行的元素
+var lineBreakTags = []string{"br", "div", "p", "table", "ul", "ol", "blockquote", "pre", "hr", "h1", "h2", "h3", "h4", "h5", "h6"}
+
+// ownsLine 判断行内元素是否独占一行，即前后为换行、块级元素或父元素的边界
+func ownsLine(n *html.Node) bool {
+	for _, sib := range []*html.Node{adjacent(n, true), adjacent(n, false)} {
+		if sib != nil && !isElement(sib, lineBreakTags...) {
+			return false
+		}
+	}
+	return true
+}
+
+// adjacent 获取相邻的兄弟节点，跳过空白文本和注释，prev为true时向前查找
+func adjacent(n *html.Node, prev bool) *html.Node {
+	step := func(n *html.Node) *html.Node {
+		if prev {
+			return n.PrevSibling
+		}
+		return n.NextSibling
+	}
+	sib := step(n)
+	for sib != nil && (sib.Type == html.CommentNode || (sib.Type == html.TextNode && strings.TrimSpace(sib.Data) == "")) {
+		sib = step(sib)
+	}
+	return sib
+}
+
+// headingLevel 按段落或其唯一子元素的字号计算标题级别，不是标题时返回0
+func headingLevel(p *html.Node) int {
+	text := nodeText(p)
+	if text == "" || len([]rune(text)) > maxHeadingText {
+		return 0
+	}
+	size, ok := fontSize(p)
+	if !ok {
+		if child := soleElement(p); child != nil {
+			size, ok = fontSize(child)
+		}
+	}
+	if !ok {
+		return 0
+	}
+	for i, min := range headingSizes {
+		if size >= min {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// soleElement 元素只包含一个子元素（忽略空白文本和注释）时返回该子元素
+func soleElement(n *html.Node) *html.Node {
+	var child *html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode && strings.TrimSpace(c.Data) == "":
+		case c.Type == html.CommentNode:
+		case c.Type == html.ElementNode && child == nil:
+			child = c
+		default:
+			return nil
+		}
+	}
+	return child
+}
+
+// fontSize 获取元素的字号（像素），支持px、pt、em、rem、关键字和font元素的size属性
+func fontSize(n *html.Node) (float64, bool) {
+	value := styleProps(n)["font-size"]
+	if value == "" && isElement(n, "font") {
+		value = strings.TrimSpace(attrValue(n, "size"))
+	}
+	if size, ok := fontSizeKeywords[value]; ok {
+		return size, true
+	}
+	for _, unit := range []struct {
+		suffix string
+		scale  float64
+	}{{"px", 1}, {"pt", 4.0 / 3}, {"rem", 16}, {"em", 16}} {
+		if number, ok := strings.CutSuffix(value, unit.suffix); ok {
+			size, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+			return size * unit.scale, err == nil
+		}
+	}
+	return 0, false
+}
+
+// inferInlineStyles 按样式将span和font替换为语义元素，不含块级元素的段落、单元格等包裹对应元素
+//
+// 声明为正常字重或字形的b、strong、i、em展开为普通文本，code中的等宽字体不再重复转换。
+func inferInlineStyles(n *html.Node, inCode bool) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type != html.ElementNode {
+			c = next
+			continue
+		}
+		code := inCode || isElement(c, "pre", "code", "kbd", "samp")
+		inferInlineStyles(c, code)
+		props := styleProps(c)
+		switch {
+		case isElement(c, "span", "font"):
+			if tags := styleTags(c, !code); len(tags) > 0 && strings.TrimSpace(nodeText(c)) != "" {
+				replaceElement(c, tags)
+			}
+		case isElement(c, "b", "strong") && (props["font-weight"] == "normal" || numericBelow(props["font-weight"], 600)),
+			isElement(c, "i", "em") && props["font-style"] == "normal":
+			unwrapNode(c)
+		case isElement(c, "p", "div", "td", "th", "li", "a", "h1", "h2", "h3", "h4", "h5", "h6") && !containsElement(c, blockTags...):
+			// 块级元素的等宽字体多为代码块，不转为行内代码
+			if tags := styleTags(c, false); len(tags) > 0 {
+				wrapChildren(c, tags)
+			}
+		}
+		c = next
+	}
+}
+
+// styleTags 按样式获取元素对应的语义标签，由外到内排列，code为true时等宽字体对应code
+func styleTags(n *html.Node, code bool) []string {
+	props := styleProps(n)
+	var tags []string
+	if weight := props["font-weight"]; weight == "bold" || weight == "bolder" || numericAtLeast(weight, 600) {
+		tags = append(tags, "strong")
+	}
+	if style := props["font-style"]; style == "italic" || style == "oblique" {
+		tags = append(tags, "em")
+	}
+	if strings.Contains(props["text-decoration"], "line-through") || strings.Contains(props["text-decoration-line"], "line-through") {
+		tags = append(tags, "del")
+	}
+	switch props["vertical-align"] {
+	case "super":
+		tags = append(tags, "sup")
+	case "sub":
+		tags = append(tags, "sub")
+	}
+	family := props["font-family"]
+	if family == "" && isElement(n, "font") {
+		family = strings.ToLower(attrValue(n, "face"))
+	}
+	if code && isMonospace(family) {
+		// 行内代码中不能包含强调，放在最内层
+		tags = append(tags, "code")
+	}
+	return tags
+}
+
+// isMonospace 判断字体列表中是否包含等宽字体
+func isMonospace(family string) bool {
+	for _, font := range monospaceFonts {
+		if strings.Contains(family, font) {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceElement 将元素改为tags中的第一个元素，其余元素依次嵌套在内
+func replaceElement(n *html.Node, tags []string) {
+	moveEdgeSpaces(n)
+	n.Data, n.DataAtom, n.Attr = tags[0], atom.Lookup([]byte(tags[0])), nil
+	wrapChildren(n, tags[1:])
+}
+
+// wrapChildren 用tags中的元素由外到内依次包裹元素的全部子节点
+func wrapChildren(n *html.Node, tags []string) {
+	inner := n
+	for _, tag := range tags {
+		el := newElement(tag)
+		for c := inner.FirstChild; c != nil; c = inner.FirstChild {
+			inner.RemoveChild(c)
+			el.AppendChild(c)
+		}
+		inner.AppendChild(el)
+		moveEdgeSpaces(el)
+		inner = el
+	}
+}
+
+// moveEdgeSpaces 将元素首尾文本中的空白移到元素外，避免相邻的强调元素在Markdown中粘连
+func moveEdgeSpaces(n *html.Node) {
+	if c := n.FirstChild; c != nil && c.Type == html.TextNode {
+		if trimmed := strings.TrimLeft(c.Data, " \t\r\n"); trimmed != c.Data {
+			n.Parent.InsertBefore(&html.Node{Type: html.TextNode, Data: c.Data[:len(c.Data)-len(trimmed)]}, n)
+			c.Data = trimmed
+		}
+	}
+	if c := n.LastChild; c != nil && c.Type == html.TextNode {
+		if trimmed := strings.TrimRight(c.Data, " \t\r\n"); trimmed != c.Data {
+			n.Parent.InsertBefore(&html.Node{Type: html.TextNode, Data: c.Data[len(trimmed):]}, n.NextSibling)
+			c.Data = trimmed
+		}
+	}
+}
+
+// unwrapSoleBold 标题和表头本身已加粗，去掉只包裹全部内容的b/strong，可位于只包裹全部内容的span、font中
+func unwrapSoleBold(h *html.Node) {
+	for n := h; n != nil; {
+		if b := onlyChild(n, "b"); b != nil {
+			unwrapNode(b)
+			return
+		}
+		if strong := onlyChild(n, "strong"); strong != nil {
+			unwrapNode(strong)
+			return
+		}
+		if n = soleElement(n); n != nil && !isElement(n, "span", "font") {
+			return
+		}
+	}
+}
+
+// numericAtLeast 判断数值型样式是否不小于min
+func numericAtLeast(value string, min float64) bool {
+	n, err := strconv.ParseFloat(value, 64)
+	return err == nil && n >= min
+}
+
+// numericBelow 判断数值型样式是否小于max
+func numericBelow(value string, max float64) bool {
+	n, err := strconv.ParseFloat(value, 64)
+	return err == nil && n < max
+}
@@ -0,0 +1,129 @@
+package converter
+
+import (
+	"slices"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestStyleInference(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"font-weight bold", `<p><span style="font-weight:bold">a</span> b</p>`, "**a** b"},
+		{"font-weight 700", `<p><span style="font-weight:700">a</span> b</p>`, "**a** b"},
+		{"font-weight 600", `<p><span style="font-weight: 600">a</span> b</p>`, "**a** b"},
+		{"font-weight bolder", `<p><span style="font-weight:bolder">a</span> b</p>`, "**a** b"},
+		{"font-weight 400", `<p><span style="font-weight:400">a</span> b</p>`, "a b"},
+		{"normal weight b unwrapped", `<p><b style="font-weight:normal">a</b> b</p>`, "a b"},
+		{"italic", `<p><span style="font-style:italic">a</span> b</p>`, "*a* b"},
+		{"oblique", `<p><span style="font-style:oblique">a</span> b</p>`, "*a* b"},
+		{"normal style em unwrapped", `<p><em style="font-style:normal">a</em> b</p>`, "a b"},
+		{"bold italic", `<p><span style="font-weight:700;font-style:italic">a</span> b</p>`, "***a*** b"},
+		{"text-decoration line-through", `<p><span style="text-decoration:line-through">old</span> new</p>`, "~~old~~ new"},
+		{"text-decoration-line", `<p><span style="text-decoration-line:underline line-through">old</span> new</p>`, "~~old~~ new"},
+		{"monospace span", `<p>run <span style="font-family:'Courier New', monospace">ls</span></p>`, "run `ls`"},
+		{"font face", `<p>run <font face="Consolas">ls</font></p>`, "run `ls`"},
+		{"monospace paragraph not code", `<p style="font-family:monospace">ls -l</p>`, "ls -l"},
+		{"monospace inside code", `<p><code><span style="font-family:monospace">ls</span></code></p>`, "`ls`"},
+		{"bold paragraph", `<p style="font-weight:700">a b</p>`, "**a b**"},
+		{"heading px", `<p style="font-size:32px">Title</p><p>body</p>`, "# Title\n\nbody"},
+		{"heading pt", `<p><span style="font-size:18pt">Title</span></p><p>body</p>`, "## Title\n\nbody"},
+		{"heading em", `<p style="font-size:1.25em">Title</p>`, "### Title"},
+		{"heading rem", `<p style="font-size:2rem">Title</p>`, "# Title"},
+		{"heading keyword", `<p style="font-size:x-large">Title</p>`, "## Title"},
+		{"heading font size", `<p><font size="6">Title</font></p>`, "# Title"},
+		{"small font not heading", `<p style="font-size:16px">Text</p>`, "Text"},
+		{"long paragraph not heading", `<p style="font-size:32px">` + longText + `</p>`, longText},
+		{"list item not heading", `<ul><li><p style="font-size:32px">Item</p></li></ul>`, "- Item"},
+		{"heading bold unwrapped", `<p><span style="font-size:32px"><b>Title</b></span></p>`, "# Title"},
+		{"span heading before block", `<span style="font-size:32px">Title</span><p>body</p>`, "# Title\n\nbody"},
+		{"span heading before br", `<div><span style="font-size:24px">Title</span><br>body text</div>`, "## Title\n\nbody text"},
+		{"span heading between br", `<div>intro<br><span style="font-size:24px">Title</span><br>body</div>`, "intro\n\n## Title\n\nbody"},
+		{"drop cap not heading", `<p><span style="font-size:48px">O</span>nce upon a time</p>`, "Once upon a time"},
+		{"display none", `<p>a<span style="display: none">b</span>c</p>`, "ac"},
+		{"visibility hidden", `<p>a<span style="visibility:hidden">b</span>c</p>`, "ac"},
+		{"hidden attribute", `<p>a<span hidden>b</span>c</p>`, "ac"},
+		{"aria-hidden", `<p>a<span aria-hidden="true">b</span>c</p>`, "ac"},
+		{"google docs title", `<b id="docs-internal-guid-1"><p dir="ltr"><span style="font-size:26pt;font-weight:400">Doc Title</span></p><p><span style="font-weight:700">body</span></p></b>`, "# Doc Title\n\n**body**"},
+	}
+
+	conv, err := New(WithStyleInference(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := conv.ConvertString(tt.html)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Markdown != tt.want {
+				t.Errorf("Markdown = %q, want %q", result.Markdown, tt.want)
+			}
+		})
+	}
+}
+
+func TestStyleTags(t *testing.T) {
+	tests := []struct {
+		style string
+		code  bool
+		want  []string
+	}{
+		{"font-weight:700;font-style:italic", true, []string{"strong", "em"}},
+		{"text-decoration:underline line-through", true, []string{"del"}},
+		{"vertical-align:super", true, []string{"sup"}},
+		{"vertical-align:sub", true, []string{"sub"}},
+		{"font-family:Menlo;font-weight:bold", true, []string{"strong", "code"}},
+		{"font-family:Menlo", false, nil},
+		{"color:red;font-size:12px", true, nil},
+	}
+	for _, tt := range tests {
+		n := newElement("span")
+		n.Attr = append(n.Attr, html.Attribute{Key: "style", Val: tt.style})
+		if got := styleTags(n, tt.code); !slices.Equal(got, tt.want) {
+			t.Errorf("styleTags(%q, %v) = %v, want %v", tt.style, tt.code, got, tt.want)
+		}
+	}
+}
+
+const longText = "This paragraph is far too long to be a heading even though its font size is large, because headings are short and body text usually is not."
+
+func TestStyleInferenceDisabled(t *testing.T) {
+	conv, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := conv.ConvertString(`<p style="font-size:32px">Title</p><p><span style="font-weight:700">a</span> <span style="display:none">b</span></p>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Title\n\na b"; result.Markdown != want {
+		t.Errorf("Markdown = %q, want %q", result.Markdown, want)
+	}
+}
+
+func TestStyleInferencePlugins(t *testing.T) {
+	conv, err := New(WithPlugins("base", "commonmark"), WithStyleInference(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(conv.opts.plugins, "strikethrough") {
+		t.Errorf("plugins = %v, want strikethrough added", conv.opts.plugins)
+	}
+	if slices.Contains(DefaultPlugins, "strikethrough") {
+		t.Errorf("DefaultPlugins modified: %v", DefaultPlugins)
+	}
+
+	conv, err = New(WithPlugins("base", "commonmark", "strikethrough"), WithStyleInference(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(conv.opts.plugins); n != 3 {
+		t.Errorf("plugins = %v, want strikethrough once", conv.opts.plugins)
+	}
+}
@@ -0,0 +1,331 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	"github.com/andybalholm/cascadia"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"golang.org/x/net/html"
+)
+
+// 插件单次调用的默认限制
+const (
+	DefaultWasmMaxMemory = 16 * 1024 * 1024
+	DefaultWasmTimeout   = 100 * time.Millisecond
+)
+
+// 插件模块需要导出的函数
+const (
+	wasmAllocExport  = "html2md_alloc"
+	wasmRenderExport = "html2md_render"
+)
+
+// wasmPageSize WebAssembly内存页大小
+const wasmPageSize = 64 * 1024
+
+// wasmCache 进程内共享的编译缓存，多个宿主加载同一模块或热加载时无需重新编译
+var wasmCache = wazero.NewCompilationCache()
+
+// ErrPluginFailed 插件执行失败，如超时、超出内存限制或返回无效结果
+var ErrPluginFailed = errors.New("插件执行失败")
+
+// WasmPlugin WebAssembly插件配置
+//
+// 模块需导出 memory 以及以下函数:
+//
+//	html2md_alloc(size i32) i32          在模块内存中分配size字节，返回地址
+//	html2md_render(ptr i32, len i32) i64 渲染元素，输入为 WasmElement 的JSON编码，
+//	                                     返回值高32位为Markdown片段的地址、低32位为长度，
+//	                                     返回0表示不处理该元素，交给内置渲染器
+//
+// 每次调用都在新的模块实例中执行，调用之间不共享状态。模块可导入WASI，
+// 但无法访问文件系统、网络和环境变量。
+type WasmPlugin struct {
+	Name      string        `json:"name" yaml:"name"`                                 // 插件名称，请求通过该名称启用
+	Path      string        `json:"path" yaml:"path"`                                 // .wasm文件路径
+	Selector  string        `json:"selector" yaml:"selector"`                         // 交给插件渲染的元素，CSS选择器
+	MaxMemory int           `json:"max_memory,omitempty" yaml:"max_memory,omitempty"` // 单次调用的内存上限（字节），默认16MB
+	Timeout   time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`       // 单次调用的时间上限，默认100ms
+}
+
+// WasmElement 传给插件的元素数据
+type WasmElement struct {
+	Tag      string            `json:"tag"`      // 标签名
+	Attrs    map[string]string `json:"attrs"`    // 属性
+	HTML     string            `json:"html"`     // 元素本身的HTML
+	Text     string            `json:"text"`     // 合并空白后的纯文本内容（已按Markdown转义）
+	Children string            `json:"children"` // 子节点转换后的Markdown
+}
+
+// ValidateWasmPlugins 检查插件配置，不读取模块文件
+func ValidateWasmPlugins(configs []WasmPlugin) error {
+	_, err := compileWasmConfigs(configs)
+	return err
+}
+
+// compileWasmConfigs 检查插件配置并解析选择器
+func compileWasmConfigs(configs []WasmPlugin) ([]cascadia.Selector, error) {
+	selectors := make([]cascadia.Selector, len(configs))
+	seen := make(map[string]bool, len(configs))
+	for i, cfg := range configs {
+		switch {
+		case cfg.Name == "":
+			return nil, fmt.Errorf("第%d个插件缺少名称", i+1)
+		case pluginFactories[cfg.Name] != nil || cfg.Name == "rules" || cfg.Name == "profiles":
+			return nil, fmt.Errorf("插件名称 %s 与内置插件冲突", cfg.Name)
+		case seen[cfg.Name]:
+			return nil, fmt.Errorf("插件名称 %s 重复", cfg.Name)
+		case cfg.Path == "":
+			return nil, fmt.Errorf("插件 %s 缺少模块路径", cfg.Name)
+		case cfg.MaxMemory < 0 || cfg.Timeout < 0:
+			return nil, fmt.Errorf("插件 %s 的资源限制不能为负数", cfg.Name)
+		case cfg.MaxMemory > 0 && cfg.MaxMemory < wasmPageSize:
+			return nil, fmt.Errorf("插件 %s 的内存上限不能小于 %d 字节", cfg.Name, wasmPageSize)
+		}
+		seen[cfg.Name] = true
+
+		sel, err := cascadia.ParseGroup(cfg.Selector)
+		if err != nil || strings.TrimSpace(cfg.Selector) == "" {
+			return nil, fmt.Errorf("插件 %s 的选择器 %q 无效", cfg.Name, cfg.Selector)
+		}
+		selectors[i] = sel.Match
+	}
+	return selectors, nil
+}
+
+// PluginHost 加载并运行WebAssembly插件，可由多个转换器共享
+type PluginHost struct {
+	mu      sync.RWMutex // 调用期间持有读锁，Close等待进行中的调用结束
+	closed  bool
+	plugins map[string]*wasmPlugin
+	names   []string
+}
+
+// wasmPlugin 已编译的插件模块
+type wasmPlugin struct {
+	host     *PluginHost
+	name     string
+	selector cascadia.Selector
+	timeout  time.Duration
+	runtime  wazero.Runtime
+	module   wazero.CompiledModule
+}
+
+// NewPluginHost 读取并编译插件模块，任一模块无效时返回错误
+func NewPluginHost(ctx context.Context, configs []WasmPlugin) (*PluginHost, error) {
+	selectors, err := compileWasmConfigs(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &PluginHost{plugins: make(map[string]*wasmPlugin, len(configs))}
+	for i, cfg := range configs {
+		p, err := loadWasmPlugin(ctx, cfg, selectors[i])
+		if err != nil {
+			h.Close()
+			return nil, err
+		}
+		p.host = h
+		h.plugins[cfg.Name] = p
+		h.names = append(h.names, cfg.Name)
+	}
+	return h, nil
+}
+
+// loadWasmPlugin 编译单个插件，每个插件使用独立的运行时以应用各自的内存上限
+func loadWasmPlugin(ctx context.Context, cfg WasmPlugin, selector cascadia.Selector) (*wasmPlugin, error) {
+	data, err := os.ReadFile(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("读取插件 %s 失败: %w", cfg.Name, err)
+	}
+
+	maxMemory, timeout := cfg.MaxMemory, cfg.Timeout
+	if maxMemory == 0 {
+		maxMemory = DefaultWasmMaxMemory
+	}
+	if timeout == 0 {
+		timeout = DefaultWasmTimeout
+	}
+
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(maxMemory/wasmPageSize)).
+		WithCloseOnContextDone(true).
+		WithCompilationCache(wasmCache))
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("初始化插件 %s 失败: %w", cfg.Name, err)
+	}
+
+	module, err := runtime.CompileModule(ctx, data)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("编译插件 %s 失败: %w", cfg.Name, err)
+	}
+	exports := module.ExportedFunctions()
+	for _, name := range []string{wasmAllocExport, wasmRenderExport} {
+		if _, ok := exports[name]; !ok {
+			runtime.Close(ctx)
+			return nil, fmt.Errorf("插件 %s 未导出函数 %s", cfg.Name, name)
+		}
+	}
+
+	return &wasmPlugin{
+		name:     cfg.Name,
+		selector: selector,
+		timeout:  timeout,
+		runtime:  runtime,
+		module:   module,
+	}, nil
+}
+
+// Names 已加载的插件名称，按配置顺序排列
+func (h *PluginHost) Names() []string {
+	if h == nil {
+		return nil
+	}
+	return slices.Clone(h.names)
+}
+
+// plugin 按名称获取插件
+func (h *PluginHost) plugin(name string) *wasmPlugin {
+	if h == nil {
+		return nil
+	}
+	return h.plugins[name]
+}
+
+// Close 等待进行中的调用结束后释放所有插件，之后匹配的元素交给内置渲染器处理
+func (h *PluginHost) Close() error {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+
+	var errs []error
+	for _, p := range h.plugins {
+		errs = append(errs, p.runtime.Close(context.Background()))
+	}
+	return errors.Join(errs...)
+}
+
+// Name 插件名称
+func (p *wasmPlugin) Name() string {
+	return p.name
+}
+
+// Init 注册渲染器，在内置插件之前、自定义规则之后执行
+func (p *wasmPlugin) Init(conv *converter.Converter) error {
+	conv.Register.Renderer(p.render, converter.PriorityEarly)
+	return nil
+}
+
+// render 将匹配的元素交给插件渲染
+func (p *wasmPlugin) render(ctx converter.Context, w converter.Writer, n *html.Node) converter.RenderStatus {
+	if n.Type != html.ElementNode || !p.selector.Match(n) {
+		return converter.RenderTryNext
+	}
+
+	data := newRuleData(ctx, n)
+	var raw bytes.Buffer
+	if err := html.Render(&raw, n); err != nil {
+		return converter.RenderTryNext
+	}
+	input, err := json.Marshal(WasmElement{
+		Tag:      data.Tag,
+		Attrs:    data.Attrs,
+		HTML:     raw.String(),
+		Text:     data.Text,
+		Children: data.Children,
+	})
+	if err != nil {
+		return converter.RenderTryNext
+	}
+
+	out, ok, err := p.call(ctx, input)
+	if err != nil {
+		recordRenderError(ctx, err)
+		return converter.RenderSuccess
+	}
+	if !ok {
+		return converter.RenderTryNext
+	}
+	writeFragment(ctx, w, n, out)
+	return converter.RenderSuccess
+}
+
+// call 在新的模块实例中执行一次渲染，ok为false表示插件不处理该元素
+func (p *wasmPlugin) call(ctx context.Context, input []byte) (out []byte, ok bool, err error) {
+	p.host.mu.RLock()
+	defer p.host.mu.RUnlock()
+	if p.host.closed {
+		return nil, false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	defer func() {
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("%w: %s 超过时间限制 %s", ErrPluginFailed, p.name, p.timeout)
+		}
+	}()
+
+	// 匿名实例可并发创建；_initialize 为reactor模块的初始化函数，不存在时跳过
+	mod, err := p.runtime.InstantiateModule(ctx, p.module, wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions("_initialize"))
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %s 实例化失败: %s", ErrPluginFailed, p.name, trapMessage(err))
+	}
+	defer mod.Close(context.Background())
+
+	mem := mod.Memory()
+	if mem == nil {
+		return nil, false, fmt.Errorf("%w: %s 未导出内存", ErrPluginFailed, p.name)
+	}
+
+	res, err := mod.ExportedFunction(wasmAllocExport).Call(ctx, uint64(len(input)))
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %s 分配内存失败: %s", ErrPluginFailed, p.name, trapMessage(err))
+	}
+	ptr := uint32(res[0])
+	if !mem.Write(ptr, input) {
+		return nil, false, fmt.Errorf("%w: %s 分配的内存地址越界", ErrPluginFailed, p.name)
+	}
+
+	res, err = mod.ExportedFunction(wasmRenderExport).Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %s 渲染失败: %s", ErrPluginFailed, p.name, trapMessage(err))
+	}
+	if res[0] == 0 {
+		return nil, false, nil
+	}
+
+	result, ok := mem.Read(uint32(res[0]>>32), uint32(res[0]))
+	if !ok {
+		return nil, false, fmt.Errorf("%w: %s 返回的结果地址越界", ErrPluginFailed, p.name)
+	}
+	// 实例关闭后内存失效，需要复制
+	return bytes.Clone(result), true, nil
+}
+
+// trapMessage 去掉运行时错误中附带的wasm调用栈
+func trapMessage(err error) string {
+	msg, _, _ := strings.Cut(err.Error(), "\n")
+	return msg
+}
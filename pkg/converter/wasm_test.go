@@ -0,0 +1,209 @@
+package converter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 测试模块中 html2md_render 的函数体
+var (
+	renderEcho = []byte{0x20, 0x00, 0xad, 0x42, 0x20, 0x86, 0x20, 0x01, 0xad, 0x84} // 返回输入本身
+	renderSkip = []byte{0x42, 0x00}                                                 // 不处理元素
+	renderLoop = []byte{0x03, 0x40, 0x0c, 0x00, 0x0b, 0x42, 0x00}                   // 无限循环
+	renderTrap = []byte{0x00}                                                       // unreachable
+)
+
+// wasmModule 生成导出 memory（1页）、html2md_alloc 和 html2md_render 的模块，alloc固定返回allocPtr
+func wasmModule(allocPtr int32, render []byte) []byte {
+	section := func(id byte, content ...byte) []byte {
+		return append([]byte{id, byte(len(content))}, content...)
+	}
+	name := func(s string) []byte {
+		return append([]byte{byte(len(s))}, s...)
+	}
+	body := func(expr []byte) []byte {
+		expr = append(append([]byte{0x00}, expr...), 0x0b)
+		return append([]byte{byte(len(expr))}, expr...)
+	}
+
+	var alloc []byte
+	alloc = append(alloc, 0x41)
+	for v := allocPtr; ; v >>= 7 {
+		b := byte(v & 0x7f)
+		if (v>>7 == 0 && b&0x40 == 0) || (v>>7 == -1 && b&0x40 != 0) {
+			alloc = append(alloc, b)
+			break
+		}
+		alloc = append(alloc, b|0x80)
+	}
+
+	var exports []byte
+	exports = append(exports, 3)
+	exports = append(append(exports, name("memory")...), 0x02, 0x00)
+	exports = append(append(exports, name(wasmAllocExport)...), 0x00, 0x00)
+	exports = append(append(exports, name(wasmRenderExport)...), 0x00, 0x01)
+
+	code := append([]byte{2}, body(alloc)...)
+	code = append(code, body(render)...)
+
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	module = append(module, section(1, 2, 0x60, 1, 0x7f, 1, 0x7f, 0x60, 2, 0x7f, 0x7f, 1, 0x7e)...)
+	module = append(module, section(3, 2, 0, 1)...)
+	module = append(module, section(5, 1, 0, 1)...)
+	module = append(module, section(7, exports...)...)
+	module = append(module, section(10, code...)...)
+	return module
+}
+
+// writeModule 将模块写入临时目录并返回路径
+func writeModule(t *testing.T, name string, module []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name+".wasm")
+	if err := os.WriteFile(path, module, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestValidateWasmPlugins(t *testing.T) {
+	valid := WasmPlugin{Name: "note", Path: "note.wasm", Selector: "x-note"}
+	with := func(f func(*WasmPlugin)) []WasmPlugin {
+		cfg := valid
+		f(&cfg)
+		return []WasmPlugin{cfg}
+	}
+
+	tests := []struct {
+		name    string
+		configs []WasmPlugin
+		wantErr bool
+	}{
+		{name: "valid", configs: []WasmPlugin{valid}},
+		{name: "missing name", configs: with(func(c *WasmPlugin) { c.Name = "" }), wantErr: true},
+		{name: "builtin name", configs: with(func(c *WasmPlugin) { c.Name = "table" }), wantErr: true},
+		{name: "rules name", configs: with(func(c *WasmPlugin) { c.Name = "rules" }), wantErr: true},
+		{name: "duplicate name", configs: []WasmPlugin{valid, valid}, wantErr: true},
+		{name: "missing path", configs: with(func(c *WasmPlugin) { c.Path = "" }), wantErr: true},
+		{name: "negative timeout", configs: with(func(c *WasmPlugin) { c.Timeout = -1 }), wantErr: true},
+		{name: "memory below one page", configs: with(func(c *WasmPlugin) { c.MaxMemory = 1024 }), wantErr: true},
+		{name: "empty selector", configs: with(func(c *WasmPlugin) { c.Selector = " " }), wantErr: true},
+		{name: "invalid selector", configs: with(func(c *WasmPlugin) { c.Selector = "x[" }), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateWasmPlugins(tt.configs); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateWasmPlugins() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewPluginHostErrors(t *testing.T) {
+	// 缺少 html2md_render 导出
+	noRender := wasmModule(1024, renderSkip)
+	noRender = []byte(strings.Replace(string(noRender), wasmRenderExport, "html2md_xxxxxx", 1))
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "missing file", path: filepath.Join(t.TempDir(), "missing.wasm")},
+		{name: "invalid module", path: writeModule(t, "invalid", []byte("not wasm"))},
+		{name: "missing export", path: writeModule(t, "noexport", noRender)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, err := NewPluginHost(context.Background(), []WasmPlugin{{Name: "p", Path: tt.path, Selector: "x-note"}})
+			if err == nil {
+				host.Close()
+				t.Fatal("NewPluginHost() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestWasmPluginRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		alloc   int32
+		render  []byte
+		want    []string
+		wantErr bool
+		timeout time.Duration
+	}{
+		{name: "echo", alloc: 1024, render: renderEcho, want: []string{`"tag":"x-note"`, `"attrs":{"level":"2"}`, `"text":"hi"`, `"children":"hi"`}},
+		{name: "skip", alloc: 1024, render: renderSkip, want: []string{"a hi"}},
+		{name: "timeout", alloc: 1024, render: renderLoop, timeout: 20 * time.Millisecond, wantErr: true},
+		{name: "trap", alloc: 1024, render: renderTrap, wantErr: true},
+		{name: "alloc out of bounds", alloc: wasmPageSize, render: renderEcho, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeModule(t, tt.name, wasmModule(tt.alloc, tt.render))
+			host, err := NewPluginHost(context.Background(), []WasmPlugin{{Name: "note", Path: path, Selector: "x-note", Timeout: tt.timeout}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer host.Close()
+
+			conv, err := New(WithPluginHost(host), WithPlugins("base", "commonmark", "note"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := conv.ConvertString(`<p>a <x-note level="2">hi</x-note></p>`)
+			if tt.wantErr {
+				if !errors.Is(err, ErrPluginFailed) {
+					t.Errorf("ConvertString() error = %v, want %v", err, ErrPluginFailed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(result.Markdown, want) {
+					t.Errorf("Markdown = %s, want %s", result.Markdown, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPluginHostClose(t *testing.T) {
+	path := writeModule(t, "echo", wasmModule(1024, renderEcho))
+	host, err := NewPluginHost(context.Background(), []WasmPlugin{{Name: "note", Path: path, Selector: "x-note"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := host.Names(); len(names) != 1 || names[0] != "note" {
+		t.Errorf("Names() = %v", names)
+	}
+
+	conv, err := New(WithPluginHost(host), WithPlugins("base", "commonmark", "note"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(strings.Join(conv.GetSupportedPlugins(), ","), "note") {
+		t.Errorf("GetSupportedPlugins() = %v", conv.GetSupportedPlugins())
+	}
+
+	if err := host.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := host.Close(); err != nil {
+		t.Errorf("second Close() = %v", err)
+	}
+	// 关闭后交给内置渲染器
+	result, err := conv.ConvertString(`<p><x-note>hi</x-note></p>`)
+	if err != nil || result.Markdown != "hi" {
+		t.Errorf("ConvertString() after Close = %+v, %v", result, err)
+	}
+}
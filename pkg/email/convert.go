@@ -0,0 +1,271 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"gopkg.in/yaml.v3"
+
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+	"github.com/relaxcloud-cn/html2md/pkg/site"
+)
+
+// ImageDir 图片的输出目录
+const ImageDir = "images"
+
+// Options 邮件转换选项
+type Options struct {
+	Converter    *converter.Converter // 转换器，为空时使用默认插件
+	FrontMatter  bool                 // 在开头输出由邮件头组成的YAML front matter
+	InlineImages bool                 // 将引用的图片内嵌为data URI，否则改写为 images/ 下的文件
+	MaxSize      int64                // 正文的最大字节数，0表示不限制
+}
+
+// Result 邮件转换结果
+type Result struct {
+	Header   Header   `json:"header"`   // 邮件头
+	Markdown string   `json:"markdown"` // Markdown文档
+	Images   []string `json:"images"`   // 已写入的图片
+}
+
+// Convert 转换r中的邮件（message/rfc822）或MHTML存档（multipart/related）
+//
+// 正文中按 cid: 或原始地址引用的图片在InlineImages为true时内嵌为data URI，
+// 否则改写为 images/ 下的相对路径并写入dst，dst为空时不写入图片。
+// 未被引用的附件和无法解析的图片地址保持不变。
+func Convert(ctx context.Context, r io.Reader, dst site.Writer, opts Options) (*Result, error) {
+	conv := opts.Converter
+	if conv == nil {
+		var err error
+		if conv, err = converter.New(); err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxSize > 0 && int64(len(m.body.data)) > opts.MaxSize {
+		return nil, fmt.Errorf("%w: 邮件正文 %d > %d 字节", converter.ErrInputTooLarge, len(m.body.data), opts.MaxSize)
+	}
+	text, err := m.body.text()
+	if err != nil {
+		return nil, err
+	}
+	if m.body.mediaType == "text/plain" {
+		text = plainToHTML(text)
+	}
+
+	doc, err := nethtml.Parse(strings.NewReader(text))
+	if err != nil {
+		return nil, fmt.Errorf("%w: 解析正文失败: %v", ErrInvalidMessage, err)
+	}
+	images := m.rewriteImages(doc, opts.InlineImages)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := nethtml.Render(&buf, doc); err != nil {
+		return nil, fmt.Errorf("渲染正文失败: %w", err)
+	}
+	converted, err := conv.ConvertString(buf.String())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Header: m.header, Markdown: converted.Markdown + "\n", Images: []string{}}
+	if opts.FrontMatter {
+		fm, err := frontMatter(m.header)
+		if err != nil {
+			return nil, err
+		}
+		result.Markdown = fm + result.Markdown
+	}
+
+	if dst != nil {
+		for _, img := range images {
+			if err := dst.WriteFile(img.name, img.part.data); err != nil {
+				return nil, fmt.Errorf("写入 %s 失败: %w", img.name, err)
+			}
+			result.Images = append(result.Images, img.name)
+		}
+	}
+	return result, nil
+}
+
+// image 正文引用的图片及其输出路径
+type image struct {
+	name string
+	part *part
+}
+
+// rewriteImages 改写正文中引用邮件内部分的图片地址，返回需要输出的图片
+func (m *message) rewriteImages(doc *nethtml.Node, inline bool) []image {
+	byCID := make(map[string]*part)
+	byLocation := make(map[string]*part)
+	for _, p := range m.parts {
+		if p == m.body {
+			continue
+		}
+		if p.contentID != "" {
+			byCID[p.contentID] = p
+		}
+		if p.location != "" {
+			byLocation[p.location] = p
+		}
+	}
+
+	var base *url.URL
+	if m.body.location != "" {
+		base, _ = url.Parse(m.body.location)
+	}
+
+	var images []image
+	names := make(map[*part]string)
+	used := make(map[string]bool)
+	walk(doc, func(n *nethtml.Node) {
+		for i := range n.Attr {
+			a := &n.Attr[i]
+			isImage := (n.DataAtom == atom.Img && a.Key == "src") || (n.Data == "image" && a.Key == "href")
+			if !isImage {
+				continue
+			}
+			p := resolve(strings.TrimSpace(a.Val), base, byCID, byLocation)
+			if p == nil {
+				continue
+			}
+			if inline {
+				a.Val = "data:" + p.mediaType + ";base64," + base64.StdEncoding.EncodeToString(p.data)
+				continue
+			}
+			name, ok := names[p]
+			if !ok {
+				name = uniqueName(p, used)
+				names[p] = name
+				images = append(images, image{name: name, part: p})
+			}
+			a.Val = (&url.URL{Path: name}).String()
+		}
+	})
+	return images
+}
+
+// resolve 查找图片地址引用的部分，cid: 地址按Content-ID查找，其余按Content-Location查找
+func resolve(src string, base *url.URL, byCID, byLocation map[string]*part) *part {
+	if len(src) > 4 && strings.EqualFold(src[:4], "cid:") {
+		id, err := url.PathUnescape(src[4:])
+		if err != nil {
+			id = src[4:]
+		}
+		return byCID[id]
+	}
+	if p := byLocation[src]; p != nil {
+		return p
+	}
+	if base == nil {
+		return nil
+	}
+	ref, err := url.Parse(src)
+	if err != nil {
+		return nil
+	}
+	return byLocation[base.ResolveReference(ref).String()]
+}
+
+// uniqueName 生成图片的输出路径，优先使用附件文件名，重名时追加序号
+func uniqueName(p *part, used map[string]bool) string {
+	name := p.filename
+	if name == "" && p.location != "" {
+		if u, err := url.Parse(p.location); err == nil {
+			name = path.Base(u.Path)
+		}
+	}
+	if name == "" {
+		name = p.contentID
+		if at := strings.IndexByte(name, '@'); at > 0 {
+			name = name[:at]
+		}
+	}
+	name = sanitizeName(name)
+	if name == "" || name == "." || name == "/" {
+		name = "image"
+	}
+	if path.Ext(name) == "" {
+		if exts, err := mime.ExtensionsByType(p.mediaType); err == nil && len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	candidate := path.Join(ImageDir, name)
+	for i := 2; used[candidate]; i++ {
+		candidate = path.Join(ImageDir, fmt.Sprintf("%s-%d%s", stem, i, ext))
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// sanitizeName 去掉文件名中的目录部分和不适合作为文件名的字符
+func sanitizeName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20 || strings.ContainsRune(`:*?"<>|`, r):
+			return -1
+		case r == ' ':
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// plainToHTML 将纯文本正文转为HTML，空行分段，段内换行保留为<br>
+func plainToHTML(text string) string {
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	var b strings.Builder
+	for _, para := range strings.Split(text, "\n\n") {
+		para = strings.Trim(para, "\n")
+		if strings.TrimSpace(para) == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(para), "\n", "<br>\n"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
+
+// frontMatter 将邮件头编码为YAML front matter
+func frontMatter(h Header) (string, error) {
+	data, err := yaml.Marshal(h)
+	if err != nil {
+		return "", fmt.Errorf("生成front matter失败: %w", err)
+	}
+	if string(data) == "{}\n" {
+		return "", nil
+	}
+	return "---\n" + string(data) + "---\n\n", nil
+}
+
+// walk 深度优先遍历元素节点
+func walk(n *nethtml.Node, fn func(*nethtml.Node)) {
+	if n.Type == nethtml.ElementNode {
+		fn(n)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
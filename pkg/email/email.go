@@ -0,0 +1,247 @@
+// Package email 将电子邮件（.eml）和MHTML网页存档转换为Markdown
+//
+// 两者都是MIME消息：选取最合适的HTML正文（没有时使用纯文本正文），
+// 解码quoted-printable/base64传输编码和字符集，将 cid: 及MHTML中按地址引用的图片
+// 改写为随结果输出的文件或内嵌的data URI，并可在开头输出由邮件头组成的front matter。
+package email
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// 输入不合法时返回的错误，可用 errors.Is 判断
+var (
+	ErrInvalidMessage = errors.New("无效的邮件或MHTML文件")
+	ErrNoContent      = errors.New("邮件中没有HTML或纯文本正文")
+)
+
+// Header 用于front matter的邮件头
+type Header struct {
+	From    string `json:"from,omitempty" yaml:"from,omitempty"`       // 发件人
+	To      string `json:"to,omitempty" yaml:"to,omitempty"`           // 收件人
+	Subject string `json:"subject,omitempty" yaml:"subject,omitempty"` // 主题
+	Date    string `json:"date,omitempty" yaml:"date,omitempty"`       // 日期，能解析时为RFC 3339格式
+	URL     string `json:"url,omitempty" yaml:"url,omitempty"`         // MHTML存档的原始网页地址
+}
+
+// message 解析后的MIME消息
+type message struct {
+	header Header
+	body   *part   // 选中的正文
+	parts  []*part // 全部叶子部分，按出现顺序排列
+}
+
+// part MIME消息的叶子部分
+type part struct {
+	mediaType   string
+	params      map[string]string
+	contentID   string // 去掉尖括号的Content-ID
+	location    string // Content-Location，MHTML中资源的原始地址
+	attachment  bool   // Content-Disposition为attachment
+	filename    string
+	data        []byte // 已解码传输编码的内容
+	contentType string // 原始Content-Type，用于识别字符集
+}
+
+// wordDecoder 解码RFC 2047编码的邮件头，支持非UTF-8字符集
+var wordDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+// parse 解析MIME消息并选取正文
+func parse(r io.Reader) (*message, error) {
+	msg, err := mail.ReadMessage(bufio.NewReader(r))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	m := &message{header: parseHeader(msg.Header)}
+	if err := m.collect(textproto.MIMEHeader(msg.Header), msg.Body); err != nil {
+		return nil, err
+	}
+
+	// HTML正文优先，其次为纯文本正文
+	for _, mediaType := range []string{"text/html", "text/plain"} {
+		for _, p := range m.parts {
+			if p.mediaType == mediaType && !p.attachment {
+				m.body = p
+				return m, nil
+			}
+		}
+	}
+	return nil, ErrNoContent
+}
+
+// parseHeader 解码用于front matter的邮件头
+func parseHeader(h mail.Header) Header {
+	header := Header{
+		From:    addresses(h, "From"),
+		To:      addresses(h, "To"),
+		Subject: decodeWords(h.Get("Subject")),
+		Date:    strings.TrimSpace(h.Get("Date")),
+	}
+	if date, err := h.Date(); err == nil {
+		header.Date = date.Format(time.RFC3339)
+	}
+	// 浏览器保存的MHTML以 Snapshot-Content-Location 记录原始地址，From为 <Saved by ...> 占位
+	if location := strings.TrimSpace(h.Get("Snapshot-Content-Location")); location != "" {
+		header.URL = location
+		if strings.HasPrefix(header.From, "<Saved by") {
+			header.From = ""
+		}
+	}
+	return header
+}
+
+// addresses 解码地址列表，格式为 Name <addr>，无法解析时返回解码后的原文
+func addresses(h mail.Header, key string) string {
+	raw := h.Get(key)
+	if raw == "" {
+		return ""
+	}
+	parser := mail.AddressParser{WordDecoder: wordDecoder}
+	list, err := parser.ParseList(raw)
+	if err != nil {
+		return decodeWords(raw)
+	}
+	formatted := make([]string, len(list))
+	for i, addr := range list {
+		if addr.Name == "" {
+			formatted[i] = addr.Address
+		} else {
+			formatted[i] = fmt.Sprintf("%s <%s>", addr.Name, addr.Address)
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// decodeWords 解码RFC 2047编码的文本，失败时返回原文
+func decodeWords(s string) string {
+	decoded, err := wordDecoder.DecodeHeader(s)
+	if err != nil {
+		return strings.TrimSpace(s)
+	}
+	return strings.TrimSpace(decoded)
+}
+
+// collect 递归收集叶子部分，附带的邮件（message/rfc822）作为整体处理而不展开
+func (m *message) collect(header textproto.MIMEHeader, body io.Reader) error {
+	contentType := header.Get("Content-Type")
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// 缺少或无法解析的Content-Type按纯文本处理
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		boundary := params["boundary"]
+		if boundary == "" {
+			return fmt.Errorf("%w: %s 缺少boundary参数", ErrInvalidMessage, mediaType)
+		}
+		mr := multipart.NewReader(body, boundary)
+		for {
+			p, err := mr.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+			}
+			if err := m.collect(p.Header, p); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(decodeTransfer(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return fmt.Errorf("%w: 解码 %s 失败: %v", ErrInvalidMessage, mediaType, err)
+	}
+
+	p := &part{
+		mediaType:   mediaType,
+		params:      params,
+		contentID:   strings.Trim(strings.TrimSpace(header.Get("Content-ID")), "<>"),
+		location:    strings.TrimSpace(header.Get("Content-Location")),
+		data:        data,
+		contentType: contentType,
+		filename:    params["name"],
+	}
+	if disposition, dparams, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		p.attachment = disposition == "attachment"
+		if dparams["filename"] != "" {
+			p.filename = dparams["filename"]
+		}
+	}
+	p.filename = decodeWords(p.filename)
+	m.parts = append(m.parts, p)
+	return nil
+}
+
+// decodeTransfer 按Content-Transfer-Encoding解码内容
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	case "base64":
+		// 标准解码器会跳过换行，其余空白需要提前去除
+		return base64.NewDecoder(base64.StdEncoding, &spaceStripper{r: r})
+	default:
+		return r
+	}
+}
+
+// spaceStripper 去除base64内容中的空格和制表符
+type spaceStripper struct {
+	r io.Reader
+}
+
+// Read 读取并去除空格和制表符
+func (s *spaceStripper) Read(p []byte) (int, error) {
+	for {
+		n, err := s.r.Read(p)
+		kept := 0
+		for _, c := range p[:n] {
+			if c != ' ' && c != '\t' {
+				p[kept] = c
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+// text 将文本部分解码为UTF-8，HTML在未声明字符集时按meta标签识别
+func (p *part) text() (string, error) {
+	var r io.Reader
+	var err error
+	if p.mediaType == "text/html" {
+		r, err = charset.NewReader(bytes.NewReader(p.data), p.contentType)
+	} else if label := p.params["charset"]; label != "" {
+		r, err = charset.NewReaderLabel(label, bytes.NewReader(p.data))
+	} else {
+		return string(p.data), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: 不支持的字符集: %v", ErrInvalidMessage, err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("%w: 字符集转换失败: %v", ErrInvalidMessage, err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,288 @@
+package email
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+)
+
+// memWriter 将结果保存在内存中
+type memWriter map[string]string
+
+func (w memWriter) WriteFile(name string, data []byte) error {
+	w[name] = string(data)
+	return nil
+}
+
+// crlf 将换行转换为邮件使用的CRLF
+func crlf(s string) string {
+	return strings.ReplaceAll(s, "\n", "\r\n")
+}
+
+func gbkBase64(t *testing.T, s string) string {
+	t.Helper()
+	data, err := simplifiedchinese.GBK.NewEncoder().String(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString([]byte(data))
+}
+
+func TestConvertBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{
+			name: "html preferred over plain text",
+			message: `Content-Type: multipart/alternative; boundary="b1"
+
+--b1
+Content-Type: text/plain
+
+plain body
+--b1
+Content-Type: text/html; charset=utf-8
+
+<h1>Title</h1><p>html body</p>
+--b1--
+`,
+			want: "# Title\n\nhtml body\n",
+		},
+		{
+			name: "plain text quoted-printable latin1",
+			message: `Content-Type: text/plain; charset=iso-8859-1
+Content-Transfer-Encoding: quoted-printable
+
+Caf=E9 au lait=
+ please.
+Second line
+
+New paragraph <b>not bold</b>
+`,
+			want: "Café au lait please.  \nSecond line\n\nNew paragraph &lt;b&gt;not bold&lt;/b&gt;\n",
+		},
+		{
+			name: "html base64 gbk charset parameter",
+			message: `Content-Type: text/html; charset=gbk
+Content-Transfer-Encoding: base64
+
+` + "{{gbk}}" + `
+`,
+			want: "中文正文\n",
+		},
+		{
+			name: "html charset from meta",
+			message: `Content-Type: text/html
+Content-Transfer-Encoding: quoted-printable
+
+<meta charset=3D"windows-1252"><p>na=EFve</p>
+`,
+			want: "naïve\n",
+		},
+		{
+			name:    "missing content type is plain text",
+			message: "Subject: x\n\nhello",
+			want:    "hello\n",
+		},
+		{
+			name: "attachment is not body",
+			message: `Content-Type: multipart/mixed; boundary=b
+
+--b
+Content-Type: text/html
+Content-Disposition: attachment; filename=page.html
+
+<p>attached</p>
+--b
+Content-Type: text/plain
+
+body
+--b--
+`,
+			want: "body\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := strings.Replace(tt.message, "{{gbk}}", gbkBase64(t, "<p>中文正文</p>"), 1)
+			result, err := Convert(context.Background(), strings.NewReader(crlf(message)), nil, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Markdown != tt.want {
+				t.Errorf("Markdown = %q, want %q", result.Markdown, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertHeader(t *testing.T) {
+	message := `From: =?UTF-8?B?5byg5LiJ?= <zhang@example.com>
+To: a@example.com, "B, Jr." <b@example.com>
+Subject: =?GB2312?B?1tyxqA==?= report
+Date: Mon, 02 Jan 2006 15:04:05 +0800
+Content-Type: text/html
+
+<p>body</p>
+`
+	result, err := Convert(context.Background(), strings.NewReader(crlf(message)), nil, Options{FrontMatter: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Header{
+		From:    "张三 <zhang@example.com>",
+		To:      "a@example.com, B, Jr. <b@example.com>",
+		Subject: "周报 report",
+		Date:    "2006-01-02T15:04:05+08:00",
+	}
+	if result.Header != want {
+		t.Errorf("Header = %+v, want %+v", result.Header, want)
+	}
+	wantMD := "---\nfrom: 张三 <zhang@example.com>\nto: a@example.com, B, Jr. <b@example.com>\nsubject: 周报 report\ndate: \"2006-01-02T15:04:05+08:00\"\n---\n\nbody\n"
+	if result.Markdown != wantMD {
+		t.Errorf("Markdown = %q, want %q", result.Markdown, wantMD)
+	}
+}
+
+func TestConvertImages(t *testing.T) {
+	png := base64.StdEncoding.EncodeToString([]byte("PNG"))
+	message := `Content-Type: multipart/related; boundary=rel
+
+--rel
+Content-Type: text/html
+
+<p><img src="cid:logo@x" alt="L"><img src="cid:other@x"><img src="cid:logo@x"><img src="cid:missing"><img src="https://example.com/a.png"></p>
+--rel
+Content-Type: image/png
+Content-ID: <logo@x>
+Content-Disposition: inline; filename="../../logo.png"
+Content-Transfer-Encoding: base64
+
+` + png + `
+--rel
+Content-Type: image/png
+Content-ID: <other@x>
+Content-Disposition: inline; filename="logo.png"
+Content-Transfer-Encoding: base64
+
+` + png[:2] + " " + png[2:] + `
+--rel--
+`
+
+	t.Run("files", func(t *testing.T) {
+		out := memWriter{}
+		result, err := Convert(context.Background(), strings.NewReader(crlf(message)), out, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "![L](images/logo.png)![](images/logo-2.png)![](images/logo.png)![](cid:missing)![](https://example.com/a.png)\n"
+		if result.Markdown != want {
+			t.Errorf("Markdown = %q, want %q", result.Markdown, want)
+		}
+		if strings.Join(result.Images, ",") != "images/logo.png,images/logo-2.png" {
+			t.Errorf("Images = %v", result.Images)
+		}
+		if out["images/logo.png"] != "PNG" || out["images/logo-2.png"] != "PNG" {
+			t.Errorf("written = %v", out)
+		}
+	})
+
+	t.Run("inline", func(t *testing.T) {
+		out := memWriter{}
+		result, err := Convert(context.Background(), strings.NewReader(crlf(message)), out, Options{InlineImages: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(result.Markdown, "![L](data:image/png;base64,"+png+")") || len(out) != 0 {
+			t.Errorf("Markdown = %q, written = %v", result.Markdown, out)
+		}
+	})
+}
+
+func TestConvertMHTML(t *testing.T) {
+	message := `From: <Saved by Blink>
+Snapshot-Content-Location: https://example.com/docs/page.html
+Subject: Page
+MIME-Version: 1.0
+Content-Type: multipart/related; type="text/html"; boundary="----MultipartBoundary"
+
+------MultipartBoundary
+Content-Type: text/html
+Content-Location: https://example.com/docs/page.html
+
+<p><img src="img/a.gif"><img src="https://example.com/b.gif"></p>
+------MultipartBoundary
+Content-Type: image/gif
+Content-Location: https://example.com/docs/img/a.gif
+
+GIF
+------MultipartBoundary
+Content-Type: image/gif
+Content-Location: https://example.com/b.gif
+
+GIF
+------MultipartBoundary--
+`
+	out := memWriter{}
+	result, err := Convert(context.Background(), strings.NewReader(crlf(message)), out, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Header.URL != "https://example.com/docs/page.html" || result.Header.From != "" {
+		t.Errorf("Header = %+v", result.Header)
+	}
+	if result.Markdown != "![](images/a.gif)![](images/b.gif)\n" {
+		t.Errorf("Markdown = %q", result.Markdown)
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		opts    Options
+		wantErr error
+	}{
+		{name: "not a message", message: "no header separator", wantErr: ErrInvalidMessage},
+		{name: "multipart without boundary", message: "Content-Type: multipart/mixed\n\nbody", wantErr: ErrInvalidMessage},
+		{name: "truncated multipart", message: "Content-Type: multipart/mixed; boundary=b\n\n--b\nContent-Type: text/plain\n\nx", wantErr: ErrInvalidMessage},
+		{name: "only attachments", message: "Content-Type: multipart/mixed; boundary=b\n\n--b\nContent-Type: image/png\n\nx\n--b--\n", wantErr: ErrNoContent},
+		{name: "unknown charset", message: "Content-Type: text/plain; charset=x-nope\n\nbody", wantErr: ErrInvalidMessage},
+		{name: "body too large", message: "Content-Type: text/html\n\n<p>long body</p>", opts: Options{MaxSize: 5}, wantErr: converter.ErrInputTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Convert(context.Background(), strings.NewReader(crlf(tt.message)), nil, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Convert() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	tests := map[string]string{
+		"logo.png":          "logo.png",
+		"../../etc/passwd":  "passwd",
+		`C:\Users\a\b.png`:  "b.png",
+		"my photo?.jpg":     "my_photo.jpg",
+		"a\x00b<c>|d.gif":   "abcd.gif",
+		"dir/sub/file.jpeg": "file.jpeg",
+	}
+	for in, want := range tests {
+		if got := sanitizeName(in); got != want {
+			t.Errorf("sanitizeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
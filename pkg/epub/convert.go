@@ -0,0 +1,374 @@
+package epub
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+	"github.com/relaxcloud-cn/html2md/pkg/site"
+)
+
+// IndexName 分章输出时目录文件的名称
+const IndexName = "index.md"
+
+// Options EPUB转换选项
+type Options struct {
+	Converter   *converter.Converter  // 转换器，为空时使用默认插件
+	Split       bool                  // 每章输出一个Markdown文件，否则合并为单个文档
+	MaxFileSize int64                 // 单个文件的最大字节数，0表示不限制
+	MaxTotal    int64                 // 读取文件的总字节数上限，0表示不限制
+	Progress    func(done, total int) // 转换进度回调，done为已转换的章节数，可为空
+}
+
+// Result EPUB转换结果
+type Result struct {
+	Title    string    `json:"title"`              // 书名
+	Chapters []Chapter `json:"chapters"`           // 按阅读顺序排列的章节
+	Images   []string  `json:"images"`             // 已写入的图片
+	Markdown string    `json:"markdown,omitempty"` // 合并输出时的Markdown文档
+}
+
+// chapter 已解析的章节
+type chapter struct {
+	*Chapter
+	doc     *html.Node
+	anchors map[string]string // 元素id -> Markdown标题锚点
+	anchor  string            // 章节开头的标题锚点，合并输出时使用
+}
+
+// Convert 按阅读顺序转换src中的EPUB电子书
+//
+// 合并输出时文档开头为书名和章节目录，Markdown保存在 Result.Markdown 中，
+// 图片按其在书中相对OPF清单的路径写入dst，dst为空时不复制图片。
+// 分章输出时每个章节写入对应的 .md 文件，目录写入 index.md。
+// 任一章节转换失败时返回错误。
+func Convert(ctx context.Context, src fs.FS, dst site.Writer, opts Options) (*Result, error) {
+	conv := opts.Converter
+	if conv == nil {
+		var err error
+		if conv, err = converter.New(); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Split && dst == nil {
+		return nil, fmt.Errorf("分章输出需要指定输出位置")
+	}
+
+	reader := site.NewLimitedReader(src, opts.MaxFileSize, opts.MaxTotal)
+	b, err := openBook(reader)
+	if err != nil {
+		return nil, err
+	}
+	b.fsys = src
+	if b.title == "" {
+		b.title = strings.TrimSuffix(path.Base(b.chapters[0].Source), path.Ext(b.chapters[0].Source))
+	}
+
+	// 合并输出时所有章节共用一个slugger，书名标题排在最前
+	slugger := converter.NewSlugger()
+	if !opts.Split {
+		slugger.Slug(b.title)
+	}
+
+	// 第一遍：解析章节，建立锚点索引
+	chapters := make([]*chapter, len(b.chapters))
+	bySource := make(map[string]*chapter, len(b.chapters))
+	for i := range b.chapters {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		ch := &chapter{Chapter: &b.chapters[i]}
+		data, err := reader.ReadFile(ch.Source)
+		if err != nil {
+			return nil, err
+		}
+		if ch.doc, err = html.Parse(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("解析章节 %s 失败: %w", ch.Source, err)
+		}
+		if ch.Title == "" {
+			ch.Title = firstHeading(ch.doc)
+		}
+		if ch.Title == "" {
+			ch.Title = strings.TrimSuffix(path.Base(ch.Source), path.Ext(ch.Source))
+		}
+		if opts.Split {
+			ch.Output = site.MarkdownPath(b.output(ch.Source))
+			ch.anchors = site.Anchors(ch.doc, converter.NewSlugger())
+		} else {
+			// 章节开头的标题作为目录和章节链接的锚点，没有标题时补充
+			id := markFirstHeading(ch.doc)
+			if id == "" {
+				insertHeading(ch.doc, ch.Title)
+				id = headingID
+			}
+			ch.anchors = site.Anchors(ch.doc, slugger)
+			ch.anchor = ch.anchors[id]
+		}
+
+		chapters[i] = ch
+		bySource[ch.Source] = ch
+	}
+
+	// 第二遍：改写链接并转换
+	result := &Result{Title: b.title, Chapters: b.chapters}
+	images := make(map[string]bool)
+	parts := []string{"# " + b.title, toc(chapters, opts.Split)}
+	for i, ch := range chapters {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		docDir := "."
+		if opts.Split {
+			docDir = path.Dir(ch.Output)
+		}
+		for _, img := range b.rewrite(ch, bySource, docDir, opts.Split) {
+			images[img] = true
+		}
+
+		var buf bytes.Buffer
+		if err := html.Render(&buf, ch.doc); err != nil {
+			return nil, fmt.Errorf("渲染章节 %s 失败: %w", ch.Source, err)
+		}
+		converted, err := conv.ConvertString(buf.String())
+		if err != nil {
+			return nil, fmt.Errorf("转换章节 %s 失败: %w", ch.Source, err)
+		}
+
+		if opts.Split {
+			if err := dst.WriteFile(ch.Output, []byte(converted.Markdown+"\n")); err != nil {
+				return nil, fmt.Errorf("写入 %s 失败: %w", ch.Output, err)
+			}
+		} else if converted.Markdown != "" {
+			parts = append(parts, converted.Markdown)
+		}
+		if opts.Progress != nil {
+			opts.Progress(i+1, len(chapters))
+		}
+	}
+
+	if opts.Split {
+		index := "# " + b.title + "\n\n" + toc(chapters, true) + "\n"
+		if err := dst.WriteFile(IndexName, []byte(index)); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %w", IndexName, err)
+		}
+	} else {
+		result.Markdown = strings.Join(parts, "\n\n") + "\n"
+	}
+
+	// 复制被引用的图片
+	result.Images = []string{}
+	if dst != nil {
+		for _, img := range sortedKeys(images) {
+			data, err := reader.ReadFile(img)
+			if err != nil {
+				return nil, err
+			}
+			out := b.output(img)
+			if err := dst.WriteFile(out, data); err != nil {
+				return nil, fmt.Errorf("写入 %s 失败: %w", out, err)
+			}
+			result.Images = append(result.Images, out)
+		}
+	}
+
+	return result, nil
+}
+
+// output 文件的输出路径，相对于OPF清单所在目录，清单目录之外的文件保留原路径
+func (b *book) output(name string) string {
+	if b.root == "." {
+		return name
+	}
+	if rel, ok := strings.CutPrefix(name, b.root+"/"); ok {
+		return rel
+	}
+	return name
+}
+
+// rewrite 改写章节中的链接和图片地址，返回引用的图片
+//
+// 指向其他章节的链接在合并输出时改写为标题锚点，分章输出时改写为对应的 .md 文件；
+// 图片地址改写为相对于输出文档docDir的路径。无法解析的链接保持不变。
+func (b *book) rewrite(ch *chapter, chapters map[string]*chapter, docDir string, split bool) []string {
+	var images []string
+	walk(ch.doc, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+		for i := range n.Attr {
+			a := &n.Attr[i]
+			isLink := n.DataAtom == atom.A && a.Key == "href"
+			isImage := (n.DataAtom == atom.Img && a.Key == "src") || (n.Data == "image" && a.Key == "href")
+			if !isLink && !isImage {
+				continue
+			}
+
+			u, err := url.Parse(strings.TrimSpace(a.Val))
+			if err != nil || u.Scheme != "" || u.Host != "" {
+				continue
+			}
+			if u.Path == "" {
+				if slug, ok := ch.anchors[u.Fragment]; ok && isLink && u.Fragment != "" {
+					a.Val = "#" + slug
+				}
+				continue
+			}
+			target := path.Join(path.Dir(ch.Source), u.Path)
+
+			if isImage {
+				if _, err := fs.Stat(b.fsys, target); err == nil {
+					images = append(images, target)
+					a.Val = (&url.URL{Path: site.RelPath(docDir, b.output(target))}).String()
+				}
+				continue
+			}
+
+			linked, ok := chapters[target]
+			if !ok {
+				continue
+			}
+			slug, hasFragment := linked.anchors[u.Fragment]
+			if !hasFragment || u.Fragment == "" {
+				slug = ""
+			}
+			if split {
+				a.Val = (&url.URL{Path: site.RelPath(docDir, linked.Output), Fragment: slug}).String()
+			} else if slug != "" {
+				a.Val = "#" + slug
+			} else {
+				a.Val = "#" + linked.anchor
+			}
+		}
+		return true
+	})
+	return images
+}
+
+// toc 生成章节目录
+func toc(chapters []*chapter, split bool) string {
+	lines := make([]string, len(chapters))
+	for i, ch := range chapters {
+		href := "#" + ch.anchor
+		if split {
+			href = (&url.URL{Path: ch.Output}).String()
+		}
+		lines[i] = fmt.Sprintf("- [%s](%s)", escapeLinkText(ch.Title), href)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// headingID 为章节开头的标题补充的id
+const headingID = "html2md-chapter"
+
+// markFirstHeading 返回第一个标题的id，标题没有id时为其补充，没有标题时返回空字符串
+func markFirstHeading(doc *html.Node) string {
+	id := ""
+	walk(doc, func(n *html.Node) bool {
+		if id != "" || !isHeading(n) {
+			return id == ""
+		}
+		if id = attr(n, "id"); id == "" {
+			id = headingID
+			n.Attr = append(n.Attr, html.Attribute{Key: "id", Val: id})
+		}
+		return false
+	})
+	return id
+}
+
+// insertHeading 在body开头插入一级标题
+func insertHeading(doc *html.Node, title string) {
+	body := doc
+	walk(doc, func(n *html.Node) bool {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Body {
+			body = n
+			return false
+		}
+		return true
+	})
+	h := &html.Node{Type: html.ElementNode, Data: "h1", DataAtom: atom.H1, Attr: []html.Attribute{{Key: "id", Val: headingID}}}
+	h.AppendChild(&html.Node{Type: html.TextNode, Data: title})
+	body.InsertBefore(h, body.FirstChild)
+}
+
+// firstHeading 获取第一个标题的文本
+func firstHeading(doc *html.Node) string {
+	title := ""
+	walk(doc, func(n *html.Node) bool {
+		if title == "" && isHeading(n) {
+			title = textContent(n)
+		}
+		return title == ""
+	})
+	return title
+}
+
+// escapeLinkText 转义链接文本中的方括号
+func escapeLinkText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(s)
+}
+
+// walk 深度优先遍历节点，fn返回false时跳过子节点
+func walk(n *html.Node, fn func(*html.Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+// isHeading 判断是否为标题元素
+func isHeading(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		return true
+	}
+	return false
+}
+
+// attr 获取属性值
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// textContent 获取元素的文本内容，合并连续空白
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	walk(n, func(c *html.Node) bool {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+			b.WriteByte(' ')
+		}
+		return true
+	})
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// sortedKeys 返回排序后的键
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,242 @@
+// Package epub 将EPUB电子书转换为Markdown
+//
+// 按OPF清单中spine的顺序转换各章节，将章节间的链接改写为标题锚点或对应的Markdown文件，
+// 并复制引用的图片。可输出附带目录的单个Markdown文档，或每章一个Markdown文件。
+package epub
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/relaxcloud-cn/html2md/pkg/site"
+)
+
+// ErrInvalidEPUB 缺少容器描述、OPF清单无法解析或没有可转换的章节
+var ErrInvalidEPUB = errors.New("无效的EPUB文件")
+
+// containerPath EPUB容器描述文件，指向OPF清单
+const containerPath = "META-INF/container.xml"
+
+// Chapter 章节
+type Chapter struct {
+	Source string `json:"source"`           // 章节在EPUB中的路径
+	Title  string `json:"title"`            // 目录中的标题，缺失时为章节内的第一个标题
+	Output string `json:"output,omitempty"` // 分章输出时的Markdown文件路径
+}
+
+// book 已解析的EPUB清单
+type book struct {
+	fsys     fs.FS
+	title    string
+	root     string // OPF文件所在目录，输出路径相对于该目录
+	chapters []Chapter
+}
+
+// container META-INF/container.xml
+type container struct {
+	Rootfiles []struct {
+		FullPath  string `xml:"full-path,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// opfPackage OPF清单中用到的部分
+type opfPackage struct {
+	Titles   []string `xml:"metadata>title"`
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine struct {
+		Toc      string `xml:"toc,attr"`
+		Itemrefs []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+// ncx EPUB2目录文件
+type ncx struct {
+	NavPoints []ncxPoint `xml:"navMap>navPoint"`
+}
+
+// ncxPoint EPUB2目录项
+type ncxPoint struct {
+	Label   string `xml:"navLabel>text"`
+	Content struct {
+		Src string `xml:"src,attr"`
+	} `xml:"content"`
+	Points []ncxPoint `xml:"navPoint"`
+}
+
+// openBook 解析容器描述和OPF清单，按spine顺序列出章节并从目录中获取章节标题
+func openBook(reader *site.LimitedReader) (*book, error) {
+	data, err := reader.ReadFile(containerPath)
+	if err != nil {
+		if errors.Is(err, site.ErrLimitExceeded) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: 缺少 %s", ErrInvalidEPUB, containerPath)
+	}
+	var c container
+	if err := xml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%w: 解析 %s 失败: %v", ErrInvalidEPUB, containerPath, err)
+	}
+	opfPath := ""
+	for _, rf := range c.Rootfiles {
+		if rf.MediaType == "" || rf.MediaType == "application/oebps-package+xml" {
+			opfPath = rf.FullPath
+			break
+		}
+	}
+	if opfPath == "" {
+		return nil, fmt.Errorf("%w: %s 未指定OPF清单", ErrInvalidEPUB, containerPath)
+	}
+
+	data, err = reader.ReadFile(opfPath)
+	if err != nil {
+		if errors.Is(err, site.ErrLimitExceeded) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: 缺少OPF清单 %s", ErrInvalidEPUB, opfPath)
+	}
+	var opf opfPackage
+	if err := xml.Unmarshal(data, &opf); err != nil {
+		return nil, fmt.Errorf("%w: 解析OPF清单失败: %v", ErrInvalidEPUB, err)
+	}
+
+	b := &book{root: path.Dir(opfPath)}
+	if len(opf.Titles) > 0 {
+		b.title = strings.Join(strings.Fields(opf.Titles[0]), " ")
+	}
+
+	// 清单中的路径相对于OPF文件，且为URL编码
+	type item struct {
+		path, mediaType, properties string
+	}
+	items := make(map[string]item, len(opf.Manifest))
+	var navPath string
+	for _, it := range opf.Manifest {
+		href, err := url.PathUnescape(it.Href)
+		if err != nil {
+			href = it.Href
+		}
+		p := path.Join(b.root, href)
+		items[it.ID] = item{path: p, mediaType: it.MediaType, properties: it.Properties}
+		if hasProperty(it.Properties, "nav") {
+			navPath = p
+		}
+	}
+
+	var titles map[string]string
+	switch {
+	case navPath != "":
+		titles = navTitles(reader, navPath)
+	case items[opf.Spine.Toc].path != "":
+		titles = ncxTitles(reader, items[opf.Spine.Toc].path)
+	}
+
+	for _, ref := range opf.Spine.Itemrefs {
+		it, ok := items[ref.IDRef]
+		if !ok || !isXHTML(it.mediaType) || hasProperty(it.properties, "nav") {
+			continue
+		}
+		b.chapters = append(b.chapters, Chapter{Source: it.path, Title: titles[it.path]})
+	}
+	if len(b.chapters) == 0 {
+		return nil, fmt.Errorf("%w: spine中没有可转换的章节", ErrInvalidEPUB)
+	}
+	return b, nil
+}
+
+// navTitles 从EPUB3导航文档中获取每个章节的标题，读取或解析失败时返回空映射
+func navTitles(reader *site.LimitedReader, navPath string) map[string]string {
+	titles := make(map[string]string)
+	data, err := reader.ReadFile(navPath)
+	if err != nil {
+		return titles
+	}
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return titles
+	}
+
+	var toc *html.Node
+	walk(doc, func(n *html.Node) bool {
+		if toc == nil && n.Type == html.ElementNode && n.DataAtom == atom.Nav && hasProperty(attr(n, "epub:type"), "toc") {
+			toc = n
+		}
+		return toc == nil
+	})
+	if toc == nil {
+		return titles
+	}
+	walk(toc, func(n *html.Node) bool {
+		if n.Type == html.ElementNode && n.DataAtom == atom.A {
+			addTitle(titles, navPath, attr(n, "href"), textContent(n))
+		}
+		return true
+	})
+	return titles
+}
+
+// ncxTitles 从EPUB2目录文件中获取每个章节的标题，读取或解析失败时返回空映射
+func ncxTitles(reader *site.LimitedReader, ncxPath string) map[string]string {
+	titles := make(map[string]string)
+	data, err := reader.ReadFile(ncxPath)
+	if err != nil {
+		return titles
+	}
+	var doc ncx
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return titles
+	}
+
+	var visit func(points []ncxPoint)
+	visit = func(points []ncxPoint) {
+		for _, p := range points {
+			addTitle(titles, ncxPath, p.Content.Src, strings.Join(strings.Fields(p.Label), " "))
+			visit(p.Points)
+		}
+	}
+	visit(doc.NavPoints)
+	return titles
+}
+
+// addTitle 记录目录项对应章节的标题，同一章节只保留第一个目录项
+func addTitle(titles map[string]string, base, href, title string) {
+	u, err := url.Parse(href)
+	if err != nil || u.Path == "" || title == "" {
+		return
+	}
+	target := path.Join(path.Dir(base), u.Path)
+	if _, exists := titles[target]; !exists {
+		titles[target] = title
+	}
+}
+
+// isXHTML 判断清单项是否为可转换的章节
+func isXHTML(mediaType string) bool {
+	return mediaType == "application/xhtml+xml" || mediaType == "text/html"
+}
+
+// hasProperty 判断空格分隔的属性列表中是否包含指定值
+func hasProperty(properties, name string) bool {
+	for _, p := range strings.Fields(properties) {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
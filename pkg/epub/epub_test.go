@@ -0,0 +1,245 @@
+package epub
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/relaxcloud-cn/html2md/pkg/site"
+)
+
+// memWriter 将结果保存在内存中
+type memWriter map[string]string
+
+func (w memWriter) WriteFile(name string, data []byte) error {
+	w[name] = string(data)
+	return nil
+}
+
+const testContainer = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`
+
+// testBook EPUB3电子书，清单顺序与spine顺序不同
+func testBook() fstest.MapFS {
+	return fstest.MapFS{
+		"mimetype":               {Data: []byte("application/epub+zip")},
+		"META-INF/container.xml": {Data: []byte(testContainer)},
+		"OEBPS/content.opf": {Data: []byte(`<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>  Test
+    Book </dc:title></metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="c1" href="text/chapter%201.xhtml" media-type="application/xhtml+xml"/>
+    <item id="c2" href="text/c2.xhtml" media-type="application/xhtml+xml"/>
+    <item id="c3" href="text/c3.xhtml" media-type="application/xhtml+xml"/>
+    <item id="img" href="images/a.png" media-type="image/png"/>
+    <item id="css" href="style.css" media-type="text/css"/>
+  </manifest>
+  <spine>
+    <itemref idref="nav"/>
+    <itemref idref="c2"/>
+    <itemref idref="css"/>
+    <itemref idref="c1"/>
+    <itemref idref="missing"/>
+    <itemref idref="c3"/>
+  </spine>
+</package>`)},
+		"OEBPS/nav.xhtml": {Data: []byte(`<html><body><nav epub:type="toc"><ol>
+  <li><a href="text/c2.xhtml">Preface</a></li>
+  <li><a href="text/chapter%201.xhtml#start">One</a></li>
+</ol></nav></body></html>`)},
+		"OEBPS/text/c2.xhtml": {Data: []byte(`<html><body><h1>Before</h1>
+<p>See <a href="chapter%201.xhtml#details">details</a> and <a href="c3.xhtml">the end</a>.</p>
+<p><img src="../images/a.png" alt="A"/></p></body></html>`)},
+		"OEBPS/text/chapter 1.xhtml": {Data: []byte(`<html><body><h1 id="start">Chapter One</h1>
+<h2 id="details">Details</h2><p><a href="#details">here</a> <a href="https://example.com/">web</a></p></body></html>`)},
+		"OEBPS/text/c3.xhtml": {Data: []byte(`<html><body><p>No heading.</p></body></html>`)},
+		"OEBPS/images/a.png":  {Data: []byte("png")},
+		"OEBPS/style.css":     {Data: []byte("p{}")},
+	}
+}
+
+func TestConvertMerged(t *testing.T) {
+	out := memWriter{}
+	var progress []int
+	result, err := Convert(context.Background(), testBook(), out, Options{
+		Progress: func(done, total int) {
+			if total != 3 {
+				t.Errorf("progress total = %d, want 3", total)
+			}
+			progress = append(progress, done)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Title != "Test Book" {
+		t.Errorf("Title = %q", result.Title)
+	}
+	wantChapters := []Chapter{
+		{Source: "OEBPS/text/c2.xhtml", Title: "Preface"},
+		{Source: "OEBPS/text/chapter 1.xhtml", Title: "One"},
+		{Source: "OEBPS/text/c3.xhtml", Title: "c3"},
+	}
+	if len(result.Chapters) != len(wantChapters) {
+		t.Fatalf("Chapters = %+v, want %+v", result.Chapters, wantChapters)
+	}
+	for i, want := range wantChapters {
+		if result.Chapters[i] != want {
+			t.Errorf("Chapters[%d] = %+v, want %+v", i, result.Chapters[i], want)
+		}
+	}
+	if len(progress) != 3 || progress[2] != 3 {
+		t.Errorf("progress = %v", progress)
+	}
+
+	md := result.Markdown
+	// 章节按spine顺序排列
+	before, one, end := strings.Index(md, "# Before"), strings.Index(md, "# Chapter One"), strings.Index(md, "# c3")
+	if before < 0 || one < 0 || end < 0 || !(before < one && one < end) {
+		t.Errorf("chapters out of spine order:\n%s", md)
+	}
+	for _, want := range []string{
+		"# Test Book\n\n- [Preface](#before)\n- [One](#chapter-one)\n- [c3](#c3)",
+		"[details](#details)",
+		"[the end](#c3)",
+		"[here](#details)",
+		"[web](https://example.com/)",
+		"![A](images/a.png)",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Join(result.Images, ",") != "images/a.png" || out["images/a.png"] != "png" {
+		t.Errorf("Images = %v, written = %v", result.Images, out)
+	}
+}
+
+func TestConvertSplit(t *testing.T) {
+	out := memWriter{}
+	result, err := Convert(context.Background(), testBook(), out, Options{Split: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Markdown != "" {
+		t.Errorf("Markdown = %q, want empty when split", result.Markdown)
+	}
+
+	wantOutputs := []string{"text/c2.md", "text/chapter 1.md", "text/c3.md"}
+	for i, ch := range result.Chapters {
+		if ch.Output != wantOutputs[i] {
+			t.Errorf("Chapters[%d].Output = %q, want %q", i, ch.Output, wantOutputs[i])
+		}
+	}
+
+	wantFiles := map[string][]string{
+		IndexName:           {"# Test Book", "- [Preface](text/c2.md)\n- [One](text/chapter%201.md)\n- [c3](text/c3.md)"},
+		"text/c2.md":        {"[details](chapter%201.md#details)", "[the end](c3.md)", "![A](../images/a.png)"},
+		"text/chapter 1.md": {"# Chapter One", "[here](#details)"},
+		"text/c3.md":        {"No heading."},
+	}
+	for name, wants := range wantFiles {
+		content, ok := out[name]
+		if !ok {
+			t.Errorf("%s not written", name)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(content, want) {
+				t.Errorf("%s missing %q:\n%s", name, want, content)
+			}
+		}
+	}
+	if out["images/a.png"] != "png" {
+		t.Error("image not copied")
+	}
+}
+
+func TestConvertNCX(t *testing.T) {
+	book := fstest.MapFS{
+		"META-INF/container.xml": {Data: []byte(strings.Replace(testContainer, "OEBPS/content.opf", "book.opf", 1))},
+		"book.opf": {Data: []byte(`<package><manifest>
+  <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+  <item id="a" href="a.html" media-type="text/html"/>
+  <item id="b" href="b.html" media-type="text/html"/>
+</manifest><spine toc="ncx"><itemref idref="b"/><itemref idref="a"/></spine></package>`)},
+		"toc.ncx": {Data: []byte(`<ncx><navMap>
+  <navPoint><navLabel><text>Part B</text></navLabel><content src="b.html"/>
+    <navPoint><navLabel><text>Part A</text></navLabel><content src="a.html#x"/></navPoint>
+  </navPoint>
+</navMap></ncx>`)},
+		"a.html": {Data: []byte(`<h1>A</h1>`)},
+		"b.html": {Data: []byte(`<p>b</p>`)},
+	}
+
+	result, err := Convert(context.Background(), book, nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 没有书名时使用第一个章节的文件名
+	if result.Title != "b" {
+		t.Errorf("Title = %q, want b", result.Title)
+	}
+	if len(result.Chapters) != 2 || result.Chapters[0].Title != "Part B" || result.Chapters[1].Title != "Part A" {
+		t.Errorf("Chapters = %+v", result.Chapters)
+	}
+	if !strings.Contains(result.Markdown, "- [Part B](#part-b)\n- [Part A](#a)") {
+		t.Errorf("Markdown = %s", result.Markdown)
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	withFile := func(name, content string) fstest.MapFS {
+		book := testBook()
+		if content == "" {
+			delete(book, name)
+		} else {
+			book[name] = &fstest.MapFile{Data: []byte(content)}
+		}
+		return book
+	}
+
+	tests := []struct {
+		name    string
+		book    fstest.MapFS
+		dst     site.Writer
+		opts    Options
+		wantErr error
+	}{
+		{name: "missing container", book: withFile(containerPath, ""), wantErr: ErrInvalidEPUB},
+		{name: "invalid container", book: withFile(containerPath, "<container"), wantErr: ErrInvalidEPUB},
+		{name: "no rootfile", book: withFile(containerPath, "<container/>"), wantErr: ErrInvalidEPUB},
+		{name: "missing opf", book: withFile("OEBPS/content.opf", ""), wantErr: ErrInvalidEPUB},
+		{name: "empty spine", book: withFile("OEBPS/content.opf", "<package><spine/></package>"), wantErr: ErrInvalidEPUB},
+		{name: "split without output", book: testBook(), opts: Options{Split: true}},
+		{name: "file too large", book: testBook(), dst: memWriter{}, opts: Options{MaxFileSize: 100}, wantErr: site.ErrLimitExceeded},
+		{name: "total too large", book: testBook(), dst: memWriter{}, opts: Options{MaxTotal: 1000}, wantErr: site.ErrLimitExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Convert(context.Background(), tt.book, tt.dst, tt.opts)
+			if err == nil {
+				t.Fatal("Convert() error = nil, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Convert() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConvertCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Convert(ctx, testBook(), nil, Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Convert() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,246 @@
+// Package fetch 抓取网页用于转换，默认阻止访问内网地址以防止SSRF
+//
+// 地址检查在建立连接时进行，对每次重定向和DNS解析的结果都生效，
+// 因此无法通过重定向或DNS重绑定访问被阻止的地址。
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// 抓取的错误
+var (
+	ErrInvalidURL  = errors.New("无效的URL")
+	ErrBlocked     = errors.New("禁止访问的地址")
+	ErrTooLarge    = errors.New("页面超过大小限制")
+	ErrUnsupported = errors.New("不支持的内容类型")
+	ErrFetch       = errors.New("抓取页面失败") // 网络错误、超时、重定向过多或非2xx响应
+)
+
+// blockedPrefixes 除回环、私有、链路本地、组播和未指定地址外，默认阻止的地址段
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // 本网络
+	netip.MustParsePrefix("100.64.0.0/10"), // 运营商级NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF协议分配
+	netip.MustParsePrefix("198.18.0.0/15"), // 网络基准测试
+	netip.MustParsePrefix("240.0.0.0/4"),   // 保留地址，包括广播地址
+
+	// 内嵌IPv4地址的IPv6地址段，经转换网关可到达任意IPv4地址
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64知名前缀
+	netip.MustParsePrefix("64:ff9b:1::/48"), // NAT64本地前缀
+	netip.MustParsePrefix("2002::/16"),      // 6to4
+}
+
+// Options 抓取选项
+type Options struct {
+	Timeout      time.Duration  // 整个请求的超时时间，包括重定向和读取响应，0表示不限制
+	MaxSize      int64          // 响应体的最大字节数，0表示不限制
+	MaxRedirects int            // 最多跟随的重定向次数，0表示不跟随重定向
+	UserAgent    string         // User-Agent请求头
+	Allow        []netip.Prefix // 允许访问的地址段，优先于默认阻止的内网地址，如测试时的 127.0.0.1/32
+}
+
+// Page 抓取到的页面
+type Page struct {
+	URL         string // 重定向后的最终地址
+	BaseURL     string // 解析相对地址的基准URL，响应有 Content-Location 头时按其解析，否则为最终地址
+	ContentType string // Content-Type响应头，用于识别字符集
+	Body        []byte // 响应体
+}
+
+// Fetcher 网页抓取器，可并发使用
+type Fetcher struct {
+	opts   Options
+	client *http.Client
+}
+
+// New 创建抓取器
+func New(opts Options) *Fetcher {
+	f := &Fetcher{opts: opts}
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: f.control,
+	}
+	f.client = &http.Client{
+		Transport: &http.Transport{
+			// 不使用代理，代理会绕过连接时的地址检查
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: opts.Timeout,
+		},
+		CheckRedirect: f.checkRedirect,
+		Timeout:       opts.Timeout,
+	}
+	return f
+}
+
+// ValidateURL 检查URL是否为带主机名的http或https地址
+func ValidateURL(rawURL string) error {
+	_, err := parseURL(rawURL)
+	return err
+}
+
+// Client 带地址检查的HTTP客户端，用于抓取页面以外的请求，如任务回调
+func (f *Fetcher) Client() *http.Client {
+	return f.client
+}
+
+// Check 检查URL，主机是IP地址或localhost时同时检查是否允许访问
+//
+// 用于在接受URL时提前拒绝内网地址，域名解析后的地址仍在建立连接时检查。
+func (f *Fetcher) Check(rawURL string) (*url.URL, error) {
+	u, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		host = "127.0.0.1"
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !f.Allowed(addr) {
+		return nil, fmt.Errorf("%w: %s", ErrBlocked, u.Hostname())
+	}
+	return u, nil
+}
+
+// Fetch 抓取HTML页面
+//
+// 只接受 text/html 和 application/xhtml+xml 内容，未声明Content-Type时按HTML处理。
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	u, err := f.Check(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+	if f.opts.UserAgent != "" {
+		req.Header.Set("User-Agent", f.opts.UserAgent)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrBlocked) || errors.Is(err, ErrInvalidURL) || errors.Is(err, ErrFetch) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%w: %s 返回 %s", ErrFetch, resp.Request.URL, resp.Status)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupported, contentType)
+		}
+	}
+	if f.opts.MaxSize > 0 && resp.ContentLength > f.opts.MaxSize {
+		return nil, fmt.Errorf("%w: %d > %d 字节", ErrTooLarge, resp.ContentLength, f.opts.MaxSize)
+	}
+
+	body := io.Reader(resp.Body)
+	if f.opts.MaxSize > 0 {
+		body = io.LimitReader(resp.Body, f.opts.MaxSize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 读取响应失败: %v", ErrFetch, err)
+	}
+	if f.opts.MaxSize > 0 && int64(len(data)) > f.opts.MaxSize {
+		return nil, fmt.Errorf("%w: 超过 %d 字节", ErrTooLarge, f.opts.MaxSize)
+	}
+
+	final := resp.Request.URL
+	base := final
+	if location := resp.Header.Get("Content-Location"); location != "" {
+		if ref, err := final.Parse(location); err == nil && (ref.Scheme == "http" || ref.Scheme == "https") {
+			base = ref
+		}
+	}
+	return &Page{
+		URL:         final.String(),
+		BaseURL:     base.String(),
+		ContentType: contentType,
+		Body:        data,
+	}, nil
+}
+
+// Allowed 判断是否允许连接该地址
+func (f *Fetcher) Allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range f.opts.Allow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return !blocked(addr)
+}
+
+// control 在建立连接前检查解析后的地址
+func (f *Fetcher) control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlocked, address)
+	}
+	if !f.Allowed(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrBlocked, addrPort.Addr())
+	}
+	return nil
+}
+
+// checkRedirect 限制重定向次数，并只允许重定向到http或https地址
+func (f *Fetcher) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > f.opts.MaxRedirects {
+		return fmt.Errorf("%w: 超过 %d 次重定向", ErrFetch, f.opts.MaxRedirects)
+	}
+	_, err := parseURL(req.URL.String())
+	return err
+}
+
+// parseURL 解析并检查URL
+func parseURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: 只支持 http 和 https 地址", ErrInvalidURL)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("%w: 缺少主机名", ErrInvalidURL)
+	}
+	return u, nil
+}
+
+// blocked 判断地址是否属于默认阻止的内网或保留地址段
+func blocked(addr netip.Addr) bool {
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsMulticast() || addr.IsUnspecified() {
+		return true
+	}
+	return slices.ContainsFunc(blockedPrefixes, func(p netip.Prefix) bool {
+		return p.Contains(addr)
+	})
+}
@@ -0,0 +1,275 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// loopback 放行测试服务器所在的回环地址
+var loopback = []netip.Prefix{netip.MustParsePrefix("127.0.0.1/32")}
+
+func newFetcher(opts Options) *Fetcher {
+	if opts.Timeout == 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	return New(opts)
+}
+
+func TestBlocked(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"fe80::1", true},
+		{"fd00::1", true},
+		{"224.0.0.1", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"100.64.0.1", true},
+		{"198.18.0.1", true},
+		{"255.255.255.255", true},
+		{"64:ff9b::a9fe:a9fe", true}, // NAT64: 169.254.169.254
+		{"64:ff9b::808:808", true},   // NAT64 也阻止公网地址，无法判断网关是否可信
+		{"64:ff9b:1::a00:1", true},
+		{"2002:a00:1::1", true}, // 6to4: 10.0.0.1
+		{"8.8.8.8", false},
+		{"93.184.216.34", false},
+		{"2606:4700:4700::1111", false},
+		{"64:ff9c::1", false},
+	}
+	for _, tt := range tests {
+		if got := blocked(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("blocked(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	f := New(Options{Allow: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("::1/128")}})
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"10.1.2.3", true},
+		{"::ffff:10.1.2.3", true},
+		{"::1", true},
+		{"11.0.0.1", true},
+		{"127.0.0.1", false},
+		{"192.168.0.1", false},
+		{"::ffff:192.168.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := f.Allowed(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("Allowed(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	f := New(Options{Allow: loopback})
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{"https://example.com/a", nil},
+		{"http://127.0.0.1:8080/", nil},
+		{"http://localhost/", nil},
+		{"ftp://example.com/", ErrInvalidURL},
+		{"/relative", ErrInvalidURL},
+		{"http://", ErrInvalidURL},
+		{"http://127.0.0.2/", ErrBlocked},
+		{"http://10.0.0.1/", ErrBlocked},
+		{"http://[::1]/", ErrBlocked},
+		{"http://[64:ff9b::a00:1]/", ErrBlocked},
+		{"http://[2002:a00:1::1]/", ErrBlocked},
+	}
+	for _, tt := range tests {
+		_, err := f.Check(tt.url)
+		if (tt.want == nil && err != nil) || (tt.want != nil && !errors.Is(err, tt.want)) {
+			t.Errorf("Check(%q) error = %v, want %v", tt.url, err, tt.want)
+		}
+	}
+
+	// 未放行时localhost视为回环地址
+	if _, err := New(Options{}).Check("http://app.localhost./"); !errors.Is(err, ErrBlocked) {
+		t.Errorf("Check(localhost) error = %v, want ErrBlocked", err)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/start":
+			http.Redirect(w, r, "/page", http.StatusFound)
+		case "/page":
+			if r.Header.Get("User-Agent") != "test-agent" {
+				t.Errorf("User-Agent = %q", r.Header.Get("User-Agent"))
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Content-Location", "/docs/")
+			fmt.Fprint(w, "<h1>Page</h1>")
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, "{}")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	f := newFetcher(Options{MaxRedirects: 2, UserAgent: "test-agent", Allow: loopback})
+	page, err := f.Fetch(context.Background(), srv.URL+"/start")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.URL != srv.URL+"/page" || page.BaseURL != srv.URL+"/docs/" || string(page.Body) != "<h1>Page</h1>" {
+		t.Errorf("Fetch() = %+v", page)
+	}
+
+	if _, err := f.Fetch(context.Background(), srv.URL+"/json"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Fetch(json) error = %v, want ErrUnsupported", err)
+	}
+	if _, err := f.Fetch(context.Background(), srv.URL+"/missing"); !errors.Is(err, ErrFetch) {
+		t.Errorf("Fetch(404) error = %v, want ErrFetch", err)
+	}
+}
+
+func TestFetchBlocked(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	// 默认阻止回环地址，连接前即被拒绝
+	_, err := newFetcher(Options{}).Fetch(context.Background(), srv.URL)
+	if !errors.Is(err, ErrBlocked) {
+		t.Errorf("Fetch(loopback) error = %v, want ErrBlocked", err)
+	}
+	// 放行的地址段之外仍被阻止
+	_, err = newFetcher(Options{Allow: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}}).Fetch(context.Background(), srv.URL)
+	if !errors.Is(err, ErrBlocked) {
+		t.Errorf("Fetch(loopback, other allow) error = %v, want ErrBlocked", err)
+	}
+	if n := hits.Load(); n != 0 {
+		t.Errorf("blocked server received %d requests", n)
+	}
+}
+
+func TestFetchRedirectBlocked(t *testing.T) {
+	var hits atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer target.Close()
+	port := target.Listener.Addr().(*net.TCPAddr).Port
+
+	tests := []struct {
+		location string
+		want     error
+	}{
+		{"http://10.0.0.1/", ErrBlocked},
+		{"http://169.254.169.254/latest/meta-data/", ErrBlocked},
+		{fmt.Sprintf("http://[::1]:%d/", port), ErrBlocked},
+		{"http://[64:ff9b::a9fe:a9fe]/", ErrBlocked},
+		{"file:///etc/passwd", ErrInvalidURL},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, tt.location, http.StatusFound)
+		}))
+		_, err := newFetcher(Options{MaxRedirects: 5, Allow: loopback}).Fetch(context.Background(), srv.URL)
+		srv.Close()
+		if !errors.Is(err, tt.want) {
+			t.Errorf("redirect to %s error = %v, want %v", tt.location, err, tt.want)
+		}
+	}
+	if n := hits.Load(); n != 0 {
+		t.Errorf("redirect target received %d requests", n)
+	}
+}
+
+func TestFetchRedirectLimit(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		http.Redirect(w, r, fmt.Sprintf("/r%d", n), http.StatusFound)
+	}))
+	defer srv.Close()
+
+	_, err := newFetcher(Options{MaxRedirects: 2, Allow: loopback}).Fetch(context.Background(), srv.URL)
+	if !errors.Is(err, ErrFetch) || !strings.Contains(err.Error(), "重定向") {
+		t.Errorf("Fetch() error = %v, want redirect limit", err)
+	}
+	if n := hits.Load(); n != 3 {
+		t.Errorf("server received %d requests, want 3", n)
+	}
+
+	// MaxRedirects为0时不跟随重定向，用于回调等请求
+	hits.Store(0)
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, nil)
+	if _, err := newFetcher(Options{Allow: loopback}).Client().Do(req); !errors.Is(err, ErrFetch) {
+		t.Errorf("Client().Do() error = %v, want ErrFetch", err)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("server received %d requests, want 1", n)
+	}
+}
+
+func TestFetchSizeLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		body := "<p>" + strings.Repeat("x", 100) + "</p>"
+		if r.URL.Path == "/chunked" {
+			// 未声明长度时读取到上限后停止
+			fmt.Fprint(w, body[:50])
+			w.(http.Flusher).Flush()
+			fmt.Fprint(w, body[50:])
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+
+	f := newFetcher(Options{MaxSize: 64, Allow: loopback})
+	for _, path := range []string{"/length", "/chunked"} {
+		if _, err := f.Fetch(context.Background(), srv.URL+path); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("Fetch(%s) error = %v, want ErrTooLarge", path, err)
+		}
+	}
+	page, err := newFetcher(Options{MaxSize: 107, Allow: loopback}).Fetch(context.Background(), srv.URL+"/chunked")
+	if err != nil || len(page.Body) != 107 {
+		t.Errorf("Fetch() at exact limit = %v, %v", page, err)
+	}
+}
+
+func TestFetchTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	_, err := newFetcher(Options{Timeout: 50 * time.Millisecond, Allow: loopback}).Fetch(context.Background(), srv.URL)
+	if !errors.Is(err, ErrFetch) {
+		t.Errorf("Fetch() error = %v, want ErrFetch", err)
+	}
+}
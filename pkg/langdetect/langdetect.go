@@ -0,0 +1,264 @@
+// Package langdetect 离线识别文本的语言
+//
+// 先按文字系统区分：中文、日文、韩文、希腊文、希伯来文、阿拉伯文、天城文和泰文直接由文字确定语言；
+// 拉丁字母和西里尔字母的文本按1至3个字符的组合（n-gram）频率与内置的语言样本比较，使用朴素贝叶斯选出最可能的语言。
+package langdetect
+
+import (
+	"embed"
+	"io/fs"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 识别使用的文本长度限制
+const (
+	minLetters  = 3     // 字母少于该数量时不识别
+	minWords    = 3     // 按字符组合识别时至少需要的不同单词数，重复的单词不增加证据
+	minMargin   = 0.5   // 最可能的语言与第二可能的语言的概率之差低于该值时不识别
+	maxLetters  = 20000 // 只统计开头的字母，避免长文档耗时过长
+	maxEvidence = 150   // 字符组合数量超过该值时按该值计算置信度，避免长文本的置信度总是接近1
+	smoothing   = 0.5   // 未在样本中出现的字符组合的平滑计数
+)
+
+// Result 识别结果
+type Result struct {
+	Language   string  `json:"language" example:"zh"`     // ISO 639-1 语言代码，无法识别时为空
+	Confidence float64 `json:"confidence" example:"0.97"` // 置信度，0到1之间
+}
+
+// scriptLanguages 由文字系统直接确定的语言
+var scriptLanguages = map[*unicode.RangeTable]string{
+	unicode.Hangul:     "ko",
+	unicode.Greek:      "el",
+	unicode.Hebrew:     "he",
+	unicode.Arabic:     "ar",
+	unicode.Devanagari: "hi",
+	unicode.Thai:       "th",
+}
+
+// profile 语言样本的字符组合频率
+type profile struct {
+	lang   string
+	counts map[string]int
+	total  int
+}
+
+// profileFS 内置的语言样本，文件名为语言代码
+//
+//go:embed profiles/*.txt
+var profileFS embed.FS
+
+// profiles 按文字系统分组的语言样本
+var profiles = make(map[*unicode.RangeTable][]*profile)
+
+func init() {
+	files, _ := fs.Glob(profileFS, "profiles/*.txt")
+	for _, file := range files {
+		data, err := profileFS.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		text := string(data)
+		p := &profile{lang: strings.TrimSuffix(path.Base(file), ".txt"), counts: ngrams(text)}
+		for _, n := range p.counts {
+			p.total += n
+		}
+		script, _ := dominantScript(text)
+		profiles[script] = append(profiles[script], p)
+	}
+}
+
+// Languages 获取可识别的语言代码
+func Languages() []string {
+	langs := []string{"zh", "ja"}
+	for _, lang := range scriptLanguages {
+		langs = append(langs, lang)
+	}
+	for _, group := range profiles {
+		for _, p := range group {
+			langs = append(langs, p.lang)
+		}
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Detect 识别文本的语言，置信度为主要文字系统所占比例与语言判定概率的乘积
+//
+// 按字符组合识别的文本不同的单词过少，或者最可能的两种语言难以区分时，不猜测语言，返回空结果。
+func Detect(text string) Result {
+	script, share := dominantScript(text)
+	switch {
+	case script == nil:
+		return Result{}
+	case script == unicode.Han:
+		return Result{Language: hanLanguage(text), Confidence: round(share)}
+	}
+	if lang, ok := scriptLanguages[script]; ok {
+		return Result{Language: lang, Confidence: round(share)}
+	}
+	group := profiles[script]
+	if len(group) == 0 {
+		return Result{}
+	}
+	if distinctWords(text) < minWords {
+		return Result{}
+	}
+	lang, p, margin := classify(group, ngrams(text))
+	if margin < minMargin {
+		return Result{}
+	}
+	return Result{Language: lang, Confidence: round(share * p)}
+}
+
+// dominantScript 获取占比最高的文字系统及其所占比例，字母过少时返回nil
+//
+// 汉字和假名计为同一文字系统，每个字计一次；其他文字按单词计数，避免夹杂的英文单词按字母数压过中日文。
+func dominantScript(text string) (*unicode.RangeTable, float64) {
+	counts := make(map[*unicode.RangeTable]int)
+	letters, total := 0, 0
+	var prev *unicode.RangeTable
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			prev = nil
+			continue
+		}
+		if letters++; letters > maxLetters {
+			break
+		}
+		script := scriptOf(r)
+		if script != prev || script == unicode.Han {
+			counts[script]++
+			total++
+		}
+		prev = script
+	}
+	if letters < minLetters {
+		return nil, 0
+	}
+	var best *unicode.RangeTable
+	for script, n := range counts {
+		if script != nil && (best == nil || n > counts[best]) {
+			best = script
+		}
+	}
+	if best == nil {
+		return nil, 0
+	}
+	return best, float64(counts[best]) / float64(total)
+}
+
+// scriptOf 获取字母所属的文字系统，假名归入汉字
+func scriptOf(r rune) *unicode.RangeTable {
+	switch {
+	case unicode.Is(unicode.Latin, r):
+		return unicode.Latin
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+		return unicode.Han
+	case unicode.Is(unicode.Cyrillic, r):
+		return unicode.Cyrillic
+	}
+	for script := range scriptLanguages {
+		if unicode.Is(script, r) {
+			return script
+		}
+	}
+	return nil
+}
+
+// hanLanguage 区分中文和日文，假名占汉字和假名总数的一成以上时视为日文
+func hanLanguage(text string) string {
+	han, kana := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		}
+	}
+	if kana*10 >= han+kana {
+		return "ja"
+	}
+	return "zh"
+}
+
+// ngrams 统计文本中长度为1到3的字符组合，字母转为小写，每个单词前后补充空格
+func ngrams(text string) map[string]int {
+	counts := make(map[string]int)
+	letters := 0
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if letters += len(word); letters > maxLetters {
+			break
+		}
+		runes := []rune(" " + strings.ToLower(word) + " ")
+		for n := 1; n <= 3; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				if gram := string(runes[i : i+n]); gram != " " {
+					counts[gram]++
+				}
+			}
+		}
+	}
+	return counts
+}
+
+// distinctWords 统计文本中不同单词的数量，不区分大小写，只统计开头的字母
+func distinctWords(text string) int {
+	seen := make(map[string]bool)
+	letters := 0
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if letters += len(word); letters > maxLetters {
+			break
+		}
+		seen[strings.ToLower(word)] = true
+	}
+	return len(seen)
+}
+
+// classify 按朴素贝叶斯选出最可能的语言，返回语言、其后验概率以及与第二可能的语言的概率之差
+func classify(group []*profile, grams map[string]int) (string, float64, float64) {
+	n := 0
+	for _, c := range grams {
+		n += c
+	}
+	if n == 0 {
+		return "", 0, 0
+	}
+	// 每个字符参与长度为1到3的三个组合，彼此并不独立，按三分之一计入
+	scale := math.Min(1, float64(maxEvidence)/float64(n)) / 3
+	scores := make([]float64, len(group))
+	for i, p := range group {
+		for gram, c := range grams {
+			prob := (float64(p.counts[gram]) + smoothing) / (float64(p.total) + smoothing*float64(len(p.counts)+1))
+			scores[i] += float64(c) * math.Log(prob) * scale
+		}
+	}
+	best, second := 0, -1
+	for i := 1; i < len(scores); i++ {
+		switch {
+		case scores[i] > scores[best]:
+			best, second = i, best
+		case second < 0 || scores[i] > scores[second]:
+			second = i
+		}
+	}
+	sum := 0.0
+	for _, s := range scores {
+		sum += math.Exp(s - scores[best])
+	}
+	p := 1 / sum
+	if second < 0 {
+		return group[best].lang, p, p
+	}
+	return group[best].lang, p, p - math.Exp(scores[second]-scores[best])/sum
+}
+
+// round 保留两位小数
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
@@ -0,0 +1,106 @@
+package langdetect
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"The meeting has been moved to Friday afternoon.", "en"},
+		{"Die Besprechung wurde auf Freitagnachmittag verschoben.", "de"},
+		{"La reunión se ha trasladado al viernes por la tarde.", "es"},
+		{"La réunion a été déplacée à vendredi après-midi.", "fr"},
+		{"La riunione è stata spostata a venerdì pomeriggio.", "it"},
+		{"De vergadering is verplaatst naar vrijdagmiddag.", "nl"},
+		{"Spotkanie zostało przeniesione na piątkowe popołudnie.", "pl"},
+		{"A reunião foi adiada para sexta-feira à tarde.", "pt"},
+		{"Mötet har flyttats till fredag eftermiddag.", "sv"},
+		{"Toplantı cuma öğleden sonraya ertelendi.", "tr"},
+		{"Встреча перенесена на вечер пятницы.", "ru"},
+		{"Зустріч перенесено на вечір п'ятниці.", "uk"},
+		{"会议改到了星期五下午。", "zh"},
+		{"会議は金曜日の午後に変更されました。", "ja"},
+		{"회의가 금요일 오후로 변경되었습니다.", "ko"},
+		{"Η συνάντηση μεταφέρθηκε την Παρασκευή.", "el"},
+		{"הפגישה נדחתה ליום שישי.", "he"},
+		{"تم نقل الاجتماع إلى يوم الجمعة.", "ar"},
+		{"बैठक शुक्रवार दोपहर तक टाल दी गई।", "hi"},
+		{"การประชุมถูกเลื่อนไปวันศุกร์", "th"},
+	}
+	for _, tt := range tests {
+		r := Detect(tt.text)
+		if r.Language != tt.want {
+			t.Errorf("Detect(%q) = %+v, want %s", tt.text, r, tt.want)
+		}
+		if r.Confidence <= 0.5 || r.Confidence > 1 {
+			t.Errorf("Detect(%q) confidence = %v", tt.text, r.Confidence)
+		}
+	}
+}
+
+func TestDetectInsufficientEvidence(t *testing.T) {
+	tests := []string{
+		"",
+		"12345 !!!",
+		"ab",
+		"word word word word word",
+		"OK OK OK",
+		"Click here",
+		"Clique aqui",
+		"foo bar baz",
+		"Paris London Berlin",
+		"API SDK HTTP JSON",
+		"Lorem ipsum dolor sit amet",
+	}
+	for _, text := range tests {
+		if r := Detect(text); r != (Result{}) {
+			t.Errorf("Detect(%q) = %+v, want empty result", text, r)
+		}
+	}
+}
+
+func TestDetectMixedScripts(t *testing.T) {
+	r := Detect("这个项目使用 Go 语言编写，支持 HTML 转 Markdown")
+	if r.Language != "zh" || r.Confidence >= 1 {
+		t.Errorf("Detect(mixed) = %+v, want zh with reduced confidence", r)
+	}
+	// 只统计开头的字母，长文本仍能识别
+	long := strings.Repeat("Please check your spelling and try again. ", 200)
+	if r := Detect(long); r.Language != "en" {
+		t.Errorf("Detect(long) = %+v, want en", r)
+	}
+}
+
+func TestDistinctWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"word Word WORD", 1},
+		{"one, two; three!", 3},
+		{"l'homme d'affaires", 4},
+	}
+	for _, tt := range tests {
+		if got := distinctWords(tt.text); got != tt.want {
+			t.Errorf("distinctWords(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	langs := Languages()
+	for _, want := range []string{"zh", "ja", "ko", "en", "de", "fr", "ru", "uk"} {
+		if !slices.Contains(langs, want) {
+			t.Errorf("Languages() = %v, missing %s", langs, want)
+		}
+	}
+	if !slices.IsSorted(langs) {
+		t.Errorf("Languages() = %v, want sorted", langs)
+	}
+}
@@ -0,0 +1,271 @@
+package site
+
+import (
+	"bytes"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+)
+
+// page 已解析的HTML页面
+type page struct {
+	name    string
+	doc     *html.Node
+	err     error
+	anchors map[string]string // 元素id -> Markdown标题锚点，空字符串表示页面顶部
+}
+
+// siteIndex 站点文件索引
+type siteIndex struct {
+	files map[string]bool
+	pages map[string]*page
+}
+
+// linkAttrs 需要处理的链接属性，a标签之外的引用都视为资源文件
+var linkAttrs = map[atom.Atom]string{
+	atom.A:      "href",
+	atom.Img:    "src",
+	atom.Source: "src",
+	atom.Video:  "src",
+	atom.Audio:  "src",
+}
+
+// parsePage 解析页面并建立元素id到标题锚点的映射
+func parsePage(name string, data []byte) *page {
+	p := &page{name: name}
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		p.err = err
+		return p
+	}
+	p.doc = doc
+	p.anchors = Anchors(doc, converter.NewSlugger())
+	return p
+}
+
+// Anchors 建立元素id到Markdown标题锚点的映射，空字符串表示页面顶部
+//
+// 标题及其内部元素的id对应该标题的锚点；紧邻标题之前的空锚点（如 <a id="x"></a><h2>）
+// 对应其后的标题；其余元素的id对应所在章节（之前最近的标题），Markdown中没有更精确的位置。
+// 多个页面合并为一个文档时按顺序共用同一个slugger，使锚点在整个文档内唯一。
+func Anchors(doc *html.Node, slugger *converter.Slugger) map[string]string {
+	anchors := make(map[string]string)
+	headings := make(map[*html.Node]string)
+	walk(doc, func(n *html.Node) bool {
+		if isHeading(n) {
+			headings[n] = slugger.Slug(textContent(n))
+			return false
+		}
+		return true
+	})
+
+	current := ""
+	var visit func(n *html.Node, heading string)
+	visit = func(n *html.Node, heading string) {
+		if n.Type == html.ElementNode {
+			if slug, ok := headings[n]; ok {
+				current, heading = slug, slug
+			}
+			for _, id := range elementIDs(n) {
+				if _, exists := anchors[id]; exists {
+					continue
+				}
+				switch {
+				case heading != "":
+					anchors[id] = heading
+				case textContent(n) == "" && nextHeading(n, headings) != "":
+					anchors[id] = nextHeading(n, headings)
+				default:
+					anchors[id] = current
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			visit(c, heading)
+		}
+	}
+	visit(doc, "")
+
+	return anchors
+}
+
+// rewrite 改写页面中的站内链接，返回渲染后的HTML和引用的资源文件
+func (s *siteIndex) rewrite(p *page, report *Report) (string, []string) {
+	var assets []string
+	walk(p.doc, func(n *html.Node) bool {
+		attr, ok := linkAttrs[n.DataAtom]
+		if !ok || n.Type != html.ElementNode {
+			return true
+		}
+		for i := range n.Attr {
+			if n.Attr[i].Key != attr {
+				continue
+			}
+			href, asset, reason := s.resolve(p, n.Attr[i].Val, n.DataAtom == atom.A)
+			if reason != "" {
+				report.BrokenLinks = append(report.BrokenLinks, BrokenLink{
+					Source: p.name,
+					Href:   n.Attr[i].Val,
+					Reason: reason,
+				})
+			}
+			if href != "" {
+				n.Attr[i].Val = href
+			}
+			if asset != "" {
+				assets = append(assets, asset)
+			}
+		}
+		return true
+	})
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, p.doc); err != nil {
+		return "", assets
+	}
+	return buf.String(), assets
+}
+
+// resolve 解析站内链接，返回改写后的链接、引用的资源文件和无法解析的原因
+//
+// 外部链接和无需改写的链接返回空字符串。
+func (s *siteIndex) resolve(p *page, raw string, isLink bool) (href, asset, reason string) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || raw == "" || u.Scheme != "" || u.Host != "" || strings.HasPrefix(raw, "//") {
+		return "", "", ""
+	}
+
+	// 页面内锚点
+	if u.Path == "" {
+		if u.Fragment == "" {
+			return "", "", ""
+		}
+		slug, ok := p.anchors[u.Fragment]
+		if !ok {
+			return "", "", "锚点不存在"
+		}
+		return "#" + slug, "", ""
+	}
+
+	var target string
+	if strings.HasPrefix(u.Path, "/") {
+		target = path.Clean(strings.TrimPrefix(u.Path, "/"))
+	} else {
+		target = path.Join(path.Dir(p.name), u.Path)
+	}
+	if target == ".." || strings.HasPrefix(target, "../") {
+		return "", "", "链接指向导出目录之外"
+	}
+
+	// 目录链接指向其中的 index.html
+	if strings.HasSuffix(u.Path, "/") || target == "." || (!s.files[target] && s.pages[path.Join(target, "index.html")] != nil) {
+		target = path.Join(target, "index.html")
+	}
+
+	if isLink && IsHTML(target) {
+		targetPage, ok := s.pages[target]
+		if !ok {
+			return "", "", "页面不存在"
+		}
+		fragment := u.Fragment
+		if fragment != "" {
+			if slug, ok := targetPage.anchors[fragment]; ok {
+				fragment = slug
+			} else {
+				reason = "锚点不存在"
+			}
+		}
+		rewritten := &url.URL{Path: RelPath(path.Dir(p.name), MarkdownPath(target)), Fragment: fragment}
+		return rewritten.String(), "", reason
+	}
+
+	if !s.files[target] {
+		return "", "", "资源文件不存在"
+	}
+	rewritten := &url.URL{Path: RelPath(path.Dir(p.name), target), Fragment: u.Fragment}
+	return rewritten.String(), target, ""
+}
+
+// RelPath 计算从目录from到文件target的相对路径，两者均为/分隔的相对路径
+func RelPath(from, target string) string {
+	if from == "." {
+		return target
+	}
+	fromParts := strings.Split(from, "/")
+	targetParts := strings.Split(target, "/")
+
+	common := 0
+	for common < len(fromParts) && common < len(targetParts)-1 && fromParts[common] == targetParts[common] {
+		common++
+	}
+
+	parts := make([]string, 0, len(fromParts)-common+len(targetParts)-common)
+	for range fromParts[common:] {
+		parts = append(parts, "..")
+	}
+	parts = append(parts, targetParts[common:]...)
+	return strings.Join(parts, "/")
+}
+
+// walk 深度优先遍历节点，fn返回false时跳过子节点
+func walk(n *html.Node, fn func(*html.Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+// isHeading 判断是否为标题元素
+func isHeading(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		return true
+	}
+	return false
+}
+
+// elementIDs 获取元素的id以及a标签的name
+func elementIDs(n *html.Node) []string {
+	var ids []string
+	for _, a := range n.Attr {
+		if (a.Key == "id" || (a.Key == "name" && n.DataAtom == atom.A)) && a.Val != "" {
+			ids = append(ids, a.Val)
+		}
+	}
+	return ids
+}
+
+// nextHeading 获取紧随元素之后的兄弟标题的锚点
+func nextHeading(n *html.Node, headings map[*html.Node]string) string {
+	for sib := n.NextSibling; sib != nil; sib = sib.NextSibling {
+		if sib.Type == html.TextNode && strings.TrimSpace(sib.Data) == "" {
+			continue
+		}
+		return headings[sib]
+	}
+	return ""
+}
+
+// textContent 获取元素的文本内容，合并连续空白
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	walk(n, func(c *html.Node) bool {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+			b.WriteByte(' ')
+		}
+		return true
+	})
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,42 @@
+package site
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DirWriter 将结果写入本地目录
+type DirWriter struct {
+	Root string
+}
+
+// WriteFile 写入文件，自动创建上级目录
+func (w DirWriter) WriteFile(name string, data []byte) error {
+	target := filepath.Join(w.Root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(target, data, 0o644)
+}
+
+// ZipWriter 将结果写入zip压缩包
+type ZipWriter struct {
+	zw *zip.Writer
+}
+
+// NewZipWriter 创建zip输出
+func NewZipWriter(zw *zip.Writer) *ZipWriter {
+	return &ZipWriter{zw: zw}
+}
+
+// WriteFile 向压缩包写入文件
+func (w *ZipWriter) WriteFile(name string, data []byte) error {
+	f, err := w.zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("创建压缩包条目失败: %w", err)
+	}
+	_, err = f.Write(data)
+	return err
+}
@@ -0,0 +1,259 @@
+// Package site 将整站导出的HTML目录树转换为Markdown目录树
+//
+// 保持原有目录结构，将站内 page.html#x 形式的链接改写为 page.md#<标题锚点>，
+// 复制被引用的本地资源文件，并报告无法解析的站内链接。
+package site
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/relaxcloud-cn/html2md/pkg/converter"
+)
+
+// ErrLimitExceeded 文件数量或大小超出 Options 中的限制
+var ErrLimitExceeded = errors.New("超出限制")
+
+// Writer 转换结果的输出位置
+type Writer interface {
+	// WriteFile 写入文件，name为使用/分隔的相对路径
+	WriteFile(name string, data []byte) error
+}
+
+// Options 目录转换选项
+type Options struct {
+	Converter   *converter.Converter  // 转换器，为空时使用默认插件
+	MaxFiles    int                   // 最多处理的文件数，0表示不限制
+	MaxFileSize int64                 // 单个文件的最大字节数，0表示不限制
+	MaxTotal    int64                 // 读取文件的总字节数上限，0表示不限制
+	Progress    func(done, total int) // 转换进度回调，done为已处理的页面数，可为空
+}
+
+// Report 目录转换报告
+type Report struct {
+	Converted   int          `json:"converted"`    // 转换成功的HTML文件数
+	Failed      int          `json:"failed"`       // 转换失败的HTML文件数
+	Assets      []string     `json:"assets"`       // 已复制的资源文件
+	Files       []FileResult `json:"files"`        // 每个HTML文件的转换结果
+	BrokenLinks []BrokenLink `json:"broken_links"` // 无法解析的站内链接
+}
+
+// FileResult 单个HTML文件的转换结果
+type FileResult struct {
+	Source string `json:"source"`           // 源文件路径
+	Output string `json:"output,omitempty"` // 输出文件路径（成功时）
+	Error  string `json:"error,omitempty"`  // 错误信息（失败时）
+}
+
+// BrokenLink 无法解析的站内链接
+type BrokenLink struct {
+	Source string `json:"source"` // 链接所在文件
+	Href   string `json:"href"`   // 原始链接
+	Reason string `json:"reason"` // 原因
+}
+
+// Convert 转换src中的全部HTML文件并写入dst
+//
+// 单个文件的转换失败记录在报告中，不会中断整体转换；
+// 只有读取目录、写入输出或超出限制时返回错误。
+func Convert(ctx context.Context, src fs.FS, dst Writer, opts Options) (*Report, error) {
+	conv := opts.Converter
+	if conv == nil {
+		var err error
+		if conv, err = converter.New(); err != nil {
+			return nil, err
+		}
+	}
+
+	files, err := listFiles(src, opts.MaxFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	// 第一遍：解析所有页面，建立锚点索引
+	reader := NewLimitedReader(src, opts.MaxFileSize, opts.MaxTotal)
+	pages := make(map[string]*page)
+	var order []string
+	for _, name := range files {
+		if !IsHTML(name) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		data, err := reader.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		pages[name] = parsePage(name, data)
+		order = append(order, name)
+	}
+
+	s := &siteIndex{files: make(map[string]bool, len(files)), pages: pages}
+	for _, name := range files {
+		s.files[name] = true
+	}
+
+	// 第二遍：改写链接并转换
+	report := &Report{Files: make([]FileResult, 0, len(order))}
+	assets := make(map[string]bool)
+	for i, name := range order {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		opts.progress(i, len(order))
+
+		p := pages[name]
+		result := FileResult{Source: name}
+		if p.err != nil {
+			result.Error = p.err.Error()
+			report.Failed++
+			report.Files = append(report.Files, result)
+			continue
+		}
+
+		html, refs := s.rewrite(p, report)
+		for _, asset := range refs {
+			assets[asset] = true
+		}
+
+		converted, err := conv.ConvertString(html)
+		if err != nil {
+			result.Error = err.Error()
+			report.Failed++
+			report.Files = append(report.Files, result)
+			continue
+		}
+
+		result.Output = MarkdownPath(name)
+		if err := dst.WriteFile(result.Output, []byte(converted.Markdown+"\n")); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %w", result.Output, err)
+		}
+		report.Converted++
+		report.Files = append(report.Files, result)
+	}
+	opts.progress(len(order), len(order))
+
+	// 复制被引用的资源文件
+	for _, asset := range sortedKeys(assets) {
+		data, err := reader.ReadFile(asset)
+		if err != nil {
+			return nil, err
+		}
+		if err := dst.WriteFile(asset, data); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %w", asset, err)
+		}
+		report.Assets = append(report.Assets, asset)
+	}
+
+	sort.SliceStable(report.BrokenLinks, func(i, j int) bool {
+		return report.BrokenLinks[i].Source < report.BrokenLinks[j].Source
+	})
+
+	return report, nil
+}
+
+// progress 报告转换进度
+func (o *Options) progress(done, total int) {
+	if o.Progress != nil {
+		o.Progress(done, total)
+	}
+}
+
+// IsHTML 判断文件是否为HTML页面
+func IsHTML(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".html", ".htm", ".xhtml":
+		return true
+	}
+	return false
+}
+
+// MarkdownPath 将HTML文件路径替换为对应的Markdown文件路径
+func MarkdownPath(name string) string {
+	return strings.TrimSuffix(name, path.Ext(name)) + ".md"
+}
+
+// listFiles 列出目录树中的全部普通文件，跳过隐藏文件和目录
+func listFiles(fsys fs.FS, maxFiles int) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		files = append(files, name)
+		if maxFiles > 0 && len(files) > maxFiles {
+			return fmt.Errorf("%w: 文件数量超过 %d", ErrLimitExceeded, maxFiles)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取目录失败: %w", err)
+	}
+	return files, nil
+}
+
+// LimitedReader 按单个文件和总量限制读取文件，防止压缩包炸弹
+type LimitedReader struct {
+	fsys     fs.FS
+	maxFile  int64
+	maxTotal int64
+	total    int64
+}
+
+// NewLimitedReader 创建受限读取器，超出限制时返回 ErrLimitExceeded，0表示不限制
+func NewLimitedReader(fsys fs.FS, maxFile, maxTotal int64) *LimitedReader {
+	return &LimitedReader{fsys: fsys, maxFile: maxFile, maxTotal: maxTotal}
+}
+
+// ReadFile 读取文件内容
+func (r *LimitedReader) ReadFile(name string) ([]byte, error) {
+	f, err := r.fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", name, err)
+	}
+	defer f.Close()
+
+	var src io.Reader = f
+	if r.maxFile > 0 {
+		src = io.LimitReader(f, r.maxFile+1)
+	}
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", name, err)
+	}
+	if r.maxFile > 0 && int64(len(data)) > r.maxFile {
+		return nil, fmt.Errorf("%w: 文件 %s 超过 %d 字节", ErrLimitExceeded, name, r.maxFile)
+	}
+
+	r.total += int64(len(data))
+	if r.maxTotal > 0 && r.total > r.maxTotal {
+		return nil, fmt.Errorf("%w: 文件总大小超过 %d 字节", ErrLimitExceeded, r.maxTotal)
+	}
+	return data, nil
+}
+
+// sortedKeys 返回排序后的键
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,176 @@
+package site
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// memWriter 将结果保存在内存中
+type memWriter map[string]string
+
+func (w memWriter) WriteFile(name string, data []byte) error {
+	w[name] = string(data)
+	return nil
+}
+
+func testSite() fstest.MapFS {
+	return fstest.MapFS{
+		"index.html": {Data: []byte(`<h1>Home</h1>
+<p><a href="docs/guide.html#setup">setup</a>
+<a href="docs/">docs</a>
+<a href="#top">top</a>
+<a href="https://example.com/x.html">external</a>
+<a href="missing.html">missing</a>
+<a href="docs/guide.html#nope">bad anchor</a>
+<a href="../outside.html">outside</a>
+<img src="img/logo.png">
+<img src="img/none.png"></p>`)},
+		"docs/index.html": {Data: []byte(`<h1>Docs</h1><p><a href="../index.html">home</a> <a href="/docs/guide.html">guide</a></p>`)},
+		"docs/guide.html": {Data: []byte(`<h1>Guide</h1><a id="top"></a><h2 id="setup">Getting Started</h2><p id="para">text</p>`)},
+		"img/logo.png":    {Data: []byte("png")},
+		"img/unused.png":  {Data: []byte("png")},
+		".git/config":     {Data: []byte("hidden")},
+	}
+}
+
+func TestConvert(t *testing.T) {
+	out := memWriter{}
+	report, err := Convert(context.Background(), testSite(), out, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Converted != 3 || report.Failed != 0 {
+		t.Errorf("converted=%d failed=%d, want 3 and 0", report.Converted, report.Failed)
+	}
+	if strings.Join(report.Assets, ",") != "img/logo.png" {
+		t.Errorf("assets = %v, want only the referenced img/logo.png", report.Assets)
+	}
+	if _, ok := out["img/unused.png"]; ok {
+		t.Error("unreferenced asset was copied")
+	}
+	if _, ok := out[".git/config"]; ok {
+		t.Error("hidden file was copied")
+	}
+
+	index := out["index.md"]
+	for _, want := range []string{
+		"(docs/guide.md#getting-started)",
+		"(docs/index.md)",
+		"(https://example.com/x.html)",
+		"![](img/logo.png)",
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index.md missing %q:\n%s", want, index)
+		}
+	}
+
+	docs := out["docs/index.md"]
+	for _, want := range []string{"(../index.md)", "(guide.md)"} {
+		if !strings.Contains(docs, want) {
+			t.Errorf("docs/index.md missing %q:\n%s", want, docs)
+		}
+	}
+
+	wantBroken := map[string]string{
+		"#top":                 "锚点不存在",
+		"missing.html":         "页面不存在",
+		"docs/guide.html#nope": "锚点不存在",
+		"../outside.html":      "链接指向导出目录之外",
+		"img/none.png":         "资源文件不存在",
+	}
+	if len(report.BrokenLinks) != len(wantBroken) {
+		t.Errorf("broken links = %+v, want %d", report.BrokenLinks, len(wantBroken))
+	}
+	for _, link := range report.BrokenLinks {
+		if link.Source != "index.html" || wantBroken[link.Href] != link.Reason {
+			t.Errorf("unexpected broken link %+v", link)
+		}
+	}
+}
+
+func TestConvertLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"max files", Options{MaxFiles: 2}},
+		{"max file size", Options{MaxFileSize: 16}},
+		{"max total", Options{MaxTotal: 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Convert(context.Background(), testSite(), memWriter{}, tt.opts)
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Errorf("err = %v, want ErrLimitExceeded", err)
+			}
+		})
+	}
+}
+
+func TestConvertProgress(t *testing.T) {
+	var calls [][2]int
+	opts := Options{Progress: func(done, total int) { calls = append(calls, [2]int{done, total}) }}
+	if _, err := Convert(context.Background(), testSite(), memWriter{}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 4 || calls[3] != [2]int{3, 3} {
+		t.Errorf("progress calls = %v, want 0..3 of 3", calls)
+	}
+}
+
+func TestAnchors(t *testing.T) {
+	p := parsePage("a.html", []byte(`<p id="intro">before</p>
+<a name="x" id="empty"></a><h2 id="h">First <span id="inner">Part</span></h2>
+<p id="body">text</p>
+<h2>Second</h2><p id="later">more</p>
+<h2>First Part</h2>`))
+
+	want := map[string]string{
+		"intro": "",
+		"empty": "first-part",
+		"h":     "first-part",
+		"inner": "first-part",
+		"body":  "first-part",
+		"later": "second",
+	}
+	for id, slug := range want {
+		if got, ok := p.anchors[id]; !ok || got != slug {
+			t.Errorf("anchors[%q] = %q, want %q", id, got, slug)
+		}
+	}
+}
+
+func TestRelPath(t *testing.T) {
+	tests := []struct {
+		from, target, want string
+	}{
+		{".", "a.md", "a.md"},
+		{"docs", "docs/a.md", "a.md"},
+		{"docs", "a.md", "../a.md"},
+		{"docs/api", "docs/guide/a.md", "../guide/a.md"},
+		{"a", "b/c.md", "../b/c.md"},
+	}
+	for _, tt := range tests {
+		if got := RelPath(tt.from, tt.target); got != tt.want {
+			t.Errorf("RelPath(%q, %q) = %q, want %q", tt.from, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownPath(t *testing.T) {
+	tests := map[string]string{
+		"index.html":     "index.md",
+		"docs/a.htm":     "docs/a.md",
+		"book/ch1.xhtml": "book/ch1.md",
+		"notes.v2.html":  "notes.v2.md",
+	}
+	for in, want := range tests {
+		if got := MarkdownPath(in); got != want {
+			t.Errorf("MarkdownPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
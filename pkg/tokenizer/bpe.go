@@ -0,0 +1,212 @@
+package tokenizer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPieceBytes 单个预分词片段参与合并的最大字节数，更长的片段按字符边界切开后分别合并，避免超长单词的合并耗时过长
+const maxPieceBytes = 128
+
+// contractions 预分词时单独切出的英文缩写后缀
+var contractions = []string{"s", "t", "re", "ve", "m", "ll", "d"}
+
+// BPE 字节对编码分词器，词表为tiktoken格式，按cl100k的规则预分词
+type BPE struct {
+	name  string
+	ranks map[string]int
+}
+
+// LoadBPE 读取tiktoken格式的词表，每行为base64编码的token和合并优先级，以空格分隔
+func LoadBPE(name string, r io.Reader) (*BPE, error) {
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		token, rankText, ok := strings.Cut(text, " ")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s 第%d行格式错误", ErrInvalidVocab, name, line)
+		}
+		b, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s 第%d行: %v", ErrInvalidVocab, name, line, err)
+		}
+		rank, err := strconv.Atoi(rankText)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s 第%d行: %v", ErrInvalidVocab, name, line, err)
+		}
+		ranks[string(b)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取词表 %s 失败: %w", name, err)
+	}
+	if len(ranks) == 0 {
+		return nil, fmt.Errorf("%w: %s 为空", ErrInvalidVocab, name)
+	}
+	return &BPE{name: name, ranks: ranks}, nil
+}
+
+// Name 分词器名称
+func (b *BPE) Name() string {
+	return b.name
+}
+
+// Count 计算文本的token数量
+func (b *BPE) Count(text string) int {
+	count := 0
+	for len(text) > 0 {
+		n := matchPiece(text)
+		count += b.countPiece(text[:n])
+		text = text[n:]
+	}
+	return count
+}
+
+// countPiece 计算预分词片段的token数量
+func (b *BPE) countPiece(piece string) int {
+	if _, ok := b.ranks[piece]; ok {
+		return 1
+	}
+	count := 0
+	for len(piece) > maxPieceBytes {
+		n := maxPieceBytes
+		for n > 0 && !utf8.RuneStart(piece[n]) {
+			n--
+		}
+		count += b.merge(piece[:n])
+		piece = piece[n:]
+	}
+	return count + b.merge(piece)
+}
+
+// merge 从单个字节开始，反复合并优先级最高的相邻片段，返回最终的片段数
+func (b *BPE) merge(piece string) int {
+	type part struct {
+		start int
+		rank  int // 与下一片段合并后的优先级，无法合并时为 math.MaxInt
+	}
+	parts := make([]part, len(piece)+1)
+	for i := range parts {
+		parts[i] = part{start: i, rank: math.MaxInt}
+	}
+	pairRank := func(i int) int {
+		if i+2 >= len(parts) {
+			return math.MaxInt
+		}
+		if rank, ok := b.ranks[piece[parts[i].start:parts[i+2].start]]; ok {
+			return rank
+		}
+		return math.MaxInt
+	}
+	for i := range parts {
+		parts[i].rank = pairRank(i)
+	}
+
+	for len(parts) > 2 {
+		best := -1
+		for i := 0; i < len(parts)-1; i++ {
+			if parts[i].rank != math.MaxInt && (best < 0 || parts[i].rank < parts[best].rank) {
+				best = i
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts = slices.Delete(parts, best+1, best+2)
+		parts[best].rank = pairRank(best)
+		if best > 0 {
+			parts[best-1].rank = pairRank(best - 1)
+		}
+	}
+	return len(parts) - 1
+}
+
+// matchPiece 按cl100k的预分词规则匹配s开头的片段，返回其字节长度
+//
+// 依次尝试: 英文缩写后缀、可带一个前导符号的字母串、1至3位数字、可带一个前导空格的符号串、
+// 以换行结尾的空白、不含最后一个空白字符的空白串（最后一个空白与随后的单词合并）、其余空白。
+func matchPiece(s string) int {
+	r, size := utf8.DecodeRuneInString(s)
+
+	if r == '\'' {
+		for _, suffix := range contractions {
+			if len(s) > len(suffix) && strings.EqualFold(s[1:1+len(suffix)], suffix) {
+				return 1 + len(suffix)
+			}
+		}
+	}
+
+	if unicode.IsLetter(r) {
+		return size + runLength(s[size:], unicode.IsLetter)
+	}
+	if r != '\r' && r != '\n' && !unicode.IsNumber(r) {
+		if next, _ := utf8.DecodeRuneInString(s[size:]); unicode.IsLetter(next) {
+			return size + runLength(s[size:], unicode.IsLetter)
+		}
+	}
+
+	if unicode.IsNumber(r) {
+		n := 0
+		for i := 0; i < 3 && n < len(s); i++ {
+			d, width := utf8.DecodeRuneInString(s[n:])
+			if !unicode.IsNumber(d) {
+				break
+			}
+			n += width
+		}
+		return n
+	}
+
+	start := 0
+	if r == ' ' {
+		start = size
+	}
+	if n := runLength(s[start:], isSymbol); n > 0 {
+		end := start + n
+		return end + runLength(s[end:], func(r rune) bool { return r == '\r' || r == '\n' })
+	}
+
+	if unicode.IsSpace(r) {
+		n := runLength(s, unicode.IsSpace)
+		if last := strings.LastIndexAny(s[:n], "\r\n"); last >= 0 {
+			return last + 1
+		}
+		if n == len(s) {
+			return n
+		}
+		if _, width := utf8.DecodeLastRuneInString(s[:n]); n > width {
+			return n - width
+		}
+		return n
+	}
+	return size
+}
+
+// isSymbol 判断是否为空白、字母和数字以外的字符
+func isSymbol(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+// runLength 获取s开头连续满足fn的字符的字节长度
+func runLength(s string, fn func(rune) bool) int {
+	n := 0
+	for n < len(s) {
+		r, width := utf8.DecodeRuneInString(s[n:])
+		if !fn(r) {
+			break
+		}
+		n += width
+	}
+	return n
+}
@@ -0,0 +1,47 @@
+// Package tokenizer 计算文本的token数量，用于控制输入大模型的内容长度
+package tokenizer
+
+import "unicode"
+
+// Estimate 估算文本的token数量
+//
+// 不依赖词表，按常见BPE分词器的规律估算：空白不单独计数，英文单词约每5个字母一个token，
+// 数字每3位一个token，其他文字的单词约每2个字符一个token，中日韩字符和标点符号各计一个token。
+func Estimate(text string) int {
+	count := 0
+	letters, digits, others := 0, 0, 0
+	flush := func() {
+		count += ceilDiv(letters, 5) + ceilDiv(digits, 3) + ceilDiv(others, 2)
+		letters, digits, others = 0, 0, 0
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case isCJK(r):
+			flush()
+			count++
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || r == '\''):
+			letters++
+		case unicode.IsDigit(r):
+			digits++
+		case unicode.IsLetter(r) || unicode.IsMark(r):
+			others++
+		default:
+			flush()
+			count++
+		}
+	}
+	flush()
+	return count
+}
+
+// isCJK 判断是否为中日韩文字，这类字符通常各占一个或多个token
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// ceilDiv 向上取整的除法
+func ceilDiv(n, d int) int {
+	return (n + d - 1) / d
+}
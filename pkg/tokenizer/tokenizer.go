@@ -0,0 +1,125 @@
+package tokenizer
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+// EstimateName 内置估算器的名称，不依赖词表，始终可用
+const EstimateName = "estimate"
+
+// PreferredName 词表可用时默认使用的分词器
+const PreferredName = "cl100k_base"
+
+// 分词器名称或词表无效时返回的错误，可用 errors.Is 判断
+var (
+	ErrUnknownTokenizer = errors.New("不支持的分词器")
+	ErrInvalidVocab     = errors.New("无效的词表")
+)
+
+// Tokenizer 分词器，可在多个goroutine中并发使用
+type Tokenizer interface {
+	// Name 分词器名称
+	Name() string
+	// Count 计算文本的token数量
+	Count(text string) int
+}
+
+// vocabFS 编译时内置的词表，vocab 目录中的每个 <名称>.tiktoken 文件注册为同名的分词器
+//
+//go:embed vocab
+var vocabFS embed.FS
+
+// entry 注册的分词器，词表在首次使用时加载
+type entry struct {
+	once sync.Once
+	load func() (Tokenizer, error)
+	tok  Tokenizer
+	err  error
+}
+
+// get 加载并返回分词器
+func (e *entry) get() (Tokenizer, error) {
+	e.once.Do(func() {
+		e.tok, e.err = e.load()
+	})
+	return e.tok, e.err
+}
+
+var (
+	registry = make(map[string]*entry)
+	names    []string
+)
+
+func init() {
+	register(EstimateName, func() (Tokenizer, error) { return estimator{}, nil })
+	files, _ := fs.Glob(vocabFS, "vocab/*.tiktoken")
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".tiktoken")
+		register(name, func() (Tokenizer, error) {
+			f, err := vocabFS.Open(file)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			return LoadBPE(name, f)
+		})
+	}
+}
+
+// register 注册分词器，仅在初始化时调用
+func register(name string, load func() (Tokenizer, error)) {
+	registry[name] = &entry{load: load}
+	names = append(names, name)
+}
+
+// Names 获取可用的分词器名称
+func Names() []string {
+	return append([]string(nil), names...)
+}
+
+// DefaultName 获取默认分词器的名称，内置了 cl100k_base 词表时使用该词表，否则使用估算器
+func DefaultName() string {
+	if _, ok := registry[PreferredName]; ok {
+		return PreferredName
+	}
+	return EstimateName
+}
+
+// Get 按名称获取分词器，名称为空时返回默认分词器
+func Get(name string) (Tokenizer, error) {
+	if name == "" {
+		name = DefaultName()
+	}
+	e, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s，可选: %s", ErrUnknownTokenizer, name, strings.Join(names, ", "))
+	}
+	return e.get()
+}
+
+// Default 获取默认分词器，词表加载失败时退回估算器
+func Default() Tokenizer {
+	if tok, err := Get(""); err == nil {
+		return tok
+	}
+	return estimator{}
+}
+
+// estimator 不依赖词表的估算器
+type estimator struct{}
+
+// Name 分词器名称
+func (estimator) Name() string {
+	return EstimateName
+}
+
+// Count 估算文本的token数量
+func (estimator) Count(text string) int {
+	return Estimate(text)
+}
@@ -0,0 +1,172 @@
+package tokenizer
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"   \n\t", 0},
+		{"hello", 1},
+		{"hello world", 2},
+		{"internationalization", 4},
+		{"don't", 1},
+		{"12345", 2},
+		{"中文", 2},
+		{"你好，世界", 5},
+		{"a+b", 3},
+		{"Привет", 3},
+	}
+	for _, tt := range tests {
+		if got := Estimate(tt.text); got != tt.want {
+			t.Errorf("Estimate(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	if !slices.Contains(Names(), EstimateName) {
+		t.Fatalf("Names() = %v, want %s", Names(), EstimateName)
+	}
+	tok, err := Get(EstimateName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.Name() != EstimateName || tok.Count("hello world") != 2 {
+		t.Errorf("Get(%q) = %s counting %d", EstimateName, tok.Name(), tok.Count("hello world"))
+	}
+
+	def, err := Get("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Name() != DefaultName() || Default().Name() != DefaultName() {
+		t.Errorf("default tokenizer = %s, want %s", def.Name(), DefaultName())
+	}
+
+	if _, err := Get("gpt-unknown"); !errors.Is(err, ErrUnknownTokenizer) {
+		t.Errorf("Get(unknown) error = %v, want ErrUnknownTokenizer", err)
+	}
+}
+
+// testVocab 生成tiktoken格式的词表，按顺序分配优先级
+func testVocab(tokens ...string) string {
+	var b strings.Builder
+	for i, token := range tokens {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), i)
+	}
+	return b.String()
+}
+
+func TestLoadBPE(t *testing.T) {
+	tests := []struct {
+		name  string
+		vocab string
+	}{
+		{"empty", ""},
+		{"missing rank", "aGk=\n"},
+		{"bad base64", "!!! 1\n"},
+		{"bad rank", "aGk= x\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadBPE("test", strings.NewReader(tt.vocab)); !errors.Is(err, ErrInvalidVocab) {
+				t.Errorf("LoadBPE() error = %v, want ErrInvalidVocab", err)
+			}
+		})
+	}
+}
+
+func TestBPECount(t *testing.T) {
+	var tokens []string
+	for c := 0; c < 256; c++ {
+		tokens = append(tokens, string(rune(c)))
+	}
+	tokens = append(tokens, "lo", "low", " l", " low", "er", "low"+"er")
+	bpe, err := LoadBPE("test", strings.NewReader(testVocab(tokens...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bpe.Name() != "test" {
+		t.Errorf("Name() = %q", bpe.Name())
+	}
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"low", 1},
+		{"lower", 1},
+		{"lowest", 4},
+		{"low low", 2},
+		{"xyz", 3},
+		{strings.Repeat("a", 1000), 1000},
+	}
+	for _, tt := range tests {
+		if got := bpe.Count(tt.text); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMatchPiece(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Hello world", []string{"Hello", " world"}},
+		{"I'm here", []string{"I", "'m", " here"}},
+		{"they'LL go", []string{"they", "'LL", " go"}},
+		{"12345", []string{"123", "45"}},
+		{"a, b!", []string{"a", ",", " b", "!"}},
+		{"x  y", []string{"x", " ", " y"}},
+		{"end.\n\nNext", []string{"end", ".\n\n", "Next"}},
+		{"a \n b", []string{"a", " \n", " b"}},
+		{"trailing  ", []string{"trailing", "  "}},
+		{"中文 text", []string{"中文", " text"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for s := tt.text; len(s) > 0; {
+			n := matchPiece(s)
+			got = append(got, s[:n])
+			s = s[n:]
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("pieces of %q = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+// TestCL100K 与tiktoken的cl100k_base结果比对，需先执行 make vocab
+func TestCL100K(t *testing.T) {
+	tok, err := Get(PreferredName)
+	if errors.Is(err, ErrUnknownTokenizer) {
+		t.Skip("未内置cl100k_base词表")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"hello world", 2},
+		{"Hello, world!", 4},
+		{"tiktoken is great!", 6},
+	}
+	for _, tt := range tests {
+		if got := tok.Count(tt.text); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}